@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/korosuke613/ghacron/scheduler"
+)
+
+// historyCSVHeader is the header row of the CSV history export.
+var historyCSVHeader = []string{
+	"id", "job_id", "name", "owner", "repo", "workflow_file", "cron_expr", "ref", "trigger",
+	"scheduled_at", "dispatched_at", "error", "run_id", "run_url", "run_status", "conclusion",
+}
+
+// handleHistoryExport serves the dispatch records between the from and to
+// parameters, oldest first, as a CSV or JSON download for reporting.
+func (s *Server) handleHistoryExport(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	s.mu.RUnlock()
+
+	values := r.URL.Query()
+	format := values.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "csv" && format != "json" {
+		writeError(w, http.StatusBadRequest, "invalid format: must be one of csv, json")
+		return
+	}
+	q := scheduler.HistoryQuery{JobID: values.Get("job")}
+	var err error
+	if q.Since, err = parseTimeParam(values, "from"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if q.Until, err = parseTimeParam(values, "to"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var records []scheduler.DispatchRecord
+	if provider != nil {
+		records = provider.QueryHistory(q)
+	}
+	slices.Reverse(records)
+
+	w.Header().Set("Content-Disposition", `attachment; filename="ghacron-history.`+format+`"`)
+	if format == "json" {
+		if records == nil {
+			records = []scheduler.DispatchRecord{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(records)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writeHistoryCSV(w, records)
+}
+
+// csvFormulaPrefixes start cells that spreadsheet applications evaluate as
+// formulas.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// writeHistoryCSV writes records as CSV with historyCSVHeader. Times are
+// RFC3339 in UTC; absent values are empty. Values starting like a formula
+// (e.g. a ref or error message from a repository) are prefixed with a quote,
+// so opening the export in a spreadsheet does not evaluate them.
+func writeHistoryCSV(w http.ResponseWriter, records []scheduler.DispatchRecord) {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(historyCSVHeader)
+	for _, rec := range records {
+		var scheduledAt, runID string
+		if rec.ScheduledAt != nil {
+			scheduledAt = formatTime(*rec.ScheduledAt)
+		}
+		if rec.RunID != 0 {
+			runID = strconv.FormatInt(rec.RunID, 10)
+		}
+		row := []string{
+			strconv.FormatInt(rec.ID, 10), rec.JobID, rec.Name, rec.Owner, rec.Repo, rec.WorkflowFile,
+			rec.CronExpr, rec.Ref, rec.Trigger, scheduledAt, formatTime(rec.DispatchedAt), rec.Error,
+			runID, rec.RunURL, rec.RunStatus, rec.Conclusion,
+		}
+		for i, v := range row {
+			if v != "" && strings.ContainsRune(csvFormulaPrefixes, rune(v[0])) {
+				row[i] = "'" + v
+			}
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+}
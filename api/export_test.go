@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/config"
+	"github.com/korosuke613/ghacron/scheduler"
+)
+
+func TestHandleHistoryExport(t *testing.T) {
+	scheduledAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	provider := &fakeStatusProvider{history: []scheduler.DispatchRecord{
+		{ID: 2, JobID: "abc", Ref: "=HYPERLINK(\"x\")", Trigger: "manual", DispatchedAt: scheduledAt.Add(time.Hour), Error: "boom"},
+		{ID: 1, JobID: "abc", Ref: "main", Trigger: "schedule", ScheduledAt: &scheduledAt, DispatchedAt: scheduledAt, RunID: 42, Conclusion: "success"},
+	}}
+	s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+	s.SetStatusProvider(provider)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handleHistoryExport(rec, httptest.NewRequest(http.MethodGet, "/history/export"+query, nil))
+		return rec
+	}
+
+	rec := get("?format=csv&from=2026-03-01T00:00:00Z&to=2026-03-02T00:00:00Z")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("status %d, content type %q; want a CSV", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if q := provider.historyQuery; !q.Since.Equal(scheduledAt.Add(-9*time.Hour)) || q.Until.IsZero() || q.Limit != 0 {
+		t.Errorf("query = %+v, want from/to as since/until without a limit", q)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "id" {
+		t.Fatalf("rows = %q, want a header and 2 records", rows)
+	}
+	// Oldest first.
+	if got := rows[1]; got[0] != "1" || got[9] != "2026-03-01T09:00:00Z" || got[12] != "42" || got[15] != "success" {
+		t.Errorf("first record = %q", got)
+	}
+	if got := rows[2]; got[7] != `'=HYPERLINK("x")` || got[9] != "" || got[11] != "boom" {
+		t.Errorf("second record = %q, want the formula ref quoted", got)
+	}
+
+	rec = get("")
+	var records []scheduler.DispatchRecord
+	if err := json.NewDecoder(rec.Body).Decode(&records); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(records) != 2 || records[0].ID != 1 {
+		t.Errorf("records = %+v, want both, oldest first", records)
+	}
+
+	if rec := get("?format=xml"); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: status %d, want 400", rec.Code)
+	}
+	if rec := get("?from=yesterday"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid from: status %d, want 400", rec.Code)
+	}
+}
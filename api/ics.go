@@ -0,0 +1,210 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/korosuke613/ghacron/scheduler"
+
+	"github.com/robfig/cron/v3"
+)
+
+// icsStarBit marks a cron field given as "*" or "?" (see cron.SpecSchedule).
+const icsStarBit = 1 << 63
+
+// icsLineLimit is the maximum length of a content line in octets, excluding
+// the line break (RFC 5545, section 3.1).
+const icsLineLimit = 75
+
+// icsEscaper escapes TEXT property values (RFC 5545, section 3.3.11).
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// handleJobsICS renders the registered jobs as an iCalendar feed with a
+// recurring event per job, so schedules can be viewed in calendar apps.
+func (s *Server) handleJobsICS(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	s.mu.RUnlock()
+
+	var details []scheduler.JobDetail
+	if provider != nil {
+		details = provider.GetJobDetails()
+	}
+	slices.SortFunc(details, func(a, b scheduler.JobDetail) int { return strings.Compare(a.ID, b.ID) })
+
+	loc := s.defaultLocation()
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="ghacron.ics"`)
+	w.Write([]byte(renderICS(details, loc, time.Now())))
+}
+
+// renderICS returns the calendar of jobs. Expressions without a CRON_TZ prefix
+// are in loc. Only cron fire times are shown: stagger, splay and jitter delays
+// are not, and jobs whose schedule cannot be expressed as a recurrence rule
+// (e.g. "@every 1h") are left out.
+func renderICS(details []scheduler.JobDetail, loc *time.Location, now time.Time) string {
+	var b strings.Builder
+	line := func(s string) { writeICSLine(&b, s) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//ghacron//ghacron//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:ghacron")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, detail := range details {
+		for _, event := range icsEvents(detail, loc, now) {
+			line("BEGIN:VEVENT")
+			line("UID:" + event.uid + "@ghacron")
+			line("DTSTAMP:" + stamp)
+			line("DTSTART" + icsTime(event.start))
+			line("DURATION:PT1M")
+			line("RRULE:" + event.rrule)
+			line("SUMMARY:" + icsEscaper.Replace(icsSummary(detail)))
+			line("DESCRIPTION:" + icsEscaper.Replace(icsDescription(detail)))
+			line("END:VEVENT")
+		}
+	}
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// icsEvent is a recurring event of a job.
+type icsEvent struct {
+	uid   string
+	start time.Time
+	rrule string
+}
+
+// icsEvents returns the recurring events of a job. A schedule restricting both
+// the day of the month and the day of the week fires on either, which a single
+// recurrence rule cannot express, so it is split into an event per day field.
+func icsEvents(detail scheduler.JobDetail, loc *time.Location, now time.Time) []icsEvent {
+	sched, err := cron.ParseStandard(detail.CronExpr)
+	if err != nil {
+		return nil
+	}
+	spec, ok := sched.(*cron.SpecSchedule)
+	if !ok {
+		return nil
+	}
+	if spec.Location != time.Local {
+		loc = spec.Location
+	}
+
+	const allDom, allDow = 0xfffffffe | icsStarBit, 0x7f | icsStarBit
+	var variants []cron.SpecSchedule
+	if spec.Dom&icsStarBit == 0 && spec.Dow&icsStarBit == 0 {
+		byDom, byDow := *spec, *spec
+		byDom.Dow = allDow
+		byDow.Dom = allDom
+		variants = []cron.SpecSchedule{byDom, byDow}
+	} else {
+		variants = []cron.SpecSchedule{*spec}
+	}
+
+	var events []icsEvent
+	for i, variant := range variants {
+		variant.Location = loc
+		start := variant.Next(now.In(loc))
+		if start.IsZero() {
+			continue
+		}
+		uid := detail.ID
+		if len(variants) > 1 {
+			uid += "-" + strconv.Itoa(i+1)
+		}
+		events = append(events, icsEvent{uid: uid, start: start, rrule: icsRRule(variant)})
+	}
+	return events
+}
+
+// icsRRule returns a daily recurrence rule limited to the fields of spec.
+func icsRRule(spec cron.SpecSchedule) string {
+	parts := []string{
+		"FREQ=DAILY",
+		"BYHOUR=" + icsBits(spec.Hour, 0, 23),
+		"BYMINUTE=" + icsBits(spec.Minute, 0, 59),
+	}
+	if spec.Dom&icsStarBit == 0 {
+		parts = append(parts, "BYMONTHDAY="+icsBits(spec.Dom, 1, 31))
+	}
+	if spec.Month&icsStarBit == 0 {
+		parts = append(parts, "BYMONTH="+icsBits(spec.Month, 1, 12))
+	}
+	if spec.Dow&icsStarBit == 0 {
+		days := []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+		var by []string
+		for d := range days {
+			if spec.Dow&(1<<d) != 0 {
+				by = append(by, days[d])
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(by, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// icsBits lists the values between lo and hi set in a cron field bitset.
+func icsBits(bits uint64, lo, hi int) string {
+	var values []string
+	for v := lo; v <= hi; v++ {
+		if bits&(1<<v) != 0 {
+			values = append(values, strconv.Itoa(v))
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// icsTime formats the DTSTART value of t, in UTC or with its time zone ID.
+func icsTime(t time.Time) string {
+	if name := t.Location().String(); name != "UTC" && name != "Local" {
+		return ";TZID=" + name + ":" + t.Format("20060102T150405")
+	}
+	return ":" + t.UTC().Format("20060102T150405Z")
+}
+
+// icsSummary names a job by repository and workflow.
+func icsSummary(detail scheduler.JobDetail) string {
+	summary := fmt.Sprintf("%s/%s %s", detail.Owner, detail.Repo, detail.WorkflowFile)
+	if detail.Name != "" {
+		summary += " (" + detail.Name + ")"
+	}
+	return summary
+}
+
+// icsDescription lists the schedule and dispatch settings of a job.
+func icsDescription(detail scheduler.JobDetail) string {
+	desc := fmt.Sprintf("Cron: %s\nType: %s", detail.CronExpr, detail.DispatchType)
+	if detail.EventType != "" {
+		desc += "\nEvent: " + detail.EventType
+	}
+	if detail.Paused {
+		desc += "\nPaused"
+	}
+	return desc
+}
+
+// writeICSLine writes a content line terminated by CRLF, folding it at
+// icsLineLimit octets without splitting UTF-8 sequences.
+func writeICSLine(b *strings.Builder, s string) {
+	limit := icsLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = icsLineLimit - 1 // continuation lines start with a space
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/scheduler"
+)
+
+func TestRenderICS(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	details := []scheduler.JobDetail{
+		{ID: "a1", Owner: "myorg", Repo: "myrepo", WorkflowFile: "ci.yml", Name: "nightly", CronExpr: "0 9 * * 1-5", DispatchType: "workflow_dispatch"},
+		{ID: "b2", Owner: "myorg", Repo: "other", WorkflowFile: "report.yml", CronExpr: "CRON_TZ=UTC 30 6 1 * 0", DispatchType: "workflow_dispatch"},
+		{ID: "c3", Owner: "myorg", Repo: "other", WorkflowFile: "every.yml", CronExpr: "@every 1h"},
+	}
+
+	got := renderICS(details, tokyo, now)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:a1@ghacron\r\n",
+		"DTSTART;TZID=Asia/Tokyo:20261016T090000\r\n",
+		"RRULE:FREQ=DAILY;BYHOUR=9;BYMINUTE=0;BYDAY=MO,TU,WE,TH,FR\r\n",
+		"SUMMARY:myorg/myrepo ci.yml (nightly)\r\n",
+		// Both day fields restricted: split into an event per field.
+		"UID:b2-1@ghacron\r\n",
+		"DTSTART:20261101T063000Z\r\n",
+		"RRULE:FREQ=DAILY;BYHOUR=6;BYMINUTE=30;BYMONTHDAY=1\r\n",
+		"UID:b2-2@ghacron\r\n",
+		"DTSTART:20261018T063000Z\r\n",
+		"RRULE:FREQ=DAILY;BYHOUR=6;BYMINUTE=30;BYDAY=SU\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("calendar does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "c3") {
+		t.Errorf("calendar contains the @every job:\n%s", got)
+	}
+}
+
+func TestWriteICSLine(t *testing.T) {
+	var b strings.Builder
+	writeICSLine(&b, "SUMMARY:"+strings.Repeat("あ", 40))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("line was not folded: %q", b.String())
+	}
+	unfolded := lines[0]
+	for _, l := range lines {
+		if len(l) > icsLineLimit {
+			t.Errorf("line %q is %d octets, want at most %d", l, len(l), icsLineLimit)
+		}
+	}
+	for _, l := range lines[1:] {
+		if !strings.HasPrefix(l, " ") {
+			t.Errorf("continuation line %q does not start with a space", l)
+		}
+		unfolded += l[1:]
+	}
+	if unfolded != "SUMMARY:"+strings.Repeat("あ", 40) {
+		t.Errorf("unfolded = %q", unfolded)
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"runtime"
+	"time"
+)
+
+// runtimeStats are process statistics shown in /status, to watch for leaks
+// without attaching a profiler.
+type runtimeStats struct {
+	Goroutines     int        `json:"goroutines"`
+	HeapAllocBytes uint64     `json:"heap_alloc_bytes"`
+	HeapSysBytes   uint64     `json:"heap_sys_bytes"`
+	HeapObjects    uint64     `json:"heap_objects"`
+	GCCount        uint32     `json:"gc_count"`
+	GCPauseSeconds float64    `json:"gc_pause_total_seconds"`
+	LastGC         *time.Time `json:"last_gc,omitempty"`
+}
+
+// readRuntimeStats reads the current runtime statistics.
+func readRuntimeStats() runtimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	stats := runtimeStats{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: m.HeapAlloc,
+		HeapSysBytes:   m.HeapSys,
+		HeapObjects:    m.HeapObjects,
+		GCCount:        m.NumGC,
+		GCPauseSeconds: time.Duration(m.PauseTotalNs).Seconds(),
+	}
+	if m.LastGC > 0 {
+		last := time.Unix(0, int64(m.LastGC)).UTC()
+		stats.LastGC = &last
+	}
+	return stats
+}
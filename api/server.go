@@ -1,16 +1,24 @@
 package api
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/korosuke613/ghacron/config"
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/metrics"
 	"github.com/korosuke613/ghacron/scanner"
 	"github.com/korosuke613/ghacron/scheduler"
 )
@@ -21,6 +29,47 @@ type StatusProvider interface {
 	GetLastReconcileTime() time.Time
 	GetJobDetails() []scheduler.JobDetail
 	GetSkippedAnnotations() []scanner.SkippedAnnotation
+	GetDegradedRepos() []scanner.DegradedRepo
+	GetRepoStatuses() []scanner.RepoStatus
+	GetConflicts() []scheduler.ScheduleConflict
+	QueryHistory(q scheduler.HistoryQuery) []scheduler.DispatchRecord
+	JobStats(id string, window time.Duration) (scheduler.JobStats, error)
+	HasReconciled() bool
+	CronHeartbeat() (time.Time, time.Duration)
+	CronEntryCount() int
+}
+
+// AuthStatusProvider reports whether GitHub authentication has succeeded.
+type AuthStatusProvider interface {
+	Authenticated() bool
+}
+
+// ConnectivityChecker verifies that GitHub can be reached with the App's
+// credentials.
+type ConnectivityChecker interface {
+	CheckConnectivity(ctx context.Context) error
+}
+
+// JobController performs operator actions on registered jobs.
+type JobController interface {
+	PauseJob(ctx context.Context, id string) error
+	ResumeJob(ctx context.Context, id string) error
+	TriggerJob(ctx context.Context, id string) error
+	ResetJob(ctx context.Context, id string) error
+}
+
+// Reconciler runs a full reconcile on demand.
+type Reconciler interface {
+	ReconcileNow(ctx context.Context) (scheduler.ReconcileSummary, error)
+}
+
+// reconcileTimeout bounds an on-demand reconcile, which can take a while for
+// installations with many repositories.
+const reconcileTimeout = 5 * time.Minute
+
+// RateLimitProvider reports the GitHub API rate limit.
+type RateLimitProvider interface {
+	RateLimit() (github.RateLimit, bool)
 }
 
 // Server is the health/status API server.
@@ -29,6 +78,16 @@ type Server struct {
 	appConfig      *config.Config
 	httpServer     *http.Server
 	statusProvider StatusProvider
+	jobController  JobController
+	repoReconciler RepoReconciler
+	pushChecker    PushChecker
+	runRecorder    RunRecorder
+	reconciler     Reconciler
+	rateLimits     RateLimitProvider
+	repoListCache  RepoListCache
+	auth           AuthStatusProvider
+	connectivity   ConnectivityChecker
+	buildInfo      BuildInfo
 	startTime      time.Time
 	mu             sync.RWMutex
 }
@@ -49,6 +108,41 @@ func (s *Server) SetStatusProvider(provider StatusProvider) {
 	s.statusProvider = provider
 }
 
+// SetJobController sets the job controller.
+func (s *Server) SetJobController(controller JobController) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobController = controller
+}
+
+// SetReconciler sets the reconciler used by POST /reconcile.
+func (s *Server) SetReconciler(reconciler Reconciler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reconciler = reconciler
+}
+
+// SetAuthStatusProvider sets the GitHub authentication status provider.
+func (s *Server) SetAuthStatusProvider(provider AuthStatusProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth = provider
+}
+
+// SetConnectivityChecker sets the GitHub connectivity check of /healthz/deep.
+func (s *Server) SetConnectivityChecker(checker ConnectivityChecker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connectivity = checker
+}
+
+// SetRateLimitProvider sets the GitHub API rate limit provider.
+func (s *Server) SetRateLimitProvider(provider RateLimitProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimits = provider
+}
+
 // Start starts the API server.
 func (s *Server) Start() error {
 	if !s.config.Enabled {
@@ -56,24 +150,36 @@ func (s *Server) Start() error {
 		return nil
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", s.handleIndex)
-	mux.HandleFunc("/healthz", s.handleHealthz)
-	mux.HandleFunc("/status", s.handleStatus)
-	mux.HandleFunc("/jobs", s.handleJobs)
-	mux.HandleFunc("/config", s.handleConfig)
+	mux := s.routes()
+
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+	clientCerts := tlsConfig != nil && tlsConfig.ClientCAs != nil
+	var handler http.Handler = mux
+	if clientCerts {
+		handler = requireClientCert(mux)
+	}
 
 	addr := net.JoinHostPort(s.config.Host, fmt.Sprintf("%d", s.config.Port))
 	s.httpServer = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      handler,
+		TLSConfig:    tlsConfig,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
 
 	go func() {
-		slog.Info("API server started", "addr", addr)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		slog.Info("API server started", "addr", addr, "tls", tlsConfig != nil, "client_certs", clientCerts, "admin", s.config.AdminEnabled)
+		var err error
+		if tlsConfig != nil {
+			err = s.httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = s.httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			slog.Error("API server error", "error", err)
 		}
 	}()
@@ -81,6 +187,41 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// routes returns the API's routes. The endpoints that change what is
+// dispatched are only registered with GHACRON_WEBAPI_ADMIN_ENABLED, as the API
+// has no authentication of its own.
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/healthz/deep", s.handleDeepHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/jobs", s.handleJobs)
+	mux.HandleFunc("GET /jobs.ics", s.handleJobsICS)
+	mux.HandleFunc("GET /jobs/{id}/stats", s.handleJobStats)
+	mux.HandleFunc("/conflicts", s.handleConflicts)
+	mux.HandleFunc("GET /repos", s.handleRepos)
+	mux.HandleFunc("GET /skipped", s.handleSkipped)
+	mux.HandleFunc("/history", s.handleHistory)
+	mux.HandleFunc("GET /history/export", s.handleHistoryExport)
+	mux.HandleFunc("/config", s.handleConfig)
+	mux.HandleFunc("/version", s.handleVersion)
+	mux.HandleFunc("POST /validate", s.handleValidate)
+	mux.Handle("GET /metrics", metrics.Default.Handler())
+	if s.config.AdminEnabled {
+		mux.HandleFunc("POST /jobs/{id}/pause", s.handlePauseJob)
+		mux.HandleFunc("POST /jobs/{id}/resume", s.handleResumeJob)
+		mux.HandleFunc("POST /jobs/{id}/dispatch", s.handleDispatchJob)
+		mux.HandleFunc("POST /jobs/{id}/reset", s.handleResetJob)
+		mux.HandleFunc("POST /reconcile", s.handleReconcile)
+	}
+	if s.config.WebhookSecret != "" {
+		mux.HandleFunc("POST /webhook", s.handleWebhook)
+	}
+	return mux
+}
+
 // Stop stops the API server.
 func (s *Server) Stop() {
 	if s.httpServer == nil {
@@ -98,15 +239,41 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	endpoints := []map[string]string{
+		{"path": "/healthz", "description": "Health check"},
+		{"path": "/healthz/deep", "description": "Health check of GitHub connectivity, the cron engine and the reconcile loop"},
+		{"path": "/readyz", "description": "Readiness check (GitHub authentication and first reconcile done)"},
+		{"path": "/status", "description": "Service status (uptime, job count, last reconcile)"},
+		{"path": "/jobs", "description": "Registered cron job list"},
+		{"path": "/jobs.ics", "description": "Registered cron jobs as an iCalendar feed"},
+		{"path": "/jobs/{id}/stats", "description": "Dispatch counts, success rates, average drift and last failure of a job (?window=<duration>, default 168h)"},
+		{"path": "/conflicts", "description": "Schedules dispatching the same workflow close together"},
+		{"path": "/repos", "description": "Scan status of the scanned repositories (?failing=true, ?sort=annotations)"},
+		{"path": "/skipped", "description": "Skipped annotations grouped by repository, with counts per category (?category=<name>)"},
+		{"path": "/history", "description": "Recent dispatches and their run outcomes (?job=<id>, ?since=/?until=<RFC3339>, ?limit=<n>)"},
+		{"path": "/history/export", "description": "Dispatch records as a CSV or JSON download (?format=csv|json, ?from=/?to=<RFC3339>, ?job=<id>)"},
+		{"path": "/config", "description": "Public configuration"},
+		{"path": "/version", "description": "Build information and enabled features"},
+		{"path": "POST /validate", "description": "Validate a cron expression and list its next fire times"},
+		{"path": "/metrics", "description": "Prometheus metrics"},
+	}
+	if s.config.AdminEnabled {
+		endpoints = append(endpoints,
+			map[string]string{"path": "POST /jobs/{id}/pause", "description": "Pause dispatches of a job"},
+			map[string]string{"path": "POST /jobs/{id}/resume", "description": "Resume dispatches of a paused job"},
+			map[string]string{"path": "POST /jobs/{id}/dispatch", "description": "Dispatch a job now"},
+			map[string]string{"path": "POST /jobs/{id}/reset", "description": "Reset the circuit breaker of a job"},
+			map[string]string{"path": "POST /reconcile", "description": "Run a full reconcile now"},
+		)
+	}
+	if s.config.WebhookSecret != "" {
+		endpoints = append(endpoints, map[string]string{"path": "POST /webhook", "description": "GitHub webhook receiver"})
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"service": "ghacron",
-		"endpoints": []map[string]string{
-			{"path": "/healthz", "description": "Health check"},
-			{"path": "/status", "description": "Service status (uptime, job count, last reconcile)"},
-			{"path": "/jobs", "description": "Registered cron job list"},
-			{"path": "/config", "description": "Public configuration"},
-		},
+		"service":   "ghacron",
+		"endpoints": endpoints,
 	})
 }
 
@@ -117,13 +284,132 @@ func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Thresholds of /healthz/deep.
+const (
+	// deepHealthTimeout bounds the GitHub connectivity check.
+	deepHealthTimeout = 10 * time.Second
+	// staleFactor is how many intervals the cron heartbeat or the reconcile
+	// loop may be late before it counts as stuck.
+	staleFactor = 3
+)
+
+// handleDeepHealthz checks the subsystems needed to keep dispatching: GitHub
+// is reachable with an installation token, the cron engine is running its
+// entries, and the reconcile loop has run recently. It returns 503 with the
+// result of each check when any is degraded.
+func (s *Server) handleDeepHealthz(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	connectivity := s.connectivity
+	appCfg := s.appConfig
+	s.mu.RUnlock()
+
+	checks := map[string]string{}
+	healthy := true
+	result := func(name, reason string) {
+		if reason == "" {
+			checks[name] = "ok"
+			return
+		}
+		checks[name] = reason
+		healthy = false
+	}
+
+	if connectivity == nil {
+		result("github", "GitHub client is not configured")
+	} else {
+		ctx, cancel := context.WithTimeout(r.Context(), deepHealthTimeout)
+		defer cancel()
+		if err := connectivity.CheckConnectivity(ctx); err != nil {
+			result("github", err.Error())
+		} else {
+			result("github", "")
+		}
+	}
+
+	if provider == nil {
+		result("cron", "scheduler is not configured")
+		result("reconcile", "scheduler is not configured")
+	} else {
+		heartbeat, heartbeatInterval := provider.CronHeartbeat()
+		result("cron", staleReason("cron engine", heartbeat, heartbeatInterval))
+
+		lastReconcile := provider.GetLastReconcileTime()
+		if lastReconcile.IsZero() {
+			lastReconcile = s.startTime
+		}
+		interval := time.Duration(appCfg.Reconcile.IntervalMinutes) * time.Minute
+		result("reconcile", staleReason("reconcile loop", lastReconcile, interval))
+	}
+
+	status := "ok"
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		status = "degraded"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+// staleReason describes a subsystem that last ran at last although it should
+// run every interval, or returns "" if it ran recently enough.
+func staleReason(subsystem string, last time.Time, interval time.Duration) string {
+	age := time.Since(last)
+	if interval <= 0 || age <= staleFactor*interval {
+		return ""
+	}
+	return fmt.Sprintf("%s has not run for %s (expected every %s)", subsystem, age.Round(time.Second), interval)
+}
+
+// handleReadyz reports readiness: GitHub authentication has succeeded and the
+// first full reconcile has registered the jobs. Unlike /healthz it fails
+// during startup.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	auth := s.auth
+	s.mu.RUnlock()
+
+	reasons := []string{}
+	if auth == nil || !auth.Authenticated() {
+		reasons = append(reasons, "GitHub authentication has not succeeded")
+	}
+	if provider == nil || !provider.HasReconciled() {
+		reasons = append(reasons, "first reconcile has not completed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(reasons) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "not ready",
+			"reasons": reasons,
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "ready",
+	})
+}
+
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
 	provider := s.statusProvider
+	rateLimits := s.rateLimits
 	s.mu.RUnlock()
 
 	status := map[string]interface{}{
 		"uptime_seconds": time.Since(s.startTime).Seconds(),
+		"runtime":        readRuntimeStats(),
+	}
+
+	if rateLimits != nil {
+		if rateLimit, ok := rateLimits.RateLimit(); ok {
+			status["github_rate_limit"] = rateLimit
+		}
 	}
 
 	if provider != nil {
@@ -132,6 +418,8 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		if !lastReconcile.IsZero() {
 			status["last_reconcile"] = lastReconcile.Format(time.RFC3339)
 		}
+		status["degraded_repos"] = provider.GetDegradedRepos()
+		status["cron_entries"] = provider.CronEntryCount()
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -164,19 +452,434 @@ func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+func (s *Server) handleConflicts(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	s.mu.RUnlock()
+
+	var conflicts []scheduler.ScheduleConflict
+	if provider != nil {
+		conflicts = provider.GetConflicts()
+	}
+	if conflicts == nil {
+		conflicts = []scheduler.ScheduleConflict{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"conflicts": conflicts,
+	})
+}
+
+// skippedRepo groups the skipped annotations of a repository.
+type skippedRepo struct {
+	Owner      string                      `json:"owner"`
+	Repo       string                      `json:"repo"`
+	Count      int                         `json:"count"`
+	Categories map[string]int              `json:"categories"`
+	Skipped    []scanner.SkippedAnnotation `json:"skipped"`
+}
+
+// handleSkipped lists the skipped annotations grouped by repository, with
+// counts per reason category, repositories with the most first. With
+// category=<name> only annotations of that category are listed.
+func (s *Server) handleSkipped(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	s.mu.RUnlock()
+
+	category := r.URL.Query().Get("category")
+	var skipped []scanner.SkippedAnnotation
+	if provider != nil {
+		skipped = provider.GetSkippedAnnotations()
+	}
+
+	total := 0
+	categories := make(map[string]int)
+	repos := []*skippedRepo{}
+	byRepo := make(map[string]*skippedRepo)
+	for _, sk := range skipped {
+		if category != "" && sk.Category != category {
+			continue
+		}
+		key := sk.Owner + "/" + sk.Repo
+		repo, ok := byRepo[key]
+		if !ok {
+			repo = &skippedRepo{Owner: sk.Owner, Repo: sk.Repo, Categories: make(map[string]int)}
+			byRepo[key] = repo
+			repos = append(repos, repo)
+		}
+		repo.Count++
+		repo.Categories[sk.Category]++
+		repo.Skipped = append(repo.Skipped, sk)
+		categories[sk.Category]++
+		total++
+	}
+	slices.SortFunc(repos, func(a, b *skippedRepo) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Owner, b.Owner), strings.Compare(a.Repo, b.Repo))
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total":      total,
+		"categories": categories,
+		"repos":      repos,
+	})
+}
+
+// handleRepos lists the scan status of the scanned repositories. With
+// failing=true only repositories whose last scan failed are listed, and
+// sort=annotations orders them by descending annotation count instead of by
+// name.
+func (s *Server) handleRepos(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	s.mu.RUnlock()
+
+	query := r.URL.Query()
+	failing := false
+	if v := query.Get("failing"); v != "" {
+		var err error
+		if failing, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid failing: must be true or false")
+			return
+		}
+	}
+	sortBy := query.Get("sort")
+	if sortBy != "" && sortBy != "name" && sortBy != "annotations" {
+		writeError(w, http.StatusBadRequest, "invalid sort: must be one of name, annotations")
+		return
+	}
+
+	repos := []scanner.RepoStatus{}
+	if provider != nil {
+		for _, repo := range provider.GetRepoStatuses() {
+			if !failing || repo.LastError != "" {
+				repos = append(repos, repo)
+			}
+		}
+	}
+	if sortBy == "annotations" {
+		slices.SortStableFunc(repos, func(a, b scanner.RepoStatus) int {
+			return cmp.Compare(b.AnnotationCount, a.AnnotationCount)
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"repos": repos,
+	})
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	s.mu.RUnlock()
+
+	q, err := parseHistoryQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var records []scheduler.DispatchRecord
+	if provider != nil {
+		records = provider.QueryHistory(q)
+	}
+	if records == nil {
+		records = []scheduler.DispatchRecord{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"history": records,
+	})
+}
+
+func (s *Server) handlePauseJob(w http.ResponseWriter, r *http.Request) {
+	s.handleSetPaused(w, r, true)
+}
+
+func (s *Server) handleResumeJob(w http.ResponseWriter, r *http.Request) {
+	s.handleSetPaused(w, r, false)
+}
+
+func (s *Server) handleSetPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	s.mu.RLock()
+	controller := s.jobController
+	s.mu.RUnlock()
+
+	if controller == nil {
+		writeError(w, http.StatusServiceUnavailable, "job controller not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	var err error
+	if paused {
+		err = controller.PauseJob(r.Context(), id)
+	} else {
+		err = controller.ResumeJob(r.Context(), id)
+	}
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     id,
+		"paused": paused,
+	})
+}
+
+func (s *Server) handleDispatchJob(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	controller := s.jobController
+	s.mu.RUnlock()
+
+	if controller == nil {
+		writeError(w, http.StatusServiceUnavailable, "job controller not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := controller.TriggerJob(r.Context(), id); err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":       id,
+		"accepted": true,
+	})
+}
+
+func (s *Server) handleResetJob(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	controller := s.jobController
+	s.mu.RUnlock()
+
+	if controller == nil {
+		writeError(w, http.StatusServiceUnavailable, "job controller not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := controller.ResetJob(r.Context(), id); err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":      id,
+		"tripped": false,
+	})
+}
+
+// defaultStatsWindow is the window of /jobs/{id}/stats without a window
+// parameter.
+const defaultStatsWindow = 7 * 24 * time.Hour
+
+func (s *Server) handleJobStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	provider := s.statusProvider
+	s.mu.RUnlock()
+
+	if provider == nil {
+		writeError(w, http.StatusServiceUnavailable, "status provider not available")
+		return
+	}
+
+	window := defaultStatsWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid window: must be a positive duration such as 24h")
+			return
+		}
+		window = d
+	}
+
+	stats, err := provider.JobStats(r.PathValue("id"), window)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (s *Server) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	reconciler := s.reconciler
+	s.mu.RUnlock()
+
+	if reconciler == nil {
+		writeError(w, http.StatusServiceUnavailable, "reconciler not available")
+		return
+	}
+
+	// A full reconcile can outlast the server's write timeout; it keeps
+	// running if the client goes away.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(reconcileTimeout + 5*time.Second)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.Warn("failed to extend write deadline", "error", err)
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), reconcileTimeout)
+	defer cancel()
+
+	summary, err := reconciler.ReconcileNow(ctx)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, scheduler.ErrReconcileInProgress) {
+			status = http.StatusConflict
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// writeJobError writes the error of a job controller action.
+func writeJobError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, scheduler.ErrJobNotFound) {
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err.Error())
+}
+
+// defaultHistoryLimit is the number of records /history returns without a
+// limit parameter.
+const defaultHistoryLimit = 1000
+
+// parseHistoryQuery parses the job, since, until (RFC3339) and limit
+// parameters of /history.
+func parseHistoryQuery(values url.Values) (scheduler.HistoryQuery, error) {
+	q := scheduler.HistoryQuery{JobID: values.Get("job"), Limit: defaultHistoryLimit}
+	var err error
+	if q.Since, err = parseTimeParam(values, "since"); err != nil {
+		return q, err
+	}
+	if q.Until, err = parseTimeParam(values, "until"); err != nil {
+		return q, err
+	}
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return q, errors.New("invalid limit: must be a positive integer")
+		}
+		q.Limit = limit
+	}
+	return q, nil
+}
+
+// parseTimeParam parses an optional RFC3339 query parameter (zero if absent).
+func parseTimeParam(values url.Values, name string) (time.Time, error) {
+	v := values.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: must be an RFC3339 time", name)
+	}
+	return t, nil
+}
+
+// writeError writes a JSON error response.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
+
 // configResponse is the public configuration exposed by /config.
 // Keys correspond to GHACRON_* environment variable names (without the prefix).
 type configResponse struct {
-	AppID                 int64  `json:"app_id"`
-	IntervalMinutes       int    `json:"reconcile_interval_minutes"`
-	DuplicateGuardSeconds int    `json:"reconcile_duplicate_guard_seconds"`
-	DryRun                bool   `json:"dry_run"`
-	Timezone              string `json:"timezone"`
-	LogLevel              string `json:"log_level"`
-	LogFormat             string `json:"log_format"`
-	WebapiEnabled         bool   `json:"webapi_enabled"`
-	WebapiHost            string `json:"webapi_host"`
-	WebapiPort            int    `json:"webapi_port"`
+	AppID                    int64             `json:"app_id"`
+	GitHubRetryAttempts      int               `json:"github_retry_attempts"`
+	GitHubRetryBackoffMS     int               `json:"github_retry_backoff_ms"`
+	GitHubHTTPCache          bool              `json:"github_http_cache"`
+	GitHubCACertPath         string            `json:"github_ca_cert_path"`
+	GitHubInsecureSkip       bool              `json:"github_insecure_skip_verify"`
+	GitHubTokenTimeout       int               `json:"github_token_timeout_seconds"`
+	GitHubRepoListTTL        int               `json:"github_repo_list_ttl_seconds"`
+	GitHubUserAgentSuffix    string            `json:"github_user_agent_suffix"`
+	GitHubRateLimitPoll      int               `json:"github_rate_limit_poll_seconds"`
+	IntervalMinutes          int               `json:"reconcile_interval_minutes"`
+	DuplicateGuardSeconds    int               `json:"reconcile_duplicate_guard_seconds"`
+	DuplicateGuardMode       string            `json:"reconcile_duplicate_guard_mode"`
+	StartupReconcile         string            `json:"reconcile_startup"`
+	ConflictWindowSeconds    int               `json:"reconcile_conflict_window_seconds"`
+	DispatchSplaySeconds     int               `json:"dispatch_splay_seconds"`
+	DispatchStaggerSeconds   int               `json:"dispatch_stagger_seconds"`
+	DispatchStaggerThreshold int               `json:"dispatch_stagger_threshold"`
+	ScanGraphQL              bool              `json:"scan_graphql"`
+	ScanBackoffMaxSkips      int               `json:"scan_backoff_max_skips"`
+	ShardIndex               int               `json:"shard_index"`
+	ShardTotal               int               `json:"shard_total"`
+	RepoTopicFilter          string            `json:"repo_topic_filter"`
+	SkipForks                bool              `json:"skip_forks"`
+	Snapshot                 bool              `json:"snapshot"`
+	HistoryPersistent        bool              `json:"history_persistent"`
+	HistoryRetentionDays     int               `json:"history_retention_days"`
+	DispatchOverlap          string            `json:"dispatch_overlap"`
+	ScheduleAliases          map[string]string `json:"schedule_aliases"`
+	OwnerTimezones           map[string]string `json:"owner_timezones"`
+	DispatchTimeout          int               `json:"dispatch_timeout_seconds"`
+	ShutdownTimeout          int               `json:"shutdown_timeout_seconds"`
+	ClaimSettleSeconds       int               `json:"state_claim_settle_seconds"`
+	StateScope               string            `json:"state_scope"`
+	StateGitRepo             string            `json:"state_git_repo"`
+	StateGitBranch           string            `json:"state_git_branch"`
+	StateGitPath             string            `json:"state_git_path"`
+	StateVariablePrefix      string            `json:"state_variable_prefix"`
+	StateCacheSeconds        int               `json:"state_cache_seconds"`
+	StateGCIntervalHours     int               `json:"state_gc_interval_hours"`
+	DispatchVerify           bool              `json:"dispatch_verify"`
+	VerifyTimeoutMinutes     int               `json:"dispatch_verify_timeout_minutes"`
+	DispatchCheckRuns        bool              `json:"dispatch_check_runs"`
+	PushCheckRuns            bool              `json:"push_check_runs"`
+	MaxConcurrency           int               `json:"dispatch_max_concurrency"`
+	MaxConcurrencyPerRepo    int               `json:"dispatch_max_concurrency_per_repo"`
+	DispatchRatePerMinute    int               `json:"dispatch_rate_per_minute"`
+	DispatchRateBurst        int               `json:"dispatch_rate_burst"`
+	DispatchQueueMax         int               `json:"dispatch_queue_max"`
+	MaxJobs                  int               `json:"max_jobs"`
+	MaxJobsPerRepo           int               `json:"max_jobs_per_repo"`
+	BreakerThreshold         int               `json:"breaker_threshold"`
+	BreakerCooldown          int               `json:"breaker_cooldown_minutes"`
+	FailureIssueThreshold    int               `json:"failure_issue_threshold"`
+	DeadmanGraceSeconds      int               `json:"deadman_grace_seconds"`
+	DeadmanWebhookEnabled    bool              `json:"deadman_webhook_enabled"`
+	DryRun                   bool              `json:"dry_run"`
+	Timezone                 string            `json:"timezone"`
+	LogLevel                 string            `json:"log_level"`
+	LogFormat                string            `json:"log_format"`
+	LogSchema                string            `json:"log_schema"`
+	LogOutput                string            `json:"log_output"`
+	LogHTTP                  bool              `json:"log_http"`
+	TracingEnabled           bool              `json:"tracing_enabled"`
+	AuditLog                 bool              `json:"audit_log"`
+	NotifyEnabled            bool              `json:"notify_enabled"`
+	NotifyMinSeverity        string            `json:"notify_min_severity"`
+	NotifyRepos              []string          `json:"notify_repos"`
+	SentryEnabled            bool              `json:"sentry_enabled"`
+	WebapiEnabled            bool              `json:"webapi_enabled"`
+	WebapiHost               string            `json:"webapi_host"`
+	WebapiPort               int               `json:"webapi_port"`
+	WebhookEnabled           bool              `json:"webhook_enabled"`
+	WebapiAdminEnabled       bool              `json:"webapi_admin_enabled"`
+	WebapiTLS                bool              `json:"webapi_tls"`
+	WebapiClientCAPath       string            `json:"webapi_client_ca_path"`
 }
 
 func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
@@ -185,16 +888,82 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	s.mu.RUnlock()
 
 	resp := configResponse{
-		AppID:                 appCfg.GitHub.AppID,
-		IntervalMinutes:       appCfg.Reconcile.IntervalMinutes,
-		DuplicateGuardSeconds: appCfg.Reconcile.DuplicateGuardSeconds,
-		DryRun:                appCfg.Reconcile.DryRun,
-		Timezone:              appCfg.Reconcile.Timezone,
-		LogLevel:              appCfg.Log.Level,
-		LogFormat:             appCfg.Log.Format,
-		WebapiEnabled:         appCfg.WebAPI.Enabled,
-		WebapiHost:            appCfg.WebAPI.Host,
-		WebapiPort:            appCfg.WebAPI.Port,
+		AppID:                    appCfg.GitHub.AppID,
+		GitHubRetryAttempts:      appCfg.GitHub.RetryAttempts,
+		GitHubRetryBackoffMS:     appCfg.GitHub.RetryBackoffMillis,
+		GitHubHTTPCache:          appCfg.GitHub.HTTPCache,
+		GitHubCACertPath:         appCfg.GitHub.CACertPath,
+		GitHubInsecureSkip:       appCfg.GitHub.InsecureSkipVerify,
+		GitHubTokenTimeout:       appCfg.GitHub.TokenTimeoutSeconds,
+		GitHubRepoListTTL:        appCfg.GitHub.RepoListTTLSeconds,
+		GitHubUserAgentSuffix:    appCfg.GitHub.UserAgentSuffix,
+		GitHubRateLimitPoll:      appCfg.GitHub.RateLimitPollSeconds,
+		IntervalMinutes:          appCfg.Reconcile.IntervalMinutes,
+		DuplicateGuardSeconds:    appCfg.Reconcile.DuplicateGuardSeconds,
+		DuplicateGuardMode:       appCfg.Reconcile.DuplicateGuardMode,
+		StartupReconcile:         appCfg.Reconcile.StartupReconcile,
+		ConflictWindowSeconds:    appCfg.Reconcile.ConflictWindowSeconds,
+		DispatchSplaySeconds:     appCfg.Reconcile.DispatchSplaySeconds,
+		DispatchStaggerSeconds:   appCfg.Reconcile.DispatchStaggerSeconds,
+		DispatchStaggerThreshold: appCfg.Reconcile.DispatchStaggerThreshold,
+		ScanGraphQL:              appCfg.Reconcile.GraphQLScan,
+		ScanBackoffMaxSkips:      appCfg.Reconcile.ScanBackoffMaxSkips,
+		ShardIndex:               appCfg.Reconcile.ShardIndex,
+		ShardTotal:               appCfg.Reconcile.ShardTotal,
+		RepoTopicFilter:          appCfg.Reconcile.RepoTopicFilter,
+		SkipForks:                appCfg.Reconcile.SkipForks,
+		Snapshot:                 appCfg.Reconcile.SnapshotPath != "",
+		HistoryPersistent:        appCfg.Reconcile.HistoryPath != "",
+		HistoryRetentionDays:     appCfg.Reconcile.HistoryRetentionDays,
+		DispatchOverlap:          appCfg.Reconcile.OverlapPolicy,
+		ScheduleAliases:          appCfg.Reconcile.ScheduleAliases,
+		OwnerTimezones:           appCfg.Reconcile.OwnerTimezones,
+		DispatchTimeout:          appCfg.Reconcile.DispatchTimeoutSeconds,
+		ShutdownTimeout:          appCfg.Reconcile.ShutdownTimeoutSeconds,
+		ClaimSettleSeconds:       appCfg.Reconcile.ClaimSettleSeconds,
+		StateScope:               appCfg.Reconcile.StateScope,
+		StateGitRepo:             appCfg.Reconcile.StateGitRepo,
+		StateGitBranch:           appCfg.Reconcile.StateGitBranch,
+		StateGitPath:             appCfg.Reconcile.StateGitPath,
+		StateVariablePrefix:      appCfg.Reconcile.StateVariablePrefix,
+		StateCacheSeconds:        appCfg.Reconcile.StateCacheSeconds,
+		StateGCIntervalHours:     appCfg.Reconcile.StateGCIntervalHours,
+		DispatchVerify:           appCfg.Reconcile.VerifyDispatches,
+		VerifyTimeoutMinutes:     appCfg.Reconcile.VerifyTimeoutMinutes,
+		DispatchCheckRuns:        appCfg.Reconcile.CheckRuns,
+		PushCheckRuns:            appCfg.Reconcile.PushCheckRuns,
+		MaxConcurrency:           appCfg.Reconcile.MaxConcurrentDispatches,
+		MaxConcurrencyPerRepo:    appCfg.Reconcile.MaxConcurrentDispatchesPerRepo,
+		DispatchRatePerMinute:    appCfg.Reconcile.DispatchRatePerMinute,
+		DispatchRateBurst:        appCfg.Reconcile.DispatchRateBurst,
+		DispatchQueueMax:         appCfg.Reconcile.DispatchQueueMax,
+		MaxJobs:                  appCfg.Reconcile.MaxJobs,
+		MaxJobsPerRepo:           appCfg.Reconcile.MaxJobsPerRepo,
+		BreakerThreshold:         appCfg.Reconcile.BreakerThreshold,
+		BreakerCooldown:          appCfg.Reconcile.BreakerCooldownMinutes,
+		FailureIssueThreshold:    appCfg.Reconcile.FailureIssueThreshold,
+		DeadmanGraceSeconds:      appCfg.Reconcile.DeadmanGraceSeconds,
+		DeadmanWebhookEnabled:    appCfg.Reconcile.DeadmanWebhookURL != "",
+		DryRun:                   appCfg.Reconcile.DryRun,
+		Timezone:                 appCfg.Reconcile.Timezone,
+		LogLevel:                 appCfg.Log.Level,
+		LogFormat:                appCfg.Log.Format,
+		LogSchema:                appCfg.Log.Schema,
+		LogOutput:                appCfg.Log.Output,
+		LogHTTP:                  appCfg.Log.HTTP,
+		TracingEnabled:           appCfg.Tracing.Endpoint != "",
+		AuditLog:                 appCfg.Log.AuditPath != "",
+		NotifyEnabled:            appCfg.Notify.Enabled(),
+		NotifyMinSeverity:        appCfg.Notify.MinSeverity,
+		NotifyRepos:              appCfg.Notify.Repos,
+		SentryEnabled:            appCfg.Sentry.DSN != "",
+		WebapiEnabled:            appCfg.WebAPI.Enabled,
+		WebapiHost:               appCfg.WebAPI.Host,
+		WebapiPort:               appCfg.WebAPI.Port,
+		WebhookEnabled:           appCfg.WebAPI.WebhookSecret != "",
+		WebapiAdminEnabled:       appCfg.WebAPI.AdminEnabled,
+		WebapiTLS:                appCfg.WebAPI.TLSCertPath != "",
+		WebapiClientCAPath:       appCfg.WebAPI.ClientCAPath,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
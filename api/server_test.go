@@ -0,0 +1,389 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/config"
+	"github.com/korosuke613/ghacron/scanner"
+	"github.com/korosuke613/ghacron/scheduler"
+)
+
+type fakeReconciler struct {
+	summary scheduler.ReconcileSummary
+	err     error
+	calls   chan struct{} // receives a value per call if set
+}
+
+func (f *fakeReconciler) ReconcileNow(_ context.Context) (scheduler.ReconcileSummary, error) {
+	if f.calls != nil {
+		f.calls <- struct{}{}
+	}
+	return f.summary, f.err
+}
+
+func TestHandleReconcile(t *testing.T) {
+	tests := map[string]struct {
+		reconciler *fakeReconciler
+		wantStatus int
+	}{
+		"success": {
+			reconciler: &fakeReconciler{summary: scheduler.ReconcileSummary{Added: []string{"3f2a9c1e0b7d4a56"}, RegisteredJobs: 1}},
+			wantStatus: http.StatusOK,
+		},
+		"in progress": {
+			reconciler: &fakeReconciler{err: scheduler.ErrReconcileInProgress},
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+			s.SetReconciler(tt.reconciler)
+
+			rec := httptest.NewRecorder()
+			s.handleReconcile(rec, httptest.NewRequest(http.MethodPost, "/reconcile", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got scheduler.ReconcileSummary
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(got.Added) != 1 || got.RegisteredJobs != 1 {
+				t.Errorf("summary = %+v, want 1 added job", got)
+			}
+		})
+	}
+}
+
+func TestRoutes_AdminEndpoints(t *testing.T) {
+	for _, admin := range []bool{false, true} {
+		s := NewServer(&config.WebAPIConfig{AdminEnabled: admin}, &config.Config{})
+		s.SetReconciler(&fakeReconciler{})
+		mux := s.routes()
+		for _, path := range []string{"/reconcile", "/jobs/abc/pause", "/jobs/abc/resume", "/jobs/abc/dispatch", "/jobs/abc/reset"} {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+			// Without a job controller, the registered job endpoints answer 503.
+			if got := rec.Code != http.StatusNotFound; got != admin {
+				t.Errorf("admin=%v: POST %s status = %d, want registered = %v", admin, path, rec.Code, admin)
+			}
+		}
+	}
+}
+
+type fakeStatusProvider struct {
+	StatusProvider
+	reconciled    bool
+	heartbeat     time.Time
+	lastReconcile time.Time
+	historyQuery  scheduler.HistoryQuery
+	history       []scheduler.DispatchRecord // newest first
+	repos         []scanner.RepoStatus
+	skipped       []scanner.SkippedAnnotation
+}
+
+func (f *fakeStatusProvider) HasReconciled() bool { return f.reconciled }
+
+func (f *fakeStatusProvider) CronHeartbeat() (time.Time, time.Duration) {
+	return f.heartbeat, 30 * time.Second
+}
+
+func (f *fakeStatusProvider) GetLastReconcileTime() time.Time { return f.lastReconcile }
+
+func (f *fakeStatusProvider) GetRegisteredJobCount() int { return 2 }
+
+func (f *fakeStatusProvider) CronEntryCount() int { return 3 }
+
+func (f *fakeStatusProvider) GetDegradedRepos() []scanner.DegradedRepo { return nil }
+
+func (f *fakeStatusProvider) GetSkippedAnnotations() []scanner.SkippedAnnotation { return f.skipped }
+
+func (f *fakeStatusProvider) GetRepoStatuses() []scanner.RepoStatus { return slices.Clone(f.repos) }
+
+func (f *fakeStatusProvider) QueryHistory(q scheduler.HistoryQuery) []scheduler.DispatchRecord {
+	f.historyQuery = q
+	return slices.Clone(f.history)
+}
+
+func (f *fakeStatusProvider) JobStats(id string, window time.Duration) (scheduler.JobStats, error) {
+	if id != "abc" {
+		return scheduler.JobStats{}, scheduler.ErrJobNotFound
+	}
+	return scheduler.JobStats{JobID: id, Since: time.Now().Add(-window)}, nil
+}
+
+type fakeConnectivity struct{ err error }
+
+func (f fakeConnectivity) CheckConnectivity(context.Context) error { return f.err }
+
+type fakeAuthStatus bool
+
+func (f fakeAuthStatus) Authenticated() bool { return bool(f) }
+
+func TestHandleReadyz(t *testing.T) {
+	tests := map[string]struct {
+		authenticated bool
+		reconciled    bool
+		wantStatus    int
+	}{
+		"ready":             {authenticated: true, reconciled: true, wantStatus: http.StatusOK},
+		"not reconciled":    {authenticated: true, wantStatus: http.StatusServiceUnavailable},
+		"not authenticated": {reconciled: true, wantStatus: http.StatusServiceUnavailable},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+			s.SetStatusProvider(&fakeStatusProvider{reconciled: tt.reconciled})
+			s.SetAuthStatusProvider(fakeAuthStatus(tt.authenticated))
+
+			rec := httptest.NewRecorder()
+			s.handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandleDeepHealthz(t *testing.T) {
+	now := time.Now()
+	tests := map[string]struct {
+		connectivityErr error
+		heartbeat       time.Time
+		lastReconcile   time.Time
+		wantStatus      int
+		wantDegraded    string
+	}{
+		"healthy": {
+			heartbeat: now, lastReconcile: now.Add(-4 * time.Minute),
+			wantStatus: http.StatusOK,
+		},
+		"GitHub unreachable": {
+			connectivityErr: errors.New("failed to reach GitHub: connection refused"),
+			heartbeat:       now, lastReconcile: now,
+			wantStatus: http.StatusServiceUnavailable, wantDegraded: "github",
+		},
+		"cron engine stuck": {
+			heartbeat: now.Add(-5 * time.Minute), lastReconcile: now,
+			wantStatus: http.StatusServiceUnavailable, wantDegraded: "cron",
+		},
+		"reconcile loop stuck": {
+			heartbeat: now, lastReconcile: now.Add(-time.Hour),
+			wantStatus: http.StatusServiceUnavailable, wantDegraded: "reconcile",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer(&config.WebAPIConfig{}, &config.Config{Reconcile: config.ReconcileConfig{IntervalMinutes: 5}})
+			s.SetStatusProvider(&fakeStatusProvider{heartbeat: tt.heartbeat, lastReconcile: tt.lastReconcile})
+			s.SetConnectivityChecker(fakeConnectivity{err: tt.connectivityErr})
+
+			rec := httptest.NewRecorder()
+			s.handleDeepHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz/deep", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var got struct {
+				Status string            `json:"status"`
+				Checks map[string]string `json:"checks"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			for _, check := range []string{"github", "cron", "reconcile"} {
+				degraded := got.Checks[check] != "ok"
+				if degraded != (check == tt.wantDegraded) {
+					t.Errorf("checks[%s] = %q", check, got.Checks[check])
+				}
+			}
+		})
+	}
+}
+
+func TestHandleStatus(t *testing.T) {
+	s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+	s.SetStatusProvider(&fakeStatusProvider{})
+
+	rec := httptest.NewRecorder()
+	s.handleStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	var got struct {
+		RegisteredJobs int          `json:"registered_jobs"`
+		CronEntries    int          `json:"cron_entries"`
+		Runtime        runtimeStats `json:"runtime"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.RegisteredJobs != 2 || got.CronEntries != 3 {
+		t.Errorf("registered_jobs = %d, cron_entries = %d, want 2 and 3", got.RegisteredJobs, got.CronEntries)
+	}
+	if got.Runtime.Goroutines == 0 || got.Runtime.HeapAllocBytes == 0 {
+		t.Errorf("runtime = %+v, want goroutine and heap statistics", got.Runtime)
+	}
+}
+
+func TestHandleHistory_Query(t *testing.T) {
+	tests := map[string]struct {
+		query      string
+		wantStatus int
+		want       scheduler.HistoryQuery
+	}{
+		"defaults": {wantStatus: http.StatusOK, want: scheduler.HistoryQuery{Limit: 1000}},
+		"filters": {
+			query:      "?job=abc&since=2026-03-01T00:00:00Z&until=2026-03-08T00:00:00Z&limit=50",
+			wantStatus: http.StatusOK,
+			want: scheduler.HistoryQuery{
+				JobID: "abc",
+				Since: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+				Until: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC),
+				Limit: 50,
+			},
+		},
+		"invalid since": {query: "?since=yesterday", wantStatus: http.StatusBadRequest},
+		"invalid limit": {query: "?limit=0", wantStatus: http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			provider := &fakeStatusProvider{}
+			s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+			s.SetStatusProvider(provider)
+
+			rec := httptest.NewRecorder()
+			s.handleHistory(rec, httptest.NewRequest(http.MethodGet, "/history"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && provider.historyQuery != tt.want {
+				t.Errorf("query = %+v, want %+v", provider.historyQuery, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleRepos(t *testing.T) {
+	s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+	s.SetStatusProvider(&fakeStatusProvider{repos: []scanner.RepoStatus{
+		{Owner: "o", Repo: "a", AnnotationCount: 1},
+		{Owner: "o", Repo: "b", AnnotationCount: 5, LastError: "status=500", ConsecutiveFailures: 2},
+		{Owner: "o", Repo: "c", AnnotationCount: 3},
+	}})
+	get := func(query string) ([]string, int) {
+		req := httptest.NewRequest(http.MethodGet, "/repos"+query, nil)
+		rec := httptest.NewRecorder()
+		s.handleRepos(rec, req)
+		var resp struct {
+			Repos []scanner.RepoStatus `json:"repos"`
+		}
+		json.NewDecoder(rec.Body).Decode(&resp)
+		var names []string
+		for _, repo := range resp.Repos {
+			names = append(names, repo.Repo)
+		}
+		return names, rec.Code
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"a", "b", "c"}},
+		{"?sort=annotations", []string{"b", "c", "a"}},
+		{"?failing=true", []string{"b"}},
+	}
+	for _, tt := range tests {
+		if got, code := get(tt.query); code != http.StatusOK || !slices.Equal(got, tt.want) {
+			t.Errorf("GET /repos%s = %d %v, want %v", tt.query, code, got, tt.want)
+		}
+	}
+	for _, query := range []string{"?sort=errors", "?failing=maybe"} {
+		if _, code := get(query); code != http.StatusBadRequest {
+			t.Errorf("GET /repos%s: status %d, want 400", query, code)
+		}
+	}
+}
+
+func TestHandleSkipped(t *testing.T) {
+	s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+	s.SetStatusProvider(&fakeStatusProvider{skipped: []scanner.SkippedAnnotation{
+		{Owner: "o", Repo: "a", Category: scanner.SkipInvalidCron},
+		{Owner: "o", Repo: "b", Category: scanner.SkipInvalidCron},
+		{Owner: "o", Repo: "b", Category: scanner.SkipRequiredInputs},
+	}})
+	type response struct {
+		Total      int            `json:"total"`
+		Categories map[string]int `json:"categories"`
+		Repos      []struct {
+			Repo       string                      `json:"repo"`
+			Count      int                         `json:"count"`
+			Categories map[string]int              `json:"categories"`
+			Skipped    []scanner.SkippedAnnotation `json:"skipped"`
+		} `json:"repos"`
+	}
+	get := func(query string) response {
+		req := httptest.NewRequest(http.MethodGet, "/skipped"+query, nil)
+		rec := httptest.NewRecorder()
+		s.handleSkipped(rec, req)
+		var resp response
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("status %d, %v", rec.Code, err)
+		}
+		return resp
+	}
+
+	resp := get("")
+	if resp.Total != 3 || resp.Categories[scanner.SkipInvalidCron] != 2 || resp.Categories[scanner.SkipRequiredInputs] != 1 {
+		t.Errorf("total = %d, categories = %v; want 3 with 2 invalid_cron", resp.Total, resp.Categories)
+	}
+	// The repository with the most skipped annotations comes first.
+	if len(resp.Repos) != 2 || resp.Repos[0].Repo != "b" || resp.Repos[0].Count != 2 || len(resp.Repos[0].Skipped) != 2 || resp.Repos[1].Repo != "a" {
+		t.Errorf("repos = %+v, want b (2) then a (1)", resp.Repos)
+	}
+
+	resp = get("?category=" + scanner.SkipRequiredInputs)
+	if resp.Total != 1 || len(resp.Repos) != 1 || resp.Repos[0].Categories[scanner.SkipRequiredInputs] != 1 {
+		t.Errorf("filtered response = %+v, want the required_inputs annotation of b", resp)
+	}
+}
+
+func TestHandleJobStats(t *testing.T) {
+	s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+	s.SetStatusProvider(&fakeStatusProvider{})
+	get := func(id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/jobs/"+id+"/stats"+query, nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		s.handleJobStats(rec, req)
+		return rec
+	}
+
+	rec := get("abc", "?window=24h")
+	var stats scheduler.JobStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status %d, %v", rec.Code, err)
+	}
+	if d := time.Since(stats.Since); d < 23*time.Hour || d > 25*time.Hour {
+		t.Errorf("since = %v, want 24h ago", stats.Since)
+	}
+	if rec := get("abc", "?window=-1h"); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative window: status %d, want 400", rec.Code)
+	}
+	if rec := get("missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown job: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/config"
+)
+
+// testCert is a certificate and its key, signed by parent (self-signed if nil).
+type testCert struct {
+	cert *x509.Certificate
+	der  []byte
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, der: der, key: key}
+}
+
+// writePEM writes the certificate and key of c to dir and returns their paths.
+func (c *testCert) writePEM(t *testing.T, dir, name string) (certPath, keyPath string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath = filepath.Join(dir, name+".pem")
+	keyPath = filepath.Join(dir, name+"-key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil, true)
+	caPath, _ := ca.writePEM(t, dir, "ca")
+	serverCert := newTestCert(t, "server", ca, false)
+	certPath, keyPath := serverCert.writePEM(t, dir, "server")
+	client := newTestCert(t, "client", ca, false)
+	untrusted := newTestCert(t, "untrusted", nil, true)
+
+	s := NewServer(&config.WebAPIConfig{TLSCertPath: certPath, TLSKeyPath: keyPath, ClientCAPath: caPath}, nil)
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(requireClientCert(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(path string, certs ...tls.Certificate) int {
+		t.Helper()
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+		resp, err := c.Get(srv.URL + path)
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if got := get("/jobs", client.tlsCertificate()); got != http.StatusNoContent {
+		t.Errorf("with a trusted client certificate: status %d, want 204", got)
+	}
+	if got := get("/jobs"); got != http.StatusUnauthorized {
+		t.Errorf("without a client certificate: status %d, want 401", got)
+	}
+	// Clients only send certificates issued by a CA the server asks for.
+	if got := get("/jobs", untrusted.tlsCertificate()); got == http.StatusNoContent {
+		t.Errorf("with an untrusted client certificate: status %d, want it rejected", got)
+	}
+	if got := get("/healthz"); got != http.StatusNoContent {
+		t.Errorf("health check without a client certificate: status %d, want 204", got)
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	cert := newTestCert(t, "server", nil, false)
+	certPath, keyPath := cert.writePEM(t, dir, "server")
+	emptyPath := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(emptyPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := NewServer(&config.WebAPIConfig{}, nil).tlsConfig(); got != nil || err != nil {
+		t.Errorf("without a certificate: %v, %v; want plain HTTP", got, err)
+	}
+	got, err := NewServer(&config.WebAPIConfig{TLSCertPath: certPath, TLSKeyPath: keyPath}, nil).tlsConfig()
+	if err != nil || got.ClientAuth != tls.NoClientCert {
+		t.Errorf("without a client CA: %v, %v; want TLS without client certificates", got, err)
+	}
+	if _, err := NewServer(&config.WebAPIConfig{TLSCertPath: certPath, TLSKeyPath: keyPath, ClientCAPath: emptyPath}, nil).tlsConfig(); err == nil {
+		t.Error("expected an error for a client CA file without certificates")
+	}
+	if _, err := NewServer(&config.WebAPIConfig{TLSCertPath: certPath, TLSKeyPath: certPath}, nil).tlsConfig(); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/korosuke613/ghacron/scanner"
+
+	"github.com/robfig/cron/v3"
+)
+
+// validateRunCount is the number of upcoming fire times returned by
+// POST /validate.
+const validateRunCount = 5
+
+// maxValidateBody bounds the request body of POST /validate.
+const maxValidateBody = 64 << 10
+
+// validateParser parses expressions like the scanner: five fields with an
+// optional CRON_TZ= or TZ= prefix.
+var validateParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// validateRequest is the body of POST /validate. Job and Name seed H tokens,
+// like the -job and -name flags of the next command.
+type validateRequest struct {
+	Expression string `json:"expression"`
+	Job        string `json:"job"` // owner/repo/workflow.yml
+	Name       string `json:"name"`
+}
+
+// validateResponse is the result of POST /validate. Normalized is the
+// expression a job would be registered with: aliases and H tokens expanded,
+// whitespace collapsed.
+type validateResponse struct {
+	Valid      bool        `json:"valid"`
+	Expression string      `json:"expression"`
+	Normalized string      `json:"normalized,omitempty"`
+	Timezone   string      `json:"timezone,omitempty"`
+	NextRuns   []time.Time `json:"next_runs,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// handleValidate checks a cron expression (or @alias) without registering a
+// job, so dashboards and chat bots can validate annotation changes before
+// committing them. Invalid expressions are reported with valid=false and a
+// 200 status; only malformed requests are rejected.
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	var req validateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxValidateBody)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: expected {\"expression\": \"...\"}")
+		return
+	}
+	if strings.TrimSpace(req.Expression) == "" {
+		writeError(w, http.StatusBadRequest, "expression is required")
+		return
+	}
+	owner, rest, _ := strings.Cut(req.Job, "/")
+	repo, workflowFile, _ := strings.Cut(rest, "/")
+	if req.Job != "" && (owner == "" || repo == "" || workflowFile == "") {
+		writeError(w, http.StatusBadRequest, "invalid job: expected owner/repo/workflow.yml")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.validateExpression(req, owner, repo, workflowFile, time.Now()))
+}
+
+// validateExpression resolves and parses the expression of a request and
+// lists its next fire times after now, in the schedule's time zone.
+func (s *Server) validateExpression(req validateRequest, owner, repo, workflowFile string, now time.Time) validateResponse {
+	resp := validateResponse{Expression: req.Expression}
+	expr := strings.Join(strings.Fields(req.Expression), " ")
+
+	if alias, ok := strings.CutPrefix(expr, "@"); ok {
+		var aliases map[string]string
+		if s.appConfig != nil {
+			aliases = s.appConfig.Reconcile.ScheduleAliases
+		}
+		if expr, ok = aliases[alias]; !ok {
+			resp.Error = fmt.Sprintf("unknown schedule alias %q", "@"+alias)
+			return resp
+		}
+	}
+
+	resolved, err := scanner.ExpandHash(expr, owner, repo, workflowFile, req.Name)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	if resolved != expr && req.Job == "" {
+		resp.Error = "H tokens depend on the job: set job (owner/repo/workflow.yml) and name if the annotation has one"
+		return resp
+	}
+	expr = resolved
+
+	schedule, err := validateParser.Parse(expr)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	// Next returns times in the location of its argument, so start in the
+	// schedule's own time zone if it has one.
+	loc := s.defaultLocation()
+	if spec, ok := schedule.(*cron.SpecSchedule); ok && spec.Location != time.Local {
+		loc = spec.Location
+	}
+	resp.Valid = true
+	resp.Normalized = expr
+	resp.Timezone = loc.String()
+	for t := now.In(loc); len(resp.NextRuns) < validateRunCount; {
+		if t = schedule.Next(t); t.IsZero() {
+			break
+		}
+		resp.NextRuns = append(resp.NextRuns, t)
+	}
+	return resp
+}
+
+// defaultLocation returns the time zone of expressions without a CRON_TZ=
+// prefix (GHACRON_TIMEZONE).
+func (s *Server) defaultLocation() *time.Location {
+	if s.appConfig != nil && s.appConfig.Reconcile.Timezone != "" {
+		if loc, err := time.LoadLocation(s.appConfig.Reconcile.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
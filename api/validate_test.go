@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/config"
+)
+
+func TestValidateExpression(t *testing.T) {
+	s := NewServer(&config.WebAPIConfig{}, &config.Config{Reconcile: config.ReconcileConfig{
+		Timezone:        "Asia/Tokyo",
+		ScheduleAliases: map[string]string{"nightly": "CRON_TZ=UTC 0 2 * * *"},
+	}})
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		req            validateRequest
+		wantValid      bool
+		wantNormalized string
+		wantTimezone   string
+		wantFirst      string
+	}{
+		"bare expression": {
+			req:            validateRequest{Expression: " 0  9 * * 1-5 "},
+			wantValid:      true,
+			wantNormalized: "0 9 * * 1-5",
+			wantTimezone:   "Asia/Tokyo",
+			wantFirst:      "2026-10-16T09:00:00+09:00",
+		},
+		"CRON_TZ prefix": {
+			req:            validateRequest{Expression: "CRON_TZ=America/New_York 30 6 * * *"},
+			wantValid:      true,
+			wantNormalized: "CRON_TZ=America/New_York 30 6 * * *",
+			wantTimezone:   "America/New_York",
+			wantFirst:      "2026-10-16T06:30:00-04:00",
+		},
+		"alias": {
+			req:            validateRequest{Expression: "@nightly"},
+			wantValid:      true,
+			wantNormalized: "CRON_TZ=UTC 0 2 * * *",
+			wantTimezone:   "UTC",
+			wantFirst:      "2026-10-16T02:00:00Z",
+		},
+		"unknown alias":            {req: validateRequest{Expression: "@weekly"}},
+		"invalid expression":       {req: validateRequest{Expression: "0 25 * * *"}},
+		"unknown time zone":        {req: validateRequest{Expression: "CRON_TZ=Asia/Tokio 0 9 * * *"}},
+		"H without job":            {req: validateRequest{Expression: "H 9 * * *"}},
+		"six fields (seconds)":     {req: validateRequest{Expression: "0 0 9 * * *"}},
+		"descriptor not supported": {req: validateRequest{Expression: "@daily"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := s.validateExpression(tt.req, "", "", "", now)
+			if got.Valid != tt.wantValid {
+				t.Fatalf("valid = %v (error %q), want %v", got.Valid, got.Error, tt.wantValid)
+			}
+			if !tt.wantValid {
+				if got.Error == "" || len(got.NextRuns) != 0 {
+					t.Errorf("got %+v, want an error without next runs", got)
+				}
+				return
+			}
+			if got.Normalized != tt.wantNormalized || got.Timezone != tt.wantTimezone {
+				t.Errorf("normalized = %q, timezone = %q; want %q, %q", got.Normalized, got.Timezone, tt.wantNormalized, tt.wantTimezone)
+			}
+			if len(got.NextRuns) != validateRunCount || got.NextRuns[0].Format(time.RFC3339) != tt.wantFirst {
+				t.Errorf("next runs = %v, want %d starting at %s", got.NextRuns, validateRunCount, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestHandleValidate(t *testing.T) {
+	s := NewServer(&config.WebAPIConfig{}, &config.Config{})
+
+	tests := map[string]struct {
+		body       string
+		wantStatus int
+		wantValid  bool
+	}{
+		"valid":          {`{"expression": "0 9 * * *"}`, http.StatusOK, true},
+		"invalid":        {`{"expression": "0 9 * *"}`, http.StatusOK, false},
+		"H with job":     {`{"expression": "H 9 * * *", "job": "o/r/ci.yml"}`, http.StatusOK, true},
+		"missing":        {`{}`, http.StatusBadRequest, false},
+		"malformed body": {`0 9 * * *`, http.StatusBadRequest, false},
+		"malformed job":  {`{"expression": "H 9 * * *", "job": "o/r"}`, http.StatusBadRequest, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.handleValidate(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got validateResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got.Valid != tt.wantValid {
+				t.Errorf("valid = %v (error %q), want %v", got.Valid, got.Error, tt.wantValid)
+			}
+		})
+	}
+}
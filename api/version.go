@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/korosuke613/ghacron/config"
+)
+
+// BuildInfo identifies the running build.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// SetBuildInfo sets the build information served by /version.
+func (s *Server) SetBuildInfo(info BuildInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buildInfo = info
+}
+
+// versionResponse is the body of /version.
+type versionResponse struct {
+	BuildInfo
+	Features []string `json:"features"`
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	info := s.buildInfo
+	appCfg := s.appConfig
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versionResponse{
+		BuildInfo: info,
+		Features:  enabledFeatures(appCfg),
+	})
+}
+
+// enabledFeatures lists the optional features turned on by the configuration.
+func enabledFeatures(cfg *config.Config) []string {
+	features := []string{}
+	for _, f := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"dry_run", cfg.Reconcile.DryRun},
+		{"http_cache", cfg.GitHub.HTTPCache},
+		{"graphql_scan", cfg.Reconcile.GraphQLScan},
+		{"dispatch_verify", cfg.Reconcile.VerifyDispatches},
+		{"check_runs", cfg.Reconcile.CheckRuns},
+		{"push_check_runs", cfg.Reconcile.PushCheckRuns},
+		{"failure_issues", cfg.Reconcile.FailureIssueThreshold > 0},
+		{"circuit_breaker", cfg.Reconcile.BreakerThreshold > 0},
+		{"deadman", cfg.Reconcile.DeadmanGraceSeconds > 0},
+		{"state_claims", cfg.Reconcile.ClaimSettleSeconds > 0},
+		{"git_state", cfg.Reconcile.StateScope == "git"},
+		{"persistent_history", cfg.Reconcile.HistoryPath != ""},
+		{"webhook", cfg.WebAPI.WebhookSecret != ""},
+		{"api_tls", cfg.WebAPI.TLSCertPath != ""},
+		{"api_client_certs", cfg.WebAPI.ClientCAPath != ""},
+		{"api_admin", cfg.WebAPI.AdminEnabled},
+		{"log_http", cfg.Log.HTTP},
+		{"tracing", cfg.Tracing.Endpoint != ""},
+	} {
+		if f.enabled {
+			features = append(features, f.name)
+		}
+	}
+	return features
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/korosuke613/ghacron/config"
+)
+
+func TestHandleVersion(t *testing.T) {
+	appCfg := &config.Config{
+		GitHub:    config.GitHubConfig{HTTPCache: true},
+		Reconcile: config.ReconcileConfig{DryRun: true, BreakerThreshold: 5},
+	}
+	s := NewServer(&config.WebAPIConfig{}, appCfg)
+	s.SetBuildInfo(BuildInfo{Version: "1.2.3", Commit: "abc1234", BuildDate: "2026-10-01T00:00:00Z", GoVersion: "go1.25.0"})
+
+	rec := httptest.NewRecorder()
+	s.handleVersion(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	var got versionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Version != "1.2.3" || got.Commit != "abc1234" || got.GoVersion != "go1.25.0" {
+		t.Errorf("build info = %+v", got.BuildInfo)
+	}
+	want := []string{"dry_run", "http_cache", "circuit_breaker"}
+	if !slices.Equal(got.Features, want) {
+		t.Errorf("features = %v, want %v", got.Features, want)
+	}
+}
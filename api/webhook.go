@@ -0,0 +1,359 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/scheduler"
+)
+
+// maxWebhookPayload is GitHub's maximum webhook payload size.
+const maxWebhookPayload = 25 << 20
+
+// repoReconcileTimeout bounds a webhook-triggered repository reconcile.
+const repoReconcileTimeout = 2 * time.Minute
+
+// runRecordTimeout bounds recording the outcome of a completed run, which
+// may update its job's persisted state.
+const runRecordTimeout = 30 * time.Second
+
+// RepoReconciler re-scans a single repository on demand.
+type RepoReconciler interface {
+	ReconcileRepo(ctx context.Context, repo github.Repository) error
+}
+
+// SetRepoReconciler sets the reconciler used by the webhook receiver.
+func (s *Server) SetRepoReconciler(reconciler RepoReconciler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repoReconciler = reconciler
+}
+
+// PushChecker reports the validation of the annotations changed by a push.
+type PushChecker interface {
+	CheckPush(ctx context.Context, repo github.Repository, sha string, paths []string) error
+}
+
+// SetPushChecker sets the checker run after webhook-triggered reconciles.
+func (s *Server) SetPushChecker(checker PushChecker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pushChecker = checker
+}
+
+// RunRecorder records the outcome of completed workflow runs on the
+// dispatches that created them.
+type RunRecorder interface {
+	RecordCompletedRun(ctx context.Context, run scheduler.CompletedRun) bool
+}
+
+// SetRunRecorder sets the recorder of workflow_run events.
+func (s *Server) SetRunRecorder(recorder RunRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runRecorder = recorder
+}
+
+// RepoListCache caches the installation repository list.
+type RepoListCache interface {
+	InvalidateInstallationRepos()
+}
+
+// SetRepoListCache sets the cache invalidated by installation events.
+func (s *Server) SetRepoListCache(cache RepoListCache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repoListCache = cache
+}
+
+// pushEvent holds the fields of a push webhook payload used by ghacron.
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		Name          string   `json:"name"`
+		DefaultBranch string   `json:"default_branch"`
+		Archived      bool     `json:"archived"`
+		Fork          bool     `json:"fork"`
+		Topics        []string `json:"topics"`
+		Owner         struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Removed  []string `json:"removed"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+// touchesWorkflows reports whether the push is to the default branch and
+// changes a file under .github/workflows/.
+func (e *pushEvent) touchesWorkflows() bool {
+	if e.Ref != "refs/heads/"+e.Repository.DefaultBranch {
+		return false
+	}
+	for _, c := range e.Commits {
+		for _, files := range [][]string{c.Added, c.Removed, c.Modified} {
+			for _, f := range files {
+				if strings.HasPrefix(f, ".github/workflows/") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// changedWorkflows returns the workflow files added or modified by the push.
+func (e *pushEvent) changedWorkflows() []string {
+	var paths []string
+	for _, c := range e.Commits {
+		for _, files := range [][]string{c.Added, c.Modified} {
+			for _, f := range files {
+				if strings.HasPrefix(f, ".github/workflows/") && !slices.Contains(paths, f) {
+					paths = append(paths, f)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayload))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read payload")
+		return
+	}
+	if !validSignature(s.config.WebhookSecret, body, r.Header.Get("X-Hub-Signature-256")) {
+		slog.Warn("rejected webhook with invalid signature", "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	switch event {
+	case "ping":
+		writeWebhookResponse(w, http.StatusOK, "pong")
+	case "push":
+		s.handlePushEvent(w, body)
+	case "installation", "installation_repositories":
+		s.handleInstallationEvent(w, event, body)
+	case "workflow_run":
+		s.handleWorkflowRunEvent(w, body)
+	default:
+		writeWebhookResponse(w, http.StatusAccepted, "ignored")
+	}
+}
+
+func (s *Server) handlePushEvent(w http.ResponseWriter, body []byte) {
+	var event pushEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid push payload")
+		return
+	}
+	if !event.touchesWorkflows() {
+		writeWebhookResponse(w, http.StatusAccepted, "ignored")
+		return
+	}
+
+	s.mu.RLock()
+	reconciler := s.repoReconciler
+	checker := s.pushChecker
+	s.mu.RUnlock()
+	if reconciler == nil {
+		writeError(w, http.StatusServiceUnavailable, "reconciler not available")
+		return
+	}
+
+	repo := github.Repository{
+		Owner:         event.Repository.Owner.Login,
+		Name:          event.Repository.Name,
+		DefaultBranch: event.Repository.DefaultBranch,
+		Archived:      event.Repository.Archived,
+		Fork:          event.Repository.Fork,
+		Topics:        event.Repository.Topics,
+	}
+	// Reply within GitHub's webhook timeout; the scan runs in the background.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), repoReconcileTimeout)
+		defer cancel()
+		if err := reconciler.ReconcileRepo(ctx, repo); err != nil {
+			slog.Error("webhook-triggered reconcile failed", "error", err)
+			return
+		}
+		if checker == nil || event.After == "" {
+			return
+		}
+		if err := checker.CheckPush(ctx, repo, event.After, event.changedWorkflows()); err != nil {
+			slog.Warn("failed to post annotation check run", "owner", repo.Owner, "repo", repo.Name, "error", err)
+		}
+	}()
+
+	writeWebhookResponse(w, http.StatusAccepted, "reconciling")
+}
+
+// installationEvent holds the fields of installation and
+// installation_repositories payloads used by ghacron.
+type installationEvent struct {
+	Action       string `json:"action"`
+	Installation struct {
+		ID      int64 `json:"id"`
+		Account struct {
+			Login string `json:"login"`
+		} `json:"account"`
+	} `json:"installation"`
+}
+
+// handleInstallationEvent runs a full reconcile when the App is installed or
+// uninstalled, or repositories are added to or removed from the installation,
+// so the set of scanned repositories is updated right away.
+func (s *Server) handleInstallationEvent(w http.ResponseWriter, eventName string, body []byte) {
+	var event installationEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid installation payload")
+		return
+	}
+
+	s.mu.RLock()
+	reconciler := s.reconciler
+	cache := s.repoListCache
+	s.mu.RUnlock()
+	if reconciler == nil {
+		writeError(w, http.StatusServiceUnavailable, "reconciler not available")
+		return
+	}
+	if cache != nil {
+		cache.InvalidateInstallationRepos()
+	}
+
+	slog.Info("installation changed, reconciling",
+		"event", eventName,
+		"action", event.Action,
+		"installation_id", event.Installation.ID,
+		"account", event.Installation.Account.Login,
+	)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+		defer cancel()
+		if _, err := reconciler.ReconcileNow(ctx); err != nil {
+			// A reconcile already in progress may predate the change; the
+			// next periodic reconcile picks it up.
+			slog.Warn("webhook-triggered reconcile failed", "error", err)
+		}
+	}()
+
+	writeWebhookResponse(w, http.StatusAccepted, "reconciling")
+}
+
+// workflowRunEvent holds the fields of a workflow_run payload used by ghacron.
+type workflowRunEvent struct {
+	Action      string `json:"action"`
+	WorkflowRun struct {
+		ID              int64     `json:"id"`
+		Path            string    `json:"path"`
+		Event           string    `json:"event"`
+		HeadBranch      string    `json:"head_branch"`
+		Status          string    `json:"status"`
+		Conclusion      string    `json:"conclusion"`
+		HTMLURL         string    `json:"html_url"`
+		CreatedAt       time.Time `json:"created_at"`
+		TriggeringActor struct {
+			Type string `json:"type"`
+		} `json:"triggering_actor"`
+	} `json:"workflow_run"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// handleWorkflowRunEvent records the outcome of completed runs started by a
+// dispatch on the dispatch that created them.
+func (s *Server) handleWorkflowRunEvent(w http.ResponseWriter, body []byte) {
+	var event workflowRunEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid workflow_run payload")
+		return
+	}
+	run := event.WorkflowRun
+	if event.Action != "completed" || (run.Event != "workflow_dispatch" && run.Event != "repository_dispatch") {
+		writeWebhookResponse(w, http.StatusAccepted, "ignored")
+		return
+	}
+
+	s.mu.RLock()
+	recorder := s.runRecorder
+	s.mu.RUnlock()
+	completed := scheduler.CompletedRun{
+		Owner:        event.Repository.Owner.Login,
+		Repo:         event.Repository.Name,
+		WorkflowPath: run.Path,
+		ByBot:        run.TriggeringActor.Type == "Bot",
+		Run: github.WorkflowRun{
+			ID:         run.ID,
+			Event:      run.Event,
+			HeadBranch: run.HeadBranch,
+			Status:     run.Status,
+			Conclusion: run.Conclusion,
+			HTMLURL:    run.HTMLURL,
+			CreatedAt:  run.CreatedAt,
+		},
+	}
+	if recorder == nil {
+		writeWebhookResponse(w, http.StatusAccepted, "ignored")
+		return
+	}
+	// Reply within GitHub's webhook timeout: recording may update the job's
+	// state, which can wait for a GitHub rate limit.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), runRecordTimeout)
+		defer cancel()
+		if !recorder.RecordCompletedRun(ctx, completed) {
+			slog.Debug("workflow run not started by a recorded dispatch",
+				"owner", completed.Owner,
+				"repo", completed.Repo,
+				"workflow_path", run.Path,
+				"run_id", run.ID,
+			)
+		}
+	}()
+	writeWebhookResponse(w, http.StatusAccepted, "recording")
+}
+
+// validSignature checks an X-Hub-Signature-256 header ("sha256=<hex>")
+// against the HMAC-SHA256 of the payload.
+func validSignature(secret string, body []byte, header string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func writeWebhookResponse(w http.ResponseWriter, status int, result string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"result": result,
+	})
+}
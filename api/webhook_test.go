@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/config"
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/scheduler"
+)
+
+const testSecret = "s3cret"
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type fakeRepoReconciler struct {
+	repos chan github.Repository
+}
+
+func (f *fakeRepoReconciler) ReconcileRepo(_ context.Context, repo github.Repository) error {
+	f.repos <- repo
+	return nil
+}
+
+func TestValidSignature(t *testing.T) {
+	body := []byte(`{"zen":"hi"}`)
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"valid", sign(string(body)), true},
+		{"wrong secret", "sha256=" + strings.Repeat("00", 32), false},
+		{"missing prefix", strings.TrimPrefix(sign(string(body)), "sha256="), false},
+		{"not hex", "sha256=zz", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validSignature(testSecret, body, tt.header); got != tt.want {
+				t.Errorf("validSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleWebhook_Push(t *testing.T) {
+	const workflowPush = `{"ref":"refs/heads/main","repository":{"name":"r","default_branch":"main","owner":{"login":"o"}},"commits":[{"modified":[".github/workflows/ci.yml"]}]}`
+	const otherBranch = `{"ref":"refs/heads/dev","repository":{"name":"r","default_branch":"main","owner":{"login":"o"}},"commits":[{"modified":[".github/workflows/ci.yml"]}]}`
+	const otherFiles = `{"ref":"refs/heads/main","repository":{"name":"r","default_branch":"main","owner":{"login":"o"}},"commits":[{"added":["README.md"]}]}`
+
+	tests := []struct {
+		name          string
+		body          string
+		signature     string
+		wantStatus    int
+		wantReconcile bool
+	}{
+		{"workflow change on default branch", workflowPush, sign(workflowPush), http.StatusAccepted, true},
+		{"other branch", otherBranch, sign(otherBranch), http.StatusAccepted, false},
+		{"no workflow change", otherFiles, sign(otherFiles), http.StatusAccepted, false},
+		{"bad signature", workflowPush, sign("tampered"), http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconciler := &fakeRepoReconciler{repos: make(chan github.Repository, 1)}
+			s := NewServer(&config.WebAPIConfig{WebhookSecret: testSecret}, &config.Config{})
+			s.SetRepoReconciler(reconciler)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			req.Header.Set("X-GitHub-Event", "push")
+			req.Header.Set("X-Hub-Signature-256", tt.signature)
+			rec := httptest.NewRecorder()
+
+			s.handleWebhook(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			select {
+			case repo := <-reconciler.repos:
+				if !tt.wantReconcile {
+					t.Errorf("unexpected reconcile of %s/%s", repo.Owner, repo.Name)
+				} else if repo.Owner != "o" || repo.Name != "r" || repo.DefaultBranch != "main" {
+					t.Errorf("reconciled repo = %+v", repo)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantReconcile {
+					t.Error("expected repository reconcile")
+				}
+			}
+		})
+	}
+}
+
+type pushCheck struct {
+	sha   string
+	paths []string
+}
+
+type fakePushChecker struct {
+	checks chan pushCheck
+}
+
+func (f *fakePushChecker) CheckPush(_ context.Context, _ github.Repository, sha string, paths []string) error {
+	f.checks <- pushCheck{sha: sha, paths: paths}
+	return nil
+}
+
+func TestHandleWebhook_PushCheck(t *testing.T) {
+	const body = `{"ref":"refs/heads/main","after":"abc123","repository":{"name":"r","default_branch":"main","owner":{"login":"o"}},"commits":[{"added":[".github/workflows/new.yml"],"modified":[".github/workflows/ci.yml","README.md"]},{"modified":[".github/workflows/ci.yml"],"removed":[".github/workflows/old.yml"]}]}`
+	checker := &fakePushChecker{checks: make(chan pushCheck, 1)}
+	s := NewServer(&config.WebAPIConfig{WebhookSecret: testSecret}, &config.Config{})
+	s.SetRepoReconciler(&fakeRepoReconciler{repos: make(chan github.Repository, 1)})
+	s.SetPushChecker(checker)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-Hub-Signature-256", sign(body))
+	s.handleWebhook(httptest.NewRecorder(), req)
+
+	select {
+	case check := <-checker.checks:
+		want := []string{".github/workflows/new.yml", ".github/workflows/ci.yml"}
+		if check.sha != "abc123" || !slices.Equal(check.paths, want) {
+			t.Errorf("check = %+v, want abc123 %v", check, want)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected a push check after the reconcile")
+	}
+}
+
+type fakeRepoListCache struct{ invalidated bool }
+
+func (f *fakeRepoListCache) InvalidateInstallationRepos() { f.invalidated = true }
+
+func TestHandleWebhook_Installation(t *testing.T) {
+	const body = `{"action":"added","installation":{"id":1,"account":{"login":"o"}}}`
+	reconciler := &fakeReconciler{calls: make(chan struct{}, 1)}
+	cache := &fakeRepoListCache{}
+	s := NewServer(&config.WebAPIConfig{WebhookSecret: testSecret}, &config.Config{})
+	s.SetReconciler(reconciler)
+	s.SetRepoListCache(cache)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "installation_repositories")
+	req.Header.Set("X-Hub-Signature-256", sign(body))
+	rec := httptest.NewRecorder()
+
+	s.handleWebhook(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	select {
+	case <-reconciler.calls:
+	case <-time.After(time.Second):
+		t.Error("expected a full reconcile")
+	}
+	if !cache.invalidated {
+		t.Error("expected the repository list cache to be invalidated")
+	}
+}
+
+// fakeRunRecorder sends the runs it records and matches runs with the ID 42.
+type fakeRunRecorder struct {
+	runs        chan scheduler.CompletedRun
+	hasDeadline bool // of the last call's context; read after receiving its run
+}
+
+func (f *fakeRunRecorder) RecordCompletedRun(ctx context.Context, run scheduler.CompletedRun) bool {
+	_, f.hasDeadline = ctx.Deadline()
+	f.runs <- run
+	return run.Run.ID == 42
+}
+
+func TestHandleWebhook_WorkflowRun(t *testing.T) {
+	tests := map[string]struct {
+		body       string
+		wantResult string
+	}{
+		"dispatched run completed": {
+			body:       `{"action":"completed","workflow_run":{"id":42,"event":"workflow_dispatch","path":".github/workflows/ci.yml","head_branch":"main","conclusion":"failure","created_at":"2026-03-01T09:00:00Z","triggering_actor":{"type":"Bot"}},"repository":{"name":"r","owner":{"login":"o"}}}`,
+			wantResult: "recording",
+		},
+		"push run": {
+			body:       `{"action":"completed","workflow_run":{"id":43,"event":"push","conclusion":"success"}}`,
+			wantResult: "ignored",
+		},
+		"in progress": {
+			body:       `{"action":"in_progress","workflow_run":{"id":44,"event":"workflow_dispatch"}}`,
+			wantResult: "ignored",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer(&config.WebAPIConfig{WebhookSecret: testSecret}, &config.Config{})
+			recorder := &fakeRunRecorder{runs: make(chan scheduler.CompletedRun, 1)}
+			s.SetRunRecorder(recorder)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			req.Header.Set("X-GitHub-Event", "workflow_run")
+			req.Header.Set("X-Hub-Signature-256", sign(tt.body))
+			rec := httptest.NewRecorder()
+
+			s.handleWebhook(rec, req)
+
+			if !strings.Contains(rec.Body.String(), tt.wantResult) {
+				t.Errorf("response = %s, want result %q", rec.Body.String(), tt.wantResult)
+			}
+			if tt.wantResult == "recording" {
+				// The run is recorded after the response.
+				select {
+				case got := <-recorder.runs:
+					if got.Owner != "o" || got.WorkflowPath != ".github/workflows/ci.yml" || !got.ByBot ||
+						got.Run.Conclusion != "failure" || got.Run.CreatedAt.IsZero() {
+						t.Errorf("recorded run = %+v", got)
+					}
+					if !recorder.hasDeadline {
+						t.Error("run recorded without a deadline")
+					}
+				case <-time.After(time.Second):
+					t.Error("run was not recorded")
+				}
+			}
+		})
+	}
+}
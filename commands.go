@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/scanner"
+)
+
+// scanOutput is the JSON printed by "ghacron scan".
+type scanOutput struct {
+	Annotations []scannedAnnotation         `json:"annotations"`
+	Skipped     []scanner.SkippedAnnotation `json:"skipped"`
+	FailedRepos []string                    `json:"failed_repos"`
+}
+
+// scannedAnnotation is a valid annotation, in the format of GET /jobs.
+type scannedAnnotation struct {
+	ID           string `json:"id"`
+	Name         string `json:"name,omitempty"`
+	Owner        string `json:"owner"`
+	Repo         string `json:"repo"`
+	WorkflowFile string `json:"workflow_file"`
+	CronExpr     string `json:"cron_expr"`
+	Alias        string `json:"schedule_alias,omitempty"`
+	HashExpr     string `json:"hash_expr,omitempty"`
+	Ref          string `json:"ref"`
+	RefPattern   string `json:"refs,omitempty"`
+	Jitter       string `json:"jitter,omitempty"`
+	Overlap      string `json:"overlap,omitempty"`
+	Timeout      string `json:"timeout,omitempty"`
+	DispatchType string `json:"type"`
+	EventType    string `json:"event,omitempty"`
+	Priority     string `json:"priority,omitempty"`
+	Path         string `json:"path"`
+	Line         int    `json:"line"`
+}
+
+func newScannedAnnotation(a github.CronAnnotation) scannedAnnotation {
+	out := scannedAnnotation{
+		ID:           a.Key().ID(),
+		Name:         a.Name,
+		Owner:        a.Owner,
+		Repo:         a.Repo,
+		WorkflowFile: a.WorkflowFile,
+		CronExpr:     a.CronExpr,
+		Alias:        a.Alias,
+		HashExpr:     a.HashExpr,
+		Ref:          a.Ref,
+		RefPattern:   a.RefPattern,
+		Overlap:      a.Overlap,
+		DispatchType: github.DispatchTypeWorkflow,
+		EventType:    a.EventType,
+		Priority:     a.Priority,
+		Path:         a.Path,
+		Line:         a.Line,
+	}
+	if a.Jitter > 0 {
+		out.Jitter = a.Jitter.String()
+	}
+	if a.Timeout > 0 {
+		out.Timeout = a.Timeout.String()
+	}
+	if a.IsRepositoryDispatch() {
+		out.DispatchType = github.DispatchTypeRepository
+	}
+	return out
+}
+
+// scanInstallation scans every installation repository, exiting on failure.
+// Logs go to stderr, leaving stdout to the command's output.
+func scanInstallation(name string, args []string) *scanner.ScanResult {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	configPath := configFlag(fs)
+	_ = fs.Parse(args)
+
+	cfg := loadConfig(*configPath, os.Stderr)
+	ghClient := newGitHubClient(cfg)
+
+	sc := scanner.New(ghClient)
+	sc.SetBatchFetch(cfg.Reconcile.GraphQLScan)
+	sc.SetScheduleAliases(cfg.Reconcile.ScheduleAliases)
+	sc.SetTopicFilter(cfg.Reconcile.RepoTopicFilter)
+	sc.SetSkipForks(cfg.Reconcile.SkipForks)
+	result, err := sc.ScanAll(context.Background())
+	if err != nil {
+		slog.Error("scan failed", "error", err)
+		os.Exit(1)
+	}
+	return result
+}
+
+// failedRepoNames returns "owner/repo" of the repositories a scan could not
+// read.
+func failedRepoNames(result *scanner.ScanResult) []string {
+	names := make([]string, 0, len(result.FailedRepos))
+	for _, repo := range result.FailedRepos {
+		names = append(names, repo.Owner+"/"+repo.Name)
+	}
+	return names
+}
+
+// scan prints the annotations found in the installation repositories as JSON.
+// It exits with status 1 if some repositories could not be scanned.
+func scan(args []string) {
+	result := scanInstallation("scan", args)
+
+	out := scanOutput{
+		Annotations: make([]scannedAnnotation, 0, len(result.Annotations)),
+		Skipped:     result.Skipped,
+		FailedRepos: failedRepoNames(result),
+	}
+	for _, a := range result.Annotations {
+		out.Annotations = append(out.Annotations, newScannedAnnotation(a))
+	}
+	if out.Skipped == nil {
+		out.Skipped = []scanner.SkippedAnnotation{}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		slog.Error("failed to write output", "error", err)
+		os.Exit(1)
+	}
+	if len(out.FailedRepos) > 0 {
+		os.Exit(1)
+	}
+}
+
+// validate prints the invalid annotations of the installation repositories
+// and exits with status 1 if there are any, or if some repositories could not
+// be scanned.
+func validate(args []string) {
+	result := scanInstallation("validate", args)
+
+	for _, sk := range result.Skipped {
+		fmt.Printf("%s/%s %s:%d: %s: %s\n", sk.Owner, sk.Repo, sk.Path, sk.Line, sk.CronExpr, sk.Reason)
+	}
+	failed := failedRepoNames(result)
+	for _, name := range failed {
+		fmt.Printf("%s: could not be scanned\n", name)
+	}
+	if len(result.Skipped) > 0 || len(failed) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("%d annotations are valid\n", len(result.Annotations))
+}
+
+// dispatch triggers a workflow_dispatch of a workflow once, on the default
+// branch of the repository unless -ref is given. The state of ghacron's jobs
+// (duplicate guard, history) is not touched.
+func dispatch(args []string) {
+	fs := flag.NewFlagSet("dispatch", flag.ExitOnError)
+	configPath := configFlag(fs)
+	ref := fs.String("ref", "", "branch or tag to dispatch on (default: the repository's default branch)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ghacron dispatch [flags] owner/repo workflow.yml")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	owner, repo, ok := strings.Cut(fs.Arg(0), "/")
+	if fs.NArg() != 2 || !ok || owner == "" || repo == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	workflowFile := fs.Arg(1)
+
+	cfg := loadConfig(*configPath, os.Stderr)
+	ghClient := newGitHubClient(cfg)
+	ctx := context.Background()
+
+	if *ref == "" {
+		branch, err := defaultBranch(ctx, ghClient, owner, repo)
+		if err != nil {
+			slog.Error("failed to resolve default branch", "owner", owner, "repo", repo, "error", err)
+			os.Exit(1)
+		}
+		*ref = branch
+	}
+
+	if err := ghClient.DispatchWorkflow(ctx, owner, repo, workflowFile, *ref, ""); err != nil {
+		slog.Error("dispatch failed", "owner", owner, "repo", repo, "workflow_file", workflowFile, "ref", *ref, "error", err)
+		os.Exit(1)
+	}
+	fmt.Printf("dispatched %s on %s/%s@%s\n", workflowFile, owner, repo, *ref)
+}
+
+// defaultBranch returns the default branch of an installation repository.
+func defaultBranch(ctx context.Context, ghClient *github.Client, owner, repo string) (string, error) {
+	repos, err := ghClient.GetInstallationRepos(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range repos {
+		if strings.EqualFold(r.Owner, owner) && strings.EqualFold(r.Name, repo) {
+			return r.DefaultBranch, nil
+		}
+	}
+	return "", fmt.Errorf("%s/%s is not accessible to the GitHub App installation", owner, repo)
+}
@@ -1,42 +1,211 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/korosuke613/ghacron/logging"
+	"github.com/korosuke613/ghacron/secrets"
+
+	"github.com/robfig/cron/v3"
 )
 
+// variablePrefixPattern matches prefixes allowed in Actions variable names.
+var variablePrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// scheduleAliasPattern matches schedule alias names.
+var scheduleAliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// cronParser validates schedule alias expressions like the scanner validates
+// annotations.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
 // Config represents the entire application configuration.
 type Config struct {
 	GitHub    GitHubConfig
 	Reconcile ReconcileConfig
 	Log       LogConfig
+	Tracing   TracingConfig
+	Notify    NotifyConfig
+	Sentry    SentryConfig
 	WebAPI    WebAPIConfig
 }
 
-// GitHubConfig holds GitHub App credentials.
+// GitHubConfig holds GitHub App credentials and API client settings.
 type GitHubConfig struct {
 	AppID          int64
 	PrivateKey     string
 	PrivateKeyPath string
+	// Retries of API requests failing with network errors or 5xx responses.
+	RetryAttempts      int
+	RetryBackoffMillis int
+	// Revalidate cached GET responses with ETags (304s are not rate limited).
+	HTTPCache bool
+	// CACertPath is a PEM bundle trusted in addition to the system roots
+	// (e.g. a GHES instance or a TLS-intercepting proxy with a private CA).
+	CACertPath         string
+	InsecureSkipVerify bool
+	// Timeout of each request of an installation token refresh.
+	TokenTimeoutSeconds int
+	// RepoListTTLSeconds caches the installation repository list for this
+	// long (0 = list it on every full reconcile).
+	RepoListTTLSeconds int
+	// RateLimitPollSeconds polls GET /rate_limit this often so the reported
+	// rate limit stays current while idle (0 = disabled).
+	RateLimitPollSeconds int
+	// UserAgentSuffix is appended to the "ghacron/<version>" User-Agent,
+	// e.g. to tell deployments apart in audit logs.
+	UserAgentSuffix string
 }
 
 // ReconcileConfig holds reconciliation loop settings.
 type ReconcileConfig struct {
-	IntervalMinutes       int
+	IntervalMinutes int
+	// StartupReconcile selects when the reconcile loop first reconciles:
+	// "immediate", "delay" (after a random delay up to the interval) or
+	// "skip" (at the first tick), so restarting replicas do not burst.
+	StartupReconcile      string
 	DuplicateGuardSeconds int
+	// DuplicateGuardMode selects how recent dispatches are detected:
+	// "variable" (state variables) or "runs" (recent workflow runs, for
+	// repositories where variables cannot be written).
+	DuplicateGuardMode    string
 	DryRun                bool
 	Timezone              string
+	ConflictWindowSeconds int
+	DispatchSplaySeconds  int
+	// DispatchStaggerSeconds offsets the cron entries of jobs sharing a cron
+	// expression with at least DispatchStaggerThreshold jobs by a stable
+	// delay below this many seconds (0 disables).
+	DispatchStaggerSeconds   int
+	DispatchStaggerThreshold int
+	// ShardIndex and ShardTotal split the installation's repositories among
+	// replicas: each schedules only the repositories whose hash modulo
+	// ShardTotal is its ShardIndex.
+	ShardIndex int
+	ShardTotal int
+	// RepoTopicFilter, if set, restricts scanning to repositories carrying
+	// this topic.
+	RepoTopicFilter string
+	// SkipForks excludes forked repositories from scanning.
+	SkipForks bool
+	// ScanBackoffMaxSkips caps the number of full scans that skip a
+	// repository after consecutive scan failures (0 retries every scan).
+	ScanBackoffMaxSkips int
+	// GraphQLScan fetches workflow files of all repositories with batched
+	// GraphQL queries during full scans instead of per-repository REST calls.
+	GraphQLScan bool
+	// ScheduleAliases maps alias names to cron expressions, which annotations
+	// reference as "@<name>" so schedules can be changed centrally.
+	ScheduleAliases map[string]string
+	// OwnerTimezones maps repository owners (lower case) to the time zone of
+	// their expressions without a CRON_TZ= prefix, instead of Timezone.
+	OwnerTimezones map[string]string
+	// OverlapPolicy is the default for the overlap= annotation option:
+	// "skip" skips a dispatch while a previous run is still active.
+	OverlapPolicy string
+	// DispatchTimeoutSeconds bounds the GitHub API calls of a single job run.
+	DispatchTimeoutSeconds int
+	// Dispatch verification: follow the created workflow run and record its outcome.
+	VerifyDispatches     bool
+	VerifyTimeoutMinutes int
+	// CheckRuns posts a check run with the dispatch result on the dispatched ref.
+	CheckRuns bool
+	// PushCheckRuns posts a check run validating the annotations of workflow
+	// files changed by webhook pushes to the default branch.
+	PushCheckRuns bool
+	// ClaimSettleSeconds is how long a replica waits after claiming a dispatch
+	// before verifying it still owns the claim (0 = no claim, single instance).
+	ClaimSettleSeconds int
+	// StateScope selects where per-job state is stored: "repo" (repository
+	// variables), "org" (organization variables of the repository owner) or
+	// "git" (a JSON file committed to a branch of StateGitRepo).
+	StateScope string
+	// StateGitRepo is the "owner/repo" repository holding the state file of
+	// the git scope.
+	StateGitRepo string
+	// StateGitBranch is the branch the state file is committed to; it is
+	// created as an orphan branch on the first write.
+	StateGitBranch string
+	// StateGitPath is the path of the state file in StateGitBranch.
+	StateGitPath string
+	// StateVariablePrefix prefixes the names of state variables, so several
+	// instances can share repositories (default "GHACRON_").
+	StateVariablePrefix string
+	// StateCacheSeconds is how long state variable values are cached in
+	// memory (0 = no cache).
+	StateCacheSeconds int
+	// StateGCIntervalHours is how often stale state variables of removed jobs
+	// are deleted (0 = never).
+	StateGCIntervalHours int
+	// SnapshotPath is the file the registered jobs are saved to after each
+	// reconcile and restored from at startup ("" = no snapshot).
+	SnapshotPath string
+	// HistoryPath is the file dispatch history is persisted to, so it
+	// survives restarts ("" = in memory only, last 1000 dispatches).
+	HistoryPath string
+	// HistoryRetentionDays is how long dispatch records are kept in the
+	// history file.
+	HistoryRetentionDays int
+	// ShutdownTimeoutSeconds bounds how long shutdown waits for in-flight dispatches.
+	ShutdownTimeoutSeconds int
+	// Dispatch concurrency limits (0 = unlimited).
+	MaxConcurrentDispatches        int
+	MaxConcurrentDispatchesPerRepo int
+	// DispatchRatePerMinute limits the job runs started per minute (0 =
+	// unlimited); runs beyond it queue. DispatchRateBurst runs may start at
+	// once before the rate applies.
+	DispatchRatePerMinute int
+	DispatchRateBurst     int
+	// DispatchQueueMax bounds the runs queued for the dispatch rate (0 =
+	// unlimited). When it is full, the lowest priority run is shed.
+	DispatchQueueMax int
+	// Registered job limits (0 = unlimited). Annotations beyond them are
+	// skipped.
+	MaxJobs        int
+	MaxJobsPerRepo int
+	// Circuit breaker: consecutive failed dispatches before a job is tripped
+	// (0 = disabled), and how long it stays tripped.
+	BreakerThreshold       int
+	BreakerCooldownMinutes int
+	// FailureIssueThreshold is the number of consecutive failed dispatches
+	// after which an issue is opened (or updated) in the target repository
+	// (0 = disabled).
+	FailureIssueThreshold int
+	// Dead-man alerting (0 = disabled).
+	DeadmanGraceSeconds int
+	DeadmanWebhookURL   string
 }
 
 // LogConfig holds logging settings.
 type LogConfig struct {
 	Level  string
 	Format string
+	// Schema names the fields of log records: "slog" keeps the keys of
+	// log/slog (time, level, msg), "ecs" uses Elastic Common Schema names.
+	Schema string
+	// Output is where logs are written: "stdout" (stderr for the CLI
+	// subcommands), "syslog" or "journald".
+	Output string
+	// SyslogAddress is the syslog server of the syslog output, e.g.
+	// "udp://host:514" or "unix:///dev/log".
+	SyslogAddress string
+	// HTTP logs every GitHub request at debug level.
+	HTTP bool
+	// AuditPath is the file receiving the audit log of dispatch decisions
+	// ("" disables it).
+	AuditPath string
 }
 
 // SlogLevel converts the Level string to slog.Level.
@@ -53,75 +222,108 @@ func (lc *LogConfig) SlogLevel() slog.Level {
 	}
 }
 
+// NotifyConfig holds notification settings. Notifications are disabled when
+// no webhook URL is set.
+type NotifyConfig struct {
+	SlackWebhookURL   string
+	DiscordWebhookURL string
+	WebhookURL        string // generic webhook receiving events as JSON
+	// MinSeverity is the lowest severity notified: "info", "warning" or "error".
+	MinSeverity string
+	// Repos limits notifications about repositories to those matching these
+	// "owner/repo" patterns (empty = all).
+	Repos []string
+}
+
+// Enabled reports whether any notification target is configured.
+func (nc *NotifyConfig) Enabled() bool {
+	return nc.SlackWebhookURL != "" || nc.DiscordWebhookURL != "" || nc.WebhookURL != ""
+}
+
+// SentryConfig holds error reporting settings. Reporting is disabled when
+// DSN is empty.
+type SentryConfig struct {
+	DSN         string
+	Environment string
+	// DispatchFailureThreshold is the number of consecutive failed dispatches
+	// of a job after which it is reported.
+	DispatchFailureThreshold int
+}
+
+// TracingConfig holds tracing settings.
+type TracingConfig struct {
+	// Endpoint is the OTLP/HTTP endpoint spans are exported to ("" = tracing
+	// disabled).
+	Endpoint string
+}
+
 // WebAPIConfig holds web API server settings.
 type WebAPIConfig struct {
 	Enabled bool
 	Host    string
 	Port    int
+	// WebhookSecret enables POST /webhook when set.
+	WebhookSecret string
+	// AdminEnabled enables the endpoints that change what is dispatched:
+	// POST /jobs/{id}/pause, /resume, /dispatch, /reset and POST /reconcile.
+	AdminEnabled bool
+	// TLSCertPath and TLSKeyPath serve the API over HTTPS when set.
+	TLSCertPath string
+	TLSKeyPath  string
+	// ClientCAPath is a PEM bundle of the CAs whose client certificates are
+	// required on every endpoint except health checks and the webhook.
+	ClientCAPath string
 }
 
 // Load reads configuration from GHACRON_* environment variables.
 func Load() (*Config, error) {
-	appID, err := envInt64("GHACRON_APP_ID", 0)
-	if err != nil {
-		return nil, fmt.Errorf("invalid GHACRON_APP_ID: %w", err)
-	}
+	return load(&source{})
+}
 
-	intervalMinutes, err := envInt("GHACRON_RECONCILE_INTERVAL_MINUTES", 5)
+// load reads the configuration from src.
+func load(src *source) (*Config, error) {
+	github, err := loadGitHub(src)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GHACRON_RECONCILE_INTERVAL_MINUTES: %w", err)
+		return nil, err
 	}
-
-	duplicateGuardSeconds, err := envInt("GHACRON_RECONCILE_DUPLICATE_GUARD_SECONDS", 60)
+	reconcile, err := loadReconcile(src)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GHACRON_RECONCILE_DUPLICATE_GUARD_SECONDS: %w", err)
+		return nil, err
 	}
-
-	dryRun, err := envBool("GHACRON_DRY_RUN", false)
+	log, err := loadLog(src)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GHACRON_DRY_RUN: %w", err)
+		return nil, err
 	}
-
-	timezone := envStr("GHACRON_TIMEZONE", "UTC")
-
-	logLevel := envStr("GHACRON_LOG_LEVEL", "info")
-	logFormat := envStr("GHACRON_LOG_FORMAT", "json")
-
-	webapiEnabled, err := envBool("GHACRON_WEBAPI_ENABLED", true)
+	sentry, err := loadSentry(src)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GHACRON_WEBAPI_ENABLED: %w", err)
+		return nil, err
 	}
-
-	webapiHost := envStr("GHACRON_WEBAPI_HOST", "0.0.0.0")
-
-	webapiPort, err := envInt("GHACRON_WEBAPI_PORT", 8080)
+	webAPI, err := loadWebAPI(src)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GHACRON_WEBAPI_PORT: %w", err)
+		return nil, err
 	}
 
 	config := &Config{
-		GitHub: GitHubConfig{
-			AppID:          appID,
-			PrivateKey:     os.Getenv("GHACRON_APP_PRIVATE_KEY"),
-			PrivateKeyPath: os.Getenv("GHACRON_APP_PRIVATE_KEY_PATH"),
+		GitHub:    github,
+		Reconcile: reconcile,
+		Log:       log,
+		Tracing: TracingConfig{
+			Endpoint: src.get("GHACRON_TRACING_ENDPOINT"),
 		},
-		Reconcile: ReconcileConfig{
-			IntervalMinutes:       intervalMinutes,
-			DuplicateGuardSeconds: duplicateGuardSeconds,
-			DryRun:                dryRun,
-			Timezone:              timezone,
-		},
-		Log: LogConfig{
-			Level:  logLevel,
-			Format: logFormat,
-		},
-		WebAPI: WebAPIConfig{
-			Enabled: webapiEnabled,
-			Host:    webapiHost,
-			Port:    webapiPort,
+		Notify: NotifyConfig{
+			SlackWebhookURL:   src.get("GHACRON_NOTIFY_SLACK_WEBHOOK_URL"),
+			DiscordWebhookURL: src.get("GHACRON_NOTIFY_DISCORD_WEBHOOK_URL"),
+			WebhookURL:        src.get("GHACRON_NOTIFY_WEBHOOK_URL"),
+			MinSeverity:       src.envStr("GHACRON_NOTIFY_MIN_SEVERITY", "warning"),
+			Repos:             parseList(src.get("GHACRON_NOTIFY_REPOS")),
 		},
+		Sentry: sentry,
+		WebAPI: webAPI,
 	}
 
+	if err := src.checkUnknownKeys(); err != nil {
+		return nil, err
+	}
 	if err := config.validate(); err != nil {
 		return nil, err
 	}
@@ -129,11 +331,272 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
+// loadGitHub reads the GitHub App credentials and API client settings.
+func loadGitHub(src *source) (GitHubConfig, error) {
+	c := GitHubConfig{
+		PrivateKey:      src.get("GHACRON_APP_PRIVATE_KEY"),
+		PrivateKeyPath:  src.get("GHACRON_APP_PRIVATE_KEY_PATH"),
+		CACertPath:      src.get("GHACRON_GITHUB_CA_CERT_PATH"),
+		UserAgentSuffix: src.get("GHACRON_GITHUB_USER_AGENT_SUFFIX"),
+	}
+	var err error
+
+	if c.AppID, err = src.envInt64("GHACRON_APP_ID", 0); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_APP_ID: %w", err)
+	}
+	if c.RetryAttempts, err = src.envInt("GHACRON_GITHUB_RETRY_ATTEMPTS", 3); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_GITHUB_RETRY_ATTEMPTS: %w", err)
+	}
+	if c.RetryBackoffMillis, err = src.envInt("GHACRON_GITHUB_RETRY_BACKOFF_MS", 500); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_GITHUB_RETRY_BACKOFF_MS: %w", err)
+	}
+	if c.HTTPCache, err = src.envBool("GHACRON_GITHUB_HTTP_CACHE", true); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_GITHUB_HTTP_CACHE: %w", err)
+	}
+	if c.InsecureSkipVerify, err = src.envBool("GHACRON_GITHUB_INSECURE_SKIP_VERIFY", false); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_GITHUB_INSECURE_SKIP_VERIFY: %w", err)
+	}
+	if c.TokenTimeoutSeconds, err = src.envInt("GHACRON_GITHUB_TOKEN_TIMEOUT_SECONDS", 30); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_GITHUB_TOKEN_TIMEOUT_SECONDS: %w", err)
+	}
+	if c.RepoListTTLSeconds, err = src.envInt("GHACRON_GITHUB_REPO_LIST_TTL_SECONDS", 0); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_GITHUB_REPO_LIST_TTL_SECONDS: %w", err)
+	}
+	if c.RateLimitPollSeconds, err = src.envInt("GHACRON_GITHUB_RATE_LIMIT_POLL_SECONDS", 0); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_GITHUB_RATE_LIMIT_POLL_SECONDS: %w", err)
+	}
+	return c, nil
+}
+
+// loadReconcile reads the reconciliation loop settings, grouped like the
+// README: scanning, dispatching, state and job limits.
+func loadReconcile(src *source) (ReconcileConfig, error) {
+	var c ReconcileConfig
+	if err := loadScan(src, &c); err != nil {
+		return c, err
+	}
+	if err := loadDispatch(src, &c); err != nil {
+		return c, err
+	}
+	if err := loadState(src, &c); err != nil {
+		return c, err
+	}
+	if err := loadLimits(src, &c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// loadScan reads the settings of the reconcile loop and repository scanning.
+func loadScan(src *source, c *ReconcileConfig) error {
+	c.StartupReconcile = src.envStr("GHACRON_RECONCILE_STARTUP", "immediate")
+	c.DuplicateGuardMode = src.envStr("GHACRON_RECONCILE_DUPLICATE_GUARD_MODE", "variable")
+	c.Timezone = src.envStr("GHACRON_TIMEZONE", "UTC")
+	c.RepoTopicFilter = src.get("GHACRON_REPO_TOPIC_FILTER")
+	var err error
+
+	if c.IntervalMinutes, err = src.envInt("GHACRON_RECONCILE_INTERVAL_MINUTES", 5); err != nil {
+		return fmt.Errorf("invalid GHACRON_RECONCILE_INTERVAL_MINUTES: %w", err)
+	}
+	if c.DuplicateGuardSeconds, err = src.envInt("GHACRON_RECONCILE_DUPLICATE_GUARD_SECONDS", 60); err != nil {
+		return fmt.Errorf("invalid GHACRON_RECONCILE_DUPLICATE_GUARD_SECONDS: %w", err)
+	}
+	if c.ConflictWindowSeconds, err = src.envInt("GHACRON_RECONCILE_CONFLICT_WINDOW_SECONDS", 300); err != nil {
+		return fmt.Errorf("invalid GHACRON_RECONCILE_CONFLICT_WINDOW_SECONDS: %w", err)
+	}
+	if c.ScheduleAliases, err = ParseScheduleAliases(src.get("GHACRON_SCHEDULE_ALIASES")); err != nil {
+		return fmt.Errorf("invalid GHACRON_SCHEDULE_ALIASES: %w", err)
+	}
+	if c.OwnerTimezones, err = ParseOwnerTimezones(src.get("GHACRON_OWNER_TIMEZONES")); err != nil {
+		return fmt.Errorf("invalid GHACRON_OWNER_TIMEZONES: %w", err)
+	}
+	if c.ShardIndex, err = src.envInt("GHACRON_SHARD_INDEX", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_SHARD_INDEX: %w", err)
+	}
+	if c.ShardTotal, err = src.envInt("GHACRON_SHARD_TOTAL", 1); err != nil {
+		return fmt.Errorf("invalid GHACRON_SHARD_TOTAL: %w", err)
+	}
+	if c.SkipForks, err = src.envBool("GHACRON_SKIP_FORKS", false); err != nil {
+		return fmt.Errorf("invalid GHACRON_SKIP_FORKS: %w", err)
+	}
+	if c.ScanBackoffMaxSkips, err = src.envInt("GHACRON_SCAN_BACKOFF_MAX_SKIPS", 8); err != nil {
+		return fmt.Errorf("invalid GHACRON_SCAN_BACKOFF_MAX_SKIPS: %w", err)
+	}
+	if c.GraphQLScan, err = src.envBool("GHACRON_SCAN_GRAPHQL", false); err != nil {
+		return fmt.Errorf("invalid GHACRON_SCAN_GRAPHQL: %w", err)
+	}
+	if c.DryRun, err = src.envBool("GHACRON_DRY_RUN", false); err != nil {
+		return fmt.Errorf("invalid GHACRON_DRY_RUN: %w", err)
+	}
+	return nil
+}
+
+// loadDispatch reads the settings of job runs and their dispatches.
+func loadDispatch(src *source, c *ReconcileConfig) error {
+	c.OverlapPolicy = src.envStr("GHACRON_DISPATCH_OVERLAP", "allow")
+	var err error
+
+	if c.DispatchSplaySeconds, err = src.envInt("GHACRON_DISPATCH_SPLAY_SECONDS", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_SPLAY_SECONDS: %w", err)
+	}
+	if c.DispatchStaggerSeconds, err = src.envInt("GHACRON_DISPATCH_STAGGER_SECONDS", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_STAGGER_SECONDS: %w", err)
+	}
+	if c.DispatchStaggerThreshold, err = src.envInt("GHACRON_DISPATCH_STAGGER_THRESHOLD", 10); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_STAGGER_THRESHOLD: %w", err)
+	}
+	if c.MaxConcurrentDispatches, err = src.envInt("GHACRON_DISPATCH_MAX_CONCURRENCY", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_MAX_CONCURRENCY: %w", err)
+	}
+	if c.MaxConcurrentDispatchesPerRepo, err = src.envInt("GHACRON_DISPATCH_MAX_CONCURRENCY_PER_REPO", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_MAX_CONCURRENCY_PER_REPO: %w", err)
+	}
+	if c.DispatchRatePerMinute, err = src.envInt("GHACRON_DISPATCH_RATE_PER_MINUTE", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_RATE_PER_MINUTE: %w", err)
+	}
+	if c.DispatchRateBurst, err = src.envInt("GHACRON_DISPATCH_RATE_BURST", 1); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_RATE_BURST: %w", err)
+	}
+	if c.DispatchQueueMax, err = src.envInt("GHACRON_DISPATCH_QUEUE_MAX", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_QUEUE_MAX: %w", err)
+	}
+	if c.DispatchTimeoutSeconds, err = src.envInt("GHACRON_DISPATCH_TIMEOUT_SECONDS", 30); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_TIMEOUT_SECONDS: %w", err)
+	}
+	if c.VerifyDispatches, err = src.envBool("GHACRON_DISPATCH_VERIFY", false); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_VERIFY: %w", err)
+	}
+	if c.VerifyTimeoutMinutes, err = src.envInt("GHACRON_DISPATCH_VERIFY_TIMEOUT_MINUTES", 60); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_VERIFY_TIMEOUT_MINUTES: %w", err)
+	}
+	if c.CheckRuns, err = src.envBool("GHACRON_DISPATCH_CHECK_RUNS", false); err != nil {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_CHECK_RUNS: %w", err)
+	}
+	if c.PushCheckRuns, err = src.envBool("GHACRON_PUSH_CHECK_RUNS", false); err != nil {
+		return fmt.Errorf("invalid GHACRON_PUSH_CHECK_RUNS: %w", err)
+	}
+	return nil
+}
+
+// loadState reads the settings of per-job state, snapshots and history.
+func loadState(src *source, c *ReconcileConfig) error {
+	c.StateScope = src.envStr("GHACRON_STATE_SCOPE", "repo")
+	c.StateGitRepo = src.envStr("GHACRON_STATE_GIT_REPO", "")
+	c.StateGitBranch = src.envStr("GHACRON_STATE_GIT_BRANCH", "ghacron-state")
+	c.StateGitPath = src.envStr("GHACRON_STATE_GIT_PATH", "state.json")
+	c.StateVariablePrefix = src.envStr("GHACRON_STATE_VARIABLE_PREFIX", "GHACRON_")
+	c.SnapshotPath = src.get("GHACRON_SNAPSHOT_PATH")
+	c.HistoryPath = src.get("GHACRON_HISTORY_PATH")
+	var err error
+
+	if c.ClaimSettleSeconds, err = src.envInt("GHACRON_STATE_CLAIM_SETTLE_SECONDS", 2); err != nil {
+		return fmt.Errorf("invalid GHACRON_STATE_CLAIM_SETTLE_SECONDS: %w", err)
+	}
+	if c.StateCacheSeconds, err = src.envInt("GHACRON_STATE_CACHE_SECONDS", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_STATE_CACHE_SECONDS: %w", err)
+	}
+	if c.StateGCIntervalHours, err = src.envInt("GHACRON_STATE_GC_INTERVAL_HOURS", 24); err != nil {
+		return fmt.Errorf("invalid GHACRON_STATE_GC_INTERVAL_HOURS: %w", err)
+	}
+	if c.HistoryRetentionDays, err = src.envInt("GHACRON_HISTORY_RETENTION_DAYS", 30); err != nil {
+		return fmt.Errorf("invalid GHACRON_HISTORY_RETENTION_DAYS: %w", err)
+	}
+	return nil
+}
+
+// loadLimits reads the job limits, the circuit breaker, failure alerting and
+// the shutdown timeout.
+func loadLimits(src *source, c *ReconcileConfig) error {
+	c.DeadmanWebhookURL = src.get("GHACRON_DEADMAN_WEBHOOK_URL")
+	var err error
+
+	if c.ShutdownTimeoutSeconds, err = src.envInt("GHACRON_SHUTDOWN_TIMEOUT_SECONDS", 20); err != nil {
+		return fmt.Errorf("invalid GHACRON_SHUTDOWN_TIMEOUT_SECONDS: %w", err)
+	}
+	if c.MaxJobs, err = src.envInt("GHACRON_MAX_JOBS", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_MAX_JOBS: %w", err)
+	}
+	if c.MaxJobsPerRepo, err = src.envInt("GHACRON_MAX_JOBS_PER_REPO", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_MAX_JOBS_PER_REPO: %w", err)
+	}
+	if c.BreakerThreshold, err = src.envInt("GHACRON_BREAKER_THRESHOLD", 5); err != nil {
+		return fmt.Errorf("invalid GHACRON_BREAKER_THRESHOLD: %w", err)
+	}
+	if c.BreakerCooldownMinutes, err = src.envInt("GHACRON_BREAKER_COOLDOWN_MINUTES", 60); err != nil {
+		return fmt.Errorf("invalid GHACRON_BREAKER_COOLDOWN_MINUTES: %w", err)
+	}
+	if c.FailureIssueThreshold, err = src.envInt("GHACRON_FAILURE_ISSUE_THRESHOLD", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_FAILURE_ISSUE_THRESHOLD: %w", err)
+	}
+	if c.DeadmanGraceSeconds, err = src.envInt("GHACRON_DEADMAN_GRACE_SECONDS", 0); err != nil {
+		return fmt.Errorf("invalid GHACRON_DEADMAN_GRACE_SECONDS: %w", err)
+	}
+	return nil
+}
+
+// loadLog reads the logging settings.
+func loadLog(src *source) (LogConfig, error) {
+	c := LogConfig{
+		Level:         src.envStr("GHACRON_LOG_LEVEL", "info"),
+		Format:        src.envStr("GHACRON_LOG_FORMAT", "json"),
+		Schema:        src.envStr("GHACRON_LOG_SCHEMA", "slog"),
+		Output:        src.envStr("GHACRON_LOG_OUTPUT", "stdout"),
+		SyslogAddress: src.envStr("GHACRON_LOG_SYSLOG_ADDRESS", "unix:///dev/log"),
+		AuditPath:     src.get("GHACRON_AUDIT_LOG_PATH"),
+	}
+	var err error
+	if c.HTTP, err = src.envBool("GHACRON_LOG_HTTP", false); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_LOG_HTTP: %w", err)
+	}
+	return c, nil
+}
+
+// loadSentry reads the error reporting settings.
+func loadSentry(src *source) (SentryConfig, error) {
+	c := SentryConfig{
+		DSN:         src.get("GHACRON_SENTRY_DSN"),
+		Environment: src.get("GHACRON_SENTRY_ENVIRONMENT"),
+	}
+	var err error
+	if c.DispatchFailureThreshold, err = src.envInt("GHACRON_SENTRY_DISPATCH_FAILURE_THRESHOLD", 3); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_SENTRY_DISPATCH_FAILURE_THRESHOLD: %w", err)
+	}
+	return c, nil
+}
+
+// loadWebAPI reads the web API server settings.
+func loadWebAPI(src *source) (WebAPIConfig, error) {
+	c := WebAPIConfig{
+		Host:          src.envStr("GHACRON_WEBAPI_HOST", "0.0.0.0"),
+		WebhookSecret: src.get("GHACRON_WEBHOOK_SECRET"),
+		TLSCertPath:   src.get("GHACRON_WEBAPI_TLS_CERT_PATH"),
+		TLSKeyPath:    src.get("GHACRON_WEBAPI_TLS_KEY_PATH"),
+		ClientCAPath:  src.get("GHACRON_WEBAPI_CLIENT_CA_PATH"),
+	}
+	var err error
+	if c.Enabled, err = src.envBool("GHACRON_WEBAPI_ENABLED", true); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_WEBAPI_ENABLED: %w", err)
+	}
+	if c.Port, err = src.envInt("GHACRON_WEBAPI_PORT", 8080); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_WEBAPI_PORT: %w", err)
+	}
+	if c.AdminEnabled, err = src.envBool("GHACRON_WEBAPI_ADMIN_ENABLED", false); err != nil {
+		return c, fmt.Errorf("invalid GHACRON_WEBAPI_ADMIN_ENABLED: %w", err)
+	}
+	return c, nil
+}
+
 // GetPrivateKey returns the private key bytes.
 // Priority: GHACRON_APP_PRIVATE_KEY env > GHACRON_APP_PRIVATE_KEY_PATH file.
-func (c *Config) GetPrivateKey() ([]byte, error) {
+// GHACRON_APP_PRIVATE_KEY may be a secret manager reference (see
+// secrets.Resolve), which is fetched on every call.
+func (c *Config) GetPrivateKey(ctx context.Context) ([]byte, error) {
 	if c.GitHub.PrivateKey != "" {
-		return []byte(c.GitHub.PrivateKey), nil
+		key, err := secrets.Resolve(ctx, c.GitHub.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch private key: %w", err)
+		}
+		return []byte(key), nil
 	}
 	if c.GitHub.PrivateKeyPath != "" {
 		data, err := os.ReadFile(c.GitHub.PrivateKeyPath)
@@ -145,40 +608,379 @@ func (c *Config) GetPrivateKey() ([]byte, error) {
 	return nil, errors.New("private key not configured (set GHACRON_APP_PRIVATE_KEY or GHACRON_APP_PRIVATE_KEY_PATH)")
 }
 
+// ResolveSecrets replaces secret manager references in settings other than
+// the private key with the secrets they refer to.
+func (c *Config) ResolveSecrets(ctx context.Context) error {
+	secret, err := secrets.Resolve(ctx, c.WebAPI.WebhookSecret)
+	if err != nil {
+		return fmt.Errorf("failed to fetch GHACRON_WEBHOOK_SECRET: %w", err)
+	}
+	c.WebAPI.WebhookSecret = secret
+	return nil
+}
+
 func (c *Config) validate() error {
-	if c.GitHub.AppID <= 0 {
+	for _, validate := range []func() error{
+		c.GitHub.validate,
+		c.WebAPI.validate,
+		c.Reconcile.validate,
+		c.Tracing.validate,
+		c.Notify.validate,
+		c.Sentry.validate,
+		c.Log.validate,
+	} {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gc *GitHubConfig) validate() error {
+	if gc.AppID <= 0 {
 		return errors.New("GHACRON_APP_ID is required")
 	}
-	if c.GitHub.PrivateKey == "" && c.GitHub.PrivateKeyPath == "" {
+	if gc.PrivateKey == "" && gc.PrivateKeyPath == "" {
 		return errors.New("GHACRON_APP_PRIVATE_KEY or GHACRON_APP_PRIVATE_KEY_PATH is required")
 	}
-	if _, err := time.LoadLocation(c.Reconcile.Timezone); err != nil {
-		return fmt.Errorf("invalid GHACRON_TIMEZONE (%q): %w", c.Reconcile.Timezone, err)
+	if gc.RetryAttempts < 0 {
+		return fmt.Errorf("invalid GHACRON_GITHUB_RETRY_ATTEMPTS (%d): must be >= 0", gc.RetryAttempts)
+	}
+	if gc.RetryAttempts > 0 && gc.RetryBackoffMillis <= 0 {
+		return fmt.Errorf("invalid GHACRON_GITHUB_RETRY_BACKOFF_MS (%d): must be > 0", gc.RetryBackoffMillis)
+	}
+	if gc.TokenTimeoutSeconds <= 0 {
+		return fmt.Errorf("invalid GHACRON_GITHUB_TOKEN_TIMEOUT_SECONDS (%d): must be > 0", gc.TokenTimeoutSeconds)
+	}
+	if strings.ContainsFunc(gc.UserAgentSuffix, unicode.IsControl) {
+		return fmt.Errorf("invalid GHACRON_GITHUB_USER_AGENT_SUFFIX (%q): must not contain control characters", gc.UserAgentSuffix)
+	}
+	if gc.RepoListTTLSeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_GITHUB_REPO_LIST_TTL_SECONDS (%d): must be >= 0", gc.RepoListTTLSeconds)
+	}
+	if gc.RateLimitPollSeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_GITHUB_RATE_LIMIT_POLL_SECONDS (%d): must be >= 0", gc.RateLimitPollSeconds)
+	}
+	return nil
+}
+
+func (wc *WebAPIConfig) validate() error {
+	if (wc.TLSCertPath == "") != (wc.TLSKeyPath == "") {
+		return errors.New("GHACRON_WEBAPI_TLS_CERT_PATH and GHACRON_WEBAPI_TLS_KEY_PATH must be set together")
+	}
+	if wc.ClientCAPath != "" && wc.TLSCertPath == "" {
+		return errors.New("GHACRON_WEBAPI_CLIENT_CA_PATH requires GHACRON_WEBAPI_TLS_CERT_PATH and GHACRON_WEBAPI_TLS_KEY_PATH")
+	}
+	return nil
+}
+
+// validate checks the reconcile settings in the groups they are loaded in.
+func (rc *ReconcileConfig) validate() error {
+	for _, validate := range []func() error{
+		rc.validateScan,
+		rc.validateDispatch,
+		rc.validateState,
+		rc.validateLimits,
+	} {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rc *ReconcileConfig) validateScan() error {
+	if rc.ConflictWindowSeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_RECONCILE_CONFLICT_WINDOW_SECONDS (%d): must be >= 0", rc.ConflictWindowSeconds)
+	}
+	if rc.ScanBackoffMaxSkips < 0 {
+		return fmt.Errorf("invalid GHACRON_SCAN_BACKOFF_MAX_SKIPS (%d): must be >= 0", rc.ScanBackoffMaxSkips)
+	}
+	if rc.ShardTotal < 1 {
+		return fmt.Errorf("invalid GHACRON_SHARD_TOTAL (%d): must be >= 1", rc.ShardTotal)
+	}
+	if rc.ShardIndex < 0 || rc.ShardIndex >= rc.ShardTotal {
+		return fmt.Errorf("invalid GHACRON_SHARD_INDEX (%d): must be >= 0 and < GHACRON_SHARD_TOTAL (%d)", rc.ShardIndex, rc.ShardTotal)
+	}
+	if err := oneOf("GHACRON_RECONCILE_STARTUP", rc.StartupReconcile, "immediate", "delay", "skip"); err != nil {
+		return err
+	}
+	if err := oneOf("GHACRON_RECONCILE_DUPLICATE_GUARD_MODE", rc.DuplicateGuardMode, "variable", "runs"); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(rc.Timezone); err != nil {
+		return fmt.Errorf("invalid GHACRON_TIMEZONE (%q): %w", rc.Timezone, err)
+	}
+	return nil
+}
+
+func (rc *ReconcileConfig) validateDispatch() error {
+	if rc.DispatchSplaySeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_SPLAY_SECONDS (%d): must be >= 0", rc.DispatchSplaySeconds)
+	}
+	if rc.DispatchStaggerSeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_STAGGER_SECONDS (%d): must be >= 0", rc.DispatchStaggerSeconds)
+	}
+	if rc.DispatchStaggerThreshold < 2 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_STAGGER_THRESHOLD (%d): must be >= 2", rc.DispatchStaggerThreshold)
+	}
+	if rc.MaxConcurrentDispatches < 0 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_MAX_CONCURRENCY (%d): must be >= 0", rc.MaxConcurrentDispatches)
+	}
+	if rc.MaxConcurrentDispatchesPerRepo < 0 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_MAX_CONCURRENCY_PER_REPO (%d): must be >= 0", rc.MaxConcurrentDispatchesPerRepo)
+	}
+	if rc.DispatchRatePerMinute < 0 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_RATE_PER_MINUTE (%d): must be >= 0", rc.DispatchRatePerMinute)
+	}
+	if rc.DispatchRateBurst < 1 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_RATE_BURST (%d): must be > 0", rc.DispatchRateBurst)
+	}
+	if rc.DispatchQueueMax < 0 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_QUEUE_MAX (%d): must be >= 0", rc.DispatchQueueMax)
+	}
+	if rc.DispatchTimeoutSeconds <= 0 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_TIMEOUT_SECONDS (%d): must be > 0", rc.DispatchTimeoutSeconds)
+	}
+	if rc.VerifyTimeoutMinutes <= 0 {
+		return fmt.Errorf("invalid GHACRON_DISPATCH_VERIFY_TIMEOUT_MINUTES (%d): must be > 0", rc.VerifyTimeoutMinutes)
+	}
+	return oneOf("GHACRON_DISPATCH_OVERLAP", rc.OverlapPolicy, "allow", "skip")
+}
+
+func (rc *ReconcileConfig) validateState() error {
+	if rc.ClaimSettleSeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_STATE_CLAIM_SETTLE_SECONDS (%d): must be >= 0", rc.ClaimSettleSeconds)
+	}
+	if !variablePrefixPattern.MatchString(rc.StateVariablePrefix) ||
+		strings.HasPrefix(strings.ToUpper(rc.StateVariablePrefix), "GITHUB_") {
+		return fmt.Errorf("invalid GHACRON_STATE_VARIABLE_PREFIX (%q): must be letters, digits and underscores, not start with a digit or GITHUB_", rc.StateVariablePrefix)
+	}
+	if rc.StateCacheSeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_STATE_CACHE_SECONDS (%d): must be >= 0", rc.StateCacheSeconds)
+	}
+	if rc.StateGCIntervalHours < 0 {
+		return fmt.Errorf("invalid GHACRON_STATE_GC_INTERVAL_HOURS (%d): must be >= 0", rc.StateGCIntervalHours)
+	}
+	if rc.HistoryRetentionDays <= 0 {
+		return fmt.Errorf("invalid GHACRON_HISTORY_RETENTION_DAYS (%d): must be > 0", rc.HistoryRetentionDays)
+	}
+	switch rc.StateScope {
+	case "repo", "org":
+		return nil
+	case "git":
+		return rc.validateStateGit()
+	default:
+		return fmt.Errorf("invalid GHACRON_STATE_SCOPE (%q): must be one of repo, org, git", rc.StateScope)
+	}
+}
+
+// validateStateGit checks the settings of the git state scope.
+func (rc *ReconcileConfig) validateStateGit() error {
+	if owner, repo, ok := strings.Cut(rc.StateGitRepo, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return fmt.Errorf("invalid GHACRON_STATE_GIT_REPO (%q): must be owner/repo when GHACRON_STATE_SCOPE is git", rc.StateGitRepo)
+	}
+	if rc.StateGitBranch == "" {
+		return errors.New("invalid GHACRON_STATE_GIT_BRANCH: must not be empty")
+	}
+	if rc.StateGitPath == "" || strings.HasPrefix(rc.StateGitPath, "/") {
+		return fmt.Errorf("invalid GHACRON_STATE_GIT_PATH (%q): must be a relative path", rc.StateGitPath)
+	}
+	return nil
+}
+
+func (rc *ReconcileConfig) validateLimits() error {
+	if rc.ShutdownTimeoutSeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_SHUTDOWN_TIMEOUT_SECONDS (%d): must be >= 0", rc.ShutdownTimeoutSeconds)
+	}
+	if rc.MaxJobs < 0 {
+		return fmt.Errorf("invalid GHACRON_MAX_JOBS (%d): must be >= 0", rc.MaxJobs)
+	}
+	if rc.MaxJobsPerRepo < 0 {
+		return fmt.Errorf("invalid GHACRON_MAX_JOBS_PER_REPO (%d): must be >= 0", rc.MaxJobsPerRepo)
+	}
+	if rc.BreakerThreshold < 0 {
+		return fmt.Errorf("invalid GHACRON_BREAKER_THRESHOLD (%d): must be >= 0", rc.BreakerThreshold)
+	}
+	if rc.BreakerThreshold > 0 && rc.BreakerCooldownMinutes <= 0 {
+		return fmt.Errorf("invalid GHACRON_BREAKER_COOLDOWN_MINUTES (%d): must be > 0", rc.BreakerCooldownMinutes)
+	}
+	if rc.FailureIssueThreshold < 0 {
+		return fmt.Errorf("invalid GHACRON_FAILURE_ISSUE_THRESHOLD (%d): must be >= 0", rc.FailureIssueThreshold)
+	}
+	if rc.DeadmanGraceSeconds < 0 {
+		return fmt.Errorf("invalid GHACRON_DEADMAN_GRACE_SECONDS (%d): must be >= 0", rc.DeadmanGraceSeconds)
+	}
+	return nil
+}
+
+func (tc *TracingConfig) validate() error {
+	if tc.Endpoint == "" {
+		return nil
+	}
+	if !isHTTPURL(tc.Endpoint) {
+		return fmt.Errorf("invalid GHACRON_TRACING_ENDPOINT (%q): must be an http or https URL", tc.Endpoint)
+	}
+	return nil
+}
+
+func (nc *NotifyConfig) validate() error {
+	for _, target := range []struct{ name, url string }{
+		{"GHACRON_NOTIFY_SLACK_WEBHOOK_URL", nc.SlackWebhookURL},
+		{"GHACRON_NOTIFY_DISCORD_WEBHOOK_URL", nc.DiscordWebhookURL},
+		{"GHACRON_NOTIFY_WEBHOOK_URL", nc.WebhookURL},
+	} {
+		if target.url != "" && !isHTTPURL(target.url) {
+			// The URL is not echoed: webhook URLs embed credentials.
+			return fmt.Errorf("invalid %s: must be an http or https URL", target.name)
+		}
 	}
-	switch strings.ToLower(c.Log.Level) {
+	if err := oneOf("GHACRON_NOTIFY_MIN_SEVERITY", nc.MinSeverity, "info", "warning", "error"); err != nil {
+		return err
+	}
+	for _, pattern := range nc.Repos {
+		if _, err := path.Match(pattern, ""); err != nil || strings.Count(pattern, "/") != 1 {
+			return fmt.Errorf("invalid GHACRON_NOTIFY_REPOS pattern %q: must be owner/repo", pattern)
+		}
+	}
+	return nil
+}
+
+func (sc *SentryConfig) validate() error {
+	if sc.DSN != "" && sc.DispatchFailureThreshold <= 0 {
+		return fmt.Errorf("invalid GHACRON_SENTRY_DISPATCH_FAILURE_THRESHOLD (%d): must be > 0", sc.DispatchFailureThreshold)
+	}
+	return nil
+}
+
+func (lc *LogConfig) validate() error {
+	switch strings.ToLower(lc.Level) {
 	case "debug", "info", "warn", "error":
 		// OK
 	default:
-		return fmt.Errorf("invalid GHACRON_LOG_LEVEL (%q): must be one of debug, info, warn, error", c.Log.Level)
+		return fmt.Errorf("invalid GHACRON_LOG_LEVEL (%q): must be one of debug, info, warn, error", lc.Level)
 	}
-	switch strings.ToLower(c.Log.Format) {
+	switch strings.ToLower(lc.Format) {
 	case "json", "text":
 		// OK
 	default:
-		return fmt.Errorf("invalid GHACRON_LOG_FORMAT (%q): must be one of json, text", c.Log.Format)
+		return fmt.Errorf("invalid GHACRON_LOG_FORMAT (%q): must be one of json, text", lc.Format)
+	}
+	switch strings.ToLower(lc.Schema) {
+	case "slog":
+		// OK
+	case "ecs":
+		if !strings.EqualFold(lc.Format, "json") {
+			return fmt.Errorf("GHACRON_LOG_SCHEMA=ecs requires GHACRON_LOG_FORMAT=json")
+		}
+	default:
+		return fmt.Errorf("invalid GHACRON_LOG_SCHEMA (%q): must be one of slog, ecs", lc.Schema)
+	}
+	switch strings.ToLower(lc.Output) {
+	case "stdout", "journald":
+		// OK
+	case "syslog":
+		if _, _, err := logging.ParseSyslogAddress(lc.SyslogAddress); err != nil {
+			return fmt.Errorf("invalid GHACRON_LOG_SYSLOG_ADDRESS: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid GHACRON_LOG_OUTPUT (%q): must be one of stdout, syslog, journald", lc.Output)
 	}
 	return nil
 }
 
-func envStr(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
+// oneOf fails if value is not one of allowed, naming the setting key.
+func oneOf(key, value string, allowed ...string) error {
+	if slices.Contains(allowed, value) {
+		return nil
+	}
+	return fmt.Errorf("invalid %s (%q): must be one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ParseScheduleAliases parses "name=expr" pairs separated by ';', e.g.
+// "nightly=CRON_TZ=Asia/Tokyo 0 2 * * *;weekly=0 3 * * 1".
+func ParseScheduleAliases(v string) (map[string]string, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	aliases := make(map[string]string)
+	for _, pair := range strings.Split(v, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, expr, ok := strings.Cut(pair, "=")
+		name, expr = strings.TrimSpace(name), strings.TrimSpace(expr)
+		if !ok || expr == "" {
+			return nil, fmt.Errorf("expected name=expression, got %q", pair)
+		}
+		if !scheduleAliasPattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid alias name %q: must match %s", name, scheduleAliasPattern.String())
+		}
+		if _, err := cronParser.Parse(expr); err != nil {
+			return nil, fmt.Errorf("invalid expression of alias %s: %w", name, err)
+		}
+		if _, dup := aliases[name]; dup {
+			return nil, fmt.Errorf("duplicate alias %s", name)
+		}
+		aliases[name] = expr
+	}
+	return aliases, nil
+}
+
+// ParseOwnerTimezones parses "owner=timezone" pairs separated by ';', e.g.
+// "acme-jp=Asia/Tokyo;acme-de=Europe/Berlin". Owners are lower-cased, since
+// GitHub logins are case-insensitive.
+func ParseOwnerTimezones(v string) (map[string]string, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	timezones := make(map[string]string)
+	for _, pair := range strings.Split(v, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		owner, tz, ok := strings.Cut(pair, "=")
+		owner, tz = strings.ToLower(strings.TrimSpace(owner)), strings.TrimSpace(tz)
+		if !ok || owner == "" || tz == "" {
+			return nil, fmt.Errorf("expected owner=timezone, got %q", pair)
+		}
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid time zone of %s: %w", owner, err)
+		}
+		if _, dup := timezones[owner]; dup {
+			return nil, fmt.Errorf("duplicate owner %s", owner)
+		}
+		timezones[owner] = tz
+	}
+	return timezones, nil
+}
+
+// parseList parses a comma-separated list, ignoring empty items.
+func parseList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (src *source) envStr(key, fallback string) string {
+	if v := src.get(key); v != "" {
 		return v
 	}
 	return fallback
 }
 
-func envInt(key string, fallback int) (int, error) {
-	v := os.Getenv(key)
+func (src *source) envInt(key string, fallback int) (int, error) {
+	v := src.get(key)
 	if v == "" {
 		return fallback, nil
 	}
@@ -189,8 +991,8 @@ func envInt(key string, fallback int) (int, error) {
 	return n, nil
 }
 
-func envInt64(key string, fallback int64) (int64, error) {
-	v := os.Getenv(key)
+func (src *source) envInt64(key string, fallback int64) (int64, error) {
+	v := src.get(key)
 	if v == "" {
 		return fallback, nil
 	}
@@ -201,8 +1003,8 @@ func envInt64(key string, fallback int64) (int64, error) {
 	return n, nil
 }
 
-func envBool(key string, fallback bool) (bool, error) {
-	v := os.Getenv(key)
+func (src *source) envBool(key string, fallback bool) (bool, error) {
+	v := src.get(key)
 	if v == "" {
 		return fallback, nil
 	}
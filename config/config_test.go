@@ -1,6 +1,7 @@
 package config
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
 	"testing"
@@ -29,6 +30,84 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.Reconcile.DuplicateGuardSeconds != 60 {
 		t.Errorf("DuplicateGuardSeconds = %d, want 60", cfg.Reconcile.DuplicateGuardSeconds)
 	}
+	if cfg.Reconcile.StartupReconcile != "immediate" {
+		t.Errorf("StartupReconcile = %q, want immediate", cfg.Reconcile.StartupReconcile)
+	}
+	if cfg.Reconcile.DuplicateGuardMode != "variable" {
+		t.Errorf("DuplicateGuardMode = %q, want variable", cfg.Reconcile.DuplicateGuardMode)
+	}
+	if cfg.Reconcile.ConflictWindowSeconds != 300 {
+		t.Errorf("ConflictWindowSeconds = %d, want 300", cfg.Reconcile.ConflictWindowSeconds)
+	}
+	if cfg.Reconcile.GraphQLScan {
+		t.Errorf("GraphQLScan = true, want false")
+	}
+	if cfg.Reconcile.DispatchSplaySeconds != 0 {
+		t.Errorf("DispatchSplaySeconds = %d, want 0", cfg.Reconcile.DispatchSplaySeconds)
+	}
+	if cfg.Reconcile.DispatchStaggerSeconds != 0 || cfg.Reconcile.DispatchStaggerThreshold != 10 {
+		t.Errorf("DispatchStagger = (%d, %d), want (0, 10)", cfg.Reconcile.DispatchStaggerSeconds, cfg.Reconcile.DispatchStaggerThreshold)
+	}
+	if cfg.Reconcile.DispatchTimeoutSeconds != 30 {
+		t.Errorf("DispatchTimeoutSeconds = %d, want 30", cfg.Reconcile.DispatchTimeoutSeconds)
+	}
+	if cfg.Reconcile.VerifyDispatches {
+		t.Errorf("VerifyDispatches = true, want false")
+	}
+	if cfg.Reconcile.CheckRuns {
+		t.Errorf("CheckRuns = true, want false")
+	}
+	if cfg.Reconcile.VerifyTimeoutMinutes != 60 {
+		t.Errorf("VerifyTimeoutMinutes = %d, want 60", cfg.Reconcile.VerifyTimeoutMinutes)
+	}
+	if cfg.Reconcile.ClaimSettleSeconds != 2 {
+		t.Errorf("ClaimSettleSeconds = %d, want 2", cfg.Reconcile.ClaimSettleSeconds)
+	}
+	if cfg.Reconcile.StateScope != "repo" {
+		t.Errorf("StateScope = %q, want %q", cfg.Reconcile.StateScope, "repo")
+	}
+	if cfg.Reconcile.StateVariablePrefix != "GHACRON_" {
+		t.Errorf("StateVariablePrefix = %q, want %q", cfg.Reconcile.StateVariablePrefix, "GHACRON_")
+	}
+	if cfg.Reconcile.StateCacheSeconds != 0 {
+		t.Errorf("StateCacheSeconds = %d, want 0", cfg.Reconcile.StateCacheSeconds)
+	}
+	if cfg.Reconcile.StateGCIntervalHours != 24 {
+		t.Errorf("StateGCIntervalHours = %d, want 24", cfg.Reconcile.StateGCIntervalHours)
+	}
+	if cfg.Reconcile.ShutdownTimeoutSeconds != 20 {
+		t.Errorf("ShutdownTimeoutSeconds = %d, want 20", cfg.Reconcile.ShutdownTimeoutSeconds)
+	}
+	if cfg.GitHub.RetryAttempts != 3 {
+		t.Errorf("RetryAttempts = %d, want 3", cfg.GitHub.RetryAttempts)
+	}
+	if cfg.GitHub.RetryBackoffMillis != 500 {
+		t.Errorf("RetryBackoffMillis = %d, want 500", cfg.GitHub.RetryBackoffMillis)
+	}
+	if !cfg.GitHub.HTTPCache {
+		t.Error("HTTPCache should default to true")
+	}
+	if cfg.GitHub.CACertPath != "" || cfg.GitHub.InsecureSkipVerify {
+		t.Errorf("CACertPath = %q, InsecureSkipVerify = %v, want unset", cfg.GitHub.CACertPath, cfg.GitHub.InsecureSkipVerify)
+	}
+	if cfg.GitHub.TokenTimeoutSeconds != 30 {
+		t.Errorf("TokenTimeoutSeconds = %d, want 30", cfg.GitHub.TokenTimeoutSeconds)
+	}
+	if cfg.Reconcile.BreakerThreshold != 5 {
+		t.Errorf("BreakerThreshold = %d, want 5", cfg.Reconcile.BreakerThreshold)
+	}
+	if cfg.Reconcile.BreakerCooldownMinutes != 60 {
+		t.Errorf("BreakerCooldownMinutes = %d, want 60", cfg.Reconcile.BreakerCooldownMinutes)
+	}
+	if cfg.Reconcile.FailureIssueThreshold != 0 {
+		t.Errorf("FailureIssueThreshold = %d, want 0", cfg.Reconcile.FailureIssueThreshold)
+	}
+	if cfg.Reconcile.DeadmanGraceSeconds != 0 {
+		t.Errorf("DeadmanGraceSeconds = %d, want 0", cfg.Reconcile.DeadmanGraceSeconds)
+	}
+	if cfg.Reconcile.OverlapPolicy != "allow" {
+		t.Errorf("OverlapPolicy = %q, want %q", cfg.Reconcile.OverlapPolicy, "allow")
+	}
 	if cfg.Reconcile.DryRun {
 		t.Errorf("DryRun = true, want false")
 	}
@@ -50,6 +129,9 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.WebAPI.Port != 8080 {
 		t.Errorf("WebAPI.Port = %d, want 8080", cfg.WebAPI.Port)
 	}
+	if cfg.WebAPI.AdminEnabled {
+		t.Errorf("WebAPI.AdminEnabled = true, want false")
+	}
 }
 
 func TestLoad_AllEnvVars(t *testing.T) {
@@ -64,6 +146,7 @@ func TestLoad_AllEnvVars(t *testing.T) {
 	t.Setenv("GHACRON_WEBAPI_ENABLED", "false")
 	t.Setenv("GHACRON_WEBAPI_HOST", "127.0.0.1")
 	t.Setenv("GHACRON_WEBAPI_PORT", "9090")
+	t.Setenv("GHACRON_WEBAPI_ADMIN_ENABLED", "true")
 
 	cfg, err := Load()
 	if err != nil {
@@ -103,6 +186,9 @@ func TestLoad_AllEnvVars(t *testing.T) {
 	if cfg.WebAPI.Port != 9090 {
 		t.Errorf("WebAPI.Port = %d, want 9090", cfg.WebAPI.Port)
 	}
+	if !cfg.WebAPI.AdminEnabled {
+		t.Errorf("WebAPI.AdminEnabled = false, want true")
+	}
 }
 
 func TestLoad_MissingAppID(t *testing.T) {
@@ -143,6 +229,439 @@ func TestLoad_InvalidTimezone(t *testing.T) {
 	}
 }
 
+func TestLoad_NegativeConflictWindow(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_RECONCILE_CONFLICT_WINDOW_SECONDS", "-1")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative conflict window")
+	}
+}
+
+func TestLoad_NegativeDispatchSplay(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_DISPATCH_SPLAY_SECONDS", "-1")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative dispatch splay")
+	}
+}
+
+func TestLoad_InvalidDispatchStagger(t *testing.T) {
+	tests := map[string]map[string]string{
+		"negative seconds": {"GHACRON_DISPATCH_STAGGER_SECONDS": "-1"},
+		"threshold of one": {"GHACRON_DISPATCH_STAGGER_THRESHOLD": "1"},
+	}
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			if _, err := Load(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidShard(t *testing.T) {
+	tests := map[string]map[string]string{
+		"zero total":         {"GHACRON_SHARD_TOTAL": "0"},
+		"negative index":     {"GHACRON_SHARD_INDEX": "-1"},
+		"index out of range": {"GHACRON_SHARD_INDEX": "3", "GHACRON_SHARD_TOTAL": "3"},
+	}
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			if _, err := Load(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestLoad_NegativeConcurrency(t *testing.T) {
+	for _, key := range []string{"GHACRON_DISPATCH_MAX_CONCURRENCY", "GHACRON_DISPATCH_MAX_CONCURRENCY_PER_REPO"} {
+		t.Run(key, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv(key, "-1")
+
+			_, err := Load()
+			if err == nil {
+				t.Fatalf("expected error for negative %s", key)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidDispatchRate(t *testing.T) {
+	tests := map[string]string{
+		"GHACRON_DISPATCH_RATE_PER_MINUTE": "-1",
+		"GHACRON_DISPATCH_RATE_BURST":      "0",
+		"GHACRON_DISPATCH_QUEUE_MAX":       "-1",
+	}
+	for key, v := range tests {
+		t.Run(key, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv(key, v)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("expected error for %s=%q", key, v)
+			}
+		})
+	}
+}
+
+func TestLoad_NonPositiveDispatchTimeout(t *testing.T) {
+	for _, v := range []string{"0", "-1"} {
+		t.Run(v, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("GHACRON_DISPATCH_TIMEOUT_SECONDS", v)
+
+			_, err := Load()
+			if err == nil {
+				t.Fatalf("expected error for dispatch timeout %s", v)
+			}
+		})
+	}
+}
+
+func TestLoad_NonPositiveVerifyTimeout(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_DISPATCH_VERIFY_TIMEOUT_MINUTES", "0")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for non-positive verify timeout")
+	}
+}
+
+func TestLoad_NegativeClaimSettle(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_STATE_CLAIM_SETTLE_SECONDS", "-1")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative claim settle delay")
+	}
+}
+
+func TestLoad_InvalidStateScope(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_STATE_SCOPE", "enterprise")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for invalid state scope")
+	}
+}
+
+func TestLoad_GitStateScope(t *testing.T) {
+	tests := map[string]bool{
+		"acme/ghacron-state": true,
+		"":                   false,
+		"acme":               false,
+		"acme/state/extra":   false,
+	}
+	for repo, valid := range tests {
+		t.Run(repo, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("GHACRON_STATE_SCOPE", "git")
+			t.Setenv("GHACRON_STATE_GIT_REPO", repo)
+
+			cfg, err := Load()
+			if valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !valid && err == nil {
+				t.Fatal("expected error for invalid state repository")
+			}
+			if valid && (cfg.Reconcile.StateGitBranch != "ghacron-state" || cfg.Reconcile.StateGitPath != "state.json") {
+				t.Errorf("branch, path = %q, %q; want the defaults", cfg.Reconcile.StateGitBranch, cfg.Reconcile.StateGitPath)
+			}
+		})
+	}
+}
+
+func TestLoad_StateVariablePrefix(t *testing.T) {
+	tests := map[string]bool{
+		"GHACRON_STAGING_": true,
+		"staging_":         true,
+		"1GHACRON_":        false,
+		"GHACRON-":         false,
+		"GITHUB_":          false,
+	}
+	for prefix, valid := range tests {
+		t.Run(prefix, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("GHACRON_STATE_VARIABLE_PREFIX", prefix)
+
+			cfg, err := Load()
+			if valid && err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if !valid && err == nil {
+				t.Fatal("expected error for invalid prefix")
+			}
+			if valid && cfg.Reconcile.StateVariablePrefix != prefix {
+				t.Errorf("StateVariablePrefix = %q, want %q", cfg.Reconcile.StateVariablePrefix, prefix)
+			}
+		})
+	}
+}
+
+func TestLoad_NegativeStateCache(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_STATE_CACHE_SECONDS", "-1")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative state cache TTL")
+	}
+}
+
+func TestLoad_NegativeStateGCInterval(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_STATE_GC_INTERVAL_HOURS", "-1")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative state GC interval")
+	}
+}
+
+func TestLoad_NegativeShutdownTimeout(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_SHUTDOWN_TIMEOUT_SECONDS", "-1")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative shutdown timeout")
+	}
+}
+
+func TestLoad_InvalidRetry(t *testing.T) {
+	tests := map[string]map[string]string{
+		"negative attempts": {"GHACRON_GITHUB_RETRY_ATTEMPTS": "-1"},
+		"zero backoff":      {"GHACRON_GITHUB_RETRY_BACKOFF_MS": "0"},
+	}
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			if _, err := Load(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidUserAgentSuffix(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_GITHUB_USER_AGENT_SUFFIX", "prod\r\nX-Injected: 1")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a suffix with control characters")
+	}
+}
+
+func TestLoad_InvalidBreaker(t *testing.T) {
+	tests := map[string]map[string]string{
+		"negative threshold": {"GHACRON_BREAKER_THRESHOLD": "-1"},
+		"zero cooldown":      {"GHACRON_BREAKER_COOLDOWN_MINUTES": "0"},
+	}
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			if _, err := Load(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestLoad_NegativeFailureIssueThreshold(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_FAILURE_ISSUE_THRESHOLD", "-1")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative failure issue threshold")
+	}
+}
+
+func TestLoad_NegativeDeadmanGrace(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_DEADMAN_GRACE_SECONDS", "-1")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative dead-man grace")
+	}
+}
+
+func TestLoad_InvalidOverlapPolicy(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_DISPATCH_OVERLAP", "queue")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for invalid overlap policy")
+	}
+}
+
+func TestLoad_InvalidHistoryRetention(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_HISTORY_RETENTION_DAYS", "0")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for zero history retention")
+	}
+}
+
+func TestLoad_InvalidDuplicateGuardMode(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_RECONCILE_DUPLICATE_GUARD_MODE", "issues")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for invalid duplicate guard mode")
+	}
+}
+
+func TestLoad_InvalidStartupReconcile(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_RECONCILE_STARTUP", "later")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for invalid startup reconcile")
+	}
+}
+
+func TestLoad_ScheduleAliases(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_SCHEDULE_ALIASES", "nightly=CRON_TZ=Asia/Tokyo 0 2 * * *; weekly = 0 3 * * 1;")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"nightly": "CRON_TZ=Asia/Tokyo 0 2 * * *", "weekly": "0 3 * * 1"}
+	if len(cfg.Reconcile.ScheduleAliases) != len(want) {
+		t.Fatalf("ScheduleAliases = %v, want %v", cfg.Reconcile.ScheduleAliases, want)
+	}
+	for name, expr := range want {
+		if got := cfg.Reconcile.ScheduleAliases[name]; got != expr {
+			t.Errorf("ScheduleAliases[%s] = %q, want %q", name, got, expr)
+		}
+	}
+}
+
+func TestLoad_InvalidScheduleAliases(t *testing.T) {
+	for _, v := range []string{
+		"nightly",                 // no expression
+		"@nightly=0 2 * * *",      // invalid name
+		"nightly=0 2 * *",         // invalid expression
+		"a=0 2 * * *;a=0 3 * * *", // duplicate
+	} {
+		t.Run(v, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("GHACRON_SCHEDULE_ALIASES", v)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("expected error for GHACRON_SCHEDULE_ALIASES=%q", v)
+			}
+		})
+	}
+}
+
+func TestLoad_OwnerTimezones(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_OWNER_TIMEZONES", "Acme-JP=Asia/Tokyo; acme-de = Europe/Berlin;")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"acme-jp": "Asia/Tokyo", "acme-de": "Europe/Berlin"}
+	if !maps.Equal(cfg.Reconcile.OwnerTimezones, want) {
+		t.Errorf("OwnerTimezones = %v, want %v", cfg.Reconcile.OwnerTimezones, want)
+	}
+}
+
+func TestLoad_InvalidOwnerTimezones(t *testing.T) {
+	for _, v := range []string{
+		"acme",                     // no time zone
+		"acme=Asia/Tokio",          // unknown time zone
+		"acme=UTC;ACME=Asia/Tokyo", // duplicate
+	} {
+		t.Run(v, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("GHACRON_OWNER_TIMEZONES", v)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("expected error for GHACRON_OWNER_TIMEZONES=%q", v)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidTracingEndpoint(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_TRACING_ENDPOINT", "otel-collector:4318")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for a tracing endpoint without scheme")
+	}
+}
+
+func TestLoad_Notify(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GHACRON_NOTIFY_SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/T0/B0/x")
+	t.Setenv("GHACRON_NOTIFY_REPOS", "myorg/*, other/repo,")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Notify.Enabled() || cfg.Notify.MinSeverity != "warning" {
+		t.Errorf("Notify = %+v, want enabled with severity warning", cfg.Notify)
+	}
+	if len(cfg.Notify.Repos) != 2 || cfg.Notify.Repos[0] != "myorg/*" || cfg.Notify.Repos[1] != "other/repo" {
+		t.Errorf("Repos = %q, want [myorg/* other/repo]", cfg.Notify.Repos)
+	}
+}
+
+func TestLoad_InvalidNotify(t *testing.T) {
+	tests := map[string]string{
+		"GHACRON_NOTIFY_WEBHOOK_URL":         "hooks.example.com/ghacron",
+		"GHACRON_NOTIFY_MIN_SEVERITY":        "critical",
+		"GHACRON_NOTIFY_REPOS":               "myorg",
+		"GHACRON_NOTIFY_DISCORD_WEBHOOK_URL": "ftp://discord.com/api/webhooks/1/x",
+	}
+	for key, v := range tests {
+		t.Run(key, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv(key, v)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("expected error for %s=%q", key, v)
+			}
+		})
+	}
+}
+
 func TestLoad_InvalidLogLevel(t *testing.T) {
 	setRequiredEnv(t)
 	t.Setenv("GHACRON_LOG_LEVEL", "verbose")
@@ -163,6 +682,57 @@ func TestLoad_InvalidLogFormat(t *testing.T) {
 	}
 }
 
+func TestLoad_LogSchema(t *testing.T) {
+	tests := []struct {
+		schema, format string
+		wantErr        bool
+	}{
+		{"ecs", "json", false},
+		{"ECS", "json", false},
+		{"ecs", "text", true},
+		{"gelf", "json", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema+"/"+tt.format, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("GHACRON_LOG_SCHEMA", tt.schema)
+			t.Setenv("GHACRON_LOG_FORMAT", tt.format)
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Log.Schema != tt.schema {
+				t.Errorf("Schema = %q, want %q", cfg.Log.Schema, tt.schema)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidLogOutput(t *testing.T) {
+	tests := map[string]map[string]string{
+		"unknown output":         {"GHACRON_LOG_OUTPUT": "file"},
+		"invalid syslog address": {"GHACRON_LOG_OUTPUT": "syslog", "GHACRON_LOG_SYSLOG_ADDRESS": "localhost:514"},
+	}
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			if _, err := Load(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
 func TestLoad_InvalidBool(t *testing.T) {
 	setRequiredEnv(t)
 	t.Setenv("GHACRON_DRY_RUN", "yes-please")
@@ -225,7 +795,7 @@ func TestGetPrivateKey_EnvPriority(t *testing.T) {
 		},
 	}
 
-	key, err := cfg.GetPrivateKey()
+	key, err := cfg.GetPrivateKey(t.Context())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -247,7 +817,7 @@ func TestGetPrivateKey_FileFallback(t *testing.T) {
 		},
 	}
 
-	key, err := cfg.GetPrivateKey()
+	key, err := cfg.GetPrivateKey(t.Context())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -259,7 +829,7 @@ func TestGetPrivateKey_FileFallback(t *testing.T) {
 func TestGetPrivateKey_NeitherSet(t *testing.T) {
 	cfg := &Config{}
 
-	_, err := cfg.GetPrivateKey()
+	_, err := cfg.GetPrivateKey(t.Context())
 	if err == nil {
 		t.Fatal("expected error when neither key nor path is set")
 	}
@@ -272,8 +842,27 @@ func TestGetPrivateKey_FileNotFound(t *testing.T) {
 		},
 	}
 
-	_, err := cfg.GetPrivateKey()
+	_, err := cfg.GetPrivateKey(t.Context())
 	if err == nil {
 		t.Fatal("expected error for nonexistent key file")
 	}
 }
+
+func TestLoad_InvalidWebAPITLS(t *testing.T) {
+	tests := map[string]map[string]string{
+		"cert without key":    {"GHACRON_WEBAPI_TLS_CERT_PATH": "/etc/ghacron/tls.crt"},
+		"key without cert":    {"GHACRON_WEBAPI_TLS_KEY_PATH": "/etc/ghacron/tls.key"},
+		"client CA over HTTP": {"GHACRON_WEBAPI_CLIENT_CA_PATH": "/etc/ghacron/ca.pem"},
+	}
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			if _, err := Load(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
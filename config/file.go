@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix is the prefix of the environment variables read by Load.
+const envPrefix = "GHACRON_"
+
+// mapSettings are the settings that may be written as mappings in the
+// configuration file.
+var mapSettings = map[string]bool{
+	"GHACRON_SCHEDULE_ALIASES": true,
+	"GHACRON_OWNER_TIMEZONES":  true,
+}
+
+// source resolves configuration values by environment variable name. A
+// non-empty environment variable takes precedence over the configuration file.
+type source struct {
+	file map[string]string // environment variable name -> value from the file
+	used map[string]bool   // names looked up
+}
+
+// get returns the value of the environment variable key, or the value of the
+// corresponding key of the configuration file.
+func (src *source) get(key string) string {
+	if src.used == nil {
+		src.used = make(map[string]bool)
+	}
+	src.used[key] = true
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return src.file[key]
+}
+
+// checkUnknownKeys fails if the configuration file has keys that do not
+// correspond to any setting, e.g. because of a typo.
+func (src *source) checkUnknownKeys() error {
+	var unknown []string
+	for key := range src.file {
+		if !src.used[key] {
+			unknown = append(unknown, fileKey(key))
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keys in configuration file: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// fileKey returns the configuration file key of an environment variable name.
+func fileKey(envName string) string {
+	return strings.ToLower(strings.TrimPrefix(envName, envPrefix))
+}
+
+// LoadFile reads configuration from a YAML file, with GHACRON_* environment
+// variables overriding its values. The file is a flat mapping whose keys are
+// the environment variable names without the GHACRON_ prefix, in lower case:
+//
+//	app_id: 123456
+//	app_private_key_path: /etc/ghacron/key.pem
+//	reconcile_interval_minutes: 10
+//
+// Map settings such as schedule_aliases and owner_timezones may be written
+// as mappings.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	file, err := parseFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return load(&source{file: file})
+}
+
+// parseFile parses a configuration file into values keyed by environment
+// variable name.
+func parseFile(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		switch v := v.(type) {
+		case nil:
+			// Unset, like an empty environment variable.
+		case string, bool, int, float64:
+			values[envPrefix+strings.ToUpper(key)] = fmt.Sprint(v)
+		case map[string]any:
+			if !mapSettings[envPrefix+strings.ToUpper(key)] {
+				return nil, fmt.Errorf("%s: expected a scalar value", key)
+			}
+			pairs, err := mappingPairs(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			values[envPrefix+strings.ToUpper(key)] = pairs
+		default:
+			return nil, fmt.Errorf("%s: expected a scalar value", key)
+		}
+	}
+	return values, nil
+}
+
+// mappingPairs encodes a mapping of scalars like the environment variable of a
+// map setting: "key=value" pairs separated by ';', sorted by key.
+func mappingPairs(m map[string]any) (string, error) {
+	pairs := make([]string, 0, len(m))
+	for key, v := range m {
+		switch v.(type) {
+		case string, bool, int, float64:
+			pairs = append(pairs, key+"="+fmt.Sprint(v))
+		default:
+			return "", fmt.Errorf("%s: expected a scalar value", key)
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";"), nil
+}
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"crypto"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -20,15 +21,35 @@ type Transport struct {
 	appID      int64
 	privateKey *rsa.PrivateKey
 	baseURL    string
+	// base sends the authenticated requests (http.DefaultTransport if nil).
+	base http.RoundTripper
+	// tokenTimeout bounds each request of a token refresh (no limit if 0),
+	// so a hung refresh can't stall every request waiting on mu.
+	tokenTimeout time.Duration
 
 	mu              sync.Mutex
 	installationID  int64
 	token           string
 	tokenExpiration time.Time
+	authenticated   bool // a token was obtained at least once
 }
 
 // NewTransport creates a new Transport from an App ID and PEM-encoded private key.
 func NewTransport(appID int64, privateKeyPEM []byte) (*Transport, error) {
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transport{
+		appID:      appID,
+		privateKey: key,
+		baseURL:    "https://api.github.com",
+	}, nil
+}
+
+// parsePrivateKey parses a PEM-encoded PKCS1 or PKCS8 RSA private key.
+func parsePrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
 	block, _ := pem.Decode(privateKeyPEM)
 	if block == nil {
 		return nil, fmt.Errorf("failed to decode PEM private key")
@@ -46,30 +67,60 @@ func NewTransport(appID int64, privateKeyPEM []byte) (*Transport, error) {
 			return nil, fmt.Errorf("private key is not an RSA key")
 		}
 	}
+	return key, nil
+}
 
-	return &Transport{
-		appID:      appID,
-		privateKey: key,
-		baseURL:    "https://api.github.com",
-	}, nil
+// SetPrivateKey replaces the App private key, e.g. after a key rotation. The
+// cached installation token is dropped so the next request signs with the new
+// key; requests in flight keep the token they already have.
+func (t *Transport) SetPrivateKey(privateKeyPEM []byte) error {
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.privateKey = key
+	t.token = ""
+	t.tokenExpiration = time.Time{}
+	return nil
 }
 
 // RoundTrip adds an installation token to the request and sends it.
 func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	token, err := t.getInstallationToken()
+	token, err := t.getInstallationToken(req.Context())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get installation token: %w", err)
 	}
 
 	req2 := req.Clone(req.Context())
 	req2.Header.Set("Authorization", "Bearer "+token)
-	req2.Header.Set("Accept", "application/vnd.github+json")
+	// Keep media types requested by the caller (e.g. the SHA-only commit format).
+	if req2.Header.Get("Accept") == "" {
+		req2.Header.Set("Accept", "application/vnd.github+json")
+	}
+
+	return t.baseTransport().RoundTrip(req2)
+}
 
-	return http.DefaultTransport.RoundTrip(req2)
+func (t *Transport) baseTransport() http.RoundTripper {
+	if t.base == nil {
+		return http.DefaultTransport
+	}
+	return t.base
+}
+
+// Authenticated reports whether an installation token has been obtained.
+func (t *Transport) Authenticated() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.authenticated
 }
 
 // getInstallationToken returns a cached token, refreshing if expired.
-func (t *Transport) getInstallationToken() (string, error) {
+// The refresh is bound to ctx, the context of the request needing the token.
+func (t *Transport) getInstallationToken(ctx context.Context) (string, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -78,24 +129,26 @@ func (t *Transport) getInstallationToken() (string, error) {
 	}
 
 	if t.installationID == 0 {
-		id, err := t.fetchInstallationID()
+		id, err := t.fetchInstallationID(ctx)
 		if err != nil {
 			return "", err
 		}
 		t.installationID = id
 	}
 
-	token, expiration, err := t.fetchInstallationToken(t.installationID)
+	token, expiration, err := t.fetchInstallationToken(ctx, t.installationID)
 	if err != nil {
 		return "", err
 	}
 
 	t.token = token
 	t.tokenExpiration = expiration
+	t.authenticated = true
 	return t.token, nil
 }
 
 // generateJWT creates a JWT for GitHub App authentication (RS256).
+// The caller must hold t.mu.
 func (t *Transport) generateJWT() (string, error) {
 	now := time.Now()
 	header := map[string]string{
@@ -126,18 +179,28 @@ func (t *Transport) generateJWT() (string, error) {
 	return signingInput + "." + sigB64, nil
 }
 
+// withTokenTimeout bounds ctx by the token refresh timeout.
+func (t *Transport) withTokenTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.tokenTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, t.tokenTimeout)
+}
+
 // fetchInstallationID retrieves the first installation ID using the App JWT.
-func (t *Transport) fetchInstallationID() (int64, error) {
+func (t *Transport) fetchInstallationID(ctx context.Context) (int64, error) {
 	jwt, err := t.generateJWT()
 	if err != nil {
 		return 0, err
 	}
 
-	req, _ := http.NewRequest("GET", t.baseURL+"/app/installations", nil)
+	ctx, cancel := t.withTokenTimeout(ctx)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", t.baseURL+"/app/installations", nil)
 	req.Header.Set("Authorization", "Bearer "+jwt)
 	req.Header.Set("Accept", "application/vnd.github+json")
 
-	resp, err := http.DefaultTransport.RoundTrip(req)
+	resp, err := t.baseTransport().RoundTrip(req)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get installations: %w", err)
 	}
@@ -162,18 +225,20 @@ func (t *Transport) fetchInstallationID() (int64, error) {
 }
 
 // fetchInstallationToken retrieves an installation access token.
-func (t *Transport) fetchInstallationToken(installationID int64) (string, time.Time, error) {
+func (t *Transport) fetchInstallationToken(ctx context.Context, installationID int64) (string, time.Time, error) {
 	jwt, err := t.generateJWT()
 	if err != nil {
 		return "", time.Time{}, err
 	}
 
+	ctx, cancel := t.withTokenTimeout(ctx)
+	defer cancel()
 	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", t.baseURL, installationID)
-	req, _ := http.NewRequest("POST", url, nil)
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
 	req.Header.Set("Authorization", "Bearer "+jwt)
 	req.Header.Set("Accept", "application/vnd.github+json")
 
-	resp, err := http.DefaultTransport.RoundTrip(req)
+	resp, err := t.baseTransport().RoundTrip(req)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to get access token: %w", err)
 	}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestTransport(t *testing.T, handler http.Handler) *Transport {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	transport, err := NewTransport(1, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	transport.baseURL = srv.URL
+	return transport
+}
+
+func TestTransport_TokenTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /app/installations", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1}]`))
+	})
+	mux.HandleFunc("POST /app/installations/1/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	transport := newTestTransport(t, mux)
+	transport.tokenTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := transport.getInstallationToken(t.Context())
+	if err == nil {
+		t.Fatal("expected an error from the hung token refresh")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("token refresh took %v, want it bounded by the timeout", elapsed)
+	}
+	if transport.installationID != 1 {
+		t.Errorf("installationID = %d, want 1", transport.installationID)
+	}
+	if transport.Authenticated() {
+		t.Error("Authenticated = true without a token")
+	}
+}
+
+func TestTransport_TokenRefreshUsesRequestContext(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /app/installations", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	transport := newTestTransport(t, mux)
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := transport.RoundTrip(req.WithContext(ctx)); err == nil {
+		t.Fatal("expected an error once the request context is done")
+	}
+}
+
+func TestTransport_SetPrivateKey(t *testing.T) {
+	transport := newTestTransport(t, http.NotFoundHandler())
+	transport.token = "cached"
+	transport.tokenExpiration = time.Now().Add(time.Hour)
+	oldKey := transport.privateKey
+
+	if err := transport.SetPrivateKey([]byte("not a key")); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+	if transport.privateKey != oldKey || transport.token != "cached" {
+		t.Error("an invalid key should leave the transport unchanged")
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := transport.SetPrivateKey(keyPEM); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !transport.privateKey.Equal(key) {
+		t.Error("private key was not replaced")
+	}
+	if transport.token != "" {
+		t.Error("cached token should be dropped after a key change")
+	}
+}
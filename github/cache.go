@@ -0,0 +1,62 @@
+package github
+
+import (
+	"sync"
+)
+
+// workflowCache keeps the last workflow listing of each repository together
+// with its ETag and the file contents, so unchanged repositories can be served
+// from a conditional request (304) without downloading anything.
+type workflowCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedWorkflows // "owner/repo@ref" -> listing
+}
+
+// cachedWorkflows is a cached workflow listing of a single repository ref.
+type cachedWorkflows struct {
+	etag  string
+	files []WorkflowFile // with Content
+}
+
+func newWorkflowCache() *workflowCache {
+	return &workflowCache{entries: make(map[string]cachedWorkflows)}
+}
+
+func workflowCacheKey(owner, repo, ref string) string {
+	return owner + "/" + repo + "@" + ref
+}
+
+// get returns the cached listing for a repository ref.
+func (wc *workflowCache) get(key string) (cachedWorkflows, bool) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	entry, ok := wc.entries[key]
+	return entry, ok
+}
+
+// put stores a listing for a repository ref.
+func (wc *workflowCache) put(key, etag string, files []WorkflowFile) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.entries[key] = cachedWorkflows{etag: etag, files: files}
+}
+
+// fillContents copies cached content into files whose path and SHA are
+// unchanged. It returns the indexes of the files it could not fill.
+func (entry cachedWorkflows) fillContents(files []WorkflowFile) []int {
+	known := make(map[string]string, len(entry.files)) // path@sha -> content
+	for _, f := range entry.files {
+		known[f.Path+"@"+f.SHA] = f.Content
+	}
+
+	var missing []int
+	for i := range files {
+		content, ok := known[files[i].Path+"@"+files[i].SHA]
+		if !ok {
+			missing = append(missing, i)
+			continue
+		}
+		files[i].Content = content
+	}
+	return missing
+}
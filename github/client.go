@@ -3,50 +3,277 @@ package github
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"path/filepath"
+	"net/url"
+	"path"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	gh "github.com/google/go-github/v68/github"
 )
 
 // Client is a GitHub API client.
 type Client struct {
-	gh *gh.Client
+	gh        *gh.Client
+	cache     *workflowCache
+	repoList  *repoListCache      // nil when the repository list is not cached
+	rateLimit *rateLimitTransport // nil in tests
+	auth      *Transport          // nil in tests
+}
+
+// ClientOptions tunes the HTTP behavior of a Client.
+type ClientOptions struct {
+	// RetryAttempts is the number of retries of a request that failed with a
+	// network error or a 5xx response (0 = no retries).
+	RetryAttempts int
+	// RetryBackoff is the delay before the first retry, doubled after each.
+	RetryBackoff time.Duration
+	// HTTPCache revalidates cached GET responses with their ETag, so
+	// unchanged resources are answered with 304 (not rate limited).
+	HTTPCache bool
+	// CACertPath is a PEM bundle trusted in addition to the system roots.
+	CACertPath string
+	// InsecureSkipVerify disables TLS certificate verification.
+	InsecureSkipVerify bool
+	// TokenTimeout bounds each request of an installation token refresh
+	// (0 = no limit).
+	TokenTimeout time.Duration
+	// LogHTTP logs every request sent to GitHub at debug level.
+	LogHTTP bool
+	// UserAgent is sent with every request (e.g. "ghacron/1.2.0"; "" keeps
+	// the default of the HTTP library).
+	UserAgent string
+	// RepoListTTL caches the installation repository list for this long
+	// (0 = list the repositories on every call).
+	RepoListTTL time.Duration
 }
 
 // NewClient creates a new GitHub client with App authentication.
-func NewClient(appID int64, privateKeyPEM []byte) (*Client, error) {
+func NewClient(appID int64, privateKeyPEM []byte, opts ClientOptions) (*Client, error) {
 	transport, err := NewTransport(appID, privateKeyPEM)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transport: %w", err)
 	}
+	httpTransport, err := newHTTPTransport(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP transport: %w", err)
+	}
+	var base http.RoundTripper = httpTransport
+	if opts.UserAgent != "" {
+		base = newUserAgentTransport(base, opts.UserAgent)
+	}
+	var sender http.RoundTripper = newTraceTransport(newMetricsTransport(base))
+	if opts.LogHTTP {
+		sender = newLogTransport(sender)
+	}
+	transport.base = sender
+	transport.tokenTimeout = opts.TokenTimeout
 
-	httpClient := &http.Client{Transport: transport}
+	var next http.RoundTripper = transport
+	if opts.RetryAttempts > 0 {
+		next = newRetryTransport(transport, opts.RetryAttempts, opts.RetryBackoff)
+	}
+	rateLimit := newRateLimitTransport(next)
+	var outer http.RoundTripper = rateLimit
+	if opts.HTTPCache {
+		outer = newETagTransport(rateLimit)
+	}
+	httpClient := &http.Client{Transport: outer}
 	ghClient := gh.NewClient(httpClient)
 
-	return &Client{gh: ghClient}, nil
+	client := &Client{gh: ghClient, cache: newWorkflowCache(), rateLimit: rateLimit, auth: transport}
+	if opts.RepoListTTL > 0 {
+		client.repoList = &repoListCache{ttl: opts.RepoListTTL}
+	}
+	return client, nil
+}
+
+// Authenticated reports whether the client has authenticated with GitHub as
+// the App installation at least once.
+func (c *Client) Authenticated() bool {
+	return c.auth != nil && c.auth.Authenticated()
+}
+
+// CheckConnectivity obtains an installation token (cached if still valid) and
+// sends a request with it, verifying that GitHub is reachable with the App's
+// credentials. The request (GET /rate_limit) does not count against the rate
+// limit.
+func (c *Client) CheckConnectivity(ctx context.Context) error {
+	if _, _, err := c.gh.RateLimit.Get(ctx); err != nil {
+		return fmt.Errorf("failed to reach GitHub: %w", classifyError(err))
+	}
+	return nil
+}
+
+// PollRateLimit requests GET /rate_limit every interval until ctx is done, so
+// the rate limit reported by RateLimit stays current while no other requests
+// are sent. The request does not count against the rate limit.
+func (c *Client) PollRateLimit(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, _, err := c.gh.RateLimit.Get(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("failed to poll GitHub rate limit", "error", classifyError(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReloadPrivateKey replaces the App private key used to authenticate, so the
+// key can be rotated without a restart.
+func (c *Client) ReloadPrivateKey(privateKeyPEM []byte) error {
+	if c.auth == nil {
+		return fmt.Errorf("client has no App authentication")
+	}
+	return c.auth.SetPrivateKey(privateKeyPEM)
+}
+
+// RateLimit returns the GitHub API rate limit as last reported by the API,
+// and false if no response has reported it yet.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	if c.rateLimit == nil {
+		return RateLimit{}, false
+	}
+	return c.rateLimit.snapshot()
 }
 
-// GetInstallationRepos returns all repositories accessible to the installation.
+// GetInstallationRepos returns all repositories accessible to the installation,
+// from the cache while it is fresh (see ClientOptions.RepoListTTL).
 func (c *Client) GetInstallationRepos(ctx context.Context) ([]Repository, error) {
-	var repos []Repository
-	opts := &gh.ListOptions{PerPage: 100}
+	if c.repoList == nil {
+		return c.listInstallationRepos(ctx)
+	}
+	if repos, ok := c.repoList.get(time.Now()); ok {
+		slog.Debug("using cached installation repository list", "repo_count", len(repos))
+		return repos, nil
+	}
+	repos, err := c.listInstallationRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.repoList.put(repos, time.Now())
+	return repos, nil
+}
+
+// repoListConcurrency bounds the concurrent page requests of the installation
+// repository list.
+const repoListConcurrency = 4
+
+// listInstallationRepos lists the repositories of the installation. The first
+// page tells the number of pages, and the others are fetched concurrently.
+func (c *Client) listInstallationRepos(ctx context.Context) ([]Repository, error) {
+	repos, resp, err := c.listReposPage(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if resp.LastPage == 0 {
+		// No last page link: either a single page, or follow next links.
+		for page := resp.NextPage; page != 0; page = resp.NextPage {
+			var pageRepos []Repository
+			pageRepos, resp, err = c.listReposPage(ctx, page)
+			if err != nil {
+				return nil, err
+			}
+			repos = append(repos, pageRepos...)
+		}
+		return repos, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pages := make([][]Repository, resp.LastPage+1)
+	sem := make(chan struct{}, repoListConcurrency)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for page := 2; page <= resp.LastPage; page++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			pageRepos, _, err := c.listReposPage(ctx, page)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel() // the remaining pages are of no use
+				}
+				mu.Unlock()
+				return
+			}
+			pages[page] = pageRepos
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	for _, pageRepos := range pages[2:] {
+		repos = append(repos, pageRepos...)
+	}
+	return repos, nil
+}
+
+// listReposPage returns a page of the installation repository list.
+func (c *Client) listReposPage(ctx context.Context, page int) ([]Repository, *gh.Response, error) {
+	result, resp, err := c.gh.Apps.ListRepos(ctx, &gh.ListOptions{Page: page, PerPage: 100})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list repositories (page %d): %w", page, classifyError(err))
+	}
+	repos := make([]Repository, 0, len(result.Repositories))
+	for _, r := range result.Repositories {
+		repos = append(repos, Repository{
+			Owner:         r.GetOwner().GetLogin(),
+			Name:          r.GetName(),
+			DefaultBranch: r.GetDefaultBranch(),
+			Archived:      r.GetArchived(),
+			Fork:          r.GetFork(),
+			Topics:        r.Topics,
+		})
+	}
+	return repos, resp, nil
+}
+
+// GetHeadSHA returns the commit SHA that ref points to. If lastSHA is set and
+// ref still points to it, GitHub answers 304 (not counted against the rate
+// limit) and lastSHA is returned.
+func (c *Client) GetHeadSHA(ctx context.Context, owner, repo, ref, lastSHA string) (string, error) {
+	sha, resp, err := c.gh.Repositories.GetCommitSHA1(ctx, owner, repo, ref, lastSHA)
+	if resp != nil && resp.StatusCode == http.StatusNotModified {
+		return lastSHA, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get head SHA (%s/%s@%s): %w", owner, repo, ref, classifyError(err))
+	}
+	return sha, nil
+}
+
+// ListBranches returns the names of all branches in a repository.
+func (c *Client) ListBranches(ctx context.Context, owner, repo string) ([]string, error) {
+	var branches []string
+	opts := &gh.BranchListOptions{ListOptions: gh.ListOptions{PerPage: 100}}
 
 	for {
-		result, resp, err := c.gh.Apps.ListRepos(ctx, opts)
+		result, resp, err := c.gh.Repositories.ListBranches(ctx, owner, repo, opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list repositories: %w", err)
+			return nil, fmt.Errorf("failed to list branches (%s/%s): %w", owner, repo, classifyError(err))
 		}
 
-		for _, r := range result.Repositories {
-			repos = append(repos, Repository{
-				Owner:         r.GetOwner().GetLogin(),
-				Name:          r.GetName(),
-				DefaultBranch: r.GetDefaultBranch(),
-			})
+		for _, b := range result {
+			branches = append(branches, b.GetName())
 		}
 
 		if resp.NextPage == 0 {
@@ -55,39 +282,132 @@ func (c *Client) GetInstallationRepos(ctx context.Context) ([]Repository, error)
 		opts.Page = resp.NextPage
 	}
 
-	return repos, nil
+	return branches, nil
+}
+
+// workflowsDir is the directory GitHub Actions loads workflows from.
+const workflowsDir = ".github/workflows"
+
+// isWorkflowFileName reports whether a file name has a workflow extension.
+func isWorkflowFileName(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".yml" || ext == ".yaml"
 }
 
 // GetWorkflowFiles returns workflow files under .github/workflows/.
 func (c *Client) GetWorkflowFiles(ctx context.Context, owner, repo string) ([]WorkflowFile, error) {
-	_, dirContent, _, err := c.gh.Repositories.GetContents(
-		ctx, owner, repo,
-		".github/workflows",
-		&gh.RepositoryContentGetOptions{},
-	)
+	files, _, _, err := c.listWorkflowDir(ctx, owner, repo, "", "")
+	return files, err
+}
+
+// listWorkflowDir lists workflow files under .github/workflows/ at ref. If etag
+// is set it is sent as If-None-Match, and notModified reports a 304 response
+// (which does not count against the rate limit).
+func (c *Client) listWorkflowDir(ctx context.Context, owner, repo, ref, etag string) (files []WorkflowFile, newETag string, notModified bool, err error) {
+	u := fmt.Sprintf("repos/%s/%s/contents/%s", owner, repo, workflowsDir)
+	if ref != "" {
+		u += "?ref=" + url.QueryEscape(ref)
+	}
+	req, err := c.gh.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to create request (%s/%s): %w", owner, repo, err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	var dirContent []*gh.RepositoryContent
+	resp, err := c.gh.Do(ctx, req, &dirContent)
+	if resp != nil && resp.StatusCode == http.StatusNotModified {
+		return nil, etag, true, nil
+	}
 	if err != nil {
 		// 404 = workflows directory does not exist
-		if ghErr, ok := err.(*gh.ErrorResponse); ok && ghErr.Response.StatusCode == 404 {
-			return nil, nil
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, resp.Header.Get("ETag"), false, nil
 		}
-		return nil, fmt.Errorf("failed to list workflows (%s/%s): %w", owner, repo, err)
+		return nil, "", false, fmt.Errorf("failed to list workflows (%s/%s): %w", owner, repo, classifyError(err))
 	}
 
-	var files []WorkflowFile
 	for _, entry := range dirContent {
 		name := entry.GetName()
-		ext := strings.ToLower(filepath.Ext(name))
-		if ext == ".yml" || ext == ".yaml" {
+		if isWorkflowFileName(name) {
 			files = append(files, WorkflowFile{
 				Name: name,
 				Path: entry.GetPath(),
+				SHA:  entry.GetSHA(),
 			})
 		}
 	}
 
+	return files, resp.Header.Get("ETag"), false, nil
+}
+
+// GetWorkflowContents returns the workflow files under .github/workflows/ at
+// ref together with their content.
+//
+// The directory listing is requested conditionally with the ETag of the
+// previous call, so an unchanged repository costs a single 304 response.
+// Contents of files whose path and SHA are unchanged are reused from the
+// cache; only the blobs of new or changed files are fetched.
+func (c *Client) GetWorkflowContents(ctx context.Context, owner, repo, ref string) ([]WorkflowFile, error) {
+	key := workflowCacheKey(owner, repo, ref)
+	cached, _ := c.cache.get(key)
+
+	files, etag, notModified, err := c.listWorkflowDir(ctx, owner, repo, ref, cached.etag)
+	if err != nil {
+		return nil, err
+	}
+	if notModified {
+		return cached.files, nil
+	}
+	for _, i := range cached.fillContents(files) {
+		if files[i].Content, err = c.getBlob(ctx, owner, repo, files[i].SHA); err != nil {
+			return nil, fmt.Errorf("failed to get %s (%s/%s): %w", files[i].Path, owner, repo, err)
+		}
+	}
+	c.cache.put(key, etag, files)
 	return files, nil
 }
 
+// getBlob returns the content of the blob with the given SHA.
+func (c *Client) getBlob(ctx context.Context, owner, repo, sha string) (string, error) {
+	data, _, err := c.gh.Git.GetBlobRaw(ctx, owner, repo, sha)
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return string(data), nil
+}
+
+// ListWorkflows returns the GitHub Actions workflows of a repository.
+func (c *Client) ListWorkflows(ctx context.Context, owner, repo string) ([]Workflow, error) {
+	var workflows []Workflow
+	opts := &gh.ListOptions{PerPage: 100}
+
+	for {
+		result, resp, err := c.gh.Actions.ListWorkflows(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Actions workflows (%s/%s): %w", owner, repo, classifyError(err))
+		}
+
+		for _, w := range result.Workflows {
+			workflows = append(workflows, Workflow{
+				ID:    w.GetID(),
+				Name:  w.GetName(),
+				Path:  w.GetPath(),
+				State: w.GetState(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return workflows, nil
+}
+
 // GetFileContent returns the content of a file in a repository.
 func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
 	opts := &gh.RepositoryContentGetOptions{}
@@ -97,7 +417,7 @@ func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref stri
 
 	fileContent, _, _, err := c.gh.Repositories.GetContents(ctx, owner, repo, path, opts)
 	if err != nil {
-		return "", fmt.Errorf("failed to get file content (%s/%s/%s): %w", owner, repo, path, err)
+		return "", fmt.Errorf("failed to get file content (%s/%s/%s): %w", owner, repo, path, classifyError(err))
 	}
 	if fileContent == nil {
 		return "", fmt.Errorf("file not found: %s/%s/%s", owner, repo, path)
@@ -122,21 +442,98 @@ func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref stri
 	return content, nil
 }
 
-// DispatchWorkflow triggers a workflow_dispatch event.
-func (c *Client) DispatchWorkflow(ctx context.Context, owner, repo, workflowFile, ref string) error {
-	resp, err := c.gh.Actions.CreateWorkflowDispatchEventByFileName(
-		ctx, owner, repo, workflowFile,
-		gh.CreateWorkflowDispatchEventRequest{
-			Ref: ref,
-		},
-	)
+// GetFile returns the content and blob SHA of a file on a branch. A missing
+// file or branch is reported as found == false.
+func (c *Client) GetFile(ctx context.Context, owner, repo, path, branch string) (content []byte, sha string, found bool, err error) {
+	fileContent, _, resp, err := c.gh.Repositories.GetContents(ctx, owner, repo, path, &gh.RepositoryContentGetOptions{Ref: branch})
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return nil, "", false, nil
+		}
+		return nil, "", false, fmt.Errorf("failed to get file (%s/%s/%s@%s): %w", owner, repo, path, branch, classifyError(err))
+	}
+	if fileContent == nil {
+		return nil, "", false, fmt.Errorf("not a file: %s/%s/%s@%s", owner, repo, path, branch)
+	}
+	decoded, err := fileContent.GetContent()
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to decode file (%s/%s/%s@%s): %w", owner, repo, path, branch, err)
+	}
+	return []byte(decoded), fileContent.GetSHA(), true, nil
+}
+
+// PutFile commits content to the file at path on an existing branch. sha is
+// the blob SHA the file was read with ("" creates the file); if the file has
+// changed since, the error wraps ErrConflict.
+func (c *Client) PutFile(ctx context.Context, owner, repo, path, branch, message string, content []byte, sha string) error {
+	opts := &gh.RepositoryContentFileOptions{
+		Message: gh.Ptr(message),
+		Content: content,
+		Branch:  gh.Ptr(branch),
+	}
+	if sha != "" {
+		opts.SHA = gh.Ptr(sha)
+	}
+	_, resp, err := c.gh.Repositories.UpdateFile(ctx, owner, repo, path, opts)
+	if err != nil {
+		// Creating a file that exists is rejected with 422 instead of 409.
+		if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity && sha == "" {
+			err = &APIError{StatusCode: resp.StatusCode, Kind: ErrConflict, Err: err}
+		}
+		return fmt.Errorf("failed to put file (%s/%s/%s@%s): %w", owner, repo, path, branch, classifyError(err))
+	}
+	return nil
+}
+
+// CreateOrphanBranch creates a branch whose only commit has no parent and
+// contains just the file at path. If the branch already exists, the error
+// wraps ErrConflict.
+func (c *Client) CreateOrphanBranch(ctx context.Context, owner, repo, branch, path, message string, content []byte) error {
+	tree, _, err := c.gh.Git.CreateTree(ctx, owner, repo, "", []*gh.TreeEntry{{
+		Path:    gh.Ptr(path),
+		Mode:    gh.Ptr("100644"),
+		Type:    gh.Ptr("blob"),
+		Content: gh.Ptr(string(content)),
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to create tree (%s/%s): %w", owner, repo, classifyError(err))
+	}
+	commit, _, err := c.gh.Git.CreateCommit(ctx, owner, repo, &gh.Commit{
+		Message: gh.Ptr(message),
+		Tree:    &gh.Tree{SHA: tree.SHA},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create commit (%s/%s): %w", owner, repo, classifyError(err))
+	}
+	_, resp, err := c.gh.Git.CreateRef(ctx, owner, repo, &gh.Reference{
+		Ref:    gh.Ptr("refs/heads/" + branch),
+		Object: &gh.GitObject{SHA: commit.SHA},
+	})
+	if err != nil {
+		// "Reference already exists"
+		if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+			err = &APIError{StatusCode: resp.StatusCode, Kind: ErrConflict, Err: err}
+		}
+		return fmt.Errorf("failed to create branch (%s/%s/%s): %w", owner, repo, branch, classifyError(err))
+	}
+	return nil
+}
+
+// DispatchWorkflow triggers a workflow_dispatch event with the given inputs
+// (a JSON object, or empty for none).
+func (c *Client) DispatchWorkflow(ctx context.Context, owner, repo, workflowFile, ref, inputs string) error {
+	req, err := workflowDispatchRequest(ref, inputs)
+	if err != nil {
+		return fmt.Errorf("failed to dispatch workflow (%s/%s/%s): %w", owner, repo, workflowFile, err)
+	}
+	resp, err := c.gh.Actions.CreateWorkflowDispatchEventByFileName(ctx, owner, repo, workflowFile, req)
 	if err != nil {
 		if resp != nil {
 			return fmt.Errorf("failed to dispatch workflow (%s/%s/%s, status=%d): %w",
-				owner, repo, workflowFile, resp.StatusCode, err)
+				owner, repo, workflowFile, resp.StatusCode, classifyError(err))
 		}
 		return fmt.Errorf("failed to dispatch workflow (%s/%s/%s): %w",
-			owner, repo, workflowFile, err)
+			owner, repo, workflowFile, classifyError(err))
 	}
 
 	slog.Info("dispatched workflow_dispatch",
@@ -148,6 +545,221 @@ func (c *Client) DispatchWorkflow(ctx context.Context, owner, repo, workflowFile
 	return nil
 }
 
+// CreateCheckRun posts a completed check run on a commit.
+func (c *Client) CreateCheckRun(ctx context.Context, owner, repo string, check CheckRun) error {
+	output := &gh.CheckRunOutput{
+		Title:   gh.Ptr(check.Title),
+		Summary: gh.Ptr(check.Summary),
+	}
+	for _, a := range check.Annotations {
+		output.Annotations = append(output.Annotations, &gh.CheckRunAnnotation{
+			Path:            gh.Ptr(a.Path),
+			StartLine:       gh.Ptr(a.Line),
+			EndLine:         gh.Ptr(a.Line),
+			AnnotationLevel: gh.Ptr(a.Level),
+			Title:           gh.Ptr(a.Title),
+			Message:         gh.Ptr(a.Message),
+		})
+	}
+	_, _, err := c.gh.Checks.CreateCheckRun(ctx, owner, repo, gh.CreateCheckRunOptions{
+		Name:       check.Name,
+		HeadSHA:    check.HeadSHA,
+		Status:     gh.Ptr("completed"),
+		Conclusion: gh.Ptr(check.Conclusion),
+		Output:     output,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create check run (%s/%s@%s): %w", owner, repo, check.HeadSHA, classifyError(err))
+	}
+	return nil
+}
+
+// FindOpenIssue returns the open issue with the given label and title, and
+// false if there is none (first 100 issues with the label only).
+func (c *Client) FindOpenIssue(ctx context.Context, owner, repo, label, title string) (Issue, bool, error) {
+	issues, _, err := c.gh.Issues.ListByRepo(ctx, owner, repo, &gh.IssueListByRepoOptions{
+		State:       "open",
+		Labels:      []string{label},
+		ListOptions: gh.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return Issue{}, false, fmt.Errorf("failed to list issues (%s/%s): %w", owner, repo, classifyError(err))
+	}
+	for _, issue := range issues {
+		if issue.GetTitle() == title && !issue.IsPullRequest() {
+			return toIssue(issue), true, nil
+		}
+	}
+	return Issue{}, false, nil
+}
+
+// CreateIssue opens an issue with the given labels.
+func (c *Client) CreateIssue(ctx context.Context, owner, repo, title, body string, labels []string) (Issue, error) {
+	issue, _, err := c.gh.Issues.Create(ctx, owner, repo, &gh.IssueRequest{
+		Title:  gh.Ptr(title),
+		Body:   gh.Ptr(body),
+		Labels: &labels,
+	})
+	if err != nil {
+		return Issue{}, fmt.Errorf("failed to create issue (%s/%s): %w", owner, repo, classifyError(err))
+	}
+	return toIssue(issue), nil
+}
+
+// CommentOnIssue adds a comment to an issue.
+func (c *Client) CommentOnIssue(ctx context.Context, owner, repo string, number int, body string) error {
+	_, _, err := c.gh.Issues.CreateComment(ctx, owner, repo, number, &gh.IssueComment{Body: gh.Ptr(body)})
+	if err != nil {
+		return fmt.Errorf("failed to comment on issue (%s/%s#%d): %w", owner, repo, number, classifyError(err))
+	}
+	return nil
+}
+
+func toIssue(issue *gh.Issue) Issue {
+	return Issue{Number: issue.GetNumber(), Title: issue.GetTitle(), HTMLURL: issue.GetHTMLURL()}
+}
+
+// DispatchWorkflowByID triggers a workflow_dispatch event of the workflow with
+// the given ID, which stays valid when the workflow file is renamed.
+func (c *Client) DispatchWorkflowByID(ctx context.Context, owner, repo string, workflowID int64, ref, inputs string) error {
+	req, err := workflowDispatchRequest(ref, inputs)
+	if err != nil {
+		return fmt.Errorf("failed to dispatch workflow (%s/%s/%d): %w", owner, repo, workflowID, err)
+	}
+	resp, err := c.gh.Actions.CreateWorkflowDispatchEventByID(ctx, owner, repo, workflowID, req)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to dispatch workflow (%s/%s/%d, status=%d): %w",
+				owner, repo, workflowID, resp.StatusCode, classifyError(err))
+		}
+		return fmt.Errorf("failed to dispatch workflow (%s/%s/%d): %w",
+			owner, repo, workflowID, classifyError(err))
+	}
+
+	slog.Info("dispatched workflow_dispatch",
+		"owner", owner,
+		"repo", repo,
+		"workflow_id", workflowID,
+		"ref", ref,
+	)
+	return nil
+}
+
+// workflowDispatchRequest builds the request of a workflow_dispatch on ref
+// with the given inputs (a JSON object, or empty for none).
+func workflowDispatchRequest(ref, inputs string) (gh.CreateWorkflowDispatchEventRequest, error) {
+	req := gh.CreateWorkflowDispatchEventRequest{Ref: ref}
+	if inputs != "" {
+		if err := json.Unmarshal([]byte(inputs), &req.Inputs); err != nil {
+			return req, fmt.Errorf("invalid inputs: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// RepositoryDispatch sends a repository_dispatch event with the given event
+// type and client payload (a JSON object, or empty for none).
+func (c *Client) RepositoryDispatch(ctx context.Context, owner, repo, eventType, payload string) error {
+	opts := gh.DispatchRequestOptions{EventType: eventType}
+	if payload != "" {
+		raw := json.RawMessage(payload)
+		opts.ClientPayload = &raw
+	}
+	_, resp, err := c.gh.Repositories.Dispatch(ctx, owner, repo, opts)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to send repository_dispatch (%s/%s/%s, status=%d): %w",
+				owner, repo, eventType, resp.StatusCode, classifyError(err))
+		}
+		return fmt.Errorf("failed to send repository_dispatch (%s/%s/%s): %w",
+			owner, repo, eventType, classifyError(err))
+	}
+
+	slog.Info("dispatched repository_dispatch",
+		"owner", owner,
+		"repo", repo,
+		"event_type", eventType,
+	)
+	return nil
+}
+
+// activeRunStatuses are the workflow run statuses that count as still running.
+var activeRunStatuses = []string{"queued", "in_progress"}
+
+// HasActiveRun reports whether a workflow_dispatch run of the workflow on ref
+// that was not triggered by a user is still queued or in progress.
+func (c *Client) HasActiveRun(ctx context.Context, owner, repo, workflowFile, ref string) (bool, error) {
+	for _, status := range activeRunStatuses {
+		runs, _, err := c.gh.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflowFile, &gh.ListWorkflowRunsOptions{
+			Branch:      ref,
+			Event:       "workflow_dispatch",
+			Status:      status,
+			ListOptions: gh.ListOptions{PerPage: 100},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to list workflow runs (%s/%s/%s): %w", owner, repo, workflowFile, classifyError(err))
+		}
+		if slices.ContainsFunc(runs.WorkflowRuns, func(r *gh.WorkflowRun) bool { return !startedByUser(r) }) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindDispatchedRun returns the workflow run created by a dispatch of the
+// workflow on ref at since: the earliest workflow_dispatch run created at or
+// after since that was not triggered by a user. It returns false if GitHub has
+// not created the run yet.
+func (c *Client) FindDispatchedRun(ctx context.Context, owner, repo, workflowFile, ref string, since time.Time) (WorkflowRun, bool, error) {
+	result, _, err := c.gh.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflowFile, &gh.ListWorkflowRunsOptions{
+		Branch:      ref,
+		Event:       "workflow_dispatch",
+		Created:     ">=" + since.UTC().Format(time.RFC3339),
+		ListOptions: gh.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return WorkflowRun{}, false, fmt.Errorf("failed to list workflow runs (%s/%s/%s): %w", owner, repo, workflowFile, classifyError(err))
+	}
+
+	// Runs are newest first; the earliest match is the one the dispatch created.
+	for i := len(result.WorkflowRuns) - 1; i >= 0; i-- {
+		r := result.WorkflowRuns[i]
+		if r.GetCreatedAt().Before(since) || startedByUser(r) {
+			continue
+		}
+		return toWorkflowRun(r), true, nil
+	}
+	return WorkflowRun{}, false, nil
+}
+
+// startedByUser reports whether a run was dispatched from the UI or by a PAT,
+// which have a User as triggering actor, rather than by ghacron, which
+// dispatches as the App's bot.
+func startedByUser(r *gh.WorkflowRun) bool {
+	return r.GetTriggeringActor().GetType() == "User"
+}
+
+// GetWorkflowRun returns a single workflow run.
+func (c *Client) GetWorkflowRun(ctx context.Context, owner, repo string, runID int64) (WorkflowRun, error) {
+	r, _, err := c.gh.Actions.GetWorkflowRunByID(ctx, owner, repo, runID)
+	if err != nil {
+		return WorkflowRun{}, fmt.Errorf("failed to get workflow run (%s/%s/%d): %w", owner, repo, runID, classifyError(err))
+	}
+	return toWorkflowRun(r), nil
+}
+
+func toWorkflowRun(r *gh.WorkflowRun) WorkflowRun {
+	return WorkflowRun{
+		ID:         r.GetID(),
+		Event:      r.GetEvent(),
+		HeadBranch: r.GetHeadBranch(),
+		Status:     r.GetStatus(),
+		Conclusion: r.GetConclusion(),
+		HTMLURL:    r.GetHTMLURL(),
+		CreatedAt:  r.GetCreatedAt().Time,
+	}
+}
+
 // GetVariable returns the value of a repository Actions variable.
 func (c *Client) GetVariable(ctx context.Context, owner, repo, name string) (string, error) {
 	variable, resp, err := c.gh.Actions.GetRepoVariable(ctx, owner, repo, name)
@@ -155,11 +767,119 @@ func (c *Client) GetVariable(ctx context.Context, owner, repo, name string) (str
 		if resp != nil && resp.StatusCode == 404 {
 			return "", nil // variable does not exist
 		}
-		return "", fmt.Errorf("failed to get variable (%s/%s/%s): %w", owner, repo, name, err)
+		return "", fmt.Errorf("failed to get variable (%s/%s/%s): %w", owner, repo, name, classifyError(err))
+	}
+	return variable.Value, nil
+}
+
+// GetOrgVariable returns the value of an organization Actions variable.
+func (c *Client) GetOrgVariable(ctx context.Context, org, name string) (string, error) {
+	variable, resp, err := c.gh.Actions.GetOrgVariable(ctx, org, name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return "", nil // variable does not exist
+		}
+		return "", fmt.Errorf("failed to get organization variable (%s/%s): %w", org, name, classifyError(err))
 	}
 	return variable.Value, nil
 }
 
+// SetOrgVariable creates or updates an organization Actions variable. New
+// variables are created with "selected" visibility and no selected
+// repositories, so no workflow can read them.
+func (c *Client) SetOrgVariable(ctx context.Context, org, name, value string) error {
+	_, err := c.gh.Actions.UpdateOrgVariable(ctx, org, &gh.ActionsVariable{
+		Name:  name,
+		Value: value,
+	})
+	if err != nil {
+		_, createErr := c.gh.Actions.CreateOrgVariable(ctx, org, &gh.ActionsVariable{
+			Name:                  name,
+			Value:                 value,
+			Visibility:            gh.Ptr("selected"),
+			SelectedRepositoryIDs: &gh.SelectedRepoIDs{},
+		})
+		if createErr != nil {
+			return fmt.Errorf("failed to set organization variable (%s/%s): update=%v, create=%w", org, name, err, classifyError(createErr))
+		}
+	}
+	return nil
+}
+
+// ListOrgVariables returns the names of all organization Actions variables.
+func (c *Client) ListOrgVariables(ctx context.Context, org string) ([]string, error) {
+	var names []string
+	opts := &gh.ListOptions{PerPage: 30} // maximum page size of the variables API
+
+	for {
+		result, resp, err := c.gh.Actions.ListOrgVariables(ctx, org, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization variables (%s): %w", org, classifyError(err))
+		}
+
+		for _, v := range result.Variables {
+			names = append(names, v.Name)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return names, nil
+}
+
+// DeleteOrgVariable deletes an organization Actions variable. Deleting a
+// variable that does not exist is not an error.
+func (c *Client) DeleteOrgVariable(ctx context.Context, org, name string) error {
+	resp, err := c.gh.Actions.DeleteOrgVariable(ctx, org, name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return nil
+		}
+		return fmt.Errorf("failed to delete organization variable (%s/%s): %w", org, name, classifyError(err))
+	}
+	return nil
+}
+
+// ListVariables returns the names of all repository Actions variables.
+func (c *Client) ListVariables(ctx context.Context, owner, repo string) ([]string, error) {
+	var names []string
+	opts := &gh.ListOptions{PerPage: 30} // maximum page size of the variables API
+
+	for {
+		result, resp, err := c.gh.Actions.ListRepoVariables(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list variables (%s/%s): %w", owner, repo, classifyError(err))
+		}
+
+		for _, v := range result.Variables {
+			names = append(names, v.Name)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return names, nil
+}
+
+// DeleteVariable deletes a repository Actions variable. Deleting a variable
+// that does not exist is not an error.
+func (c *Client) DeleteVariable(ctx context.Context, owner, repo, name string) error {
+	resp, err := c.gh.Actions.DeleteRepoVariable(ctx, owner, repo, name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return nil
+		}
+		return fmt.Errorf("failed to delete variable (%s/%s/%s): %w", owner, repo, name, classifyError(err))
+	}
+	return nil
+}
+
 // SetVariable creates or updates a repository Actions variable.
 func (c *Client) SetVariable(ctx context.Context, owner, repo, name, value string) error {
 	_, err := c.gh.Actions.UpdateRepoVariable(ctx, owner, repo, &gh.ActionsVariable{
@@ -172,7 +892,7 @@ func (c *Client) SetVariable(ctx context.Context, owner, repo, name, value strin
 			Value: value,
 		})
 		if createErr != nil {
-			return fmt.Errorf("failed to set variable (%s/%s/%s): update=%v, create=%v", owner, repo, name, err, createErr)
+			return fmt.Errorf("failed to set variable (%s/%s/%s): update=%v, create=%w", owner, repo, name, err, classifyError(createErr))
 		}
 	}
 	return nil
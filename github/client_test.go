@@ -0,0 +1,279 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v68/github"
+)
+
+// newTestClient returns a Client whose API is served by handler.
+func newTestClient(t *testing.T, handler http.Handler) (*Client, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	ghClient := gh.NewClient(nil)
+	baseURL, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ghClient.BaseURL = baseURL
+
+	return &Client{gh: ghClient, cache: newWorkflowCache()}, srv
+}
+
+func TestGetWorkflowContents_Cache(t *testing.T) {
+	blobs := map[string]string{
+		"sha-ci-1":     "on:\n  workflow_dispatch:\n",
+		"sha-ci-2":     "on:\n  workflow_dispatch: {}\n",
+		"sha-deploy-1": "on:\n  repository_dispatch:\n",
+	}
+	var listing atomic.Value
+	listing.Store(map[string]string{"ci.yml": "sha-ci-1", "deploy.yml": "sha-deploy-1"})
+	var etag atomic.Value
+	etag.Store(`"v1"`)
+	var listings, notModified, blobFetches atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/contents/.github/workflows", func(w http.ResponseWriter, r *http.Request) {
+		listings.Add(1)
+		current := etag.Load().(string)
+		if r.Header.Get("If-None-Match") == current {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", current)
+		var entries []map[string]string
+		for name, sha := range listing.Load().(map[string]string) {
+			entries = append(entries, map[string]string{"name": name, "path": ".github/workflows/" + name, "sha": sha, "type": "file"})
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+	mux.HandleFunc("/repos/o/r/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		blobFetches.Add(1)
+		content, ok := blobs[r.PathValue("sha")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	})
+	client, _ := newTestClient(t, mux)
+
+	ctx := t.Context()
+	contents := func(files []WorkflowFile) map[string]string {
+		m := make(map[string]string)
+		for _, f := range files {
+			m[f.Name] = f.Content
+		}
+		return m
+	}
+
+	// First call: listing + a blob per file.
+	files, err := client.GetWorkflowContents(ctx, "o", "r", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := contents(files); got["ci.yml"] != blobs["sha-ci-1"] || got["deploy.yml"] != blobs["sha-deploy-1"] {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	if blobFetches.Load() != 2 {
+		t.Errorf("blob fetches = %d, want 2", blobFetches.Load())
+	}
+
+	// Second call: unchanged ETag -> 304, served from cache.
+	files, err = client.GetWorkflowContents(ctx, "o", "r", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 || notModified.Load() != 1 || blobFetches.Load() != 2 {
+		t.Errorf("files = %d, 304 responses = %d, blob fetches = %d; want 2, 1, 2 (served from cache)", len(files), notModified.Load(), blobFetches.Load())
+	}
+
+	// Third call: one file changed -> only its blob is fetched.
+	listing.Store(map[string]string{"ci.yml": "sha-ci-2", "deploy.yml": "sha-deploy-1"})
+	etag.Store(`"v2"`)
+	files, err = client.GetWorkflowContents(ctx, "o", "r", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := contents(files); got["ci.yml"] != blobs["sha-ci-2"] || got["deploy.yml"] != blobs["sha-deploy-1"] {
+		t.Errorf("unexpected files: %v", files)
+	}
+	if blobFetches.Load() != 3 {
+		t.Errorf("blob fetches = %d, want 3 (unchanged contents reused by path+SHA)", blobFetches.Load())
+	}
+	if listings.Load() != 3 {
+		t.Errorf("listings = %d, want 3", listings.Load())
+	}
+}
+
+func TestFindDispatchedRun(t *testing.T) {
+	since := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/actions/workflows/ci.yml/runs", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("event"); got != "workflow_dispatch" {
+			t.Errorf("event = %q, want workflow_dispatch", got)
+		}
+		run := func(id int64, created time.Time, actorType string) map[string]any {
+			return map[string]any{
+				"id":               id,
+				"status":           "queued",
+				"created_at":       created.Format(time.RFC3339),
+				"triggering_actor": map[string]string{"type": actorType},
+			}
+		}
+		// Newest first, as returned by GitHub.
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"total_count": 4,
+			"workflow_runs": []map[string]any{
+				run(4, since.Add(time.Minute), "Bot"),
+				run(3, since.Add(10*time.Second), "Bot"),
+				run(2, since.Add(time.Second), "User"),
+				run(1, since.Add(-time.Hour), "Bot"),
+			},
+		})
+	})
+	client, _ := newTestClient(t, mux)
+
+	run, found, err := client.FindDispatchedRun(t.Context(), "o", "r", "ci.yml", "main", since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found || run.ID != 3 {
+		t.Errorf("run = %d (found %v), want 3 (earliest bot run since the dispatch)", run.ID, found)
+	}
+}
+
+func TestHasActiveRun(t *testing.T) {
+	tests := map[string]struct {
+		actors []string // triggering actors of the queued runs
+		want   bool
+	}{
+		"none":            {want: false},
+		"user runs only":  {actors: []string{"User", "User"}, want: false},
+		"bot run":         {actors: []string{"Bot"}, want: true},
+		"user and bot":    {actors: []string{"User", "Bot"}, want: true},
+		"unknown trigger": {actors: []string{""}, want: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/o/r/actions/workflows/ci.yml/runs", func(w http.ResponseWriter, r *http.Request) {
+				var runs []map[string]any
+				if r.URL.Query().Get("status") == "queued" {
+					for i, actor := range tt.actors {
+						runs = append(runs, map[string]any{"id": i + 1, "status": "queued", "triggering_actor": map[string]string{"type": actor}})
+					}
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"total_count": len(runs), "workflow_runs": runs})
+			})
+			client, _ := newTestClient(t, mux)
+
+			got, err := client.HasActiveRun(t.Context(), "o", "r", "ci.yml", "main")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasActiveRun = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListInstallationRepos_Pages(t *testing.T) {
+	const lastPage = 5
+	var srvURL string
+	client, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/installation/repositories?page=2&per_page=100>; rel="next", <%s/installation/repositories?page=%d&per_page=100>; rel="last"`, srvURL, srvURL, lastPage))
+		}
+		fmt.Fprintf(w, `{"total_count":%d,"repositories":[{"name":"repo-%s-a","owner":{"login":"o"}},{"name":"repo-%s-b","owner":{"login":"o"}}]}`, 2*lastPage, page, page)
+	}))
+	srvURL = srv.URL
+
+	repos, err := client.GetInstallationRepos(context.Background())
+	if err != nil {
+		t.Fatalf("GetInstallationRepos: %v", err)
+	}
+	if len(repos) != 2*lastPage {
+		t.Fatalf("repos = %d, want %d", len(repos), 2*lastPage)
+	}
+	for i, repo := range repos {
+		want := fmt.Sprintf("repo-%d-%c", i/2+1, 'a'+i%2)
+		if repo.Name != want {
+			t.Errorf("repos[%d] = %s, want %s (in page order)", i, repo.Name, want)
+		}
+	}
+}
+
+func TestListInstallationRepos_PageError(t *testing.T) {
+	var srvURL string
+	client, srv := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "", "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/installation/repositories?page=3&per_page=100>; rel="last"`, srvURL))
+		case "2":
+			http.Error(w, `{"message":"boom"}`, http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"total_count":3,"repositories":[{"name":"r","owner":{"login":"o"}}]}`)
+	}))
+	srvURL = srv.URL
+
+	if _, err := client.GetInstallationRepos(context.Background()); err == nil {
+		t.Error("expected the error of a failed page")
+	}
+}
+
+func TestPutFile_Conflict(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /repos/o/r/contents/state.json", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			SHA    string `json:"sha"`
+			Branch string `json:"branch"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Branch != "ghacron-state" {
+			t.Errorf("branch = %q, want ghacron-state", body.Branch)
+		}
+		switch body.SHA {
+		case "current":
+			_, _ = fmt.Fprint(w, `{"content":{"sha":"next"}}`)
+		case "":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = fmt.Fprint(w, `{"message":"Invalid request.\n\n\"sha\" wasn't supplied."}`)
+		default:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = fmt.Fprint(w, `{"message":"state.json does not match outdated"}`)
+		}
+	})
+	client, _ := newTestClient(t, mux)
+
+	put := func(sha string) error {
+		return client.PutFile(t.Context(), "o", "r", "state.json", "ghacron-state", "update", []byte("{}"), sha)
+	}
+	if err := put("current"); err != nil {
+		t.Errorf("put with the current SHA: %v", err)
+	}
+	if err := put("outdated"); !errors.Is(err, ErrConflict) {
+		t.Errorf("put with an outdated SHA: %v, want ErrConflict", err)
+	}
+	if err := put(""); !errors.Is(err, ErrConflict) {
+		t.Errorf("create of an existing file: %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	gh "github.com/google/go-github/v68/github"
+)
+
+// Sentinel errors classifying failed API calls. Client methods wrap their
+// errors so callers can test them with errors.Is.
+var (
+	// ErrNotFound means the resource does not exist or is not visible to the
+	// App installation.
+	ErrNotFound = errors.New("not found")
+	// ErrForbidden means the App lacks the permission for the request, or the
+	// resource does not allow it (e.g. an archived repository).
+	ErrForbidden = errors.New("forbidden")
+	// ErrRateLimited means the request hit a primary or secondary rate limit,
+	// or was held back while requests are paused for one.
+	ErrRateLimited = errors.New("GitHub API rate limit exceeded")
+	// ErrWorkflowDisabled means the workflow cannot be dispatched because it
+	// is disabled.
+	ErrWorkflowDisabled = errors.New("workflow is disabled")
+	// ErrConflict means a write was rejected because the resource changed
+	// since it was read (e.g. a file updated with an outdated SHA).
+	ErrConflict = errors.New("conflict")
+)
+
+// APIError is a failed API call classified by one of the sentinel errors.
+// Its message is the message of the underlying error.
+type APIError struct {
+	StatusCode int   // 0 if no response was received
+	Kind       error // ErrNotFound, ErrForbidden, ErrRateLimited, ErrWorkflowDisabled or ErrConflict
+	Err        error
+}
+
+func (e *APIError) Error() string { return e.Err.Error() }
+
+// Unwrap makes both the kind and the underlying error visible to errors.Is
+// and errors.As.
+func (e *APIError) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// classifyError wraps an error returned by go-github in an APIError if it
+// matches one of the sentinel errors, and returns it unchanged otherwise.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) || errors.Is(err, ErrRateLimited) {
+		return err
+	}
+
+	var rateErr *gh.RateLimitError
+	var abuseErr *gh.AbuseRateLimitError
+	switch {
+	case errors.As(err, &rateErr):
+		return &APIError{StatusCode: statusCodeOf(rateErr.Response), Kind: ErrRateLimited, Err: err}
+	case errors.As(err, &abuseErr):
+		return &APIError{StatusCode: statusCodeOf(abuseErr.Response), Kind: ErrRateLimited, Err: err}
+	}
+
+	var respErr *gh.ErrorResponse
+	if !errors.As(err, &respErr) || respErr.Response == nil {
+		return err
+	}
+	status := respErr.Response.StatusCode
+	var kind error
+	switch {
+	case status == http.StatusTooManyRequests:
+		kind = ErrRateLimited
+	case status == http.StatusNotFound:
+		kind = ErrNotFound
+	case status == http.StatusForbidden:
+		kind = ErrForbidden
+	case status == http.StatusConflict:
+		kind = ErrConflict
+	case status == http.StatusUnprocessableEntity && strings.Contains(strings.ToLower(respErr.Message), "disabled"):
+		// "Cannot trigger a 'workflow_dispatch' on a disabled workflow"
+		kind = ErrWorkflowDisabled
+	default:
+		return err
+	}
+	return &APIError{StatusCode: status, Kind: kind, Err: err}
+}
+
+func statusCodeOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
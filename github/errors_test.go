@@ -0,0 +1,69 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := map[string]struct {
+		status  int
+		headers map[string]string
+		body    string
+		want    error
+	}{
+		"not found":         {status: http.StatusNotFound, body: `{"message": "Not Found"}`, want: ErrNotFound},
+		"forbidden":         {status: http.StatusForbidden, body: `{"message": "Resource not accessible by integration"}`, want: ErrForbidden},
+		"disabled workflow": {status: http.StatusUnprocessableEntity, body: `{"message": "Cannot trigger a 'workflow_dispatch' on a disabled workflow"}`, want: ErrWorkflowDisabled},
+		"conflict":          {status: http.StatusConflict, body: `{"message": "Conflict"}`, want: ErrConflict},
+		"rate limited": {
+			status: http.StatusForbidden,
+			headers: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "1",
+			},
+			body: `{"message": "API rate limit exceeded"}`,
+			want: ErrRateLimited,
+		},
+		"secondary rate limit": {status: http.StatusTooManyRequests, body: `{"message": "You have exceeded a secondary rate limit"}`, want: ErrRateLimited},
+		"server error":         {status: http.StatusInternalServerError, body: `{"message": "boom"}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/o/r/actions/workflows/ci.yml/dispatches", func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			client, _ := newTestClient(t, mux)
+
+			err := client.DispatchWorkflow(t.Context(), "o", "r", "ci.yml", "main", "")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, kind := range []error{ErrNotFound, ErrForbidden, ErrRateLimited, ErrWorkflowDisabled, ErrConflict} {
+				if got := errors.Is(err, kind); got != (kind == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v (err: %v)", kind, got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport_PausedErrorIsRateLimited(t *testing.T) {
+	transport := newRateLimitTransport(http.DefaultTransport)
+	transport.paused = time.Now().Add(time.Hour)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+	if _, err := transport.RoundTrip(req.WithContext(ctx)); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+}
@@ -0,0 +1,151 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// graphqlBatchSize is the number of repositories fetched per GraphQL query.
+// Each repository returns the full text of its workflow files, so batches are
+// kept small enough for responses of a reasonable size.
+const graphqlBatchSize = 25
+
+// RepoWorkflows holds the workflow files of a repository's default branch
+// together with the commit they were read at.
+type RepoWorkflows struct {
+	HeadSHA string
+	Files   []WorkflowFile // with Content
+}
+
+// graphqlRepository is the per-repository part of the batch query result.
+type graphqlRepository struct {
+	DefaultBranchRef *struct {
+		Name   string `json:"name"`
+		Target struct {
+			OID string `json:"oid"`
+		} `json:"target"`
+	} `json:"defaultBranchRef"`
+	Object *struct {
+		Entries []struct {
+			Name   string `json:"name"`
+			Path   string `json:"path"`
+			OID    string `json:"oid"`
+			Type   string `json:"type"`
+			Object *struct {
+				Text        *string `json:"text"`
+				IsTruncated bool    `json:"isTruncated"`
+				IsBinary    bool    `json:"isBinary"`
+			} `json:"object"`
+		} `json:"entries"`
+	} `json:"object"`
+}
+
+// GetWorkflowContentsBatch fetches the workflow files of the default branch of
+// many repositories with a few GraphQL queries instead of several REST calls
+// per repository. GraphQL requests are rate limited separately from the REST
+// API. Repositories that could not be fetched completely (not found, default
+// branch renamed, truncated or binary files) are omitted from the result, keyed
+// by "owner/repo", so callers can fall back to GetWorkflowContents for them.
+func (c *Client) GetWorkflowContentsBatch(ctx context.Context, repos []Repository) (map[string]RepoWorkflows, error) {
+	result := make(map[string]RepoWorkflows, len(repos))
+	for start := 0; start < len(repos); start += graphqlBatchSize {
+		batch := repos[start:min(start+graphqlBatchSize, len(repos))]
+		if err := c.fetchWorkflowBatch(ctx, batch, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// fetchWorkflowBatch runs a single batch query and adds the complete
+// repositories to result.
+func (c *Client) fetchWorkflowBatch(ctx context.Context, repos []Repository, result map[string]RepoWorkflows) error {
+	query, variables := workflowBatchQuery(repos)
+	req, err := c.gh.NewRequest(http.MethodPost, "graphql", map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+
+	var resp struct {
+		Data   map[string]*graphqlRepository `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := c.gh.Do(ctx, req, &resp); err != nil {
+		return fmt.Errorf("failed to query workflow files: %w", classifyError(err))
+	}
+	if resp.Data == nil && len(resp.Errors) > 0 {
+		return fmt.Errorf("failed to query workflow files: %s", resp.Errors[0].Message)
+	}
+
+	for i, repo := range repos {
+		files, headSHA, ok := resp.Data[fmt.Sprintf("r%d", i)].workflows(repo.DefaultBranch)
+		if !ok {
+			slog.Debug("repository not fetched by batch query",
+				"owner", repo.Owner,
+				"repo", repo.Name,
+			)
+			continue
+		}
+		result[repo.Owner+"/"+repo.Name] = RepoWorkflows{HeadSHA: headSHA, Files: files}
+	}
+	return nil
+}
+
+// workflowBatchQuery builds a query fetching the workflow directory of each
+// repository's default branch under the alias r<index>.
+func workflowBatchQuery(repos []Repository) (string, map[string]string) {
+	var params, fields strings.Builder
+	variables := make(map[string]string, 3*len(repos))
+	for i, repo := range repos {
+		fmt.Fprintf(&params, "$o%d: String!, $n%d: String!, $e%d: String!, ", i, i, i)
+		fmt.Fprintf(&fields, `r%d: repository(owner: $o%d, name: $n%d) {
+  defaultBranchRef { name target { oid } }
+  object(expression: $e%d) { ... on Tree { entries { name path oid type object { ... on Blob { text isTruncated isBinary } } } } }
+}
+`, i, i, i, i)
+		variables[fmt.Sprintf("o%d", i)] = repo.Owner
+		variables[fmt.Sprintf("n%d", i)] = repo.Name
+		variables[fmt.Sprintf("e%d", i)] = repo.DefaultBranch + ":" + workflowsDir
+	}
+	query := fmt.Sprintf("query(%s) {\n%s}", strings.TrimSuffix(params.String(), ", "), fields.String())
+	return query, variables
+}
+
+// workflows returns the workflow files of the repository and the head commit
+// of defaultBranch, or false if the result is missing or incomplete.
+func (r *graphqlRepository) workflows(defaultBranch string) ([]WorkflowFile, string, bool) {
+	if r == nil || r.DefaultBranchRef == nil || r.DefaultBranchRef.Name != defaultBranch {
+		return nil, "", false
+	}
+	headSHA := r.DefaultBranchRef.Target.OID
+	if r.Object == nil {
+		// No workflows directory.
+		return nil, headSHA, true
+	}
+
+	var files []WorkflowFile
+	for _, entry := range r.Object.Entries {
+		if entry.Type != "blob" || !isWorkflowFileName(entry.Name) {
+			continue
+		}
+		blob := entry.Object
+		if blob == nil || blob.Text == nil || blob.IsTruncated || blob.IsBinary {
+			return nil, "", false
+		}
+		files = append(files, WorkflowFile{
+			Name:    entry.Name,
+			Path:    entry.Path,
+			SHA:     entry.OID,
+			Content: *blob.Text,
+		})
+	}
+	return files, headSHA, true
+}
@@ -0,0 +1,61 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGetWorkflowContentsBatch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Variables["o0"] != "o" || req.Variables["n1"] != "renamed" || req.Variables["e0"] != "main:.github/workflows" {
+			t.Errorf("unexpected variables: %v", req.Variables)
+		}
+		_, _ = w.Write([]byte(`{"data": {
+			"r0": {
+				"defaultBranchRef": {"name": "main", "target": {"oid": "head1"}},
+				"object": {"entries": [
+					{"name": "ci.yml", "path": ".github/workflows/ci.yml", "oid": "blob1", "type": "blob", "object": {"text": "on: push\n"}},
+					{"name": "README.md", "path": ".github/workflows/README.md", "oid": "blob2", "type": "blob", "object": {"text": "docs"}},
+					{"name": "nested", "path": ".github/workflows/nested", "oid": "tree1", "type": "tree", "object": {}}
+				]}
+			},
+			"r1": {"defaultBranchRef": {"name": "trunk", "target": {"oid": "head2"}}, "object": null},
+			"r2": {"defaultBranchRef": {"name": "main", "target": {"oid": "head3"}}, "object": null},
+			"r3": null
+		}, "errors": [{"message": "Could not resolve to a Repository"}]}`))
+	})
+	client, _ := newTestClient(t, mux)
+
+	repos := []Repository{
+		{Owner: "o", Name: "r", DefaultBranch: "main"},
+		{Owner: "o", Name: "renamed", DefaultBranch: "main"},
+		{Owner: "o", Name: "empty", DefaultBranch: "main"},
+		{Owner: "o", Name: "gone", DefaultBranch: "main"},
+	}
+	got, err := client.GetWorkflowContentsBatch(t.Context(), repos)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := got["o/r"]
+	if r.HeadSHA != "head1" || len(r.Files) != 1 || r.Files[0].SHA != "blob1" || r.Files[0].Content != "on: push\n" {
+		t.Errorf("o/r = %+v, want ci.yml at head1", r)
+	}
+	if empty, ok := got["o/empty"]; !ok || empty.HeadSHA != "head3" || len(empty.Files) != 0 {
+		t.Errorf("o/empty = %+v (%v), want no files at head3", empty, ok)
+	}
+	for _, name := range []string{"o/renamed", "o/gone"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("%s should be left to the REST fallback", name)
+		}
+	}
+}
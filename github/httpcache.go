@@ -0,0 +1,124 @@
+package github
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/korosuke613/ghacron/metrics"
+)
+
+// maxCachedResponses bounds the number of responses kept by etagTransport.
+const maxCachedResponses = 2000
+
+var apiCacheHits = metrics.Default.NewCounter(
+	"ghacron_github_cache_hits_total",
+	"GitHub API requests answered with 304 Not Modified and served from the response cache.",
+)
+
+// etagTransport caches successful GET responses that carry an ETag and
+// revalidates them with If-None-Match. GitHub answers unchanged resources with
+// 304 Not Modified, which does not count against the rate limit, and the
+// cached response is returned in its place. Requests that already carry a
+// conditional header are passed through untouched.
+type etagTransport struct {
+	next http.RoundTripper
+
+	mu      sync.Mutex
+	entries map[string]cachedResponse // method + URL + Accept -> response
+}
+
+// cachedResponse is a stored 200 response.
+type cachedResponse struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+func newETagTransport(next http.RoundTripper) *etagTransport {
+	return &etagTransport{next: next, entries: make(map[string]cachedResponse)}
+}
+
+// RoundTrip sends the request, conditionally if a cached response exists.
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	key := responseCacheKey(req)
+	cached, ok := t.get(key)
+	if ok {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case ok && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close()
+		apiCacheHits.Inc()
+		return cached.response(req, resp.Header), nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		t.put(key, cachedResponse{etag: resp.Header.Get("ETag"), header: resp.Header.Clone(), body: body})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	default:
+		return resp, nil
+	}
+}
+
+// responseCacheKey identifies a response by request method, URL and the
+// requested media type, which changes the representation.
+func responseCacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String() + " " + req.Header.Get("Accept")
+}
+
+func (t *etagTransport) get(key string) (cachedResponse, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[key]
+	return entry, ok
+}
+
+func (t *etagTransport) put(key string, entry cachedResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.entries[key]; !ok && len(t.entries) >= maxCachedResponses {
+		// Evict an arbitrary entry; the cache only saves rate limit.
+		for k := range t.entries {
+			delete(t.entries, k)
+			break
+		}
+	}
+	t.entries[key] = entry
+}
+
+// response rebuilds the cached 200 response for req. Headers of the fresh 304
+// response (such as the rate limit) take precedence over the cached ones.
+func (entry cachedResponse) response(req *http.Request, fresh http.Header) *http.Response {
+	header := entry.header.Clone()
+	for k, v := range fresh {
+		header[k] = v
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Request:       req,
+	}
+}
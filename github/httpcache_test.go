@@ -0,0 +1,66 @@
+package github
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestETagTransport_RevalidatesCachedResponse(t *testing.T) {
+	var calls, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, `{"name":"ci.yml"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: newETagTransport(http.DefaultTransport)}
+	for i := range 3 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != `{"name":"ci.yml"}` {
+			t.Errorf("request %d: status %d body %q, want 200 with the cached body", i, resp.StatusCode, body)
+		}
+	}
+
+	if calls.Load() != 3 || notModified.Load() != 2 {
+		t.Errorf("%d calls with %d 304s, want 3 calls with 2 304s", calls.Load(), notModified.Load())
+	}
+}
+
+func TestETagTransport_PassesThroughNonGET(t *testing.T) {
+	var conditional atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional.Store(true)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: newETagTransport(http.DefaultTransport)}
+	for range 2 {
+		resp, err := client.Post(srv.URL, "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if conditional.Load() {
+		t.Error("POST requests should not be revalidated")
+	}
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// logTransport logs every request sent to GitHub at debug level, for
+// troubleshooting slow reconciles and unexpected 403s. Request headers are
+// not logged, so the Authorization header never reaches the logs.
+type logTransport struct {
+	next http.RoundTripper
+}
+
+func newLogTransport(next http.RoundTripper) *logTransport {
+	return &logTransport{next: next}
+}
+
+// RoundTrip sends the request and logs its outcome.
+func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	args := []any{
+		"method", req.Method,
+		"url", redactURL(req.URL),
+		"duration", time.Since(start).Round(time.Millisecond).String(),
+	}
+	if err != nil {
+		slog.Debug("GitHub API request failed", append(args, "error", err)...)
+		return resp, err
+	}
+	args = append(args, "status", resp.StatusCode)
+	for _, h := range []string{
+		"X-RateLimit-Resource",
+		"X-RateLimit-Remaining",
+		"X-RateLimit-Reset",
+		"Retry-After",
+		"X-GitHub-Request-Id",
+	} {
+		if v := resp.Header.Get(h); v != "" {
+			args = append(args, h, v)
+		}
+	}
+	slog.Debug("GitHub API request", args...)
+	return resp, err
+}
+
+// redactURL returns u with credentials passed as query parameters (such as
+// the token of a signed download link) replaced.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if !query.Has("token") && !query.Has("access_token") {
+		return u.Redacted()
+	}
+	for _, k := range []string{"token", "access_token"} {
+		if query.Has(k) {
+			query.Set(k, "REDACTED")
+		}
+	}
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.Redacted()
+}
@@ -0,0 +1,49 @@
+package github
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLogTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/repos/o/r", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	resp, err := newLogTransport(http.DefaultTransport).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	out := buf.String()
+	for _, want := range []string{"method=GET", "/repos/o/r", "status=403", "X-RateLimit-Remaining=4999"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret-token") {
+		t.Errorf("log leaks the Authorization header:\n%s", out)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("https://codeload.github.com/o/r/legacy.tar.gz/main?token=abc")
+	if got := redactURL(u); strings.Contains(got, "abc") {
+		t.Errorf("redactURL = %q, want the token redacted", got)
+	}
+}
@@ -0,0 +1,42 @@
+package github
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// newHTTPTransport returns the transport used for all GitHub traffic: the
+// default transport (which honors HTTPS_PROXY, HTTP_PROXY and NO_PROXY) with
+// the TLS trust configured in opts.
+func newHTTPTransport(opts ClientOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.CACertPath == "" && !opts.InsecureSkipVerify {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CACertPath != "" {
+		pem, err := os.ReadFile(opts.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", opts.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if opts.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification of GitHub API requests is disabled")
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // explicitly requested via GHACRON_GITHUB_INSECURE_SKIP_VERIFY
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,59 @@
+package github
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHTTPTransport_TLSTrust(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		opts    ClientOptions
+		wantErr bool
+	}{
+		"system roots only": {opts: ClientOptions{}, wantErr: true},
+		"custom CA":         {opts: ClientOptions{CACertPath: caPath}},
+		"skip verify":       {opts: ClientOptions{InsecureSkipVerify: true}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			transport, err := newHTTPTransport(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewHTTPTransport_InvalidCA(t *testing.T) {
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newHTTPTransport(ClientOptions{CACertPath: caPath}); err == nil {
+		t.Error("expected an error for a CA file without PEM certificates")
+	}
+	if _, err := newHTTPTransport(ClientOptions{CACertPath: caPath + ".missing"}); err == nil {
+		t.Error("expected an error for a missing CA file")
+	}
+}
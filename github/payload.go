@@ -0,0 +1,102 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// PayloadData holds the values of the placeholders of a payload= or inputs=
+// option, such as {{ .ScheduledTime }}, substituted at dispatch time.
+type PayloadData struct {
+	// ScheduledTime is the RFC 3339 time of the cron tick, in UTC. Manual
+	// runs use the dispatch time.
+	ScheduledTime string
+	// RunDate is the date (2006-01-02) of ScheduledTime in the job's time
+	// zone, e.g. the logical date of a daily job.
+	RunDate string
+	// DispatchTime is the RFC 3339 time of the dispatch, in UTC.
+	DispatchTime string
+	Owner        string
+	Repo         string
+	WorkflowFile string
+	Name         string
+	Ref          string
+	Trigger      string // "schedule" or "manual"
+}
+
+// ParsePayload validates a payload= option: a JSON object whose strings may
+// contain placeholders of PayloadData.
+func ParsePayload(payload string) error {
+	rendered, err := RenderPayload(payload, PayloadData{})
+	if err != nil {
+		return err
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(rendered), &obj); err != nil || obj == nil {
+		return fmt.Errorf("must be a JSON object")
+	}
+	return nil
+}
+
+// ParseInputs validates an inputs= option: a JSON object of workflow_dispatch
+// inputs whose values are strings, numbers or booleans, and whose strings may
+// contain placeholders of PayloadData. It returns the sorted input names.
+func ParseInputs(inputs string) ([]string, error) {
+	rendered, err := RenderPayload(inputs, PayloadData{})
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(rendered), &obj); err != nil || obj == nil {
+		return nil, fmt.Errorf("must be a JSON object")
+	}
+	names := make([]string, 0, len(obj))
+	for name, value := range obj {
+		switch value.(type) {
+		case string, float64, bool:
+		default:
+			return nil, fmt.Errorf("input %q must be a string, number or boolean", name)
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// RenderPayload substitutes the placeholders of a payload or inputs. Values are escaped
+// for JSON strings, so placeholders belong inside quotes. Payloads without
+// placeholders are returned unchanged.
+func RenderPayload(payload string, data PayloadData) (string, error) {
+	if !strings.Contains(payload, "{{") {
+		return payload, nil
+	}
+	tmpl, err := template.New("payload").Option("missingkey=error").Parse(payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid placeholder: %w", err)
+	}
+	escaped := PayloadData{
+		ScheduledTime: jsonEscape(data.ScheduledTime),
+		RunDate:       jsonEscape(data.RunDate),
+		DispatchTime:  jsonEscape(data.DispatchTime),
+		Owner:         jsonEscape(data.Owner),
+		Repo:          jsonEscape(data.Repo),
+		WorkflowFile:  jsonEscape(data.WorkflowFile),
+		Name:          jsonEscape(data.Name),
+		Ref:           jsonEscape(data.Ref),
+		Trigger:       jsonEscape(data.Trigger),
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, escaped); err != nil {
+		return "", fmt.Errorf("invalid placeholder: %w", err)
+	}
+	return b.String(), nil
+}
+
+// jsonEscape returns s escaped as the content of a JSON string.
+func jsonEscape(s string) string {
+	quoted, _ := json.Marshal(s) // strings always encode
+	return string(quoted[1 : len(quoted)-1])
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRenderPayload(t *testing.T) {
+	data := PayloadData{RunDate: "2026-03-02", Ref: `feat/"quoted"`}
+	tests := []struct {
+		payload, want string
+	}{
+		{`{"env": "prod"}`, `{"env": "prod"}`},
+		{`{"date": "{{ .RunDate }}"}`, `{"date": "2026-03-02"}`},
+		// Values are escaped for JSON strings.
+		{`{"ref": "{{ .Ref }}"}`, `{"ref": "feat/\"quoted\""}`},
+	}
+	for _, tt := range tests {
+		got, err := RenderPayload(tt.payload, data)
+		if err != nil || got != tt.want {
+			t.Errorf("RenderPayload(%s) = %s, %v; want %s", tt.payload, got, err, tt.want)
+		}
+	}
+}
+
+func TestParsePayload(t *testing.T) {
+	tests := map[string]bool{
+		`{"env": "prod"}`:                   true,
+		`{"at": "{{ .ScheduledTime }}"}`:    true,
+		`{"at": "{{ .Unknown }}"}`:          false,
+		`{"at": "{{ .ScheduledTime "}`:      false,
+		`[1]`:                               false,
+		`{"n": {{ .RunDate }}}`:             false,
+		`{"d": "{{ .RunDate }}", "t": "x"}`: true,
+	}
+	for payload, valid := range tests {
+		if err := ParsePayload(payload); (err == nil) != valid {
+			t.Errorf("ParsePayload(%s) error = %v, want valid = %t", payload, err, valid)
+		}
+	}
+}
+
+func TestParseInputs(t *testing.T) {
+	tests := []struct {
+		inputs    string
+		wantNames []string
+		wantErr   bool
+	}{
+		{`{"env": "prod", "date": "{{ .RunDate }}"}`, []string{"date", "env"}, false},
+		{`{"dry_run": true, "count": 3}`, []string{"count", "dry_run"}, false},
+		{`{}`, []string{}, false},
+		{`{"env": {"name": "prod"}}`, nil, true},
+		{`{"env": null}`, nil, true},
+		{`["env"]`, nil, true},
+		{`{"at": "{{ .Unknown }}"}`, nil, true},
+	}
+	for _, tt := range tests {
+		names, err := ParseInputs(tt.inputs)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && !slices.Equal(names, tt.wantNames)) {
+			t.Errorf("ParseInputs(%s) = %v, %v; want %v, error = %t", tt.inputs, names, err, tt.wantNames, tt.wantErr)
+		}
+	}
+}
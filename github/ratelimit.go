@@ -0,0 +1,243 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/metrics"
+)
+
+// secondaryLimitBackoff is how long requests pause after a secondary rate
+// limit response without Retry-After, as recommended by GitHub.
+const secondaryLimitBackoff = time.Minute
+
+var rateLimitRemaining = metrics.Default.NewGauge(
+	"ghacron_github_rate_limit_remaining",
+	"Remaining GitHub API requests in the current rate limit window.",
+)
+
+var rateLimitLimit = metrics.Default.NewGauge(
+	"ghacron_github_rate_limit_limit",
+	"GitHub API requests allowed per rate limit window.",
+)
+
+var rateLimitReset = metrics.Default.NewGauge(
+	"ghacron_github_rate_limit_reset_timestamp_seconds",
+	"Unix time at which the current GitHub API rate limit window resets.",
+)
+
+// RateLimit is a snapshot of the GitHub API rate limit as last reported by
+// response headers.
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	// UpdatedAt is when the response reporting the limit was received.
+	UpdatedAt time.Time `json:"updated_at"`
+	// PausedUntil is set while requests are held back after the limit was
+	// exhausted or a secondary rate limit was hit.
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+}
+
+// rateLimitTransport tracks the rate limit headers of every response and
+// pauses requests while the limit is exhausted. A request that hits the limit
+// is retried once after the pause, if the pause ends before its context
+// deadline; otherwise it fails without waiting.
+type rateLimitTransport struct {
+	next http.RoundTripper
+
+	mu     sync.Mutex
+	known  bool // whether any rate limit headers were seen
+	state  RateLimit
+	paused time.Time // requests wait until this time
+}
+
+func newRateLimitTransport(next http.RoundTripper) *rateLimitTransport {
+	return &rateLimitTransport{next: next}
+}
+
+// RoundTrip waits out an active pause, sends the request, and records the
+// rate limit reported by the response.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.waitForPause(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if !t.observe(resp, isSecondaryRateLimit(resp)) || attempt > 0 || !replayable(req) {
+			return resp, nil
+		}
+
+		// Rate limited: retry once after the pause if the caller can wait.
+		if err := t.checkDeadline(req); err != nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// waitForPause blocks until an active pause ends or fails if the request's
+// deadline comes first.
+func (t *rateLimitTransport) waitForPause(req *http.Request) error {
+	if err := t.checkDeadline(req); err != nil {
+		return err
+	}
+	wait := t.pausedUntil().Sub(time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// checkDeadline fails if the request's deadline is before the end of the pause.
+func (t *rateLimitTransport) checkDeadline(req *http.Request) error {
+	until := t.pausedUntil()
+	if deadline, ok := req.Context().Deadline(); ok && deadline.Before(until) {
+		return fmt.Errorf("%w, requests paused until %s", ErrRateLimited, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (t *rateLimitTransport) pausedUntil() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
+
+// observe records the rate limit headers of a response, pausing requests if
+// the limit is exhausted or secondary (a secondary rate limit response). It
+// reports whether the response was rate limited.
+func (t *rateLimitTransport) observe(resp *http.Response, secondary bool) bool {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Only the REST quota is tracked; GraphQL has a separate one, so an
+	// exhausted GraphQL quota must not pause REST requests. Secondary rate
+	// limits apply to every request.
+	if resource := resp.Header.Get("X-RateLimit-Resource"); resource != "" && resource != "core" && !secondary {
+		return false
+	}
+
+	remaining, known := t.recordHeaders(resp.Header, now)
+	exhausted := known && remaining == 0
+	retryAfter := resp.Header.Get("Retry-After")
+	limited := resp.StatusCode == http.StatusTooManyRequests || secondary ||
+		(resp.StatusCode == http.StatusForbidden && (exhausted || retryAfter != ""))
+
+	until := t.pauseEnd(now, retryAfter, exhausted, limited)
+	if until.After(t.paused) && until.After(now) {
+		t.paused = until
+		slog.Warn("GitHub API rate limit reached, pausing requests",
+			"until", until.Format(time.RFC3339),
+			"status", resp.StatusCode,
+			"secondary", secondary,
+		)
+	}
+	return limited
+}
+
+// recordHeaders records the X-RateLimit-* headers of a response, and returns
+// the remaining requests and whether the header was present. The caller must
+// hold t.mu.
+func (t *rateLimitTransport) recordHeaders(h http.Header, now time.Time) (int, bool) {
+	if limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+		t.known = true
+		t.state.Limit = limit
+		t.state.UpdatedAt = now
+		rateLimitLimit.Set(float64(limit))
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err == nil {
+		t.known = true
+		t.state.Remaining = remaining
+		rateLimitRemaining.Set(float64(remaining))
+	}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		t.state.Reset = time.Unix(reset, 0)
+		rateLimitReset.Set(float64(reset))
+	}
+	return remaining, err == nil
+}
+
+// pauseEnd returns until when requests are paused after a response: for its
+// Retry-After, else until the reset of an exhausted limit, else for
+// secondaryLimitBackoff if it was rate limited. It returns the zero time if
+// requests need not pause. The caller must hold t.mu.
+func (t *rateLimitTransport) pauseEnd(now time.Time, retryAfter string, exhausted, limited bool) time.Time {
+	switch {
+	case retryAfter != "":
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second)
+		}
+	case exhausted:
+		return t.state.Reset
+	case limited:
+		return now.Add(secondaryLimitBackoff)
+	}
+	return time.Time{}
+}
+
+// snapshot returns the last known rate limit, and false if none was seen yet.
+func (t *rateLimitTransport) snapshot() (RateLimit, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.state
+	if t.paused.After(time.Now()) {
+		paused := t.paused
+		state.PausedUntil = &paused
+	}
+	return state, t.known
+}
+
+// secondaryLimitMessage identifies secondary rate limit responses, which may
+// come without rate limit headers or Retry-After.
+const secondaryLimitMessage = "secondary rate limit"
+
+// isSecondaryRateLimit reports whether a response is a secondary rate limit
+// error, peeking at its message. The body is left unread for the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests) || resp.Body == nil {
+		return false
+	}
+	peek, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body = readCloser{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+	return err == nil && bytes.Contains(bytes.ToLower(peek), []byte(secondaryLimitMessage))
+}
+
+// readCloser combines a reader with the closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// replayable reports whether a request can be sent again.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
@@ -0,0 +1,196 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v68/github"
+)
+
+func TestRateLimitTransport_TracksHeaders(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}))
+	t.Cleanup(srv.Close)
+
+	rt := newRateLimitTransport(http.DefaultTransport)
+	if _, ok := rt.snapshot(); ok {
+		t.Fatal("snapshot known before any response")
+	}
+
+	resp, err := (&http.Client{Transport: rt}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	got, ok := rt.snapshot()
+	if !ok || got.Limit != 5000 || got.Remaining != 4999 || !got.Reset.Equal(reset) || got.PausedUntil != nil {
+		t.Errorf("snapshot = %+v, %v; want 5000/4999 reset %v, not paused", got, ok, reset)
+	}
+}
+
+func TestRateLimitTransport_RetriesAfterRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	rt := newRateLimitTransport(http.DefaultTransport)
+	start := time.Now()
+	resp, err := (&http.Client{Transport: rt}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("status = %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("retried after %v, want the Retry-After pause", elapsed)
+	}
+}
+
+func TestRateLimitTransport_FailsFastBeyondDeadline(t *testing.T) {
+	var calls atomic.Int32
+	reset := time.Now().Add(time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	rt := newRateLimitTransport(http.DefaultTransport)
+	client := &http.Client{Transport: rt}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The limited response is returned as is: the pause outlasts the deadline.
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", resp.StatusCode)
+	}
+
+	// Later requests fail without reaching GitHub while paused.
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Error("expected error while paused")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server calls = %d, want 1", calls.Load())
+	}
+	if got, _ := rt.snapshot(); got.PausedUntil == nil {
+		t.Error("snapshot not paused")
+	}
+}
+
+func TestRateLimitTransport_IgnoresGraphQLQuota(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Resource", "graphql")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	rt := newRateLimitTransport(http.DefaultTransport)
+	resp, err := (&http.Client{Transport: rt}).Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got, ok := rt.snapshot(); ok || got.PausedUntil != nil {
+		t.Errorf("snapshot = %+v, %v; want the GraphQL quota ignored", got, ok)
+	}
+}
+
+func TestRateLimitTransport_DetectsSecondaryLimitMessage(t *testing.T) {
+	const body = `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Secondary limits may come without Retry-After, on any resource.
+		w.Header().Set("X-RateLimit-Resource", "graphql")
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	rt := newRateLimitTransport(http.DefaultTransport)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(got) != body {
+		t.Errorf("body = %q, want the original body", got)
+	}
+	snap, _ := rt.snapshot()
+	if snap.PausedUntil == nil || time.Until(*snap.PausedUntil) < 50*time.Second {
+		t.Errorf("PausedUntil = %v, want about a minute from now", snap.PausedUntil)
+	}
+}
+
+func TestPollRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rate_limit" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(4900-int(calls.Add(1))))
+		w.Header().Set("X-RateLimit-Reset", "1900000000")
+		w.Header().Set("X-RateLimit-Resource", "core")
+		w.Write([]byte(`{"resources":{}}`))
+	}))
+	client.rateLimit = newRateLimitTransport(http.DefaultTransport)
+	baseURL := client.gh.BaseURL
+	client.gh = gh.NewClient(&http.Client{Transport: client.rateLimit})
+	client.gh.BaseURL = baseURL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		client.PollRateLimit(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	got, ok := client.RateLimit()
+	if !ok || got.Limit != 5000 || got.Remaining >= 4899 || got.Reset.Unix() != 1900000000 || got.UpdatedAt.IsZero() {
+		t.Errorf("RateLimit() = %+v, %v; want the limit of the last poll", got, ok)
+	}
+}
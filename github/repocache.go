@@ -0,0 +1,51 @@
+package github
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// repoListCache keeps the installation repository list for a TTL, so full
+// reconciles do not page through every repository each time.
+type repoListCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	repos   []Repository
+	fetched time.Time // zero when empty or invalidated
+}
+
+// get returns the cached list if it is younger than the TTL.
+func (rc *repoListCache) get(now time.Time) ([]Repository, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.fetched.IsZero() || now.Sub(rc.fetched) >= rc.ttl {
+		return nil, false
+	}
+	return slices.Clone(rc.repos), true
+}
+
+// put stores a freshly fetched list.
+func (rc *repoListCache) put(repos []Repository, now time.Time) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.repos = slices.Clone(repos)
+	rc.fetched = now
+}
+
+// invalidate drops the cached list.
+func (rc *repoListCache) invalidate() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.repos = nil
+	rc.fetched = time.Time{}
+}
+
+// InvalidateInstallationRepos makes the next GetInstallationRepos list the
+// repositories again, e.g. after an installation webhook reported a change.
+func (c *Client) InvalidateInstallationRepos() {
+	if c.repoList != nil {
+		c.repoList.invalidate()
+	}
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetInstallationRepos_Cache(t *testing.T) {
+	var listings atomic.Int32
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listings.Add(1)
+		fmt.Fprint(w, `{"total_count":1,"repositories":[{"name":"r","owner":{"login":"o"},"default_branch":"main","fork":true,"topics":["ghacron-enabled"]}]}`)
+	}))
+	client.repoList = &repoListCache{ttl: time.Hour}
+
+	for range 2 {
+		repos, err := client.GetInstallationRepos(context.Background())
+		if err != nil {
+			t.Fatalf("GetInstallationRepos: %v", err)
+		}
+		if len(repos) != 1 || repos[0].Name != "r" || !repos[0].Fork || len(repos[0].Topics) != 1 {
+			t.Fatalf("repos = %+v", repos)
+		}
+	}
+	if n := listings.Load(); n != 1 {
+		t.Errorf("listings = %d, want 1 while the cache is fresh", n)
+	}
+
+	client.InvalidateInstallationRepos()
+	if _, err := client.GetInstallationRepos(context.Background()); err != nil {
+		t.Fatalf("GetInstallationRepos: %v", err)
+	}
+	if n := listings.Load(); n != 2 {
+		t.Errorf("listings = %d, want 2 after invalidation", n)
+	}
+}
+
+func TestRepoListCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := &repoListCache{ttl: time.Minute}
+	cache.put([]Repository{{Owner: "o", Name: "r"}}, now)
+
+	if _, ok := cache.get(now.Add(59 * time.Second)); !ok {
+		t.Error("cache expired before the TTL")
+	}
+	if _, ok := cache.get(now.Add(time.Minute)); ok {
+		t.Error("cache still fresh after the TTL")
+	}
+}
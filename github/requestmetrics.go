@@ -0,0 +1,75 @@
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/korosuke613/ghacron/metrics"
+)
+
+var (
+	apiRequests = metrics.Default.NewCounter(
+		"ghacron_github_requests_total",
+		"GitHub API requests sent, by endpoint class and status code.",
+		"class", "status",
+	)
+	apiRequestDuration = metrics.Default.NewHistogram(
+		"ghacron_github_request_duration_seconds",
+		"Latency of GitHub API requests, by endpoint class.",
+		metrics.DefaultBuckets,
+		"class",
+	)
+)
+
+// metricsTransport counts every request sent to GitHub (including retries
+// and token refreshes) and records its latency.
+type metricsTransport struct {
+	next http.RoundTripper
+}
+
+func newMetricsTransport(next http.RoundTripper) *metricsTransport {
+	return &metricsTransport{next: next}
+}
+
+// RoundTrip sends the request and records it under its endpoint class.
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	class := endpointClass(req.URL.Path)
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	apiRequestDuration.Observe(time.Since(start).Seconds(), class)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	apiRequests.Inc(class, status)
+	return resp, err
+}
+
+// endpointClass groups API paths by what ghacron uses them for, so the API
+// budget can be attributed: auth (token refresh), dispatch, state (Actions
+// variables), verify (workflow runs), checks, issues, rate_limit (quota polls
+// and connectivity checks), and scan (everything else: repositories, branches,
+// commits, workflows and their contents).
+func endpointClass(path string) string {
+	switch {
+	case strings.Contains(path, "/app/installations"):
+		return "auth"
+	case strings.HasSuffix(path, "/dispatches"):
+		return "dispatch"
+	case strings.Contains(path, "/actions/variables"):
+		return "state"
+	case strings.Contains(path, "/actions/runs"), strings.HasSuffix(path, "/runs"):
+		return "verify"
+	case strings.Contains(path, "/check-runs"):
+		return "checks"
+	case strings.Contains(path, "/issues"):
+		return "issues"
+	case strings.HasSuffix(path, "/rate_limit"):
+		return "rate_limit"
+	default:
+		return "scan"
+	}
+}
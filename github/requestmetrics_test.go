@@ -0,0 +1,29 @@
+package github
+
+import "testing"
+
+func TestEndpointClass(t *testing.T) {
+	tests := map[string]string{
+		"/app/installations/1/access_tokens":                 "auth",
+		"/repos/o/r/actions/workflows/ci.yml/dispatches":     "dispatch",
+		"/repos/o/r/dispatches":                              "dispatch",
+		"/repos/o/r/actions/variables/GHACRON_X":             "state",
+		"/orgs/o/actions/variables":                          "state",
+		"/repos/o/r/actions/workflows/ci.yml/runs":           "verify",
+		"/repos/o/r/actions/runs/42":                         "verify",
+		"/repos/o/r/check-runs":                              "checks",
+		"/repos/o/r/issues/3/comments":                       "issues",
+		"/api/v3/rate_limit":                                 "rate_limit",
+		"/api/v3/repos/o/r/contents/.github/workflows":       "scan",
+		"/repos/o/r/actions/workflows":                       "scan",
+		"/installation/repositories":                         "scan",
+		"/graphql":                                           "scan",
+		"/api/v3/repos/o/r/actions/workflows/123/dispatches": "dispatch",
+		"/repos/o/r/commits/main":                            "scan",
+	}
+	for path, want := range tests {
+		if got := endpointClass(path); got != want {
+			t.Errorf("endpointClass(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
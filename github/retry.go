@@ -0,0 +1,95 @@
+package github
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/korosuke613/ghacron/metrics"
+)
+
+var apiRetries = metrics.Default.NewCounter(
+	"ghacron_github_retries_total",
+	"GitHub API requests retried after a transient error.",
+)
+
+// retryTransport retries requests that fail with a network error or a 5xx
+// response, with exponential backoff and jitter. Note that a retried
+// workflow_dispatch whose first attempt did reach GitHub can create a second
+// run; transient 5xx responses usually mean the request was not processed.
+type retryTransport struct {
+	next     http.RoundTripper
+	retries  int           // retries after the first attempt
+	backoff  time.Duration // delay before the first retry, doubled after each
+	maxDelay time.Duration
+}
+
+// maxRetryDelay caps the backoff between two attempts.
+const maxRetryDelay = 30 * time.Second
+
+func newRetryTransport(next http.RoundTripper, retries int, backoff time.Duration) *retryTransport {
+	return &retryTransport{next: next, retries: retries, backoff: backoff, maxDelay: maxRetryDelay}
+}
+
+// RoundTrip sends the request, retrying transient failures while the
+// request's context allows.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if !transient(resp, err) || attempt >= t.retries || !replayable(req) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		wait := delay/2 + rand.N(delay/2+1) // jitter in [delay/2, delay]
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+		slog.Debug("retrying GitHub API request after transient error",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"attempt", attempt+1,
+			"delay", wait.Round(time.Millisecond).String(),
+			"status", statusOf(resp),
+			"error", err,
+		)
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		apiRetries.Inc()
+		delay = min(delay*2, t.maxDelay)
+	}
+}
+
+// transient reports whether a request failed in a way worth retrying.
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := map[string]struct {
+		failures  int32 // leading 502 responses
+		status    int
+		wantCalls int32
+	}{
+		"recovers":        {failures: 2, status: http.StatusOK, wantCalls: 3},
+		"gives up":        {failures: 5, status: http.StatusBadGateway, wantCalls: 3},
+		"no retry on 4xx": {failures: 0, status: http.StatusNotFound, wantCalls: 1},
+		"no retry on 2xx": {failures: 0, status: http.StatusOK, wantCalls: 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if n := calls.Add(1); n <= tt.failures {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				if tt.status == http.StatusNotFound {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			t.Cleanup(srv.Close)
+
+			client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 2, time.Millisecond)}
+			resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"ref":"main"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status || calls.Load() != tt.wantCalls {
+				t.Errorf("status %d after %d calls, want %d after %d", resp.StatusCode, calls.Load(), tt.status, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_ReplaysBody(t *testing.T) {
+	var calls atomic.Int32
+	var lastBody atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := new(strings.Builder)
+		_, _ = io.Copy(buf, r.Body)
+		lastBody.Store(buf.String())
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 1, time.Millisecond)}
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"ref":"main"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got := lastBody.Load(); got != `{"ref":"main"}` {
+		t.Errorf("retried body = %q, want the original body", got)
+	}
+}
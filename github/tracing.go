@@ -0,0 +1,46 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/korosuke613/ghacron/tracing"
+)
+
+// traceTransport records every request sent to GitHub (including retries and
+// token refreshes) as a client span, a child of the span of the operation
+// that made it. It records nothing unless tracing is enabled.
+type traceTransport struct {
+	next http.RoundTripper
+}
+
+func newTraceTransport(next http.RoundTripper) *traceTransport {
+	return &traceTransport{next: next}
+}
+
+// RoundTrip sends the request within a span named after its method and
+// endpoint class.
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	class := endpointClass(req.URL.Path)
+	ctx, span := tracing.StartClient(req.Context(), req.Method+" "+class,
+		tracing.String("http.request.method", req.Method),
+		tracing.String("url.full", redactURL(req.URL)),
+		tracing.String("server.address", req.URL.Hostname()),
+		tracing.String("ghacron.endpoint_class", class),
+	)
+	defer span.End()
+	if span != nil {
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.SetError(err)
+		return resp, err
+	}
+	span.SetAttributes(tracing.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetError(fmt.Errorf("status=%d", resp.StatusCode))
+	}
+	return resp, err
+}
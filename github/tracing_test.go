@@ -0,0 +1,48 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/korosuke613/ghacron/tracing"
+)
+
+func TestTraceTransport(t *testing.T) {
+	exported := make(chan string, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		exported <- string(body)
+	}))
+	t.Cleanup(collector.Close)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	tracer := tracing.Init(collector.URL, "ghacron", "test")
+	ctx, parent := tracing.Start(context.Background(), "dispatch")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/repos/o/r/actions/workflows/ci.yml/dispatches", nil)
+	resp, err := newTraceTransport(http.DefaultTransport).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	parent.End()
+	tracer.Shutdown(context.Background())
+
+	body := <-exported
+	for _, want := range []string{
+		`"name":"POST dispatch"`,
+		`"key":"http.response.status_code","value":{"intValue":"404"}`,
+		`"message":"status=404"`,
+		`"parentSpanId"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("exported spans do not contain %s:\n%s", want, body)
+		}
+	}
+}
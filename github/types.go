@@ -1,12 +1,71 @@
 package github
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
 // CronAnnotation represents a cron annotation extracted from a workflow file.
 type CronAnnotation struct {
-	Owner        string // repository owner
-	Repo         string // repository name
-	WorkflowFile string // workflow file name (e.g. "build.yml")
-	CronExpr     string // cron expression (5-field format, optional CRON_TZ=/TZ= prefix)
-	Ref          string // default branch
+	Owner        string        // repository owner
+	Repo         string        // repository name
+	WorkflowFile string        // workflow file name (e.g. "build.yml")
+	CronExpr     string        // cron expression (5-field format, optional CRON_TZ=/TZ= prefix)
+	Alias        string        // schedule alias CronExpr was expanded from ("" = none)
+	HashExpr     string        // expression with H tokens CronExpr was resolved from ("" = none)
+	Ref          string        // default branch
+	Name         string        // optional human-readable job name (name= option)
+	RefPattern   string        // optional branch glob to fan out dispatches to (refs= option)
+	Jitter       time.Duration // optional maximum random dispatch delay (jitter= option)
+	Overlap      string        // optional overlap policy, "allow" or "skip" (overlap= option; "" = global default)
+	Timeout      time.Duration // optional handler timeout (timeout= option; 0 = global default)
+	DispatchType string        // DispatchTypeWorkflow ("" = default) or DispatchTypeRepository (type= option)
+	EventType    string        // repository_dispatch event type (event= option)
+	Payload      string        // repository_dispatch client payload as a JSON object (payload= option)
+	Inputs       string        // workflow_dispatch inputs as a JSON object (inputs= option)
+	Priority     string        // optional dispatch priority, PriorityHigh or PriorityLow (priority= option; "" = normal)
+	// Stagger delays every fire time of the job; the reconciler sets it for
+	// jobs sharing their schedule with many others (0 = none).
+	Stagger time.Duration
+
+	// Source location of the annotation (informational; not part of the job config).
+	Path string // workflow file path (e.g. ".github/workflows/build.yml")
+	Line int    // 1-based line number of the annotation
+	// WorkflowID is the Actions workflow ID resolved at scan time (0 = unknown).
+	// It is not part of the job config either.
+	WorkflowID int64
+}
+
+// Dispatch types selected with the type= annotation option.
+const (
+	DispatchTypeWorkflow   = "workflow_dispatch"
+	DispatchTypeRepository = "repository_dispatch"
+)
+
+// Job priorities selected with the priority= annotation option. When runs
+// queue for the dispatch rate, higher priorities go first and lower ones are
+// shed first.
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// IsRepositoryDispatch reports whether the job sends a repository_dispatch
+// event instead of a workflow_dispatch.
+func (a *CronAnnotation) IsRepositoryDispatch() bool {
+	return a.DispatchType == DispatchTypeRepository
+}
+
+// SameConfig reports whether two annotations describe the same job
+// configuration, ignoring their source location and workflow ID.
+func (a *CronAnnotation) SameConfig(b CronAnnotation) bool {
+	x := *a
+	x.Path, x.Line, x.WorkflowID = "", 0, 0
+	b.Path, b.Line, b.WorkflowID = "", 0, 0
+	return x == b
 }
 
 // CronJobKey uniquely identifies a cron job.
@@ -17,6 +76,13 @@ type CronJobKey struct {
 	CronExpr     string
 }
 
+// ID returns a stable short identifier of the job, used to address it in the API.
+// Format: first 16 hex chars of SHA256("owner/repo/workflow_file:cron_expr").
+func (k CronJobKey) ID() string {
+	hash := sha256.Sum256([]byte(k.Owner + "/" + k.Repo + "/" + k.WorkflowFile + ":" + k.CronExpr))
+	return hex.EncodeToString(hash[:8])
+}
+
 // Key generates a CronJobKey from a CronAnnotation.
 func (a *CronAnnotation) Key() CronJobKey {
 	return CronJobKey{
@@ -27,15 +93,82 @@ func (a *CronAnnotation) Key() CronJobKey {
 	}
 }
 
+// NameToken returns the job name normalized for use in identifiers such as
+// state variable names (upper-case, "-" replaced with "_"). Returns "" if unnamed.
+func (a *CronAnnotation) NameToken() string {
+	return strings.ToUpper(strings.ReplaceAll(a.Name, "-", "_"))
+}
+
 // Repository represents a GitHub App installation repository.
 type Repository struct {
 	Owner         string
 	Name          string
 	DefaultBranch string
+	Archived      bool
+	Fork          bool
+	Topics        []string
 }
 
 // WorkflowFile represents a workflow file in a repository.
 type WorkflowFile struct {
-	Name string // file name (e.g. "build.yml")
-	Path string // full path (e.g. ".github/workflows/build.yml")
+	Name    string // file name (e.g. "build.yml")
+	Path    string // full path (e.g. ".github/workflows/build.yml")
+	SHA     string // git blob SHA of the file
+	Content string // file content (set by GetWorkflowContents)
+}
+
+// Workflow represents a workflow registered in GitHub Actions.
+type Workflow struct {
+	ID    int64
+	Name  string
+	Path  string // full path (e.g. ".github/workflows/build.yml")
+	State string // e.g. "active", "disabled_manually", "disabled_inactivity"
+}
+
+// IsDisabled reports whether the workflow is disabled and cannot be dispatched.
+func (w *Workflow) IsDisabled() bool {
+	return strings.HasPrefix(w.State, "disabled")
+}
+
+// WorkflowRun represents a single run of a workflow.
+type WorkflowRun struct {
+	ID         int64
+	Event      string // e.g. "workflow_dispatch"
+	HeadBranch string
+	Status     string // e.g. "queued", "in_progress", "completed"
+	Conclusion string // set once completed, e.g. "success", "failure"
+	HTMLURL    string
+	CreatedAt  time.Time
+}
+
+// IsCompleted reports whether the run has finished.
+func (r *WorkflowRun) IsCompleted() bool {
+	return r.Status == "completed"
+}
+
+// CheckRun is a completed check run reporting a result on a commit.
+type CheckRun struct {
+	Name       string // e.g. "ghacron/nightly-build"
+	HeadSHA    string
+	Conclusion string // e.g. "success", "failure"
+	Title      string
+	Summary    string // Markdown
+	// Annotations point at lines of files of the commit (at most 50).
+	Annotations []CheckAnnotation
+}
+
+// CheckAnnotation is a message on a line of a file in a check run.
+type CheckAnnotation struct {
+	Path    string
+	Line    int
+	Level   string // "notice", "warning" or "failure"
+	Title   string
+	Message string
+}
+
+// Issue represents a repository issue.
+type Issue struct {
+	Number  int
+	Title   string
+	HTMLURL string
 }
@@ -0,0 +1,22 @@
+package github
+
+import "net/http"
+
+// userAgentTransport sets the User-Agent of every request, including token
+// refreshes, so GitHub audit logs attribute the traffic
+// to ghacron.
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func newUserAgentTransport(next http.RoundTripper, userAgent string) *userAgentTransport {
+	return &userAgentTransport{next: next, userAgent: userAgent}
+}
+
+// RoundTrip sends the request with the User-Agent header replaced.
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.next.RoundTrip(req)
+}
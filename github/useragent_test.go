@@ -0,0 +1,33 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserAgentTransport(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "go-github/v68")
+	resp, err := newUserAgentTransport(http.DefaultTransport, "ghacron/1.2.0 prod").RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	resp.Body.Close()
+
+	if got != "ghacron/1.2.0 prod" {
+		t.Errorf("User-Agent = %q, want %q", got, "ghacron/1.2.0 prod")
+	}
+	if req.Header.Get("User-Agent") != "go-github/v68" {
+		t.Error("the caller's request was modified")
+	}
+}
@@ -5,6 +5,7 @@ go 1.25
 require (
 	github.com/google/go-github/v68 v68.0.0
 	github.com/robfig/cron/v3 v3.0.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/google/go-querystring v1.1.0 // indirect
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/korosuke613/ghacron/config"
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/scanner"
+)
+
+// lint validates the annotations of local workflow files without the GitHub
+// API or App credentials, for pre-commit hooks and CI of the scheduled
+// repositories. Arguments are workflow files, or directories whose
+// .github/workflows (or the directory itself, without one) holds them. It
+// exits with status 1 if there are findings.
+func lint(args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	aliases := flags.String("aliases", os.Getenv("GHACRON_SCHEDULE_ALIASES"), `schedule aliases as "name=expr;..." (default: $GHACRON_SCHEDULE_ALIASES)`)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: ghacron lint [flags] [path ...]")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	// Findings are printed to stdout; the scanner's warnings would repeat them.
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+
+	scheduleAliases, err := config.ParseScheduleAliases(*aliases)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -aliases: %v\n", err)
+		os.Exit(2)
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := readWorkflowFiles(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	sc := scanner.New(nil)
+	sc.SetScheduleAliases(scheduleAliases)
+	annotations, skipped := sc.Lint(files)
+
+	for _, sk := range skipped {
+		fmt.Printf("%s:%d: %s: %s\n", sk.Path, sk.Line, sk.CronExpr, sk.Reason)
+	}
+	if len(skipped) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("%d annotations are valid\n", len(annotations))
+}
+
+// readWorkflowFiles reads the workflow files of the given files and
+// directories, in path order.
+func readWorkflowFiles(paths []string) ([]github.WorkflowFile, error) {
+	var names []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			names = append(names, p)
+			continue
+		}
+		dir := filepath.Join(p, ".github", "workflows")
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			dir = p
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if entry.Type().IsRegular() && (ext == ".yml" || ext == ".yaml") {
+				names = append(names, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	files := make([]github.WorkflowFile, 0, len(names))
+	for _, name := range names {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, github.WorkflowFile{
+			Name:    filepath.Base(name),
+			Path:    filepath.ToSlash(name),
+			Content: string(content),
+		})
+	}
+	return files, nil
+}
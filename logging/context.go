@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type attrsKey struct{}
+
+// WithAttrs returns a context whose log records carry args (key-value pairs
+// or slog.Attrs, as in slog.Logger.With) in addition to those of ctx. The
+// attributes are added by ContextHandler to records logged with the context,
+// e.g. by slog.InfoContext.
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	attrs := append([]slog.Attr(nil), contextAttrs(ctx)...)
+	// A record converts the arguments to attributes like the slog functions.
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// contextAttrs returns the attributes added to ctx by WithAttrs.
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// ContextHandler wraps a handler and adds the attributes of the context of
+// every record (see WithAttrs), so that IDs such as a reconcile ID appear on
+// every line logged while handling it.
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler returns a handler adding context attributes before
+// passing records to next.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := contextAttrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// ecsVersion is the Elastic Common Schema version of ECS log records.
+const ecsVersion = "8.11.0"
+
+// ecsFields maps attribute keys logged by ghacron to ECS field names. Other
+// attributes, such as owner, repo or reconcile_id, keep their keys as custom
+// fields.
+var ecsFields = map[string]string{
+	"error":       "error.message",
+	"method":      "http.request.method",
+	"url":         "url.full",
+	"status":      "http.response.status_code",
+	"remote_addr": "client.address",
+	"addr":        "server.address",
+	"path":        "file.path",
+}
+
+// NewECSHandler returns a JSON handler writing records in Elastic Common
+// Schema: "@timestamp", "log.level" and "message" instead of the keys of
+// log/slog, known attributes renamed to their ECS fields, and the
+// ecs.version, service.name and service.version fields on every record.
+// Dotted names are written as flat keys, which Elasticsearch and the
+// OpenTelemetry collector expand into objects.
+func NewECSHandler(w io.Writer, opts *slog.HandlerOptions, serviceName, serviceVersion string) slog.Handler {
+	o := slog.HandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	replace := o.ReplaceAttr
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if replace != nil {
+			a = replace(groups, a)
+		}
+		return ecsAttr(groups, a)
+	}
+	return slog.NewJSONHandler(w, &o).WithAttrs([]slog.Attr{
+		slog.String("ecs.version", ecsVersion),
+		slog.String("service.name", serviceName),
+		slog.String("service.version", serviceVersion),
+	})
+}
+
+// ecsAttr renames a top-level attribute to its ECS field.
+func ecsAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		a.Key = "@timestamp"
+	case slog.LevelKey:
+		return slog.String("log.level", strings.ToLower(a.Value.String()))
+	case slog.MessageKey:
+		a.Key = "message"
+	case slog.SourceKey:
+		a.Key = "log.origin"
+	default:
+		if field, ok := ecsFields[a.Key]; ok {
+			a.Key = field
+		}
+	}
+	return a
+}
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestECSHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRedactHandler(NewECSHandler(&buf, nil, "ghacron", "1.2.3")))
+
+	logger.With("owner", "o").Warn("GitHub API request failed",
+		"method", "GET",
+		"status", 502,
+		"error", errors.New("bad gateway"),
+		"token", "opaque-value",
+		slog.Group("request", "path", "/repos"),
+	)
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"log.level":                 "warn",
+		"message":                   "GitHub API request failed",
+		"ecs.version":               ecsVersion,
+		"service.name":              "ghacron",
+		"service.version":           "1.2.3",
+		"owner":                     "o",
+		"http.request.method":       "GET",
+		"http.response.status_code": float64(502),
+		"error.message":             "bad gateway",
+		"token":                     redacted,
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+	if _, ok := got["@timestamp"]; !ok {
+		t.Error("record has no @timestamp")
+	}
+	for _, key := range []string{"time", "level", "msg", "error", "method"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("record has slog key %q", key)
+		}
+	}
+	// Attributes in groups keep their keys.
+	if request, _ := got["request"].(map[string]any); request["path"] != "/repos" {
+		t.Errorf("request = %v, want the path attribute", got["request"])
+	}
+}
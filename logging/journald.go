@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+)
+
+// JournaldSocket is the socket of the native journald protocol.
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldWriter sends records to journald with its native protocol, with
+// the formatted record as MESSAGE and its level as PRIORITY, so
+// "journalctl -p warning" filters them.
+type JournaldWriter struct {
+	conn       *net.UnixConn
+	identifier string
+}
+
+// DialJournald connects to the journald socket at path. Records are tagged
+// with identifier (SYSLOG_IDENTIFIER).
+func DialJournald(path, identifier string) (*JournaldWriter, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to journald at %s: %w", path, err)
+	}
+	return &JournaldWriter{conn: conn, identifier: identifier}, nil
+}
+
+// WriteRecord sends a record as one datagram.
+func (w *JournaldWriter) WriteRecord(level slog.Level, msg []byte) error {
+	_, err := w.conn.Write(journaldEntry(level, w.identifier, msg))
+	return err
+}
+
+// Close closes the connection.
+func (w *JournaldWriter) Close() error {
+	return w.conn.Close()
+}
+
+// journaldEntry encodes the fields of a record. MESSAGE uses the binary-safe
+// encoding (name, newline, little-endian 64-bit length, value), since a
+// record may contain newlines.
+func journaldEntry(level slog.Level, identifier string, msg []byte) []byte {
+	var b []byte
+	b = append(b, "PRIORITY="+strconv.Itoa(syslogSeverity(level))+"\n"...)
+	b = append(b, "SYSLOG_IDENTIFIER="+identifier+"\n"...)
+	b = append(b, "MESSAGE\n"...)
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(msg)))
+	b = append(b, msg...)
+	return append(b, '\n')
+}
@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournaldWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := DialJournald(path, "ghacron")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	msg := []byte("first line\nsecond line")
+	if err := w.WriteRecord(slog.LevelWarn, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	want.WriteString("PRIORITY=4\nSYSLOG_IDENTIFIER=ghacron\nMESSAGE\n")
+	binary.Write(&want, binary.LittleEndian, uint64(len(msg)))
+	want.Write(msg)
+	want.WriteByte('\n')
+	if got := buf[:n]; !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("entry = %q, want %q", got, want.Bytes())
+	}
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// RecordWriter writes formatted log records to a destination that keeps the
+// level of every record, such as syslog or journald.
+type RecordWriter interface {
+	WriteRecord(level slog.Level, msg []byte) error
+}
+
+// OutputHandler formats records with a handler (e.g. a JSON handler) and
+// passes each formatted record, with its level, to a RecordWriter.
+type OutputHandler struct {
+	next   slog.Handler
+	output *output
+}
+
+// output is the state shared by an OutputHandler and those derived from it.
+type output struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	w   RecordWriter
+}
+
+// NewOutputHandler returns a handler writing records formatted by the handler
+// newHandler creates for a writer to w.
+func NewOutputHandler(newHandler func(io.Writer) slog.Handler, w RecordWriter) *OutputHandler {
+	o := &output{w: w}
+	return &OutputHandler{next: newHandler(&o.buf), output: o}
+}
+
+func (h *OutputHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *OutputHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.output.mu.Lock()
+	defer h.output.mu.Unlock()
+	h.output.buf.Reset()
+	if err := h.next.Handle(ctx, r); err != nil {
+		return err
+	}
+	return h.output.w.WriteRecord(r.Level, bytes.TrimSuffix(h.output.buf.Bytes(), []byte("\n")))
+}
+
+func (h *OutputHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &OutputHandler{next: h.next.WithAttrs(attrs), output: h.output}
+}
+
+func (h *OutputHandler) WithGroup(name string) slog.Handler {
+	return &OutputHandler{next: h.next.WithGroup(name), output: h.output}
+}
@@ -0,0 +1,182 @@
+// Package logging provides slog handlers shared by ghacron's components.
+package logging
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// redacted replaces sensitive values in log output.
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys (lower case, without '-' and '_') whose
+// values are always redacted.
+var sensitiveKeys = map[string]bool{
+	"authorization":      true,
+	"token":              true,
+	"accesstoken":        true,
+	"jwt":                true,
+	"privatekey":         true,
+	"secret":             true,
+	"webhooksecret":      true,
+	"password":           true,
+	"xhubsignature":      true,
+	"xvaulttoken":        true,
+	"proxyauthorization": true,
+}
+
+// sensitivePatterns match credentials embedded in log strings such as error
+// messages.
+var sensitivePatterns = []*regexp.Regexp{
+	// PEM private keys.
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(-----END [A-Z ]*PRIVATE KEY-----|$)`),
+	// GitHub tokens: installation (ghs_), OAuth (gho_), user (ghu_, ghp_), refresh (ghr_) and fine-grained PATs.
+	regexp.MustCompile(`\b(gh[opsur]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})`),
+	// JWTs, e.g. GitHub App tokens.
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	// Bearer credentials of Authorization headers.
+	regexp.MustCompile(`(?i)\bBearer\s+[A-Za-z0-9._~+/=-]{8,}`),
+	// Vault service, batch and recovery tokens.
+	regexp.MustCompile(`\bhv[sbr]\.[A-Za-z0-9_-]{20,}`),
+}
+
+// minSecretLength is the length below which registered secrets are ignored,
+// as redacting them would also redact ordinary words.
+const minSecretLength = 8
+
+// secretValues are the credentials registered with RegisterSecrets.
+var secretValues struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+// RegisterSecrets redacts values wherever they appear in log output, for
+// credentials without a recognizable format such as a webhook secret or an
+// AWS secret access key.
+func RegisterSecrets(values ...string) {
+	secretValues.mu.Lock()
+	defer secretValues.mu.Unlock()
+	for _, v := range values {
+		if len(v) >= minSecretLength && !slices.Contains(secretValues.values, v) {
+			secretValues.values = append(secretValues.values, v)
+		}
+	}
+}
+
+// RedactHandler wraps a handler and redacts credentials from the message
+// and attributes of every record: attributes with sensitive keys (tokens,
+// Authorization headers, secrets) entirely, including the entries of maps
+// such as http.Header, and tokens, private keys or registered secrets found
+// in other values as they are formatted.
+type RedactHandler struct {
+	next slog.Handler
+}
+
+// NewRedactHandler returns a handler redacting credentials before passing
+// records to next.
+func NewRedactHandler(next slog.Handler) *RedactHandler {
+	return &RedactHandler{next: next}
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redactedAttrs[i] = redactAttr(a)
+	}
+	return &RedactHandler{next: h.next.WithAttrs(redactedAttrs)}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr returns a with sensitive values replaced.
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if isSensitiveKey(a.Key) && a.Value.Kind() != slog.KindGroup {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redactString(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = redactAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	case slog.KindAny:
+		return redactAny(a)
+	}
+	return a
+}
+
+// redactAny redacts a value of any other type: maps with string keys entry by
+// entry, and other values if their formatted string contains a credential.
+func redactAny(a slog.Attr) slog.Attr {
+	v := a.Value.Any()
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String && rv.Len() > 0 {
+		return redactMap(a.Key, rv)
+	}
+	var formatted string
+	if err, ok := v.(error); ok {
+		formatted = err.Error()
+	} else {
+		formatted = fmt.Sprintf("%+v", v)
+	}
+	if r := redactString(formatted); r != formatted {
+		return slog.String(a.Key, r)
+	}
+	return a
+}
+
+// redactMap returns a map as a group of its entries, in key order, so entries
+// with sensitive keys (e.g. the Authorization of an http.Header) are redacted.
+func redactMap(key string, rv reflect.Value) slog.Attr {
+	keys := rv.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.String(), b.String()) })
+	attrs := make([]slog.Attr, len(keys))
+	for i, k := range keys {
+		attrs[i] = redactAttr(slog.Any(k.String(), rv.MapIndex(k).Interface()))
+	}
+	return slog.Attr{Key: key, Value: slog.GroupValue(attrs...)}
+}
+
+func isSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	return sensitiveKeys[normalized]
+}
+
+func redactString(s string) string {
+	for _, pattern := range sensitivePatterns {
+		s = pattern.ReplaceAllString(s, redacted)
+	}
+	secretValues.mu.RLock()
+	defer secretValues.mu.RUnlock()
+	for _, v := range secretValues.values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+	return s
+}
@@ -0,0 +1,176 @@
+package logging
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syslogFacilityDaemon is the facility of ghacron's syslog messages.
+const syslogFacilityDaemon = 3
+
+// syslogTimestamp is the RFC 5424 TIMESTAMP format (at most 6 fractional
+// digits).
+const syslogTimestamp = "2006-01-02T15:04:05.000000Z07:00"
+
+const (
+	// syslogDialTimeout bounds the connection made at startup.
+	syslogDialTimeout = 5 * time.Second
+	// syslogWriteTimeout bounds a write, and a reconnect while logging, so a
+	// stalled server delays the logging goroutine by about a second at most.
+	syslogWriteTimeout = time.Second
+	// syslogRedialInterval is the minimum time between reconnects; records
+	// logged while the connection is down in between are dropped.
+	syslogRedialInterval = 5 * time.Second
+)
+
+// SyslogWriter sends records as RFC 5424 syslog messages. Messages sent over
+// TCP are framed by octet counting (RFC 6587).
+type SyslogWriter struct {
+	network  string
+	addr     string
+	appName  string
+	hostname string
+	pid      int
+
+	mu       sync.Mutex
+	conn     net.Conn
+	redialAt time.Time // earliest time of the next reconnect
+}
+
+// DialSyslog connects to the syslog server at address: "udp://host:port",
+// "tcp://host:port" or "unix:///path" (e.g. unix:///dev/log, tried as a
+// datagram socket first).
+func DialSyslog(address, appName string) (*SyslogWriter, error) {
+	network, addr, err := ParseSyslogAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	w := &SyslogWriter{
+		network:  network,
+		addr:     addr,
+		appName:  appName,
+		hostname: cmp.Or(hostname, "-"),
+		pid:      os.Getpid(),
+	}
+	if err := w.connect(syslogDialTimeout); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// ParseSyslogAddress splits a syslog address into its network and address.
+func ParseSyslogAddress(address string) (network, addr string, err error) {
+	network, addr, ok := strings.Cut(address, "://")
+	if !ok || addr == "" {
+		return "", "", fmt.Errorf("invalid syslog address %q: must be udp://host:port, tcp://host:port or unix:///path", address)
+	}
+	switch network {
+	case "udp", "tcp", "unix":
+		return network, addr, nil
+	}
+	return "", "", fmt.Errorf("invalid syslog address %q: network must be one of udp, tcp, unix", address)
+}
+
+// connect (re)connects to the server.
+func (w *SyslogWriter) connect(timeout time.Duration) error {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	var conn net.Conn
+	var err error
+	if w.network == "unix" {
+		// Local syslog daemons usually listen on a datagram socket.
+		if conn, err = net.Dial("unixgram", w.addr); err != nil {
+			conn, err = net.Dial("unix", w.addr)
+		}
+	} else {
+		conn, err = net.DialTimeout(w.network, w.addr, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog at %s://%s: %w", w.network, w.addr, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+// WriteRecord sends a record. When the connection was lost it reconnects once
+// per syslogRedialInterval and drops the record while it cannot, rather than
+// blocking the caller on an unreachable server.
+func (w *SyslogWriter) WriteRecord(level slog.Level, msg []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	line := w.format(level, now, msg)
+	if w.conn != nil {
+		if err := w.write(line); err == nil {
+			return nil
+		}
+	}
+	if now.Before(w.redialAt) {
+		return fmt.Errorf("syslog at %s://%s is unavailable, record dropped", w.network, w.addr)
+	}
+	w.redialAt = now.Add(syslogRedialInterval)
+	if err := w.connect(syslogWriteTimeout); err != nil {
+		return err
+	}
+	return w.write(line)
+}
+
+// write sends a formatted message within syslogWriteTimeout. A failed write
+// closes the connection, as a partial TCP message would corrupt the framing
+// of the next one.
+func (w *SyslogWriter) write(line []byte) error {
+	_ = w.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	if _, err := w.conn.Write(line); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
+
+// format returns the syslog message of a record, framed for the network.
+func (w *SyslogWriter) format(level slog.Level, t time.Time, msg []byte) []byte {
+	pri := syslogFacilityDaemon*8 + syslogSeverity(level)
+	// No MSGID and no STRUCTURED-DATA: the record itself carries its fields.
+	line := fmt.Appendf(nil, "<%d>1 %s %s %s %d - - %s", pri, t.UTC().Format(syslogTimestamp), w.hostname, w.appName, w.pid, msg)
+	if w.network == "tcp" {
+		return append(fmt.Appendf(nil, "%d ", len(line)), line...)
+	}
+	return line
+}
+
+// Close closes the connection.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// syslogSeverity returns the syslog severity of a level: error (3),
+// warning (4), informational (6) or debug (7).
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 4
+	case level >= slog.LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
@@ -0,0 +1,153 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log/slog"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogWriter_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := DialSyslog("udp://"+conn.LocalAddr().String(), "ghacron")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	logger := slog.New(NewOutputHandler(func(w io.Writer) slog.Handler { return slog.NewJSONHandler(w, nil) }, w))
+	logger.Warn("dispatch failed", "repo", "r")
+
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Facility daemon (3) * 8 + severity warning (4).
+	want := regexp.MustCompile(`^<28>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z \S+ ghacron \d+ - - \{.*"msg":"dispatch failed","repo":"r"\}$`)
+	if got := string(buf[:n]); !want.MatchString(got) {
+		t.Errorf("message = %q, want match of %s", got, want)
+	}
+}
+
+func TestSyslogWriter_TCPFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	w, err := DialSyslog("tcp://"+ln.Addr().String(), "ghacron")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := w.WriteRecord(slog.LevelError, []byte("line one\nline two")); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	length, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		t.Fatalf("invalid frame length %q", length)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(body), "<27>1 ") || !strings.HasSuffix(string(body), " - - line one\nline two") {
+		t.Errorf("message = %q, want an error message with the record", body)
+	}
+}
+
+func TestSyslogWriter_RedialBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	w, err := DialSyslog("tcp://"+ln.Addr().String(), "ghacron")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	first, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+
+	// Connection lost within the backoff period: dropped without a redial.
+	w.Close()
+	w.redialAt = time.Now().Add(time.Minute)
+	if err := w.WriteRecord(slog.LevelInfo, []byte("dropped")); err == nil {
+		t.Fatal("expected the record to be dropped")
+	}
+	if w.conn != nil {
+		t.Fatal("reconnected within the backoff period")
+	}
+
+	// Backoff elapsed: reconnected and sent.
+	w.redialAt = time.Time{}
+	if err := w.WriteRecord(slog.LevelInfo, []byte("sent")); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	want := []byte(" - - sent")
+	var line []byte
+	buf := make([]byte, 4096)
+	for !bytes.HasSuffix(line, want) {
+		n, err := conn.Read(buf)
+		if err != nil {
+			break
+		}
+		line = append(line, buf[:n]...)
+	}
+	if !bytes.HasSuffix(line, want) {
+		t.Errorf("message = %q, want the record logged after the backoff", line)
+	}
+	if !w.redialAt.After(time.Now()) {
+		t.Error("redialAt not advanced by the reconnect")
+	}
+}
+
+func TestParseSyslogAddress(t *testing.T) {
+	tests := map[string]bool{
+		"udp://localhost:514": true,
+		"tcp://10.0.0.1:601":  true,
+		"unix:///dev/log":     true,
+		"localhost:514":       false,
+		"http://localhost":    false,
+		"udp://":              false,
+	}
+	for address, valid := range tests {
+		if _, _, err := ParseSyslogAddress(address); (err == nil) != valid {
+			t.Errorf("ParseSyslogAddress(%q) error = %v, want valid = %t", address, err, valid)
+		}
+	}
+}
@@ -4,112 +4,200 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"os/signal"
+	"runtime"
+	"runtime/debug"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/korosuke613/ghacron/api"
 	"github.com/korosuke613/ghacron/config"
 	"github.com/korosuke613/ghacron/github"
-	"github.com/korosuke613/ghacron/scheduler"
+	"github.com/korosuke613/ghacron/logging"
+	"github.com/korosuke613/ghacron/secrets"
 )
 
-var version = "dev"
+// Build information, set with -ldflags "-X main.version=... -X main.commit=... -X main.date=...".
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
 
 func main() {
-	showVersion := flag.Bool("version", false, "show version")
-	flag.Parse()
+	// Without a subcommand (e.g. "ghacron -config ..."), serve.
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	if *showVersion {
-		fmt.Printf("ghacron v%s\n", version)
-		return
+	switch command {
+	case "serve":
+		serve(args)
+	case "once":
+		once(args)
+	case "scan":
+		scan(args)
+	case "validate":
+		validate(args)
+	case "dispatch":
+		dispatch(args)
+	case "lint":
+		lint(args)
+	case "next":
+		next(args)
+	case "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		usage()
+		os.Exit(2)
 	}
+}
 
-	// Bootstrap logger with JSON/stdout defaults (before config is available)
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+// usage prints the subcommands.
+func usage() {
+	fmt.Fprint(os.Stderr, `Usage: ghacron [command] [flags]
+
+Commands:
+  serve     run the scheduler and the web API (default)
+  once      reconcile, dispatch the jobs due within -window and exit
+  scan      print the annotations of all installation repositories as JSON
+  validate  exit with status 1 if any annotation is invalid
+  dispatch  dispatch a workflow once: ghacron dispatch [-ref ref] owner/repo workflow.yml
+  lint      check the annotations of local workflow files: ghacron lint [path ...]
+  next      print upcoming fire times: ghacron next [-count n] "cron expression"
+
+Run "ghacron <command> -h" for the flags of a command.
+`)
+}
+
+// configFlag defines the -config flag shared by the subcommands.
+func configFlag(fs *flag.FlagSet) *string {
+	return fs.String("config", "", "path to a YAML configuration file (GHACRON_* environment variables override it)")
+}
 
-	cfg, err := config.Load()
+// loadConfig loads the configuration and sets up logging to w, exiting on
+// failure.
+func loadConfig(path string, w io.Writer) *config.Config {
+	// Bootstrap logger with JSON defaults (before config is available)
+	slog.SetDefault(slog.New(logging.NewContextHandler(logging.NewRedactHandler(slog.NewJSONHandler(w, nil)))))
+
+	var cfg *config.Config
+	var err error
+	if path != "" {
+		cfg, err = config.LoadFile(path)
+	} else {
+		cfg, err = config.Load()
+	}
 	if err != nil {
 		slog.Error("failed to load config", "error", err)
 		os.Exit(1)
 	}
 
 	// Re-initialize logger with configured level and format
-	initLogger(&cfg.Log)
-
-	slog.Info("starting ghacron", "version", version)
+	initLogger(&cfg.Log, w)
+	logging.RegisterSecrets(secrets.Credentials()...)
+	return cfg
+}
 
-	// Initialize GitHub App client
-	privateKey, err := cfg.GetPrivateKey()
+// newGitHubClient creates the GitHub App client, exiting on failure.
+func newGitHubClient(cfg *config.Config) *github.Client {
+	privateKey, err := cfg.GetPrivateKey(context.Background())
 	if err != nil {
 		slog.Error("failed to get private key", "error", err)
 		os.Exit(1)
 	}
 
-	ghClient, err := github.NewClient(cfg.GitHub.AppID, privateKey)
+	ghClient, err := github.NewClient(cfg.GitHub.AppID, privateKey, github.ClientOptions{
+		RetryAttempts: cfg.GitHub.RetryAttempts,
+		RetryBackoff:  time.Duration(cfg.GitHub.RetryBackoffMillis) * time.Millisecond,
+		HTTPCache:     cfg.GitHub.HTTPCache,
+
+		CACertPath:         cfg.GitHub.CACertPath,
+		InsecureSkipVerify: cfg.GitHub.InsecureSkipVerify,
+		TokenTimeout:       time.Duration(cfg.GitHub.TokenTimeoutSeconds) * time.Second,
+		LogHTTP:            cfg.Log.HTTP,
+		RepoListTTL:        time.Duration(cfg.GitHub.RepoListTTLSeconds) * time.Second,
+		UserAgent:          userAgent(cfg.GitHub.UserAgentSuffix),
+	})
 	if err != nil {
 		slog.Error("failed to initialize GitHub client", "error", err)
 		os.Exit(1)
 	}
+	return ghClient
+}
 
-	// Load timezone
-	loc, err := time.LoadLocation(cfg.Reconcile.Timezone)
-	if err != nil {
-		slog.Error("failed to load timezone", "error", err)
-		os.Exit(1)
+// userAgent returns the User-Agent of GitHub requests: "ghacron/<version>",
+// followed by the configured suffix.
+func userAgent(suffix string) string {
+	ua := "ghacron/" + version
+	if suffix != "" {
+		ua += " " + suffix
 	}
+	return ua
+}
 
-	// Initialize scheduler
-	sched := scheduler.New(ghClient, &cfg.Reconcile, loc)
-
-	// Initialize and start API server
-	apiServer := api.NewServer(&cfg.WebAPI, cfg)
-	apiServer.SetStatusProvider(sched)
-	if err := apiServer.Start(); err != nil {
-		slog.Error("failed to start API server", "error", err)
-		os.Exit(1)
+// buildInfo returns the build information, falling back to the VCS stamp of
+// the Go toolchain for builds without -ldflags.
+func buildInfo() api.BuildInfo {
+	info := api.BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: date,
+		GoVersion: runtime.Version(),
 	}
-
-	// Start reconciliation loop
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	go sched.RunReconcileLoop(ctx, time.Duration(cfg.Reconcile.IntervalMinutes)*time.Minute)
-
-	slog.Info("ghacron started",
-		"interval_minutes", cfg.Reconcile.IntervalMinutes,
-		"duplicate_guard_seconds", cfg.Reconcile.DuplicateGuardSeconds,
-		"dry_run", cfg.Reconcile.DryRun,
-	)
-
-	// Wait for shutdown signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	sig := <-sigChan
-	slog.Info("received signal, shutting down", "signal", sig.String())
-
-	cancel()
-	sched.Stop()
-	apiServer.Stop()
-
-	slog.Info("ghacron stopped")
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
 }
 
-func initLogger(logCfg *config.LogConfig) {
+// initLogger sets up the configured logger writing to w, or to syslog or
+// journald, exiting if they cannot be reached.
+func initLogger(logCfg *config.LogConfig, w io.Writer) {
 	level := logCfg.SlogLevel()
 	opts := &slog.HandlerOptions{Level: level}
 
+	newHandler := func(w io.Writer) slog.Handler {
+		switch {
+		case strings.EqualFold(logCfg.Schema, "ecs"):
+			return logging.NewECSHandler(w, opts, "ghacron", version)
+		case strings.EqualFold(logCfg.Format, "text"):
+			return slog.NewTextHandler(w, opts)
+		default:
+			return slog.NewJSONHandler(w, opts)
+		}
+	}
+
 	var handler slog.Handler
-	switch strings.ToLower(logCfg.Format) {
-	case "text":
-		handler = slog.NewTextHandler(os.Stdout, opts)
+	switch strings.ToLower(logCfg.Output) {
+	case "syslog":
+		out, err := logging.DialSyslog(logCfg.SyslogAddress, "ghacron")
+		if err != nil {
+			slog.Error("failed to set up log output", "error", err)
+			os.Exit(1)
+		}
+		handler = logging.NewOutputHandler(newHandler, out)
+	case "journald":
+		out, err := logging.DialJournald(logging.JournaldSocket, "ghacron")
+		if err != nil {
+			slog.Error("failed to set up log output", "error", err)
+			os.Exit(1)
+		}
+		handler = logging.NewOutputHandler(newHandler, out)
 	default:
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = newHandler(w)
 	}
 
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(logging.NewContextHandler(logging.NewRedactHandler(handler))))
 }
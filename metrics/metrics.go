@@ -0,0 +1,229 @@
+// Package metrics provides a minimal registry of counters, gauges and
+// histograms exposed in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default is the registry served by the /metrics endpoint.
+var Default = NewRegistry()
+
+// Registry holds registered metrics.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*family
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// family is a named metric with a set of labeled series.
+type family struct {
+	name       string
+	help       string
+	kind       string // "counter", "gauge" or "histogram"
+	labelNames []string
+	buckets    []float64 // upper bounds of histogram buckets, ascending
+
+	mu     sync.Mutex
+	series map[string]*series // joined label values -> series
+}
+
+type series struct {
+	labelValues []string
+	value       float64 // sum of observations for histograms
+
+	count        uint64   // histogram observations
+	bucketCounts []uint64 // histogram observations per bucket (not cumulative)
+}
+
+func (r *Registry) register(name, help, kind string, labelNames []string, buckets []float64) *family {
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     make(map[string]*series),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, f)
+	return f
+}
+
+// update applies fn to the series identified by labelValues, creating it if needed.
+func (f *family) update(labelValues []string, fn func(*series)) {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		f.series[key] = s
+	}
+	fn(s)
+}
+
+// Counter is a monotonically increasing metric.
+type Counter struct{ f *family }
+
+// NewCounter registers a counter with the given label names.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{f: r.register(name, help, "counter", labelNames, nil)}
+}
+
+// Inc increments the series identified by labelValues by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the series identified by labelValues by v (v must be >= 0).
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.f.update(labelValues, func(s *series) { s.value += v })
+}
+
+// Gauge is a metric that can go up and down.
+type Gauge struct{ f *family }
+
+// NewGauge registers a gauge with the given label names.
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{f: r.register(name, help, "gauge", labelNames, nil)}
+}
+
+// Set sets the series identified by labelValues to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.update(labelValues, func(s *series) { s.value = v })
+}
+
+// DefaultBuckets are histogram buckets suited to latencies in seconds.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Histogram counts observations in buckets.
+type Histogram struct{ f *family }
+
+// NewHistogram registers a histogram with the given bucket upper bounds
+// (ascending; the +Inf bucket is implicit) and label names.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: %s buckets are not sorted", name))
+	}
+	return &Histogram{f: r.register(name, help, "histogram", labelNames, buckets)}
+}
+
+// Observe adds v to the series identified by labelValues.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	bucket := sort.SearchFloat64s(h.f.buckets, v) // first bound >= v
+	h.f.update(labelValues, func(s *series) {
+		if s.bucketCounts == nil {
+			s.bucketCounts = make([]uint64, len(h.f.buckets)+1)
+		}
+		s.bucketCounts[bucket]++
+		s.count++
+		s.value += v
+	})
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]*family(nil), r.metrics...)
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	var b strings.Builder
+	for _, f := range families {
+		f.writeText(&b)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (f *family) writeText(b *strings.Builder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := f.series[k]
+		if f.kind != "histogram" {
+			f.writeSample(b, f.name, s.labelValues, "", s.value)
+			continue
+		}
+		var cumulative uint64
+		for i, bound := range f.buckets {
+			cumulative += s.bucketCounts[i]
+			f.writeSample(b, f.name+"_bucket", s.labelValues, formatFloat(bound), float64(cumulative))
+		}
+		f.writeSample(b, f.name+"_bucket", s.labelValues, "+Inf", float64(s.count))
+		f.writeSample(b, f.name+"_sum", s.labelValues, "", s.value)
+		f.writeSample(b, f.name+"_count", s.labelValues, "", float64(s.count))
+	}
+}
+
+// writeSample writes a single sample line, with an le label for histogram
+// buckets.
+func (f *family) writeSample(b *strings.Builder, name string, labelValues []string, le string, value float64) {
+	b.WriteString(name)
+	if len(f.labelNames) > 0 || le != "" {
+		b.WriteByte('{')
+		for i, label := range f.labelNames {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, "%s=\"%s\"", label, escapeLabelValue(labelValues[i]))
+		}
+		if le != "" {
+			if len(f.labelNames) > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, "le=\"%s\"", le)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(value))
+	b.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+// Handler returns an HTTP handler serving the registry.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := r.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("test_events_total", "Events seen.", "repo")
+	g := r.NewGauge("test_jobs", "Registered jobs.")
+
+	c.Inc("b")
+	c.Add(2, "a")
+	c.Inc("a")
+	g.Set(4)
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+
+	want := `# HELP test_events_total Events seen.
+# TYPE test_events_total counter
+test_events_total{repo="a"} 3
+test_events_total{repo="b"} 1
+# HELP test_jobs Registered jobs.
+# TYPE test_jobs gauge
+test_jobs 4
+`
+	if b.String() != want {
+		t.Errorf("WriteText output:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	got := escapeLabelValue("a\"b\\c\nd")
+	want := `a\"b\\c\nd`
+	if got != want {
+		t.Errorf("escapeLabelValue = %q, want %q", got, want)
+	}
+}
+
+func TestCounter_LabelCountMismatch(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("test_total", "Test.", "repo")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for missing label value")
+		}
+	}()
+	c.Inc()
+}
+
+func TestHistogram_WriteText(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("test_duration_seconds", "Durations.", []float64{0.1, 1}, "class")
+
+	h.Observe(0.05, "scan")
+	h.Observe(0.5, "scan")
+	h.Observe(2, "scan")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+
+	want := `# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{class="scan",le="0.1"} 1
+test_duration_seconds_bucket{class="scan",le="1"} 2
+test_duration_seconds_bucket{class="scan",le="+Inf"} 3
+test_duration_seconds_sum{class="scan"} 2.55
+test_duration_seconds_count{class="scan"} 3
+`
+	if b.String() != want {
+		t.Errorf("WriteText output:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/korosuke613/ghacron/config"
+	"github.com/korosuke613/ghacron/scanner"
+
+	"github.com/robfig/cron/v3"
+)
+
+// nextTimeFormat shows the weekday, which is easy to get wrong in cron
+// expressions.
+const nextTimeFormat = "2006-01-02 15:04 Mon MST"
+
+// next prints the upcoming fire times of a cron expression (or @alias) in the
+// schedule's time zone and in UTC, to check an expression before committing
+// it. Expressions without a CRON_TZ= prefix use -tz, like jobs use
+// GHACRON_TIMEZONE.
+func next(args []string) {
+	fs := flag.NewFlagSet("next", flag.ExitOnError)
+	count := fs.Int("count", 5, "number of fire times to print")
+	tz := fs.String("tz", envOr("GHACRON_TIMEZONE", "UTC"), "time zone of expressions without a CRON_TZ= prefix, from $GHACRON_TIMEZONE if set")
+	job := fs.String("job", "", "owner/repo/workflow.yml the expression belongs to, which seeds H tokens")
+	name := fs.String("name", "", "name= option of the annotation, which seeds H tokens as well")
+	aliases := fs.String("aliases", os.Getenv("GHACRON_SCHEDULE_ALIASES"), `schedule aliases as "name=expr;..." (default: $GHACRON_SCHEDULE_ALIASES)`)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), `Usage: ghacron next [flags] "cron expression"`)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	// Allow flags after the expression as well.
+	expr := fs.Arg(0)
+	_ = fs.Parse(fs.Args()[min(1, fs.NArg()):])
+	if expr == "" || fs.NArg() > 0 || *count <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -tz: %v\n", err)
+		os.Exit(2)
+	}
+	expr = expandHash(expandAlias(expr, *aliases), *job, *name)
+
+	schedule, err := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow).Parse(expr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid expression %q: %v\n", expr, err)
+		os.Exit(1)
+	}
+
+	// Next returns times in the location of its argument, so start in the
+	// schedule's own time zone if it has one.
+	if spec, ok := schedule.(*cron.SpecSchedule); ok && spec.Location != time.Local {
+		loc = spec.Location
+	}
+	t := time.Now().In(loc)
+	for range *count {
+		if t = schedule.Next(t); t.IsZero() {
+			fmt.Fprintln(os.Stderr, "no further fire times")
+			os.Exit(1)
+		}
+		fmt.Printf("%s  %s\n", t.Format(nextTimeFormat), t.UTC().Format(nextTimeFormat))
+	}
+}
+
+// expandAlias returns the expression of an @alias from the -aliases flag, or
+// expr itself if it is not an alias.
+func expandAlias(expr, aliases string) string {
+	alias, ok := strings.CutPrefix(expr, "@")
+	if !ok {
+		return expr
+	}
+	scheduleAliases, err := config.ParseScheduleAliases(aliases)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -aliases: %v\n", err)
+		os.Exit(2)
+	}
+	if expr, ok = scheduleAliases[alias]; !ok {
+		fmt.Fprintf(os.Stderr, "unknown schedule alias %q\n", "@"+alias)
+		os.Exit(1)
+	}
+	return expr
+}
+
+// expandHash resolves the H tokens of expr for the job of the -job and -name
+// flags.
+func expandHash(expr, job, name string) string {
+	owner, rest, _ := strings.Cut(job, "/")
+	repo, workflowFile, _ := strings.Cut(rest, "/")
+	if job != "" && (owner == "" || repo == "" || workflowFile == "") {
+		fmt.Fprintf(os.Stderr, "invalid -job %q: expected owner/repo/workflow.yml\n", job)
+		os.Exit(2)
+	}
+	resolved, err := scanner.ExpandHash(expr, owner, repo, workflowFile, name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if resolved == expr {
+		return expr
+	}
+	if job == "" {
+		fmt.Fprintln(os.Stderr, "H tokens depend on the job: set -job owner/repo/workflow.yml (and -name if the annotation has one)")
+		os.Exit(2)
+	}
+	fmt.Fprintf(os.Stderr, "%s resolves to %s\n", expr, resolved)
+	return resolved
+}
+
+// envOr returns the environment variable key, or def if it is unset or empty.
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,241 @@
+// Package notify posts operational events (failed dispatches, tripped
+// circuit breakers, failed reconciles, ...) to Slack, Discord or generic
+// webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/metrics"
+)
+
+// postTimeout bounds a single webhook request.
+const postTimeout = 10 * time.Second
+
+var notificationsTotal = metrics.Default.NewCounter(
+	"ghacron_notifications_total",
+	"Notifications posted to webhooks, by target kind and result (sent, failed).",
+	"target", "result",
+)
+
+// Severity orders events; targets receive events of at least the configured
+// minimum severity.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// ParseSeverity parses "info", "warning" or "error".
+func ParseSeverity(s string) (Severity, error) {
+	switch s {
+	case "info":
+		return SeverityInfo, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q", s)
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// MarshalText encodes the severity by name in JSON payloads.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Event kinds.
+const (
+	KindDispatchFailed     = "dispatch_failed"
+	KindBreakerTripped     = "breaker_tripped"
+	KindReconcileFailed    = "reconcile_failed"
+	KindAnnotationsSkipped = "annotations_skipped"
+	KindAnnotationsFixed   = "annotations_fixed"
+	KindRunFailed          = "run_failed"
+)
+
+// Event is a notification. Owner and Repo are empty for events that do not
+// concern a single repository (e.g. a failed full reconcile).
+type Event struct {
+	Time     time.Time `json:"time"`
+	Severity Severity  `json:"severity"`
+	Kind     string    `json:"kind"`
+	Title    string    `json:"title"`
+	Text     string    `json:"text,omitempty"`
+	Owner    string    `json:"owner,omitempty"`
+	Repo     string    `json:"repo,omitempty"`
+}
+
+// Target kinds, which select the payload format.
+const (
+	TargetSlack   = "slack"
+	TargetDiscord = "discord"
+	TargetWebhook = "webhook"
+)
+
+// Target is a webhook receiving notifications.
+type Target struct {
+	Kind string // TargetSlack, TargetDiscord or TargetWebhook
+	URL  string
+}
+
+// Notifier posts events to its targets in the background.
+type Notifier struct {
+	targets     []Target
+	minSeverity Severity
+	repos       []string // owner/repo patterns; empty matches every repository
+	httpClient  *http.Client
+
+	wg sync.WaitGroup
+}
+
+// New creates a Notifier posting events of at least minSeverity to targets.
+// If repos is not empty, events of other repositories are dropped; repos are
+// path.Match patterns of "owner/repo" (e.g. "myorg/*").
+func New(targets []Target, minSeverity Severity, repos []string) *Notifier {
+	return &Notifier{
+		targets:     targets,
+		minSeverity: minSeverity,
+		repos:       repos,
+		httpClient:  &http.Client{Timeout: postTimeout},
+	}
+}
+
+// Notify posts an event to every target without waiting for the responses.
+// It is a no-op on a nil Notifier and for filtered events.
+func (n *Notifier) Notify(event Event) {
+	if n == nil || !n.wants(event) {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, target := range n.targets {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			n.send(target, event)
+		}()
+	}
+}
+
+// Wait waits until pending notifications are posted, or the timeout elapses.
+func (n *Notifier) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("timed out waiting for notifications to be sent")
+	}
+}
+
+// wants reports whether an event passes the severity and repository filters.
+// Events without a repository pass the repository filter.
+func (n *Notifier) wants(event Event) bool {
+	if event.Severity < n.minSeverity {
+		return false
+	}
+	if len(n.repos) == 0 || event.Repo == "" {
+		return true
+	}
+	for _, pattern := range n.repos {
+		if matched, _ := path.Match(pattern, event.Owner+"/"+event.Repo); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Notifier) send(target Target, event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), postTimeout)
+	defer cancel()
+
+	if err := n.post(ctx, target, event); err != nil {
+		notificationsTotal.Inc(target.Kind, "failed")
+		slog.Error("failed to send notification",
+			"target", target.Kind,
+			"kind", event.Kind,
+			"error", err,
+		)
+		return
+	}
+	notificationsTotal.Inc(target.Kind, "sent")
+}
+
+// post sends an event to a target in the target's format.
+func (n *Notifier) post(ctx context.Context, target Target, event Event) error {
+	body, err := json.Marshal(payload(target.Kind, event))
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		// Webhook URLs embed credentials, so the URL is left out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// payload returns the request body of an event for a target kind: a message
+// for Slack and Discord, the event itself for generic webhooks.
+func payload(kind string, event Event) any {
+	switch kind {
+	case TargetSlack:
+		return map[string]string{"text": message(event, "*")}
+	case TargetDiscord:
+		return map[string]string{"content": message(event, "**")}
+	default:
+		return event
+	}
+}
+
+// message renders an event as chat text, with the title emphasized by the
+// given markup.
+func message(event Event, bold string) string {
+	msg := fmt.Sprintf("%s[%s] %s%s", bold, event.Severity, event.Title, bold)
+	if event.Text != "" {
+		msg += "\n" + event.Text
+	}
+	return msg
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver is a webhook endpoint recording the bodies posted to it.
+type receiver struct {
+	mu     sync.Mutex
+	bodies []map[string]any
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, string) {
+	t.Helper()
+	r := &receiver{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		r.mu.Lock()
+		r.bodies = append(r.bodies, body)
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv.URL
+}
+
+func TestNotifier_Formats(t *testing.T) {
+	slack, slackURL := newReceiver(t, http.StatusOK)
+	discord, discordURL := newReceiver(t, http.StatusNoContent)
+	generic, genericURL := newReceiver(t, http.StatusOK)
+	n := New([]Target{
+		{Kind: TargetSlack, URL: slackURL},
+		{Kind: TargetDiscord, URL: discordURL},
+		{Kind: TargetWebhook, URL: genericURL},
+	}, SeverityWarning, nil)
+
+	n.Notify(Event{
+		Severity: SeverityError,
+		Kind:     KindDispatchFailed,
+		Title:    "Dispatch failed",
+		Text:     "Error: boom",
+		Owner:    "o",
+		Repo:     "r",
+	})
+	n.Wait(5 * time.Second)
+
+	if len(slack.bodies) != 1 || slack.bodies[0]["text"] != "*[error] Dispatch failed*\nError: boom" {
+		t.Errorf("slack bodies = %v", slack.bodies)
+	}
+	if len(discord.bodies) != 1 || discord.bodies[0]["content"] != "**[error] Dispatch failed**\nError: boom" {
+		t.Errorf("discord bodies = %v", discord.bodies)
+	}
+	if len(generic.bodies) != 1 {
+		t.Fatalf("generic bodies = %v", generic.bodies)
+	}
+	event := generic.bodies[0]
+	if event["severity"] != "error" || event["kind"] != KindDispatchFailed || event["repo"] != "r" || event["time"] == nil {
+		t.Errorf("generic event = %v", event)
+	}
+}
+
+func TestNotifier_Filters(t *testing.T) {
+	tests := map[string]struct {
+		event Event
+		want  bool
+	}{
+		"below min severity":  {Event{Severity: SeverityInfo, Owner: "myorg", Repo: "a"}, false},
+		"matching repository": {Event{Severity: SeverityWarning, Owner: "myorg", Repo: "a"}, true},
+		"other repository":    {Event{Severity: SeverityError, Owner: "other", Repo: "a"}, false},
+		"no repository":       {Event{Severity: SeverityError}, true},
+	}
+	n := New(nil, SeverityWarning, []string{"myorg/*"})
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := n.wants(tt.event); got != tt.want {
+				t.Errorf("wants = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifier_PostErrorHidesURL(t *testing.T) {
+	n := New(nil, SeverityInfo, nil)
+	err := n.post(t.Context(), Target{Kind: TargetSlack, URL: "http://127.0.0.1:0/services/secret"}, Event{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error %q contains the webhook URL", err)
+	}
+
+	_, url := newReceiver(t, http.StatusInternalServerError)
+	if err := n.post(t.Context(), Target{Kind: TargetWebhook, URL: url}, Event{}); err == nil {
+		t.Error("expected error for a 500 response")
+	}
+}
+
+func TestNotifier_Nil(t *testing.T) {
+	var n *Notifier
+	n.Notify(Event{Severity: SeverityError}) // must not panic
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		got, err := ParseSeverity(s.String())
+		if err != nil || got != s {
+			t.Errorf("ParseSeverity(%q) = %v, %v", s, got, err)
+		}
+	}
+	if _, err := ParseSeverity("critical"); err == nil {
+		t.Error("expected error for an unknown severity")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/korosuke613/ghacron/tracing"
+)
+
+// defaultOnceWindow matches a 5-minute schedule of the external scheduler.
+const defaultOnceWindow = 5 * time.Minute
+
+// once reconciles a single time, dispatches the jobs due within the lookahead
+// window and exits, for running ghacron from an external cron or a GitHub
+// Actions schedule instead of as a daemon. It exits with status 1 if the
+// reconcile fails.
+func once(args []string) {
+	fs := flag.NewFlagSet("once", flag.ExitOnError)
+	configPath := configFlag(fs)
+	window := fs.Duration("window", defaultOnceWindow, "dispatch the jobs due within this long after the start (set to the interval of the external scheduler)")
+	_ = fs.Parse(args)
+
+	if *window <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := loadConfig(*configPath, os.Stdout)
+
+	if cfg.Tracing.Endpoint != "" {
+		tracer := tracing.Init(cfg.Tracing.Endpoint, "ghacron", version)
+		defer shutdownTracing(tracer)
+	}
+
+	ghClient := newGitHubClient(cfg)
+	sched, stopScheduler := newScheduler(cfg, ghClient)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	due, err := sched.RunOnce(ctx, *window)
+	stopScheduler()
+	if err != nil {
+		slog.Error("run failed", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("run completed", "due", due, "window", window.String())
+}
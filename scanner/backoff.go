@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// DegradedRepo is a repository whose recent scans failed.
+type DegradedRepo struct {
+	Owner               string    `json:"owner"`
+	Repo                string    `json:"repo"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error"`
+	LastFailure         time.Time `json:"last_failure"`
+	// SkipScans is the number of full scans skipped before the next attempt.
+	SkipScans int `json:"skip_scans"`
+}
+
+// repoFailures tracks the consecutive scan failures of a repository.
+type repoFailures struct {
+	count     int
+	lastError string
+	last      time.Time
+	skip      int // full scans left to skip
+}
+
+// SetScanBackoff makes full scans skip a repository after consecutive
+// failures: for 1, 3, 7, ... scans after the 2nd, 3rd, 4th, ... failure, up
+// to maxSkips (0 retries every scan). Skipped repositories are reported as
+// failed, so their jobs are kept.
+func (s *Scanner) SetScanBackoff(maxSkips int) {
+	s.maxBackoffSkips = maxSkips
+}
+
+// backingOff reports whether a full scan should skip a repository, counting
+// the skipped scan.
+func (s *Scanner) backingOff(repo github.Repository) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.failures[repo.Owner+"/"+repo.Name]
+	if !ok || f.skip == 0 {
+		return false
+	}
+	f.skip--
+	return true
+}
+
+// recordScanFailure counts a failed scan of a repository and sets the number
+// of full scans to skip before the next attempt.
+func (s *Scanner) recordScanFailure(ctx context.Context, repo github.Repository, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := repo.Owner + "/" + repo.Name
+	f, ok := s.failures[key]
+	if !ok {
+		f = &repoFailures{}
+		s.failures[key] = f
+	}
+	f.count++
+	f.lastError = err.Error()
+	f.last = time.Now()
+	s.recordRepoScan(repo, f.last, false, 0, 0)
+	f.skip = min(1<<min(f.count-1, 30)-1, s.maxBackoffSkips)
+	if f.skip > 0 {
+		slog.WarnContext(ctx, "repository scan keeps failing, backing off",
+			"owner", repo.Owner,
+			"repo", repo.Name,
+			"consecutive_failures", f.count,
+			"skip_scans", f.skip,
+		)
+	}
+}
+
+// recordScanSuccess clears the failures of a repository and records the
+// number of annotations the scan found.
+func (s *Scanner) recordScanSuccess(repo github.Repository, annotations, skipped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, repo.Owner+"/"+repo.Name)
+	s.recordRepoScan(repo, time.Now(), true, annotations, skipped)
+}
+
+// DegradedRepos returns the repositories whose last scan failed, ordered by
+// name.
+func (s *Scanner) DegradedRepos() []DegradedRepo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repos := make([]DegradedRepo, 0, len(s.failures))
+	for key, f := range s.failures {
+		owner, name, _ := strings.Cut(key, "/")
+		repos = append(repos, DegradedRepo{
+			Owner:               owner,
+			Repo:                name,
+			ConsecutiveFailures: f.count,
+			LastError:           f.lastError,
+			LastFailure:         f.last,
+			SkipScans:           f.skip,
+		})
+	}
+	slices.SortFunc(repos, func(a, b DegradedRepo) int {
+		return cmp.Or(strings.Compare(a.Owner, b.Owner), strings.Compare(a.Repo, b.Repo))
+	})
+	return repos
+}
@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestScanAll_Backoff(t *testing.T) {
+	repo := github.Repository{Owner: "o", Name: "broken", DefaultBranch: "main"}
+	client := &mockScannerClient{
+		repos: []github.Repository{repo},
+		errs:  map[string]error{"o/broken": errors.New("boom")},
+	}
+	s := New(client)
+	s.SetScanBackoff(2)
+
+	// Attempts after 0, 1, 2 and 2 skipped scans (1, 3, 7 capped at 2).
+	var attempted []int
+	for scan := 1; scan <= 7; scan++ {
+		calls := client.contentCalls
+		result, err := s.ScanAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.FailedRepos) != 1 {
+			t.Fatalf("scan %d: failed repos = %d, want 1", scan, len(result.FailedRepos))
+		}
+		if client.contentCalls > calls {
+			attempted = append(attempted, scan)
+		} else if result.BackedOffRepos != 1 {
+			t.Errorf("scan %d: backed off repos = %d, want 1", scan, result.BackedOffRepos)
+		}
+	}
+	if want := []int{1, 2, 4, 7}; !slices.Equal(attempted, want) {
+		t.Errorf("attempted scans = %v, want %v", attempted, want)
+	}
+
+	degraded := s.DegradedRepos()
+	if len(degraded) != 1 || degraded[0].ConsecutiveFailures != 4 || degraded[0].LastError != "boom" || degraded[0].SkipScans != 2 {
+		t.Fatalf("degraded repos = %+v, want broken with 4 failures", degraded)
+	}
+
+	// A successful scan, e.g. after a push, clears the failures.
+	delete(client.errs, "o/broken")
+	if _, err := s.ScanRepo(context.Background(), repo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if degraded := s.DegradedRepos(); len(degraded) != 0 {
+		t.Errorf("degraded repos = %+v, want none after a successful scan", degraded)
+	}
+}
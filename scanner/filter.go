@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// SetTopicFilter restricts the scanner to repositories carrying topic, so
+// repositories opt in to scheduling. An empty topic scans every repository.
+func (s *Scanner) SetTopicFilter(topic string) {
+	s.topic = topic
+}
+
+// SetSkipForks excludes forked repositories, whose workflows (and their
+// annotations) were usually copied from the upstream repository.
+func (s *Scanner) SetSkipForks(skip bool) {
+	s.skipForks = skip
+}
+
+// included reports whether a repository passes the fork and topic filters.
+// GitHub stores topics in lowercase, but the configured topic may not be.
+func (s *Scanner) included(repo github.Repository) bool {
+	if s.skipForks && repo.Fork {
+		return false
+	}
+	if s.topic == "" {
+		return true
+	}
+	return slices.ContainsFunc(repo.Topics, func(t string) bool {
+		return strings.EqualFold(t, s.topic)
+	})
+}
+
+// includedRepos returns the repositories passing the fork and topic filters.
+func (s *Scanner) includedRepos(repos []github.Repository) []github.Repository {
+	if s.topic == "" && !s.skipForks {
+		return repos
+	}
+	var included []github.Repository
+	for _, repo := range repos {
+		if s.included(repo) {
+			included = append(included, repo)
+		}
+	}
+	return included
+}
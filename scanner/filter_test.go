@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestScanAll_TopicFilter(t *testing.T) {
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n"
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	client := &mockScannerClient{
+		repos: []github.Repository{
+			{Owner: "o", Name: "enabled", DefaultBranch: "main", Topics: []string{"go", "ghacron-enabled"}},
+			{Owner: "o", Name: "other", DefaultBranch: "main", Topics: []string{"go"}},
+			{Owner: "o", Name: "untagged", DefaultBranch: "main"},
+		},
+		files:    map[string][]github.WorkflowFile{},
+		contents: map[string]string{},
+	}
+	for _, repo := range client.repos {
+		client.files["o/"+repo.Name] = []github.WorkflowFile{file}
+		client.contents["o/"+repo.Name+"/.github/workflows/ci.yml"] = content
+	}
+
+	s := New(client)
+	s.SetTopicFilter("GHACRON-enabled")
+	result, err := s.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Annotations) != 1 || result.Annotations[0].Repo != "enabled" {
+		t.Errorf("annotations = %+v, want only the repository with the topic", result.Annotations)
+	}
+	if len(result.ScannedRepos) != 1 {
+		t.Errorf("scanned repos = %+v, want 1", result.ScannedRepos)
+	}
+
+	repoResult, err := s.ScanRepo(context.Background(), client.repos[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repoResult.Annotations) != 0 || len(repoResult.ScannedRepos) != 0 {
+		t.Errorf("ScanRepo of a repository without the topic = %+v, want an empty result", repoResult)
+	}
+}
+
+func TestScanAll_SkipForks(t *testing.T) {
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n"
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	client := &mockScannerClient{
+		repos: []github.Repository{
+			{Owner: "o", Name: "upstream", DefaultBranch: "main"},
+			{Owner: "o", Name: "fork", DefaultBranch: "main", Fork: true},
+		},
+		files:    map[string][]github.WorkflowFile{},
+		contents: map[string]string{},
+	}
+	for _, repo := range client.repos {
+		client.files["o/"+repo.Name] = []github.WorkflowFile{file}
+		client.contents["o/"+repo.Name+"/.github/workflows/ci.yml"] = content
+	}
+
+	for _, skip := range []bool{false, true} {
+		s := New(client)
+		s.SetSkipForks(skip)
+		result, err := s.ScanAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := 2
+		if skip {
+			want = 1
+		}
+		if len(result.Annotations) != want {
+			t.Errorf("skip forks %v: annotations = %d, want %d", skip, len(result.Annotations), want)
+		}
+	}
+}
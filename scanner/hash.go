@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hashTokenRe matches a Jenkins-style H token: H, H(lo-hi), H/step or
+// H(lo-hi)/step.
+var hashTokenRe = regexp.MustCompile(`^H(?:\((\d+)-(\d+)\))?(?:/(\d+))?$`)
+
+// hashFieldRanges are the value ranges H picks from in each of the five
+// fields. Days of the month stop at 28 so the job runs every month.
+var hashFieldRanges = [5]struct {
+	name   string
+	lo, hi int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 28},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// ExpandHash resolves the H tokens of a cron expression to values derived
+// from the job's identity (owner, repo, workflow file and name), so each job
+// gets a stable pseudo-random schedule, e.g. "H H(0-5) * * *" becomes
+// "37 2 * * *". Expressions without H are returned unchanged.
+func ExpandHash(expr, owner, repo, workflowFile, name string) (string, error) {
+	fields := strings.Fields(expr)
+	prefix := ""
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		prefix, fields = fields[0]+" ", fields[1:]
+	}
+	if len(fields) != len(hashFieldRanges) || !strings.Contains(strings.Join(fields, " "), "H") {
+		return expr, nil
+	}
+
+	seed := owner + "/" + repo + "/" + workflowFile + "/" + name
+	for i, field := range fields {
+		parts := strings.Split(field, ",")
+		for j, part := range parts {
+			if !strings.Contains(part, "H") {
+				continue
+			}
+			resolved, err := expandHashToken(part, i, seed)
+			if err != nil {
+				return "", err
+			}
+			parts[j] = resolved
+		}
+		fields[i] = strings.Join(parts, ",")
+	}
+	return prefix + strings.Join(fields, " "), nil
+}
+
+// expandHashToken resolves a single H token of field i.
+func expandHashToken(token string, i int, seed string) (string, error) {
+	field := hashFieldRanges[i]
+	m := hashTokenRe.FindStringSubmatch(token)
+	if m == nil {
+		return "", fmt.Errorf("invalid H token %q in %s field: expected H, H(lo-hi), H/step or H(lo-hi)/step", token, field.name)
+	}
+	lo, hi := field.lo, field.hi
+	if m[1] != "" {
+		lo, _ = strconv.Atoi(m[1])
+		hi, _ = strconv.Atoi(m[2])
+		if lo < field.lo || hi > field.hi || lo > hi {
+			return "", fmt.Errorf("invalid H range %q in %s field: must be within %d-%d", token, field.name, field.lo, field.hi)
+		}
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(seed + "\x00" + strconv.Itoa(i)))
+	sum := h.Sum64()
+
+	if m[3] == "" {
+		return strconv.Itoa(lo + int(sum%uint64(hi-lo+1))), nil
+	}
+	step, _ := strconv.Atoi(m[3])
+	if step <= 0 || step > hi-lo+1 {
+		return "", fmt.Errorf("invalid H step %q in %s field: must be 1 to %d", token, field.name, hi-lo+1)
+	}
+	// The first value is hashed within the first step, so the job runs at
+	// the same interval at a job-specific offset.
+	start := lo + int(sum%uint64(step))
+	return fmt.Sprintf("%d-%d/%d", start, hi, step), nil
+}
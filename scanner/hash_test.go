@@ -0,0 +1,123 @@
+package scanner
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestExpandHash(t *testing.T) {
+	s := New(nil)
+	tests := []struct {
+		name string
+		expr string
+		// check validates the resolved expression field by field.
+		check func(t *testing.T, fields []string)
+	}{
+		{"no H", "0 9 * * 1-5", func(t *testing.T, fields []string) {
+			if strings.Join(fields, " ") != "0 9 * * 1-5" {
+				t.Errorf("expression changed: %v", fields)
+			}
+		}},
+		{"minute and hour range", "H H(0-5) * * *", func(t *testing.T, fields []string) {
+			if !inRange(fields[0], 0, 59) || !inRange(fields[1], 0, 5) || fields[2] != "*" {
+				t.Errorf("fields = %v", fields)
+			}
+		}},
+		{"day of month stays below 29", "0 0 H * *", func(t *testing.T, fields []string) {
+			if !inRange(fields[2], 1, 28) {
+				t.Errorf("fields = %v", fields)
+			}
+		}},
+		{"step", "H/15 * * * *", func(t *testing.T, fields []string) {
+			start, rest, _ := strings.Cut(fields[0], "-")
+			if !inRange(start, 0, 14) || rest != "59/15" {
+				t.Errorf("fields = %v", fields)
+			}
+		}},
+		{"list", "0,H(30-40) * * * *", func(t *testing.T, fields []string) {
+			first, second, _ := strings.Cut(fields[0], ",")
+			if first != "0" || !inRange(second, 30, 40) {
+				t.Errorf("fields = %v", fields)
+			}
+		}},
+		{"time zone prefix", "CRON_TZ=Asia/Tokyo H 9 * * *", func(t *testing.T, fields []string) {
+			if fields[0] != "CRON_TZ=Asia/Tokyo" || !inRange(fields[1], 0, 59) {
+				t.Errorf("fields = %v", fields)
+			}
+		}},
+		{"H in the time zone only", "TZ=Asia/Ho_Chi_Minh 0 9 * * *", func(t *testing.T, fields []string) {
+			if strings.Join(fields, " ") != "TZ=Asia/Ho_Chi_Minh 0 9 * * *" {
+				t.Errorf("expression changed: %v", fields)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandHash(tt.expr, "org", "repo", "ci.yml", "")
+			if err != nil {
+				t.Fatalf("ExpandHash: %v", err)
+			}
+			tt.check(t, strings.Fields(got))
+			if _, err := s.cronParser.Parse(got); err != nil {
+				t.Errorf("resolved expression %q is invalid: %v", got, err)
+			}
+			if again, _ := ExpandHash(tt.expr, "org", "repo", "ci.yml", ""); again != got {
+				t.Errorf("not stable: %q, then %q", got, again)
+			}
+		})
+	}
+}
+
+func TestExpandHash_SeededByJob(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		got, err := ExpandHash("H H * * *", "org", "repo", "ci.yml", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("every job resolved to the same schedule: %v", seen)
+	}
+}
+
+func TestExpandHash_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"H(0-60) * * * *",
+		"* H(5-1) * * *",
+		"H/0 * * * *",
+		"H/61 * * * *",
+		"*/H * * * *",
+		"HH * * * *",
+	} {
+		if got, err := ExpandHash(expr, "org", "repo", "ci.yml", ""); err == nil {
+			t.Errorf("ExpandHash(%q) = %q, want error", expr, got)
+		}
+	}
+}
+
+func TestParseFile_HashExpr(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "org", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	content := "on:\n  # ghacron: \"H H(0-5) * * *\" name=nightly\n  workflow_dispatch:\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 1 || len(skipped) != 0 {
+		t.Fatalf("annotations = %+v, skipped = %+v", annotations, skipped)
+	}
+	want, _ := ExpandHash("H H(0-5) * * *", "org", "repo", "ci.yml", "nightly")
+	if a := annotations[0]; a.CronExpr != want || a.HashExpr != "H H(0-5) * * *" {
+		t.Errorf("CronExpr = %q, HashExpr = %q; want %q from the H expression", a.CronExpr, a.HashExpr, want)
+	}
+}
+
+// inRange reports whether a field is a single number within lo-hi.
+func inRange(field string, lo, hi int) bool {
+	n, err := strconv.Atoi(field)
+	return err == nil && n >= lo && n <= hi
+}
@@ -0,0 +1,34 @@
+package scanner
+
+import (
+	"github.com/korosuke613/ghacron/github"
+)
+
+// Lint validates the annotations of local workflow files of a single
+// repository, without the GitHub API. Unlike a scan, annotations in files
+// triggered by neither dispatch event are reported instead of ignored, since
+// they are likely a mistake in the repository being linted. Owner, Repo and
+// Ref of the results are empty.
+func (s *Scanner) Lint(files []github.WorkflowFile) ([]github.CronAnnotation, []SkippedAnnotation) {
+	var repo github.Repository
+	var annotations []github.CronAnnotation
+	var skipped []SkippedAnnotation
+	directive := findTimezoneDirective(files)
+
+	for _, file := range files {
+		if !HasWorkflowDispatch(file.Content) && !HasTrigger(file.Content, github.DispatchTypeRepository) {
+			for _, p := range ParseAnnotations(file.Content) {
+				skipped = append(skipped, newSkipped(sourceAnnotation(repo, file, p), SkipMissingTrigger,
+					"neither workflow_dispatch nor repository_dispatch is in the on: section"))
+			}
+			continue
+		}
+		fileAnnotations, fileSkipped := s.parseFile(repo, file, file.Content, directive.zone)
+		annotations = append(annotations, fileAnnotations...)
+		skipped = append(skipped, fileSkipped...)
+	}
+	skipped = directive.checkZone(repo, skipped)
+
+	annotations, dupSkipped := dropDuplicateNames(annotations)
+	return annotations, append(skipped, dupSkipped...)
+}
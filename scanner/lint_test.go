@@ -0,0 +1,43 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestLint(t *testing.T) {
+	s := New(nil)
+	s.SetScheduleAliases(map[string]string{"nightly": "0 3 * * *"})
+	files := []github.WorkflowFile{
+		{Name: "ok.yml", Path: ".github/workflows/ok.yml", Content: "on:\n  # ghacron: \"0 8 * * *\" name=build\n  # ghacron: \"@nightly\"\n  workflow_dispatch:\n"},
+		{Name: "bad.yml", Path: ".github/workflows/bad.yml", Content: "on:\n  # ghacron: \"CRON_TZ=Invalid/Zone 0 8 * * *\"\n  # ghacron: \"0 9 * * *\" name=build\n  workflow_dispatch:\n"},
+		{Name: "push.yml", Path: ".github/workflows/push.yml", Content: "on:\n  # ghacron: \"0 8 * * *\"\n  push:\n"},
+		{Name: "plain.yml", Path: ".github/workflows/plain.yml", Content: "on:\n  push:\n"},
+	}
+
+	annotations, skipped := s.Lint(files)
+
+	if len(annotations) != 2 {
+		t.Errorf("annotations: got %d, want 2", len(annotations))
+	}
+	want := []struct {
+		path   string
+		line   int
+		reason string
+	}{
+		{".github/workflows/bad.yml", 2, "time zone"},
+		{".github/workflows/push.yml", 2, "neither workflow_dispatch nor repository_dispatch"},
+		{".github/workflows/bad.yml", 3, "duplicate name"},
+	}
+	if len(skipped) != len(want) {
+		t.Fatalf("skipped: got %+v, want %d entries", skipped, len(want))
+	}
+	for i, w := range want {
+		sk := skipped[i]
+		if sk.Path != w.path || sk.Line != w.line || !strings.Contains(sk.Reason, w.reason) {
+			t.Errorf("skipped[%d] = %s:%d %q, want %s:%d containing %q", i, sk.Path, sk.Line, sk.Reason, w.path, w.line, w.reason)
+		}
+	}
+}
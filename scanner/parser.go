@@ -6,39 +6,93 @@ import (
 )
 
 // Regex for extracting annotations.
-// Format: # ghacron: "0 8 * * *" or # ghacron: '0 8 * * *'
-var annotationRe = regexp.MustCompile(`^\s*#\s*ghacron:\s*["'](.+?)["']\s*$`)
+// Format: # ghacron: "0 8 * * *" or # ghacron: '0 8 * * *', optionally
+// followed by key=value options (e.g. # ghacron: "0 8 * * *" name=nightly).
+var annotationRe = regexp.MustCompile(`^\s*#\s*ghacron:\s*["'](.+?)["']((?:\s+[A-Za-z][A-Za-z0-9_.-]*=(?:"[^"]*"|'[^']*'|[^\s"']+))*)\s*$`)
+
+// timezoneRe matches the repository-level time zone directive, e.g.
+// # ghacron-tz: Asia/Tokyo
+var timezoneRe = regexp.MustCompile(`^\s*#\s*ghacron-tz:\s*["']?([^"'\s]+)["']?\s*$`)
+
+// optionRe matches a single key=value option. Values may be quoted to include spaces.
+var optionRe = regexp.MustCompile(`([A-Za-z][A-Za-z0-9_.-]*)=("[^"]*"|'[^']*'|[^\s"']+)`)
+
+// Annotation is a single annotation extracted from a workflow file.
+type Annotation struct {
+	CronExpr string
+	Options  map[string]string // key=value options following the expression (nil if none)
+	Line     int               // 1-based line number in the file
+}
 
 // ParseAnnotations extracts cron annotations from workflow file content.
-func ParseAnnotations(content string) []string {
-	var exprs []string
+func ParseAnnotations(content string) []Annotation {
+	var annotations []Annotation
 	lines := strings.Split(content, "\n")
 
-	for _, line := range lines {
+	for i, line := range lines {
 		matches := annotationRe.FindStringSubmatch(line)
-		if len(matches) >= 2 {
+		if len(matches) >= 3 {
 			expr := strings.TrimSpace(matches[1])
 			if expr != "" {
-				exprs = append(exprs, expr)
+				annotations = append(annotations, Annotation{
+					CronExpr: expr,
+					Options:  parseOptions(matches[2]),
+					Line:     i + 1,
+				})
 			}
 		}
 	}
 
-	return exprs
+	return annotations
+}
+
+// ParseTimezone returns the zone of the first "# ghacron-tz: <zone>" line of
+// workflow file content and its 1-based line number ("" and 0 if there is
+// none).
+func ParseTimezone(content string) (string, int) {
+	for i, line := range strings.Split(content, "\n") {
+		if m := timezoneRe.FindStringSubmatch(line); m != nil {
+			return m[1], i + 1
+		}
+	}
+	return "", 0
+}
+
+// parseOptions parses the key=value options trailing an annotation.
+// Surrounding quotes are stripped from values. Returns nil if there are no options.
+func parseOptions(s string) map[string]string {
+	matches := optionRe.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	opts := make(map[string]string, len(matches))
+	for _, m := range matches {
+		value := m[2]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		opts[m[1]] = value
+	}
+	return opts
 }
 
 // HasWorkflowDispatch checks if workflow_dispatch is in the on: section.
 func HasWorkflowDispatch(content string) bool {
+	return HasTrigger(content, "workflow_dispatch")
+}
+
+// HasTrigger checks if the event (e.g. repository_dispatch) is in the on: section.
+func HasTrigger(content, event string) bool {
 	lines := strings.Split(content, "\n")
 	inOn := false
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
-		// Detect start of on: section (handles workflow_dispatch on the same line).
+		// Detect start of on: section (handles the event on the same line).
 		if isOnSectionStart(trimmed) {
 			inOn = true
-			if strings.Contains(trimmed, "workflow_dispatch") {
+			if strings.Contains(trimmed, event) {
 				return true
 			}
 			continue
@@ -52,7 +106,11 @@ func HasWorkflowDispatch(content string) bool {
 			inOn = false
 			continue
 		}
-		if strings.Contains(trimmed, "workflow_dispatch") {
+		// Comments such as ghacron annotations may mention the event.
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.Contains(trimmed, event) {
 			return true
 		}
 	}
@@ -60,6 +118,125 @@ func HasWorkflowDispatch(content string) bool {
 	return false
 }
 
+// RequiredInputsWithoutDefault returns the names of workflow_dispatch inputs
+// that are declared required: true without a default. Dispatching such a
+// workflow without inputs always fails.
+func RequiredInputsWithoutDefault(content string) []string {
+	lines := strings.Split(content, "\n")
+
+	start, dispatchIndent := findWorkflowDispatchLine(lines)
+	if start < 0 {
+		return nil
+	}
+
+	inputs := inputsBlock{inputsIndent: -1, inputIndent: -1}
+	for _, line := range lines[start+1:] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := indentOf(line)
+		if indent <= dispatchIndent {
+			break // end of workflow_dispatch block
+		}
+		inputs.line(indent, trimmed)
+	}
+	inputs.flush()
+
+	return inputs.missing
+}
+
+// inputsBlock collects the required inputs without defaults from the lines
+// of a workflow_dispatch block.
+type inputsBlock struct {
+	inputsIndent int // indentation of inputs: (-1 = outside of it)
+	inputIndent  int // indentation of the input names (-1 = none seen yet)
+	current      *workflowInput
+	missing      []string
+}
+
+// line processes a non-empty line of the workflow_dispatch block.
+func (b *inputsBlock) line(indent int, trimmed string) {
+	switch {
+	case b.inputsIndent < 0:
+		if trimmed == "inputs:" {
+			b.inputsIndent = indent
+		}
+	case indent <= b.inputsIndent:
+		// Sibling key of inputs: (or end of inputs block).
+		b.flush()
+		b.inputsIndent, b.inputIndent = -1, -1
+		if trimmed == "inputs:" {
+			b.inputsIndent = indent
+		}
+	case b.inputIndent < 0 || indent == b.inputIndent:
+		b.flush()
+		b.inputIndent = indent
+		b.current = &workflowInput{name: strings.TrimSuffix(trimmed, ":")}
+	case b.current != nil:
+		b.current.applyField(trimmed)
+	}
+}
+
+// flush records the current input if it is required without a default.
+func (b *inputsBlock) flush() {
+	if b.current != nil && b.current.required && !b.current.hasDefault {
+		b.missing = append(b.missing, b.current.name)
+	}
+	b.current = nil
+}
+
+// workflowInput tracks the fields of a single workflow_dispatch input.
+type workflowInput struct {
+	name       string
+	required   bool
+	hasDefault bool
+}
+
+// applyField records a "key: value" line belonging to the input.
+func (in *workflowInput) applyField(trimmed string) {
+	key, value, ok := strings.Cut(trimmed, ":")
+	if !ok {
+		return
+	}
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	switch strings.TrimSpace(key) {
+	case "required":
+		in.required = value == "true"
+	case "default":
+		in.hasDefault = true
+	}
+}
+
+// findWorkflowDispatchLine returns the index and indentation of the
+// workflow_dispatch: key inside the on: section, or -1 if absent.
+func findWorkflowDispatchLine(lines []string) (int, int) {
+	inOn := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if isOnSectionStart(trimmed) {
+			inOn = true
+			continue
+		}
+		if !inOn {
+			continue
+		}
+		if isTopLevelKey(line, trimmed) {
+			inOn = false
+			continue
+		}
+		if strings.HasPrefix(trimmed, "workflow_dispatch:") {
+			return i, indentOf(line)
+		}
+	}
+	return -1, -1
+}
+
+// indentOf returns the number of leading whitespace characters in a line.
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
 // isOnSectionStart reports whether a trimmed line begins the on: section.
 // HasPrefix already matches the exact "on:" string, so no equality check is needed.
 func isOnSectionStart(trimmed string) bool {
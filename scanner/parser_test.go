@@ -56,6 +56,11 @@ func TestParseAnnotations(t *testing.T) {
 			content:  `# ghacron: ""`,
 			expected: nil,
 		},
+		{
+			name:     "trailing options",
+			content:  `# ghacron: "0 8 * * *" name=nightly-build`,
+			expected: []string{"0 8 * * *"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -64,15 +69,86 @@ func TestParseAnnotations(t *testing.T) {
 			if len(got) != len(tt.expected) {
 				t.Fatalf("got %d annotations, want %d: %v", len(got), len(tt.expected), got)
 			}
-			for i, expr := range got {
-				if expr != tt.expected[i] {
-					t.Errorf("annotation[%d] = %q, want %q", i, expr, tt.expected[i])
+			for i, a := range got {
+				if a.CronExpr != tt.expected[i] {
+					t.Errorf("annotation[%d] = %q, want %q", i, a.CronExpr, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseAnnotations_Options(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]string
+	}{
+		{
+			name:     "no options",
+			content:  `# ghacron: "0 8 * * *"`,
+			expected: nil,
+		},
+		{
+			name:     "single option",
+			content:  `# ghacron: "0 8 * * *" name=nightly-build`,
+			expected: map[string]string{"name": "nightly-build"},
+		},
+		{
+			name:     "multiple options",
+			content:  `# ghacron: "0 8 * * *" name=nightly foo=bar`,
+			expected: map[string]string{"name": "nightly", "foo": "bar"},
+		},
+		{
+			name:     "quoted value",
+			content:  `# ghacron: "0 8 * * *" name="nightly"`,
+			expected: map[string]string{"name": "nightly"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAnnotations(tt.content)
+			if len(got) != 1 {
+				t.Fatalf("got %d annotations, want 1", len(got))
+			}
+			if len(got[0].Options) != len(tt.expected) {
+				t.Fatalf("got %d options, want %d: %v", len(got[0].Options), len(tt.expected), got[0].Options)
+			}
+			for k, v := range tt.expected {
+				if got[0].Options[k] != v {
+					t.Errorf("Options[%q] = %q, want %q", k, got[0].Options[k], v)
 				}
 			}
 		})
 	}
 }
 
+func TestParseAnnotations_Line(t *testing.T) {
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n  # ghacron: \"0 9 * * *\"\n"
+
+	got := ParseAnnotations(content)
+	if len(got) != 2 {
+		t.Fatalf("got %d annotations, want 2", len(got))
+	}
+	if got[0].Line != 2 || got[1].Line != 4 {
+		t.Errorf("lines = %d, %d, want 2, 4", got[0].Line, got[1].Line)
+	}
+}
+
+func TestParseTimezone(t *testing.T) {
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  # ghacron-tz: Asia/Tokyo\n  # ghacron-tz: UTC\n  workflow_dispatch:\n"
+	if zone, line := ParseTimezone(content); zone != "Asia/Tokyo" || line != 3 {
+		t.Errorf("ParseTimezone = %q, %d, want the first directive on line 3", zone, line)
+	}
+	if zone, line := ParseTimezone("# ghacron: \"0 8 * * *\"\n"); zone != "" || line != 0 {
+		t.Errorf("ParseTimezone without directive = %q, %d, want none", zone, line)
+	}
+	if got := ParseAnnotations(content); len(got) != 1 {
+		t.Errorf("got %d annotations, want the directive not to be one", len(got))
+	}
+}
+
 func TestHasWorkflowDispatch(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -104,6 +180,16 @@ func TestHasWorkflowDispatch(t *testing.T) {
 			content:  "on:\n  push:\njobs:\n  workflow_dispatch:\n",
 			expected: false,
 		},
+		{
+			name:     "commented-out workflow_dispatch",
+			content:  "on:\n  push:\n  # workflow_dispatch:\n",
+			expected: false,
+		},
+		{
+			name:     "annotation mentioning workflow_dispatch",
+			content:  "on:\n  # ghacron: \"0 8 * * *\" type=workflow_dispatch\n  push:\n",
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -115,3 +201,64 @@ func TestHasWorkflowDispatch(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiredInputsWithoutDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "no inputs",
+			content:  "on:\n  workflow_dispatch:\n",
+			expected: nil,
+		},
+		{
+			name: "required with default",
+			content: "on:\n  workflow_dispatch:\n    inputs:\n      env:\n" +
+				"        required: true\n        default: staging\n",
+			expected: nil,
+		},
+		{
+			name: "required without default",
+			content: "on:\n  workflow_dispatch:\n    inputs:\n      env:\n" +
+				"        description: target\n        required: true\n",
+			expected: []string{"env"},
+		},
+		{
+			name: "optional without default",
+			content: "on:\n  workflow_dispatch:\n    inputs:\n      env:\n" +
+				"        required: false\n",
+			expected: nil,
+		},
+		{
+			name: "multiple inputs",
+			content: "on:\n  workflow_dispatch:\n    inputs:\n" +
+				"      a:\n        required: true\n" +
+				"      b:\n        required: true\n        default: x\n" +
+				"      c:\n        required: true\n" +
+				"  push:\n",
+			expected: []string{"a", "c"},
+		},
+		{
+			name: "inputs of another trigger ignored",
+			content: "on:\n  workflow_dispatch:\n  workflow_call:\n    inputs:\n" +
+				"      env:\n        required: true\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RequiredInputsWithoutDefault(tt.content)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("got %v, want %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("input[%d] = %q, want %q", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -1,40 +1,148 @@
 package scanner
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/tracing"
 
 	"github.com/robfig/cron/v3"
 )
 
+// jobNameRe restricts job names to characters usable in state variable names.
+var jobNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// hashNameRe matches names whose NameToken would look like the 8 hex digit
+// hash that names the state variables of unnamed jobs.
+var hashNameRe = regexp.MustCompile(`^[0-9A-Fa-f]{8}$`)
+
 // SkippedAnnotation holds info about an annotation that failed validation.
 type SkippedAnnotation struct {
 	Owner        string `json:"owner"`
 	Repo         string `json:"repo"`
 	WorkflowFile string `json:"workflow_file"`
+	Path         string `json:"path"`
+	Line         int    `json:"line"`
 	CronExpr     string `json:"cron_expr"`
-	Reason       string `json:"reason"`
+	// Category classifies Reason (one of the Skip* constants).
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
+}
+
+// Categories of skipped annotations.
+const (
+	SkipInvalidCron     = "invalid_cron"
+	SkipInvalidTimezone = "invalid_timezone"
+	// SkipInvalidTimezoneDirective is reported once per repository, for its
+	// ghacron-tz: directive.
+	SkipInvalidTimezoneDirective = "invalid_timezone_directive"
+	SkipInvalidOption            = "invalid_option"
+	SkipMissingTrigger           = "missing_trigger"
+	SkipRequiredInputs           = "required_inputs"
+	SkipWorkflowDisabled         = "workflow_disabled"
+	SkipDuplicateName            = "duplicate_name"
+	SkipJobLimit                 = "job_limit"
+)
+
+// annotationError is an error of an invalid annotation, with its category.
+type annotationError struct {
+	category string
+	err      error
+}
+
+func (e *annotationError) Error() string { return e.err.Error() }
+
+func (e *annotationError) Unwrap() error { return e.err }
+
+// errUnknownTimezone is the error of an expression whose CRON_TZ= or TZ=
+// prefix names an unknown time zone.
+var errUnknownTimezone = errors.New("unknown time zone")
+
+// skipCategory returns the category of an error of buildAnnotation.
+func skipCategory(err error) string {
+	var ae *annotationError
+	switch {
+	case errors.As(err, &ae):
+		return ae.category
+	case errors.Is(err, errUnknownTimezone):
+		return SkipInvalidTimezone
+	}
+	return SkipInvalidCron
 }
 
 // ScanResult holds the scan results.
 type ScanResult struct {
-	Annotations []github.CronAnnotation
-	Skipped     []SkippedAnnotation
+	Annotations   []github.CronAnnotation
+	Skipped       []SkippedAnnotation
+	ArchivedRepos int // number of archived repositories that were not scanned
+	// BackedOffRepos is the number of repositories not scanned because their
+	// recent scans failed (see SetScanBackoff); they are also in FailedRepos.
+	BackedOffRepos int
+	// ScannedRepos are the repositories scanned successfully, i.e. whose
+	// annotations are complete in this result.
+	ScannedRepos []github.Repository
+	// FailedRepos are the repositories that could not be scanned, including
+	// those left over when a rate limit stopped the scan. Their annotations
+	// are missing from this result.
+	FailedRepos []github.Repository
 }
 
 // ScannerClient is the GitHub API interface used by the scanner.
 type ScannerClient interface {
 	GetInstallationRepos(ctx context.Context) ([]github.Repository, error)
-	GetWorkflowFiles(ctx context.Context, owner, repo string) ([]github.WorkflowFile, error)
-	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	GetWorkflowContents(ctx context.Context, owner, repo, ref string) ([]github.WorkflowFile, error)
+	ListWorkflows(ctx context.Context, owner, repo string) ([]github.Workflow, error)
+	GetHeadSHA(ctx context.Context, owner, repo, ref, lastSHA string) (string, error)
+	GetWorkflowContentsBatch(ctx context.Context, repos []github.Repository) (map[string]github.RepoWorkflows, error)
 }
 
 // Scanner scans repositories for cron annotations.
 type Scanner struct {
 	client     ScannerClient
 	cronParser cron.Parser
+	// batchFetch fetches the workflow files of all repositories with a few
+	// GraphQL queries in ScanAll instead of per-repository REST calls.
+	batchFetch bool
+	// aliases maps schedule alias names to the cron expressions that
+	// annotations like "@nightly" expand to.
+	aliases map[string]string
+	// ownerTimezones maps lower-case owners to the time zone prefixed to
+	// their expressions without one, unless the repository sets one (see
+	// SetOwnerTimezones).
+	ownerTimezones map[string]string
+	// shardIndex and shardTotal select the repositories scanned by this
+	// replica (see SetShard).
+	shardIndex, shardTotal int
+	// topic, if set, is the topic repositories must carry to be scanned
+	// (see SetTopicFilter).
+	topic string
+	// skipForks excludes forked repositories (see SetSkipForks).
+	skipForks bool
+
+	// maxBackoffSkips caps the full scans skipped after repeated failures of
+	// a repository (see SetScanBackoff).
+	maxBackoffSkips int
+
+	mu        sync.Mutex
+	snapshots map[string]repoSnapshot  // "owner/repo" -> workflow files at last scanned head
+	failures  map[string]*repoFailures // "owner/repo" -> consecutive scan failures
+	statuses  map[string]*repoStatus   // "owner/repo" -> last scan
+}
+
+// repoSnapshot holds the workflow files of a repository at a given head SHA.
+type repoSnapshot struct {
+	headSHA string
+	files   []github.WorkflowFile
 }
 
 // New creates a new Scanner.
@@ -42,46 +150,165 @@ func New(client ScannerClient) *Scanner {
 	return &Scanner{
 		client:     client,
 		cronParser: cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow),
+		snapshots:  make(map[string]repoSnapshot),
+		failures:   make(map[string]*repoFailures),
+		statuses:   make(map[string]*repoStatus),
 	}
 }
 
+// SetBatchFetch enables fetching workflow files with batched GraphQL queries
+// during full scans.
+func (s *Scanner) SetBatchFetch(enabled bool) {
+	s.batchFetch = enabled
+}
+
+// SetScheduleAliases sets the schedule aliases annotations may reference as
+// "@<name>".
+func (s *Scanner) SetScheduleAliases(aliases map[string]string) {
+	s.aliases = aliases
+}
+
+// SetOwnerTimezones sets default time zones per repository owner (keys in
+// lower case). Expressions of their annotations without a CRON_TZ= or TZ=
+// prefix, in repositories without a ghacron-tz: directive, get a CRON_TZ=
+// prefix for it.
+func (s *Scanner) SetOwnerTimezones(timezones map[string]string) {
+	s.ownerTimezones = timezones
+}
+
 // ScanAll scans all installation repositories and collects annotations.
 func (s *Scanner) ScanAll(ctx context.Context) (*ScanResult, error) {
-	repos, err := s.client.GetInstallationRepos(ctx)
+	installed, err := s.client.GetInstallationRepos(ctx)
 	if err != nil {
 		return nil, err
 	}
+	repos := s.includedRepos(s.ownedRepos(installed))
 
-	slog.Info("scanning repositories", "repo_count", len(repos))
+	slog.InfoContext(ctx, "scanning repositories", "repo_count", len(repos), "installation_repo_count", len(installed))
 
 	result := &ScanResult{}
+	prefetched := s.prefetchWorkflows(ctx, repos)
 
-	for _, repo := range repos {
-		annotations, skipped, err := s.scanRepo(ctx, repo)
+	for i, repo := range repos {
+		// Dispatching to archived repositories always fails (403).
+		if repo.Archived {
+			slog.DebugContext(ctx, "skipping archived repository", "owner", repo.Owner, "repo", repo.Name)
+			result.ArchivedRepos++
+			continue
+		}
+		if s.backingOff(repo) {
+			slog.DebugContext(ctx, "skipping repository with failing scans", "owner", repo.Owner, "repo", repo.Name)
+			result.BackedOffRepos++
+			result.FailedRepos = append(result.FailedRepos, repo)
+			continue
+		}
+
+		var files *github.RepoWorkflows
+		if workflows, ok := prefetched[repo.Owner+"/"+repo.Name]; ok {
+			files = &workflows
+		}
+		annotations, skipped, err := s.scanRepo(ctx, repo, files)
+		if errors.Is(err, github.ErrRateLimited) {
+			// Every further request would fail too.
+			slog.WarnContext(ctx, "rate limited, stopping scan",
+				"owner", repo.Owner,
+				"repo", repo.Name,
+				"error", err,
+			)
+			for _, rest := range repos[i:] {
+				if !rest.Archived {
+					result.FailedRepos = append(result.FailedRepos, rest)
+				}
+			}
+			break
+		}
 		if err != nil {
-			slog.Error("failed to scan repository",
+			slog.ErrorContext(ctx, "failed to scan repository",
 				"owner", repo.Owner,
 				"repo", repo.Name,
 				"error", err,
 			)
+			s.recordScanFailure(ctx, repo, err)
+			result.FailedRepos = append(result.FailedRepos, repo)
 			continue
 		}
+		s.recordScanSuccess(repo, len(annotations), len(skipped))
 		result.Annotations = append(result.Annotations, annotations...)
 		result.Skipped = append(result.Skipped, skipped...)
+		result.ScannedRepos = append(result.ScannedRepos, repo)
 	}
 
-	slog.Info("scan completed",
+	s.pruneSnapshots(repos)
+
+	slog.InfoContext(ctx, "scan completed",
 		"annotation_count", len(result.Annotations),
 		"skipped_count", len(result.Skipped),
+		"archived_repo_count", result.ArchivedRepos,
+		"backed_off_repo_count", result.BackedOffRepos,
 	)
 	return result, nil
 }
 
-// scanRepo scans workflow files in a single repository.
-func (s *Scanner) scanRepo(ctx context.Context, repo github.Repository) ([]github.CronAnnotation, []SkippedAnnotation, error) {
-	files, err := s.client.GetWorkflowFiles(ctx, repo.Owner, repo.Name)
+// ScanRepo scans a single repository, e.g. after a push to its default branch.
+// Archived repositories and repositories excluded by the fork or topic filter
+// yield no annotations.
+func (s *Scanner) ScanRepo(ctx context.Context, repo github.Repository) (*ScanResult, error) {
+	result := &ScanResult{}
+	if repo.Archived {
+		result.ArchivedRepos = 1
+		return result, nil
+	}
+	if !s.included(repo) {
+		slog.DebugContext(ctx, "repository is filtered out, skipping scan", "owner", repo.Owner, "repo", repo.Name, "fork", repo.Fork, "topic", s.topic)
+		return result, nil
+	}
+
+	// Pushes may fix a failing repository, so backoff does not apply here.
+	annotations, skipped, err := s.scanRepo(ctx, repo, nil)
 	if err != nil {
-		return nil, nil, err
+		if !errors.Is(err, github.ErrRateLimited) {
+			s.recordScanFailure(ctx, repo, err)
+		}
+		return nil, err
+	}
+	s.recordScanSuccess(repo, len(annotations), len(skipped))
+	result.Annotations = annotations
+	result.Skipped = skipped
+	result.ScannedRepos = []github.Repository{repo}
+
+	slog.InfoContext(ctx, "repository scan completed",
+		"owner", repo.Owner,
+		"repo", repo.Name,
+		"annotation_count", len(result.Annotations),
+		"skipped_count", len(result.Skipped),
+	)
+	return result, nil
+}
+
+// scanRepo scans workflow files in a single repository. prefetched holds the
+// files fetched by a batch query, if any.
+func (s *Scanner) scanRepo(ctx context.Context, repo github.Repository, prefetched *github.RepoWorkflows) (_ []github.CronAnnotation, _ []SkippedAnnotation, err error) {
+	ctx, span := tracing.Start(ctx, "scan_repo",
+		tracing.String("ghacron.owner", repo.Owner),
+		tracing.String("ghacron.repo", repo.Name),
+		tracing.Bool("ghacron.prefetched", prefetched != nil),
+	)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	var files []github.WorkflowFile
+	if prefetched != nil {
+		files = prefetched.Files
+		s.mu.Lock()
+		s.snapshots[repo.Owner+"/"+repo.Name] = repoSnapshot{headSHA: prefetched.HeadSHA, files: files}
+		s.mu.Unlock()
+	} else {
+		var err error
+		if files, err = s.workflowFiles(ctx, repo); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	if len(files) == 0 {
@@ -91,70 +318,537 @@ func (s *Scanner) scanRepo(ctx context.Context, repo github.Repository) ([]githu
 	var annotations []github.CronAnnotation
 	var skipped []SkippedAnnotation
 
+	workflows := s.listWorkflows(ctx, repo)
+	directive := findTimezoneDirective(files)
+
 	for _, file := range files {
-		content, err := s.client.GetFileContent(ctx, repo.Owner, repo.Name, file.Path, repo.DefaultBranch)
-		if err != nil {
-			slog.Error("failed to read file",
-				"owner", repo.Owner,
-				"repo", repo.Name,
-				"path", file.Path,
-				"error", err,
-			)
-			continue
+		fileAnnotations, fileSkipped := s.parseFile(repo, file, file.Content, directive.zone)
+		if w, ok := workflows[file.Path]; ok {
+			if w.IsDisabled() {
+				fileSkipped = append(fileSkipped, skipAll(fileAnnotations, SkipWorkflowDisabled, fmt.Sprintf("workflow is disabled (%s)", w.State))...)
+				fileAnnotations = nil
+			}
+			for i := range fileAnnotations {
+				fileAnnotations[i].WorkflowID = w.ID
+			}
 		}
-
-		fileAnnotations, fileSkipped := s.parseFile(repo, file, content)
 		annotations = append(annotations, fileAnnotations...)
 		skipped = append(skipped, fileSkipped...)
 	}
 
+	skipped = directive.checkZone(repo, skipped)
+
+	annotations, dupSkipped := dropDuplicateNames(annotations)
+	skipped = append(skipped, dupSkipped...)
+
+	for _, sk := range skipped {
+		slog.WarnContext(ctx, "skipping annotation",
+			"owner", sk.Owner,
+			"repo", sk.Repo,
+			"workflow_file", sk.WorkflowFile,
+			"line", sk.Line,
+			"cron_expr", sk.CronExpr,
+			"reason", sk.Reason,
+		)
+	}
 	return annotations, skipped, nil
 }
 
-// parseFile parses a workflow file and extracts cron annotations.
-func (s *Scanner) parseFile(repo github.Repository, file github.WorkflowFile, content string) ([]github.CronAnnotation, []SkippedAnnotation) {
-	// Check if workflow_dispatch is in the on: trigger
-	if !HasWorkflowDispatch(content) {
+// workflowFiles returns the workflow files of a repository's default branch.
+// Contents are only re-fetched when the branch head moved since the last scan;
+// the head check itself is a conditional request that is free when unchanged.
+func (s *Scanner) workflowFiles(ctx context.Context, repo github.Repository) ([]github.WorkflowFile, error) {
+	key := repo.Owner + "/" + repo.Name
+
+	s.mu.Lock()
+	snapshot, hasSnapshot := s.snapshots[key]
+	s.mu.Unlock()
+
+	headSHA, err := s.client.GetHeadSHA(ctx, repo.Owner, repo.Name, repo.DefaultBranch, snapshot.headSHA)
+	if err != nil {
+		// Fall back to a full fetch without recording a snapshot.
+		slog.WarnContext(ctx, "failed to get head SHA, fetching workflows",
+			"owner", repo.Owner,
+			"repo", repo.Name,
+			"error", err,
+		)
+		return s.client.GetWorkflowContents(ctx, repo.Owner, repo.Name, repo.DefaultBranch)
+	}
+	if hasSnapshot && headSHA == snapshot.headSHA {
+		slog.DebugContext(ctx, "head unchanged, reusing workflow files",
+			"owner", repo.Owner,
+			"repo", repo.Name,
+			"head_sha", headSHA,
+		)
+		return snapshot.files, nil
+	}
+
+	files, err := s.client.GetWorkflowContents(ctx, repo.Owner, repo.Name, repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.snapshots[key] = repoSnapshot{headSHA: headSHA, files: files}
+	s.mu.Unlock()
+
+	return files, nil
+}
+
+// prefetchWorkflows fetches the workflow files of all unarchived repositories
+// with batched queries when enabled. Repositories missing from the result are
+// fetched individually; a failed batch fetch falls back to that for all.
+func (s *Scanner) prefetchWorkflows(ctx context.Context, repos []github.Repository) map[string]github.RepoWorkflows {
+	if !s.batchFetch {
+		return nil
+	}
+	active := make([]github.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if !repo.Archived {
+			active = append(active, repo)
+		}
+	}
+	if len(active) == 0 {
+		return nil
+	}
+
+	prefetched, err := s.client.GetWorkflowContentsBatch(ctx, active)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to batch fetch workflow files, fetching per repository", "error", err)
+		return nil
+	}
+	slog.DebugContext(ctx, "batch fetched workflow files",
+		"repo_count", len(active),
+		"fetched_count", len(prefetched),
+	)
+	return prefetched
+}
+
+// pruneSnapshots drops snapshots, scan failures and scan statuses of
+// repositories no longer in the installation.
+func (s *Scanner) pruneSnapshots(repos []github.Repository) {
+	current := make(map[string]struct{}, len(repos))
+	for _, repo := range repos {
+		current[repo.Owner+"/"+repo.Name] = struct{}{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.snapshots {
+		if _, ok := current[key]; !ok {
+			delete(s.snapshots, key)
+		}
+	}
+	for key := range s.failures {
+		if _, ok := current[key]; !ok {
+			delete(s.failures, key)
+		}
+	}
+	for key := range s.statuses {
+		if _, ok := current[key]; !ok {
+			delete(s.statuses, key)
+		}
+	}
+}
+
+// listWorkflows returns the Actions workflows of a repository keyed by path.
+// On failure it fails open: all workflows are treated as enabled and their
+// IDs are left unresolved.
+func (s *Scanner) listWorkflows(ctx context.Context, repo github.Repository) map[string]github.Workflow {
+	workflows, err := s.client.ListWorkflows(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list workflow states, assuming all enabled",
+			"owner", repo.Owner,
+			"repo", repo.Name,
+			"error", err,
+		)
+		return nil
+	}
+
+	byPath := make(map[string]github.Workflow, len(workflows))
+	for _, w := range workflows {
+		byPath[w.Path] = w
+	}
+	return byPath
+}
+
+// skipAll converts annotations into skipped entries sharing the same reason.
+func skipAll(annotations []github.CronAnnotation, category, reason string) []SkippedAnnotation {
+	skipped := make([]SkippedAnnotation, 0, len(annotations))
+	for _, a := range annotations {
+		skipped = append(skipped, newSkipped(a, category, reason))
+	}
+	return skipped
+}
+
+// newSkipped builds a skipped entry for an annotation.
+func newSkipped(a github.CronAnnotation, category, reason string) SkippedAnnotation {
+	return SkippedAnnotation{
+		Owner:        a.Owner,
+		Repo:         a.Repo,
+		WorkflowFile: a.WorkflowFile,
+		Path:         a.Path,
+		Line:         a.Line,
+		CronExpr:     a.CronExpr,
+		Category:     category,
+		Reason:       reason,
+	}
+}
+
+// timezoneDirective is the ghacron-tz: directive of a repository.
+type timezoneDirective struct {
+	zone string
+	file github.WorkflowFile
+	line int
+}
+
+// findTimezoneDirective returns the first ghacron-tz: directive of a
+// repository's workflow files in path order, which applies to all of them
+// (an empty zone if there is none).
+func findTimezoneDirective(files []github.WorkflowFile) timezoneDirective {
+	sorted := slices.SortedFunc(slices.Values(files), func(a, b github.WorkflowFile) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+	for _, file := range sorted {
+		if zone, line := ParseTimezone(file.Content); zone != "" {
+			return timezoneDirective{zone: zone, file: file, line: line}
+		}
+	}
+	return timezoneDirective{}
+}
+
+// checkZone reports an unknown directive zone: the skipped entries of the
+// annotations depending on it are replaced with a single entry for the
+// directive.
+func (d timezoneDirective) checkZone(repo github.Repository, skipped []SkippedAnnotation) []SkippedAnnotation {
+	if d.zone == "" {
+		return skipped
+	}
+	_, err := time.LoadLocation(d.zone)
+	if err == nil {
+		return skipped
+	}
+	affected := 0
+	kept := skipped[:0]
+	for _, sk := range skipped {
+		if sk.Category == SkipInvalidTimezoneDirective {
+			affected++
+			continue
+		}
+		kept = append(kept, sk)
+	}
+	return append(kept, SkippedAnnotation{
+		Owner:        repo.Owner,
+		Repo:         repo.Name,
+		WorkflowFile: d.file.Name,
+		Path:         d.file.Path,
+		Line:         d.line,
+		Category:     SkipInvalidTimezoneDirective,
+		Reason:       fmt.Sprintf("invalid ghacron-tz directive: %v; %d annotations without CRON_TZ= are not registered", err, affected),
+	})
+}
+
+// parseFile parses a workflow file and extracts cron annotations. timezone
+// is the zone of the repository's ghacron-tz: directive ("" = none).
+func (s *Scanner) parseFile(repo github.Repository, file github.WorkflowFile, content, timezone string) ([]github.CronAnnotation, []SkippedAnnotation) {
+	// Files triggered by neither dispatch event are not ghacron targets.
+	hasWorkflowDispatch := HasWorkflowDispatch(content)
+	hasRepositoryDispatch := HasTrigger(content, github.DispatchTypeRepository)
+	if !hasWorkflowDispatch && !hasRepositoryDispatch {
 		return nil, nil
 	}
 
 	// Extract annotations
-	cronExprs := ParseAnnotations(content)
-	if len(cronExprs) == 0 {
+	parsed := ParseAnnotations(content)
+	if len(parsed) == 0 {
 		return nil, nil
 	}
 
 	var annotations []github.CronAnnotation
 	var skipped []SkippedAnnotation
 
-	for _, expr := range cronExprs {
-		// Validate cron expression
-		if _, err := s.cronParser.Parse(expr); err != nil {
-			slog.Warn("skipping invalid cron expression",
-				"owner", repo.Owner,
-				"repo", repo.Name,
-				"workflow_file", file.Name,
-				"cron_expr", expr,
-				"error", err,
-			)
-			skipped = append(skipped, SkippedAnnotation{
-				Owner:        repo.Owner,
-				Repo:         repo.Name,
-				WorkflowFile: file.Name,
-				CronExpr:     expr,
-				Reason:       err.Error(),
-			})
+	// Required inputs without defaults make every workflow_dispatch fail.
+	missingInputs := RequiredInputsWithoutDefault(content)
+
+	for _, p := range parsed {
+		annotation, err := s.buildAnnotation(repo, file, p, timezone)
+		if err != nil {
+			skipped = append(skipped, newSkipped(sourceAnnotation(repo, file, p), skipCategory(err), err.Error()))
 			continue
 		}
-
-		annotations = append(annotations, github.CronAnnotation{
-			Owner:        repo.Owner,
-			Repo:         repo.Name,
-			WorkflowFile: file.Name,
-			CronExpr:     expr,
-			Ref:          repo.DefaultBranch,
-		})
+		var category, reason string
+		switch {
+		case annotation.IsRepositoryDispatch() && !hasRepositoryDispatch:
+			category, reason = SkipMissingTrigger, "repository_dispatch is not in the on: section"
+		case annotation.IsRepositoryDispatch():
+			// OK
+		case !hasWorkflowDispatch:
+			category, reason = SkipMissingTrigger, "workflow_dispatch is not in the on: section"
+		default:
+			if missing := unsetInputs(missingInputs, annotation.Inputs); len(missing) > 0 {
+				category = SkipRequiredInputs
+				reason = fmt.Sprintf("workflow_dispatch has required inputs without defaults: %s", strings.Join(missing, ", "))
+			}
+		}
+		if reason != "" {
+			skipped = append(skipped, newSkipped(annotation, category, reason))
+			continue
+		}
+		annotations = append(annotations, annotation)
 	}
 
 	return annotations, skipped
 }
+
+// unsetInputs returns the required inputs that an annotation's inputs= option
+// does not set.
+func unsetInputs(required []string, inputs string) []string {
+	if len(required) == 0 || inputs == "" {
+		return required
+	}
+	set, _ := github.ParseInputs(inputs) // validated by applyOption
+	var missing []string
+	for _, name := range required {
+		if !slices.Contains(set, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// sourceAnnotation returns a CronAnnotation with the identity and source
+// location of a parsed annotation, before options are applied.
+func sourceAnnotation(repo github.Repository, file github.WorkflowFile, p Annotation) github.CronAnnotation {
+	return github.CronAnnotation{
+		Owner:        repo.Owner,
+		Repo:         repo.Name,
+		WorkflowFile: file.Name,
+		CronExpr:     p.CronExpr,
+		Ref:          repo.DefaultBranch,
+		Path:         file.Path,
+		Line:         p.Line,
+	}
+}
+
+// buildAnnotation validates a parsed annotation and converts it into a
+// CronAnnotation, applying the repository's directive time zone.
+func (s *Scanner) buildAnnotation(repo github.Repository, file github.WorkflowFile, p Annotation, timezone string) (github.CronAnnotation, error) {
+	annotation := sourceAnnotation(repo, file, p)
+
+	if alias, ok := strings.CutPrefix(p.CronExpr, "@"); ok {
+		expr, found := s.aliases[alias]
+		if !found {
+			return github.CronAnnotation{}, fmt.Errorf("unknown schedule alias %q", p.CronExpr)
+		}
+		annotation.CronExpr = expr
+		annotation.Alias = alias
+	}
+
+	for key, value := range p.Options {
+		if err := applyOption(&annotation, key, value); err != nil {
+			return github.CronAnnotation{}, &annotationError{SkipInvalidOption, err}
+		}
+	}
+
+	// H tokens are seeded with the name, which is an option.
+	expr, err := ExpandHash(annotation.CronExpr, annotation.Owner, annotation.Repo, annotation.WorkflowFile, annotation.Name)
+	if err != nil {
+		return github.CronAnnotation{}, err
+	}
+	if expr != annotation.CronExpr {
+		annotation.HashExpr, annotation.CronExpr = annotation.CronExpr, expr
+	}
+	usesDirective := timezone != "" && !hasTimezone(annotation.CronExpr)
+	annotation.CronExpr = s.withDefaultTimezone(annotation.Owner, timezone, annotation.CronExpr)
+
+	// Validate cron expression
+	if err := checkTimezone(annotation.CronExpr); err != nil {
+		if usesDirective {
+			return github.CronAnnotation{}, &annotationError{SkipInvalidTimezoneDirective, err}
+		}
+		return github.CronAnnotation{}, err
+	}
+	if _, err := s.cronParser.Parse(annotation.CronExpr); err != nil {
+		return github.CronAnnotation{}, err
+	}
+	if err := validateDispatchType(annotation); err != nil {
+		return github.CronAnnotation{}, &annotationError{SkipInvalidOption, err}
+	}
+
+	return annotation, nil
+}
+
+// withDefaultTimezone prefixes an expression without a time zone with the
+// time zone of its repository's ghacron-tz: directive, else the default time
+// zone of its owner, if any.
+func (s *Scanner) withDefaultTimezone(owner, repoTimezone, expr string) string {
+	if hasTimezone(expr) {
+		return expr
+	}
+	tz := cmp.Or(repoTimezone, s.ownerTimezones[strings.ToLower(owner)])
+	if tz == "" {
+		return expr
+	}
+	return "CRON_TZ=" + tz + " " + expr
+}
+
+// hasTimezone reports whether an expression has a CRON_TZ= or TZ= prefix.
+func hasTimezone(expr string) bool {
+	return strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=")
+}
+
+// checkTimezone checks the time zone of an expression's CRON_TZ= or TZ=
+// prefix, if any, like the cron parser does, so that unknown time zones are
+// told apart from other parse errors.
+func checkTimezone(expr string) error {
+	if !hasTimezone(expr) {
+		return nil
+	}
+	prefix, _, _ := strings.Cut(expr, " ")
+	_, zone, _ := strings.Cut(prefix, "=")
+	if _, err := time.LoadLocation(zone); err != nil {
+		return fmt.Errorf("%w %q", errUnknownTimezone, zone)
+	}
+	return nil
+}
+
+// validateDispatchType checks that the repository_dispatch options are used
+// together, and only with options that apply to repository_dispatch.
+func validateDispatchType(annotation github.CronAnnotation) error {
+	if !annotation.IsRepositoryDispatch() {
+		if annotation.EventType != "" || annotation.Payload != "" {
+			return fmt.Errorf("event and payload require type=%s", github.DispatchTypeRepository)
+		}
+		return nil
+	}
+	switch {
+	case annotation.Inputs != "":
+		return fmt.Errorf("inputs is not supported with type=%s (use payload)", github.DispatchTypeRepository)
+	case annotation.EventType == "":
+		return fmt.Errorf("type=%s requires event", github.DispatchTypeRepository)
+	case annotation.RefPattern != "":
+		return fmt.Errorf("refs is not supported with type=%s (it always runs on the default branch)", github.DispatchTypeRepository)
+	case annotation.Overlap == "skip":
+		return fmt.Errorf("overlap=skip is not supported with type=%s", github.DispatchTypeRepository)
+	}
+	return nil
+}
+
+// applyOption validates a single key=value annotation option and applies it.
+func applyOption(annotation *github.CronAnnotation, key, value string) error {
+	set, ok := optionSetters[key]
+	if !ok {
+		return fmt.Errorf("unknown option %q", key)
+	}
+	return set(annotation, value)
+}
+
+// optionSetters validate the value of each annotation option and apply it.
+var optionSetters = map[string]func(annotation *github.CronAnnotation, value string) error{
+	"name": func(annotation *github.CronAnnotation, value string) error {
+		if !jobNameRe.MatchString(value) {
+			return fmt.Errorf("invalid name %q: must match %s", value, jobNameRe.String())
+		}
+		if hashNameRe.MatchString(value) {
+			return fmt.Errorf("invalid name %q: 8 hex digits are reserved for the state variables of unnamed jobs", value)
+		}
+		annotation.Name = value
+		return nil
+	},
+	"refs": func(annotation *github.CronAnnotation, value string) error {
+		if _, err := path.Match(value, ""); err != nil {
+			return fmt.Errorf("invalid refs pattern %q: %w", value, err)
+		}
+		annotation.RefPattern = value
+		return nil
+	},
+	"jitter": func(annotation *github.CronAnnotation, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid jitter %q: must be a positive duration (e.g. 300s)", value)
+		}
+		annotation.Jitter = d
+		return nil
+	},
+	"timeout": func(annotation *github.CronAnnotation, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q: must be a positive duration (e.g. 60s)", value)
+		}
+		annotation.Timeout = d
+		return nil
+	},
+	"overlap": func(annotation *github.CronAnnotation, value string) error {
+		if value != "allow" && value != "skip" {
+			return fmt.Errorf("invalid overlap %q: must be one of allow, skip", value)
+		}
+		annotation.Overlap = value
+		return nil
+	},
+	"type": func(annotation *github.CronAnnotation, value string) error {
+		if value != github.DispatchTypeWorkflow && value != github.DispatchTypeRepository {
+			return fmt.Errorf("invalid type %q: must be one of %s, %s", value, github.DispatchTypeWorkflow, github.DispatchTypeRepository)
+		}
+		if value == github.DispatchTypeRepository {
+			annotation.DispatchType = value
+		}
+		return nil
+	},
+	"event": func(annotation *github.CronAnnotation, value string) error {
+		// GitHub limits event_type to 100 characters.
+		if value == "" || len(value) > 100 {
+			return fmt.Errorf("invalid event %q: must be 1 to 100 characters", value)
+		}
+		annotation.EventType = value
+		return nil
+	},
+	"payload": func(annotation *github.CronAnnotation, value string) error {
+		if err := github.ParsePayload(value); err != nil {
+			return fmt.Errorf("invalid payload %q: %w", value, err)
+		}
+		annotation.Payload = value
+		return nil
+	},
+	"inputs": func(annotation *github.CronAnnotation, value string) error {
+		if _, err := github.ParseInputs(value); err != nil {
+			return fmt.Errorf("invalid inputs %q: %w", value, err)
+		}
+		annotation.Inputs = value
+		return nil
+	},
+	"priority": func(annotation *github.CronAnnotation, value string) error {
+		if value != github.PriorityHigh && value != github.PriorityNormal && value != github.PriorityLow {
+			return fmt.Errorf("invalid priority %q: must be one of %s, %s, %s", value, github.PriorityHigh, github.PriorityNormal, github.PriorityLow)
+		}
+		if value != github.PriorityNormal {
+			annotation.Priority = value
+		}
+		return nil
+	},
+}
+
+// dropDuplicateNames skips named annotations whose name is already used by
+// another annotation in the same repository. Names compare by NameToken so
+// they map to distinct state variables.
+func dropDuplicateNames(annotations []github.CronAnnotation) ([]github.CronAnnotation, []SkippedAnnotation) {
+	seen := make(map[string]string) // name token -> workflow file
+	var kept []github.CronAnnotation
+	var skipped []SkippedAnnotation
+
+	for _, a := range annotations {
+		token := a.NameToken()
+		if token == "" {
+			kept = append(kept, a)
+			continue
+		}
+		if firstFile, exists := seen[token]; exists {
+			reason := fmt.Sprintf("duplicate name %q (already used in %s)", a.Name, firstFile)
+			skipped = append(skipped, newSkipped(a, SkipDuplicateName, reason))
+			continue
+		}
+		seen[token] = a.WorkflowFile
+		kept = append(kept, a)
+	}
+
+	return kept, skipped
+}
@@ -1,11 +1,74 @@
 package scanner
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/korosuke613/ghacron/github"
 )
 
+// mockScannerClient serves fixed repositories and file contents.
+type mockScannerClient struct {
+	repos []github.Repository
+	files map[string][]github.WorkflowFile // "owner/repo" -> files
+	// contents maps "owner/repo/path" to file content.
+	contents  map[string]string
+	workflows map[string][]github.Workflow // "owner/repo" -> workflows
+	heads     map[string]string            // "owner/repo" -> head SHA
+	batched   map[string]bool              // "owner/repo" returned by GetWorkflowContentsBatch
+	errs      map[string]error             // "owner/repo" -> GetWorkflowContents error
+
+	contentCalls int
+	batchCalls   int
+}
+
+func (m *mockScannerClient) GetHeadSHA(_ context.Context, owner, repo, _, _ string) (string, error) {
+	return m.heads[owner+"/"+repo], nil
+}
+
+func (m *mockScannerClient) ListWorkflows(_ context.Context, owner, repo string) ([]github.Workflow, error) {
+	return m.workflows[owner+"/"+repo], nil
+}
+
+func (m *mockScannerClient) GetInstallationRepos(_ context.Context) ([]github.Repository, error) {
+	return m.repos, nil
+}
+
+func (m *mockScannerClient) GetWorkflowContents(_ context.Context, owner, repo, _ string) ([]github.WorkflowFile, error) {
+	m.contentCalls++
+	if err := m.errs[owner+"/"+repo]; err != nil {
+		return nil, err
+	}
+	var files []github.WorkflowFile
+	for _, f := range m.files[owner+"/"+repo] {
+		f.Content = m.contents[owner+"/"+repo+"/"+f.Path]
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (m *mockScannerClient) GetWorkflowContentsBatch(ctx context.Context, repos []github.Repository) (map[string]github.RepoWorkflows, error) {
+	m.batchCalls++
+	result := make(map[string]github.RepoWorkflows)
+	for _, repo := range repos {
+		key := repo.Owner + "/" + repo.Name
+		if !m.batched[key] {
+			continue
+		}
+		var files []github.WorkflowFile
+		for _, f := range m.files[key] {
+			f.Content = m.contents[key+"/"+f.Path]
+			files = append(files, f)
+		}
+		result[key] = github.RepoWorkflows{HeadSHA: m.heads[key], Files: files}
+	}
+	return result, nil
+}
+
 func TestParseFile_StandardCron(t *testing.T) {
 	s := New(nil)
 	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
@@ -13,7 +76,7 @@ func TestParseFile_StandardCron(t *testing.T) {
 
 	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n"
 
-	annotations, skipped := s.parseFile(repo, file, content)
+	annotations, skipped := s.parseFile(repo, file, content, "")
 	if len(annotations) != 1 {
 		t.Fatalf("expected 1 annotation, got %d", len(annotations))
 	}
@@ -32,7 +95,7 @@ func TestParseFile_CronTZ(t *testing.T) {
 
 	content := "on:\n  # ghacron: \"CRON_TZ=Asia/Tokyo 0 8 * * *\"\n  workflow_dispatch:\n"
 
-	annotations, skipped := s.parseFile(repo, file, content)
+	annotations, skipped := s.parseFile(repo, file, content, "")
 	if len(annotations) != 1 {
 		t.Fatalf("expected 1 annotation, got %d", len(annotations))
 	}
@@ -51,7 +114,7 @@ func TestParseFile_TZPrefix(t *testing.T) {
 
 	content := "on:\n  # ghacron: \"TZ=UTC 30 6 * * 1-5\"\n  workflow_dispatch:\n"
 
-	annotations, skipped := s.parseFile(repo, file, content)
+	annotations, skipped := s.parseFile(repo, file, content, "")
 	if len(annotations) != 1 {
 		t.Fatalf("expected 1 annotation, got %d", len(annotations))
 	}
@@ -70,7 +133,7 @@ func TestParseFile_InvalidTZ(t *testing.T) {
 
 	content := "on:\n  # ghacron: \"CRON_TZ=Invalid/Zone 0 8 * * *\"\n  workflow_dispatch:\n"
 
-	annotations, skipped := s.parseFile(repo, file, content)
+	annotations, skipped := s.parseFile(repo, file, content, "")
 	if len(annotations) != 0 {
 		t.Fatalf("expected 0 annotations for invalid TZ, got %d", len(annotations))
 	}
@@ -83,6 +146,165 @@ func TestParseFile_InvalidTZ(t *testing.T) {
 	if skipped[0].Reason == "" {
 		t.Error("skipped Reason should not be empty")
 	}
+	if skipped[0].Category != SkipInvalidTimezone {
+		t.Errorf("skipped Category = %q, want %q", skipped[0].Category, SkipInvalidTimezone)
+	}
+}
+
+func TestSkipCategory(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want string
+	}{
+		"invalid cron":     {fmt.Errorf("expected 5 fields"), SkipInvalidCron},
+		"unknown timezone": {checkTimezone("CRON_TZ=Asia/Tokio 0 8 * * *"), SkipInvalidTimezone},
+		"wrapped unknown timezone": {
+			fmt.Errorf("line 3: %w", checkTimezone("TZ=Asia/Tokio 0 8 * * *")),
+			SkipInvalidTimezone,
+		},
+		"wrapped annotation error": {
+			fmt.Errorf("line 3: %w", &annotationError{SkipInvalidOption, fmt.Errorf("unknown option")}),
+			SkipInvalidOption,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := skipCategory(tt.err); got != tt.want {
+				t.Errorf("skipCategory(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+	if err := checkTimezone("CRON_TZ=Asia/Tokyo 0 8 * * *"); err != nil {
+		t.Errorf("checkTimezone of a known time zone = %v", err)
+	}
+}
+
+func TestParseFile_ScheduleAlias(t *testing.T) {
+	s := New(nil)
+	s.SetScheduleAliases(map[string]string{"nightly": "CRON_TZ=Asia/Tokyo 0 2 * * *"})
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n  # ghacron: \"@nightly\"\n  # ghacron: \"@weekly\"\n  workflow_dispatch:\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(annotations))
+	}
+	if annotations[0].CronExpr != "CRON_TZ=Asia/Tokyo 0 2 * * *" || annotations[0].Alias != "nightly" {
+		t.Errorf("CronExpr = %q, Alias = %q; want the expanded nightly alias", annotations[0].CronExpr, annotations[0].Alias)
+	}
+	if len(skipped) != 1 || skipped[0].CronExpr != "@weekly" || !strings.Contains(skipped[0].Reason, "unknown schedule alias") || skipped[0].Category != SkipInvalidCron {
+		t.Errorf("skipped = %+v, want @weekly as an unknown alias", skipped)
+	}
+}
+
+func TestParseFile_OwnerTimezone(t *testing.T) {
+	s := New(nil)
+	s.SetOwnerTimezones(map[string]string{"acme-jp": "Asia/Tokyo"})
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	content := "on:\n" +
+		"  # ghacron: \"0 8 * * *\"\n" +
+		"  # ghacron: \"TZ=UTC 0 9 * * *\"\n" +
+		"  workflow_dispatch:\n"
+
+	tests := []struct {
+		owner string
+		want  []string
+	}{
+		{"Acme-JP", []string{"CRON_TZ=Asia/Tokyo 0 8 * * *", "TZ=UTC 0 9 * * *"}},
+		{"other", []string{"0 8 * * *", "TZ=UTC 0 9 * * *"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			repo := github.Repository{Owner: tt.owner, Name: "repo", DefaultBranch: "main"}
+			annotations, _ := s.parseFile(repo, file, content, "")
+			var got []string
+			for _, a := range annotations {
+				got = append(got, a.CronExpr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expressions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFile_RepoTimezone(t *testing.T) {
+	s := New(nil)
+	s.SetOwnerTimezones(map[string]string{"acme-jp": "Asia/Tokyo"})
+	repo := github.Repository{Owner: "acme-jp", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	// The repository's time zone takes precedence over the owner's, and an
+	// expression's own prefix over both.
+	content := "on:\n" +
+		"  # ghacron: \"0 8 * * *\"\n" +
+		"  # ghacron: \"CRON_TZ=UTC 0 9 * * *\"\n" +
+		"  workflow_dispatch:\n"
+	annotations, skipped := s.parseFile(repo, file, content, "Europe/Berlin")
+	if len(annotations) != 2 || len(skipped) != 0 {
+		t.Fatalf("got %d annotations, %d skipped; want 2, 0", len(annotations), len(skipped))
+	}
+	if annotations[0].CronExpr != "CRON_TZ=Europe/Berlin 0 8 * * *" || annotations[1].CronExpr != "CRON_TZ=UTC 0 9 * * *" {
+		t.Errorf("expressions = %q, %q", annotations[0].CronExpr, annotations[1].CronExpr)
+	}
+}
+
+func TestScanRepo_TimezoneDirective(t *testing.T) {
+	files := []github.WorkflowFile{
+		{Name: "b.yml", Path: ".github/workflows/b.yml"},
+		{Name: "a.yml", Path: ".github/workflows/a.yml"},
+	}
+	scan := func(t *testing.T, a, b string) *ScanResult {
+		t.Helper()
+		client := &mockScannerClient{
+			files: map[string][]github.WorkflowFile{"o/r": files},
+			contents: map[string]string{
+				"o/r/.github/workflows/a.yml": a,
+				"o/r/.github/workflows/b.yml": b,
+			},
+		}
+		result, err := New(client).ScanRepo(context.Background(), github.Repository{Owner: "o", Name: "r", DefaultBranch: "main"})
+		if err != nil {
+			t.Fatalf("ScanRepo: %v", err)
+		}
+		return result
+	}
+
+	// The first directive in path order applies to every workflow file.
+	result := scan(t,
+		"# ghacron-tz: Asia/Tokyo\non:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n",
+		"# ghacron-tz: UTC\non:\n  # ghacron: \"0 9 * * *\"\n  workflow_dispatch:\n",
+	)
+	if len(result.Annotations) != 2 || len(result.Skipped) != 0 {
+		t.Fatalf("annotations/skipped = %d/%d, want 2/0", len(result.Annotations), len(result.Skipped))
+	}
+	for _, a := range result.Annotations {
+		if !strings.HasPrefix(a.CronExpr, "CRON_TZ=Asia/Tokyo ") {
+			t.Errorf("%s: expression = %q, want the Asia/Tokyo directive", a.WorkflowFile, a.CronExpr)
+		}
+	}
+
+	// An unknown zone is reported once, at the directive, and the
+	// expressions depending on it are not registered.
+	result = scan(t,
+		"on:\n  # ghacron: \"0 8 * * *\"\n  # ghacron: \"CRON_TZ=UTC 0 9 * * *\"\n  workflow_dispatch:\n",
+		"# ghacron-tz: Asia/Tokio\non:\n  # ghacron: \"0 10 * * *\"\n  workflow_dispatch:\n",
+	)
+	if len(result.Annotations) != 1 || result.Annotations[0].CronExpr != "CRON_TZ=UTC 0 9 * * *" {
+		t.Errorf("annotations = %+v, want only the prefixed expression", result.Annotations)
+	}
+	if len(result.Skipped) != 1 {
+		t.Fatalf("skipped = %+v, want one entry for the directive", result.Skipped)
+	}
+	sk := result.Skipped[0]
+	if sk.Category != SkipInvalidTimezoneDirective || sk.Path != ".github/workflows/b.yml" || sk.Line != 1 {
+		t.Errorf("skipped = %+v, want the directive at b.yml:1", sk)
+	}
+	if !strings.Contains(sk.Reason, "2 annotations") {
+		t.Errorf("reason = %q, want the number of affected annotations", sk.Reason)
+	}
 }
 
 func TestParseFile_NoWorkflowDispatch(t *testing.T) {
@@ -92,7 +314,7 @@ func TestParseFile_NoWorkflowDispatch(t *testing.T) {
 
 	content := "on:\n  # ghacron: \"0 8 * * *\"\n  push:\n"
 
-	annotations, skipped := s.parseFile(repo, file, content)
+	annotations, skipped := s.parseFile(repo, file, content, "")
 	if len(annotations) != 0 {
 		t.Fatalf("expected 0 annotations without workflow_dispatch, got %d", len(annotations))
 	}
@@ -108,7 +330,7 @@ func TestParseFile_AnnotationFields(t *testing.T) {
 
 	content := "on:\n  # ghacron: \"CRON_TZ=Asia/Tokyo 0 9 * * 1\"\n  workflow_dispatch:\n"
 
-	annotations, _ := s.parseFile(repo, file, content)
+	annotations, _ := s.parseFile(repo, file, content, "")
 	if len(annotations) != 1 {
 		t.Fatalf("expected 1 annotation, got %d", len(annotations))
 	}
@@ -135,7 +357,7 @@ func TestParseFile_SkippedFields(t *testing.T) {
 
 	content := "on:\n  # ghacron: \"CRON_TZ=Asis/Tokyo 0 8 * * *\"\n  workflow_dispatch:\n"
 
-	_, skipped := s.parseFile(repo, file, content)
+	_, skipped := s.parseFile(repo, file, content, "")
 	if len(skipped) != 1 {
 		t.Fatalf("expected 1 skipped, got %d", len(skipped))
 	}
@@ -152,4 +374,440 @@ func TestParseFile_SkippedFields(t *testing.T) {
 	if sk.CronExpr != "CRON_TZ=Asis/Tokyo 0 8 * * *" {
 		t.Errorf("CronExpr = %q, want %q", sk.CronExpr, "CRON_TZ=Asis/Tokyo 0 8 * * *")
 	}
+	if sk.Path != ".github/workflows/bad.yml" {
+		t.Errorf("Path = %q, want %q", sk.Path, ".github/workflows/bad.yml")
+	}
+	if sk.Line != 2 {
+		t.Errorf("Line = %d, want 2", sk.Line)
+	}
+}
+
+func TestParseFile_Name(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n  # ghacron: \"0 8 * * *\" name=nightly-build\n  workflow_dispatch:\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(annotations))
+	}
+	if annotations[0].Name != "nightly-build" {
+		t.Errorf("Name = %q, want %q", annotations[0].Name, "nightly-build")
+	}
+	if len(skipped) != 0 {
+		t.Errorf("expected 0 skipped, got %d", len(skipped))
+	}
+}
+
+func TestParseFile_Priority(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n" +
+		"  # ghacron: \"0 8 * * *\" priority=high\n" +
+		"  # ghacron: \"0 9 * * *\" priority=normal\n" +
+		"  workflow_dispatch:\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 2 || len(skipped) != 0 {
+		t.Fatalf("got %d annotations, %d skipped; want 2, 0", len(annotations), len(skipped))
+	}
+	// priority=normal is the default and leaves the job config unchanged.
+	if annotations[0].Priority != github.PriorityHigh || annotations[1].Priority != "" {
+		t.Errorf("priorities = %q, %q; want %q, \"\"", annotations[0].Priority, annotations[1].Priority, github.PriorityHigh)
+	}
+}
+
+func TestParseFile_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+	}{
+		{"invalid name", `# ghacron: "0 8 * * *" name=-bad`},
+		{"hash-like name", `# ghacron: "0 8 * * *" name=deadBEEF`},
+		{"unknown option", `# ghacron: "0 8 * * *" color=blue`},
+		{"invalid refs pattern", `# ghacron: "0 8 * * *" refs=release/[`},
+		{"invalid jitter", `# ghacron: "0 8 * * *" jitter=soon`},
+		{"negative jitter", `# ghacron: "0 8 * * *" jitter=-5s`},
+		{"invalid overlap", `# ghacron: "0 8 * * *" overlap=queue`},
+		{"invalid priority", `# ghacron: "0 8 * * *" priority=urgent`},
+		{"zero timeout", `# ghacron: "0 8 * * *" timeout=0s`},
+		{"invalid type", `# ghacron: "0 8 * * *" type=push`},
+		{"event without type", `# ghacron: "0 8 * * *" event=nightly`},
+		{"repository_dispatch without event", `# ghacron: "0 8 * * *" type=repository_dispatch`},
+		{"payload not an object", `# ghacron: "0 8 * * *" type=repository_dispatch event=nightly payload='[1]'`},
+		{"payload with unknown placeholder", `# ghacron: "0 8 * * *" type=repository_dispatch event=nightly payload='{"d":"{{ .Date }}"}'`},
+		{"inputs not an object", `# ghacron: "0 8 * * *" inputs='"prod"'`},
+		{"inputs with unknown placeholder", `# ghacron: "0 8 * * *" inputs='{"d":"{{ .Date }}"}'`},
+		{"repository_dispatch with inputs", `# ghacron: "0 8 * * *" type=repository_dispatch event=nightly inputs='{"env":"prod"}'`},
+		{"repository_dispatch with refs", `# ghacron: "0 8 * * *" type=repository_dispatch event=nightly refs=release/*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(nil)
+			repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+			file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+			content := "on:\n  " + tt.annotation + "\n  workflow_dispatch:\n"
+
+			annotations, skipped := s.parseFile(repo, file, content, "")
+			if len(annotations) != 0 {
+				t.Fatalf("expected 0 annotations, got %d", len(annotations))
+			}
+			if len(skipped) != 1 {
+				t.Fatalf("expected 1 skipped, got %d", len(skipped))
+			}
+			if skipped[0].Reason == "" {
+				t.Error("skipped Reason should not be empty")
+			}
+			if skipped[0].Category != SkipInvalidOption {
+				t.Errorf("skipped Category = %q, want %q", skipped[0].Category, SkipInvalidOption)
+			}
+		})
+	}
+}
+
+func TestDropDuplicateNames(t *testing.T) {
+	annotations := []github.CronAnnotation{
+		{Owner: "o", Repo: "r", WorkflowFile: "a.yml", CronExpr: "0 8 * * *", Name: "nightly-build"},
+		{Owner: "o", Repo: "r", WorkflowFile: "b.yml", CronExpr: "0 9 * * *", Name: "NIGHTLY_BUILD"},
+		{Owner: "o", Repo: "r", WorkflowFile: "c.yml", CronExpr: "0 10 * * *"},
+	}
+
+	kept, skipped := dropDuplicateNames(annotations)
+	if len(kept) != 2 {
+		t.Fatalf("expected 2 kept, got %d", len(kept))
+	}
+	if len(skipped) != 1 {
+		t.Fatalf("expected 1 skipped, got %d", len(skipped))
+	}
+	if skipped[0].WorkflowFile != "b.yml" {
+		t.Errorf("skipped WorkflowFile = %q, want %q", skipped[0].WorkflowFile, "b.yml")
+	}
+}
+
+func TestParseFile_RequiredInputs(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n    inputs:\n      env:\n        required: true\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 0 {
+		t.Fatalf("expected 0 annotations, got %d", len(annotations))
+	}
+	if len(skipped) != 1 {
+		t.Fatalf("expected 1 skipped, got %d", len(skipped))
+	}
+	if !strings.Contains(skipped[0].Reason, "env") || skipped[0].Category != SkipRequiredInputs {
+		t.Errorf("skipped = %+v, want required inputs mentioning the input name", skipped[0])
+	}
+}
+
+func TestParseFile_RequiredInputsSetByOption(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n" +
+		"  # ghacron: \"0 8 * * *\" inputs='{\"env\": \"prod\", \"date\": \"{{ .RunDate }}\"}'\n" +
+		"  # ghacron: \"0 9 * * *\" inputs='{\"env\": \"prod\"}'\n" +
+		"  workflow_dispatch:\n" +
+		"    inputs:\n" +
+		"      env:\n" +
+		"        required: true\n" +
+		"      date:\n" +
+		"        required: true\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 1 || annotations[0].CronExpr != "0 8 * * *" {
+		t.Fatalf("annotations = %+v, want the one setting every required input", annotations)
+	}
+	if want := `{"env": "prod", "date": "{{ .RunDate }}"}`; annotations[0].Inputs != want {
+		t.Errorf("Inputs = %s, want %s", annotations[0].Inputs, want)
+	}
+	if len(skipped) != 1 || skipped[0].Category != SkipRequiredInputs || !strings.HasSuffix(skipped[0].Reason, ": date") {
+		t.Errorf("skipped = %+v, want the one missing date", skipped)
+	}
+}
+
+func TestScanAll_SkipsArchivedRepos(t *testing.T) {
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n"
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	client := &mockScannerClient{
+		repos: []github.Repository{
+			{Owner: "o", Name: "active", DefaultBranch: "main"},
+			{Owner: "o", Name: "old", DefaultBranch: "main", Archived: true},
+		},
+		files: map[string][]github.WorkflowFile{
+			"o/active": {file},
+			"o/old":    {file},
+		},
+		contents: map[string]string{
+			"o/active/.github/workflows/ci.yml": content,
+			"o/old/.github/workflows/ci.yml":    content,
+		},
+	}
+
+	result, err := New(client).ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(result.Annotations))
+	}
+	if result.Annotations[0].Repo != "active" {
+		t.Errorf("Repo = %q, want %q", result.Annotations[0].Repo, "active")
+	}
+	if result.ArchivedRepos != 1 {
+		t.Errorf("ArchivedRepos = %d, want 1", result.ArchivedRepos)
+	}
+}
+
+func TestScanAll_SkipsDisabledWorkflows(t *testing.T) {
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n"
+	active := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	disabled := github.WorkflowFile{Name: "old.yml", Path: ".github/workflows/old.yml"}
+	client := &mockScannerClient{
+		repos: []github.Repository{{Owner: "o", Name: "r", DefaultBranch: "main"}},
+		files: map[string][]github.WorkflowFile{"o/r": {active, disabled}},
+		contents: map[string]string{
+			"o/r/.github/workflows/ci.yml":  content,
+			"o/r/.github/workflows/old.yml": content,
+		},
+		workflows: map[string][]github.Workflow{
+			"o/r": {
+				{ID: 1, Path: active.Path, State: "active"},
+				{ID: 2, Path: disabled.Path, State: "disabled_manually"},
+			},
+		},
+	}
+
+	result, err := New(client).ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Annotations) != 1 || result.Annotations[0].WorkflowFile != "ci.yml" {
+		t.Fatalf("expected only ci.yml annotation, got %v", result.Annotations)
+	}
+	if result.Annotations[0].WorkflowID != 1 {
+		t.Errorf("WorkflowID = %d, want 1", result.Annotations[0].WorkflowID)
+	}
+	if len(result.Skipped) != 1 {
+		t.Fatalf("expected 1 skipped, got %d", len(result.Skipped))
+	}
+	if !strings.Contains(result.Skipped[0].Reason, "disabled_manually") || result.Skipped[0].Category != SkipWorkflowDisabled {
+		t.Errorf("skipped = %+v, want a disabled workflow mentioning its state", result.Skipped[0])
+	}
+}
+
+func TestParseFile_Jitter(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n  # ghacron: \"0 * * * *\" jitter=300s\n  workflow_dispatch:\n"
+
+	annotations, _ := s.parseFile(repo, file, content, "")
+	if len(annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(annotations))
+	}
+	if annotations[0].Jitter != 300*time.Second {
+		t.Errorf("Jitter = %v, want %v", annotations[0].Jitter, 300*time.Second)
+	}
+}
+
+func TestScanRepo(t *testing.T) {
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	client := &mockScannerClient{
+		files: map[string][]github.WorkflowFile{"o/r": {file}},
+		contents: map[string]string{
+			"o/r/.github/workflows/ci.yml": "on:\n  # ghacron: \"0 8 * * *\"\n  # ghacron: \"bad\"\n  workflow_dispatch:\n",
+		},
+	}
+	s := New(client)
+
+	result, err := s.ScanRepo(context.Background(), github.Repository{Owner: "o", Name: "r", DefaultBranch: "main"})
+	if err != nil {
+		t.Fatalf("ScanRepo: %v", err)
+	}
+	if len(result.Annotations) != 1 || len(result.Skipped) != 1 {
+		t.Errorf("annotations/skipped = %d/%d, want 1/1", len(result.Annotations), len(result.Skipped))
+	}
+	if len(result.ScannedRepos) != 1 {
+		t.Errorf("scanned repos = %d, want 1", len(result.ScannedRepos))
+	}
+
+	result, err = s.ScanRepo(context.Background(), github.Repository{Owner: "o", Name: "r", Archived: true})
+	if err != nil {
+		t.Fatalf("ScanRepo: %v", err)
+	}
+	if len(result.Annotations) != 0 || result.ArchivedRepos != 1 {
+		t.Errorf("archived repo: annotations=%d archived=%d, want 0/1", len(result.Annotations), result.ArchivedRepos)
+	}
+}
+
+func TestScanAll_ReusesFilesWhenHeadUnchanged(t *testing.T) {
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	client := &mockScannerClient{
+		repos: []github.Repository{{Owner: "o", Name: "r", DefaultBranch: "main"}},
+		files: map[string][]github.WorkflowFile{"o/r": {file}},
+		contents: map[string]string{
+			"o/r/.github/workflows/ci.yml": "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n",
+		},
+		heads: map[string]string{"o/r": "sha1"},
+	}
+	s := New(client)
+
+	for i := 0; i < 2; i++ {
+		result, err := s.ScanAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Annotations) != 1 {
+			t.Fatalf("scan %d: expected 1 annotation, got %d", i, len(result.Annotations))
+		}
+	}
+	if client.contentCalls != 1 {
+		t.Errorf("GetWorkflowContents call count: got %d, want 1 (head unchanged)", client.contentCalls)
+	}
+
+	client.heads["o/r"] = "sha2"
+	if _, err := s.ScanAll(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.contentCalls != 2 {
+		t.Errorf("GetWorkflowContents call count: got %d, want 2 (head moved)", client.contentCalls)
+	}
+}
+
+func TestScanAll_BatchFetch(t *testing.T) {
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n"
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	client := &mockScannerClient{
+		repos: []github.Repository{
+			{Owner: "o", Name: "batched", DefaultBranch: "main"},
+			{Owner: "o", Name: "fallback", DefaultBranch: "main"},
+		},
+		files: map[string][]github.WorkflowFile{
+			"o/batched":  {file},
+			"o/fallback": {file},
+		},
+		contents: map[string]string{
+			"o/batched/.github/workflows/ci.yml":  content,
+			"o/fallback/.github/workflows/ci.yml": content,
+		},
+		batched: map[string]bool{"o/batched": true},
+	}
+	s := New(client)
+	s.SetBatchFetch(true)
+
+	result, err := s.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", len(result.Annotations))
+	}
+	if client.batchCalls != 1 {
+		t.Errorf("GetWorkflowContentsBatch call count: got %d, want 1", client.batchCalls)
+	}
+	if client.contentCalls != 1 {
+		t.Errorf("GetWorkflowContents call count: got %d, want 1 (only the repo missing from the batch)", client.contentCalls)
+	}
+}
+
+func TestParseFile_RepositoryDispatch(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n" +
+		"  # ghacron: \"0 8 * * *\" type=repository_dispatch event=nightly payload='{\"env\": \"prod\"}'\n" +
+		"  # ghacron: \"0 9 * * *\"\n" +
+		"  repository_dispatch:\n" +
+		"    types: [nightly]\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(annotations))
+	}
+	a := annotations[0]
+	if !a.IsRepositoryDispatch() || a.EventType != "nightly" || a.Payload != `{"env": "prod"}` {
+		t.Errorf("annotation = %+v, want repository_dispatch of nightly with payload", a)
+	}
+	// The workflow_dispatch annotation has no workflow_dispatch trigger.
+	if len(skipped) != 1 || !strings.Contains(skipped[0].Reason, "workflow_dispatch is not in the on: section") || skipped[0].Category != SkipMissingTrigger {
+		t.Errorf("skipped = %+v, want the workflow_dispatch annotation", skipped)
+	}
+}
+
+func TestParseFile_PayloadPlaceholders(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n" +
+		"  # ghacron: \"0 8 * * *\" type=repository_dispatch event=nightly payload='{\"date\": \"{{ .RunDate }}\"}'\n" +
+		"  repository_dispatch:\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 1 || len(skipped) != 0 {
+		t.Fatalf("annotations = %+v, skipped = %+v; want 1 annotation", annotations, skipped)
+	}
+	// Placeholders are substituted at dispatch time.
+	if want := `{"date": "{{ .RunDate }}"}`; annotations[0].Payload != want {
+		t.Errorf("Payload = %s, want %s", annotations[0].Payload, want)
+	}
+}
+
+func TestParseFile_RepositoryDispatchRequiresTrigger(t *testing.T) {
+	s := New(nil)
+	repo := github.Repository{Owner: "test", Name: "repo", DefaultBranch: "main"}
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+
+	content := "on:\n  # ghacron: \"0 8 * * *\" type=repository_dispatch event=nightly\n  workflow_dispatch:\n"
+
+	annotations, skipped := s.parseFile(repo, file, content, "")
+	if len(annotations) != 0 || len(skipped) != 1 {
+		t.Fatalf("got %d annotations, %d skipped; want 0, 1", len(annotations), len(skipped))
+	}
+	if !strings.Contains(skipped[0].Reason, "repository_dispatch is not in the on: section") {
+		t.Errorf("Reason = %q", skipped[0].Reason)
+	}
+}
+
+func TestScanAll_StopsWhenRateLimited(t *testing.T) {
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n"
+	client := &mockScannerClient{
+		repos: []github.Repository{
+			{Owner: "o", Name: "a", DefaultBranch: "main"},
+			{Owner: "o", Name: "b", DefaultBranch: "main"},
+			{Owner: "o", Name: "c", DefaultBranch: "main"},
+		},
+		files:    map[string][]github.WorkflowFile{"o/a": {file}, "o/c": {file}},
+		contents: map[string]string{"o/a/.github/workflows/ci.yml": content, "o/c/.github/workflows/ci.yml": content},
+		errs:     map[string]error{"o/b": fmt.Errorf("failed to list workflows: %w", github.ErrRateLimited)},
+	}
+	s := New(client)
+
+	result, err := s.ScanAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Annotations) != 1 || len(result.ScannedRepos) != 1 {
+		t.Errorf("annotations/scanned = %d/%d, want 1/1", len(result.Annotations), len(result.ScannedRepos))
+	}
+	if client.contentCalls != 2 {
+		t.Errorf("GetWorkflowContents call count: got %d, want 2 (stopped at o/b)", client.contentCalls)
+	}
+	if len(result.FailedRepos) != 2 || result.FailedRepos[0].Name != "b" || result.FailedRepos[1].Name != "c" {
+		t.Errorf("failed repos = %+v, want o/b and o/c", result.FailedRepos)
+	}
 }
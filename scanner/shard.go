@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// SetShard restricts the scanner to the repositories of shard index out of
+// total, so replicas sharing an installation each schedule a disjoint subset.
+// A total of 1 (or less) disables sharding.
+func (s *Scanner) SetShard(index, total int) {
+	s.shardIndex, s.shardTotal = index, total
+}
+
+// Owns reports whether a repository belongs to the scanner's shard.
+func (s *Scanner) Owns(repo github.Repository) bool {
+	if s.shardTotal <= 1 {
+		return true
+	}
+	return RepoShard(repo.Owner, repo.Name, s.shardTotal) == s.shardIndex
+}
+
+// RepoShard returns the shard of a repository out of total. Names are
+// case-insensitive on GitHub, so the hash is of the lowercase "owner/repo".
+func RepoShard(owner, repo string, total int) int {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(owner + "/" + repo)))
+	return int(h.Sum32() % uint32(total))
+}
+
+// ownedRepos returns the repositories of the scanner's shard.
+func (s *Scanner) ownedRepos(repos []github.Repository) []github.Repository {
+	if s.shardTotal <= 1 {
+		return repos
+	}
+	owned := make([]github.Repository, 0, len(repos)/s.shardTotal+1)
+	for _, repo := range repos {
+		if s.Owns(repo) {
+			owned = append(owned, repo)
+		}
+	}
+	return owned
+}
@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestRepoShard(t *testing.T) {
+	if RepoShard("MyOrg", "Repo", 4) != RepoShard("myorg", "repo", 4) {
+		t.Error("shard depends on the case of the name")
+	}
+	counts := make([]int, 3)
+	for i := range 300 {
+		shard := RepoShard("org", fmt.Sprintf("repo-%d", i), 3)
+		if shard < 0 || shard >= 3 {
+			t.Fatalf("shard = %d, want [0, 3)", shard)
+		}
+		counts[shard]++
+	}
+	for shard, n := range counts {
+		if n < 50 {
+			t.Errorf("shard %d owns %d of 300 repositories, want an even split", shard, n)
+		}
+	}
+}
+
+func TestScanAll_Shard(t *testing.T) {
+	content := "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n"
+	file := github.WorkflowFile{Name: "ci.yml", Path: ".github/workflows/ci.yml"}
+	client := &mockScannerClient{
+		files:    map[string][]github.WorkflowFile{},
+		contents: map[string]string{},
+	}
+	for i := range 20 {
+		name := fmt.Sprintf("repo-%d", i)
+		client.repos = append(client.repos, github.Repository{Owner: "o", Name: name, DefaultBranch: "main"})
+		client.files["o/"+name] = []github.WorkflowFile{file}
+		client.contents["o/"+name+"/.github/workflows/ci.yml"] = content
+	}
+
+	seen := make(map[string]int)
+	for index := range 2 {
+		s := New(client)
+		s.SetShard(index, 2)
+		result, err := s.ScanAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, a := range result.Annotations {
+			if RepoShard(a.Owner, a.Repo, 2) != index {
+				t.Errorf("shard %d scanned %s of another shard", index, a.Repo)
+			}
+			seen[a.Repo]++
+		}
+		for _, repo := range result.ScannedRepos {
+			if !s.Owns(repo) {
+				t.Errorf("shard %d reports %s as scanned", index, repo.Name)
+			}
+		}
+	}
+	if len(seen) != 20 {
+		t.Errorf("shards scanned %d of 20 repositories", len(seen))
+	}
+	for repo, n := range seen {
+		if n != 1 {
+			t.Errorf("%s scanned by %d shards, want 1", repo, n)
+		}
+	}
+}
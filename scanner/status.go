@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// RepoStatus is the scan status of a repository.
+type RepoStatus struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	// LastScan is the time of the last scan attempt, and LastSuccess that of
+	// the last successful scan (nil if none succeeded).
+	LastScan    time.Time  `json:"last_scan"`
+	LastSuccess *time.Time `json:"last_success"`
+	// AnnotationCount and SkippedCount are the valid and skipped annotations
+	// found by the last successful scan.
+	AnnotationCount int `json:"annotation_count"`
+	SkippedCount    int `json:"skipped_count"`
+	// LastError is the error of the last scan, if it failed.
+	LastError           string `json:"last_error,omitempty"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+}
+
+// repoStatus is the scan status of a repository, as recorded by scans.
+type repoStatus struct {
+	lastScan    time.Time
+	lastSuccess time.Time
+	annotations int
+	skipped     int
+}
+
+// recordRepoScan records a scan of a repository at now. The caller must hold
+// s.mu.
+func (s *Scanner) recordRepoScan(repo github.Repository, now time.Time, succeeded bool, annotations, skipped int) {
+	key := repo.Owner + "/" + repo.Name
+	st, ok := s.statuses[key]
+	if !ok {
+		st = &repoStatus{}
+		s.statuses[key] = st
+	}
+	st.lastScan = now
+	if succeeded {
+		st.lastSuccess = now
+		st.annotations = annotations
+		st.skipped = skipped
+	}
+}
+
+// RepoStatuses returns the scan status of every repository scanned since
+// startup and still in the installation, ordered by name.
+func (s *Scanner) RepoStatuses() []RepoStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]RepoStatus, 0, len(s.statuses))
+	for key, st := range s.statuses {
+		owner, name, _ := strings.Cut(key, "/")
+		status := RepoStatus{
+			Owner:           owner,
+			Repo:            name,
+			LastScan:        st.lastScan,
+			AnnotationCount: st.annotations,
+			SkippedCount:    st.skipped,
+		}
+		if !st.lastSuccess.IsZero() {
+			lastSuccess := st.lastSuccess
+			status.LastSuccess = &lastSuccess
+		}
+		if f, ok := s.failures[key]; ok {
+			status.LastError = f.lastError
+			status.ConsecutiveFailures = f.count
+		}
+		statuses = append(statuses, status)
+	}
+	slices.SortFunc(statuses, func(a, b RepoStatus) int {
+		return cmp.Or(strings.Compare(a.Owner, b.Owner), strings.Compare(a.Repo, b.Repo))
+	})
+	return statuses
+}
@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestRepoStatuses(t *testing.T) {
+	file := github.WorkflowFile{Path: ".github/workflows/ci.yml"}
+	client := &mockScannerClient{
+		repos: []github.Repository{
+			{Owner: "o", Name: "ok", DefaultBranch: "main"},
+			{Owner: "o", Name: "broken", DefaultBranch: "main"},
+			{Owner: "o", Name: "old", DefaultBranch: "main", Archived: true},
+		},
+		files: map[string][]github.WorkflowFile{"o/ok": {file}},
+		contents: map[string]string{
+			"o/ok/.github/workflows/ci.yml": "on:\n  # ghacron: \"0 8 * * *\"\n  # ghacron: \"bad\"\n  workflow_dispatch:\n",
+		},
+		errs: map[string]error{"o/broken": errors.New("boom")},
+	}
+	s := New(client)
+	if _, err := s.ScanAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := s.RepoStatuses()
+	if len(statuses) != 2 {
+		t.Fatalf("statuses = %+v, want broken and ok (archived repositories are not scanned)", statuses)
+	}
+	broken, ok := statuses[0], statuses[1]
+	if broken.Repo != "broken" || broken.LastError != "boom" || broken.ConsecutiveFailures != 1 || broken.LastSuccess != nil || broken.LastScan.IsZero() {
+		t.Errorf("broken = %+v, want a failed scan", broken)
+	}
+	if ok.Repo != "ok" || ok.AnnotationCount != 1 || ok.SkippedCount != 1 || ok.LastError != "" || ok.LastSuccess == nil {
+		t.Errorf("ok = %+v, want 1 annotation and 1 skipped", ok)
+	}
+
+	// A failed scan keeps the counts of the last successful one.
+	client.errs["o/ok"] = errors.New("boom")
+	client.heads = map[string]string{"o/ok": "moved"}
+	if _, err := s.ScanAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ok := s.RepoStatuses()[1]; ok.AnnotationCount != 1 || ok.LastError != "boom" || ok.LastSuccess == nil {
+		t.Errorf("ok = %+v, want the last counts and the error", ok)
+	}
+
+	// Repositories removed from the installation are dropped.
+	client.repos = client.repos[:1]
+	if _, err := s.ScanAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if statuses := s.RepoStatuses(); len(statuses) != 1 || statuses[0].Repo != "ok" {
+		t.Errorf("statuses = %+v, want only ok", statuses)
+	}
+}
@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// Audit actions.
+const (
+	auditDispatch       = "dispatch"
+	auditDispatchFailed = "dispatch_failed"
+	auditSkip           = "skip"
+	auditRollback       = "rollback"
+)
+
+// Reasons of skipped dispatches in the audit log.
+const (
+	skipPaused         = "paused"
+	skipShutdown       = "shutdown"
+	skipCircuitOpen    = "circuit_open"
+	skipDuplicateGuard = "duplicate_guard"
+	skipNoRefs         = "no_refs"
+	skipOverlap        = "overlap"
+	skipDryRun         = "dry_run"
+	skipClaimedByOther = "claimed_by_other_instance"
+	skipStateSaveError = "state_save_failed"
+	skipQueueFull      = "queue_full"
+)
+
+// AuditEvent is a line of the audit log: a dispatch decision about a job.
+type AuditEvent struct {
+	Time         time.Time `json:"time"`
+	Action       string    `json:"action"`
+	Reason       string    `json:"reason,omitempty"`
+	Trigger      string    `json:"trigger"`
+	Instance     string    `json:"instance"`
+	JobID        string    `json:"job_id"`
+	Name         string    `json:"name,omitempty"`
+	Owner        string    `json:"owner"`
+	Repo         string    `json:"repo"`
+	WorkflowFile string    `json:"workflow_file"`
+	CronExpr     string    `json:"cron_expr"`
+	DispatchType string    `json:"type"`
+	Ref          string    `json:"ref,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// AuditLog writes every dispatch, skipped dispatch and rollback as a JSON
+// line, separately from the operational logs.
+type AuditLog struct {
+	instance string
+
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// OpenAuditLog opens (or creates) the audit log file at path for appending.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	a := newAuditLog(f)
+	a.closer = f
+	return a, nil
+}
+
+func newAuditLog(w io.Writer) *AuditLog {
+	instance, _ := os.Hostname()
+	return &AuditLog{instance: instance, w: w}
+}
+
+// Close closes the audit log file.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// write appends an event. Failures are logged, and do not stop dispatches.
+func (a *AuditLog) write(event AuditEvent) {
+	event.Instance = a.instance
+	line, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("failed to encode audit event", "error", err)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(line, '\n')); err != nil {
+		slog.Error("failed to write audit event", "action", event.Action, "job_id", event.JobID, "error", err)
+	}
+}
+
+// SetAuditLog enables the audit log. It must be called before jobs run.
+func (s *Scheduler) SetAuditLog(audit *AuditLog) {
+	s.audit = audit
+}
+
+// auditEvent records a dispatch decision about a job, if the audit log is
+// enabled.
+func (s *Scheduler) auditEvent(annotation github.CronAnnotation, action, trigger, reason, ref string, err error) {
+	if s.audit == nil {
+		return
+	}
+	event := AuditEvent{
+		Time:         time.Now(),
+		Action:       action,
+		Reason:       reason,
+		Trigger:      trigger,
+		JobID:        annotation.Key().ID(),
+		Name:         annotation.Name,
+		Owner:        annotation.Owner,
+		Repo:         annotation.Repo,
+		WorkflowFile: annotation.WorkflowFile,
+		CronExpr:     annotation.CronExpr,
+		DispatchType: dispatchType(annotation),
+		Ref:          ref,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.audit.write(event)
+}
+
+// auditSkipped records a skipped dispatch.
+func (s *Scheduler) auditSkipped(annotation github.CronAnnotation, trigger, reason string) {
+	s.auditEvent(annotation, auditSkip, trigger, reason, "", nil)
+}
+
+// auditDispatched records the outcome of a dispatch to ref.
+func (s *Scheduler) auditDispatched(annotation github.CronAnnotation, trigger, ref string, err error) {
+	action := auditDispatch
+	if err != nil {
+		action = auditDispatchFailed
+	}
+	s.auditEvent(annotation, action, trigger, "", ref, err)
+}
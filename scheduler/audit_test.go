@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// auditEvents decodes the lines written to an audit log.
+func auditEvents(t *testing.T, data []byte) []AuditEvent {
+	t.Helper()
+	var events []AuditEvent
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var event AuditEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			t.Fatalf("invalid audit line %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestAudit(t *testing.T) {
+	tests := map[string]struct {
+		setup func(s *Scheduler, mock *mockClient)
+		want  []string // action/reason of each event
+	}{
+		"dispatch": {
+			want: []string{"dispatch/"},
+		},
+		"failure and rollback": {
+			setup: func(_ *Scheduler, mock *mockClient) { mock.dispatchErr = errors.New("API error") },
+			want:  []string{"dispatch_failed/", "rollback/"},
+		},
+		"paused": {
+			setup: func(s *Scheduler, _ *mockClient) {
+				annotation := testAnnotation()
+				s.paused[annotation.Key()] = struct{}{}
+			},
+			want: []string{"skip/paused"},
+		},
+		"dry run": {
+			setup: func(s *Scheduler, _ *mockClient) { s.config.DryRun = true },
+			want:  []string{"skip/dry_run"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := &mockClient{}
+			s := newTestScheduler(mock, defaultConfig())
+			var buf bytes.Buffer
+			s.SetAuditLog(newAuditLog(&buf))
+			if tt.setup != nil {
+				tt.setup(s, mock)
+			}
+
+			s.createJobHandler(testAnnotation())()
+
+			events := auditEvents(t, buf.Bytes())
+			if len(events) != len(tt.want) {
+				t.Fatalf("got %d events %+v, want %v", len(events), events, tt.want)
+			}
+			annotation := testAnnotation()
+			for i, event := range events {
+				if got := event.Action + "/" + event.Reason; got != tt.want[i] {
+					t.Errorf("event %d: got %s, want %s", i, got, tt.want[i])
+				}
+				if event.JobID != annotation.Key().ID() || event.Trigger != triggerSchedule || event.Time.IsZero() {
+					t.Errorf("event %d does not identify the job run: %+v", i, event)
+				}
+			}
+		})
+	}
+}
+
+func TestAudit_DispatchError(t *testing.T) {
+	mock := &mockClient{dispatchErr: errors.New("API error")}
+	s := newTestScheduler(mock, defaultConfig())
+	var buf bytes.Buffer
+	s.SetAuditLog(newAuditLog(&buf))
+
+	s.createJobHandler(testAnnotation())()
+
+	event := auditEvents(t, buf.Bytes())[0]
+	if event.Ref != "main" || event.Error != "API error" || event.WorkflowFile != "ci.yml" {
+		t.Errorf("failed dispatch event = %+v", event)
+	}
+}
+
+func TestOpenAuditLog_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for range 2 {
+		audit, err := OpenAuditLog(path)
+		if err != nil {
+			t.Fatalf("OpenAuditLog: %v", err)
+		}
+		s := newTestScheduler(&mockClient{}, defaultConfig())
+		s.SetAuditLog(audit)
+		s.auditSkipped(testAnnotation(), triggerManual, skipPaused)
+		if err := audit.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events := auditEvents(t, data); len(events) != 2 {
+		t.Errorf("got %d events, want 2 (file should be appended to)", len(events))
+	}
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// breaker is a per-job circuit breaker. After threshold consecutive failed
+// dispatches a job is tripped and not attempted again until the cool-down has
+// passed or it is reset. The first attempt after the cool-down is a probe: a
+// failure trips the job again immediately, a success closes the breaker.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures map[github.CronJobKey]int       // consecutive failed dispatches
+	tripped  map[github.CronJobKey]time.Time // when each tripped job was tripped
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		failures:  make(map[github.CronJobKey]int),
+		tripped:   make(map[github.CronJobKey]time.Time),
+	}
+}
+
+// allow reports whether a job may be attempted at now.
+func (b *breaker) allow(key github.CronJobKey, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	trippedAt, ok := b.tripped[key]
+	if !ok {
+		return true
+	}
+	if now.Sub(trippedAt) < b.cooldown {
+		return false
+	}
+	// Cool-down passed: let one probe through. The failure count stays at the
+	// threshold so a failed probe trips the job again.
+	delete(b.tripped, key)
+	return true
+}
+
+// recordSuccess closes the breaker of a job.
+func (b *breaker) recordSuccess(key github.CronJobKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, key)
+	delete(b.tripped, key)
+}
+
+// recordFailure counts a failed dispatch and reports whether it tripped the job.
+func (b *breaker) recordFailure(key github.CronJobKey, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures[key]++
+	if b.failures[key] < b.threshold {
+		return false
+	}
+	b.tripped[key] = now
+	return true
+}
+
+// state returns the consecutive failure count of a job and, if it is
+// tripped, when its cool-down ends (zero otherwise).
+func (b *breaker) state(key github.CronJobKey) (int, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	trippedAt, ok := b.tripped[key]
+	if !ok {
+		return b.failures[key], time.Time{}
+	}
+	return b.failures[key], trippedAt.Add(b.cooldown)
+}
+
+// reset closes the breaker of a job and reports whether it was tripped.
+func (b *breaker) reset(key github.CronJobKey) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, tripped := b.tripped[key]
+	delete(b.failures, key)
+	delete(b.tripped, key)
+	return tripped
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(2, time.Hour)
+	annotation := testAnnotation()
+	key := annotation.Key()
+	now := time.Now()
+
+	if b.recordFailure(key, now) {
+		t.Fatal("tripped after 1 failure, want threshold 2")
+	}
+	if !b.recordFailure(key, now) {
+		t.Fatal("not tripped after 2 failures")
+	}
+	if b.allow(key, now.Add(30*time.Minute)) {
+		t.Error("allowed during cool-down")
+	}
+	if failures, until := b.state(key); failures != 2 || !until.Equal(now.Add(time.Hour)) {
+		t.Errorf("state = %d, %v; want 2, %v", failures, until, now.Add(time.Hour))
+	}
+
+	// After the cool-down one probe is allowed; its failure trips immediately.
+	if !b.allow(key, now.Add(time.Hour)) {
+		t.Fatal("probe not allowed after cool-down")
+	}
+	if !b.recordFailure(key, now.Add(time.Hour)) {
+		t.Error("failed probe did not trip again")
+	}
+
+	b.recordSuccess(key)
+	if failures, until := b.state(key); failures != 0 || !until.IsZero() {
+		t.Errorf("state after success = %d, %v; want closed", failures, until)
+	}
+}
+
+func TestHandler_BreakerTripsAfterFailures(t *testing.T) {
+	mock := &mockClient{dispatchErr: errors.New("workflow not found")}
+	s := newTestScheduler(mock, defaultConfig())
+	s.breaker = newBreaker(2, time.Hour)
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+	handler := s.createJobHandler(annotation)
+
+	for range 3 {
+		handler()
+	}
+
+	if mock.dispatchCalls != 2 {
+		t.Errorf("DispatchWorkflow call count: got %d, want 2 (tripped)", mock.dispatchCalls)
+	}
+	detail := s.GetJobDetails()[0]
+	if !detail.Tripped || detail.TrippedUntil == nil || detail.ConsecutiveFailures != 2 {
+		t.Errorf("job detail = %+v, want tripped after 2 failures", detail)
+	}
+
+	// A manual reset lets the next tick through.
+	if err := s.ResetJob(context.Background(), annotation.Key().ID()); err != nil {
+		t.Fatalf("ResetJob: %v", err)
+	}
+	mock.dispatchErr = nil
+	handler()
+	if mock.dispatchCalls != 3 {
+		t.Errorf("DispatchWorkflow call count after reset: got %d, want 3", mock.dispatchCalls)
+	}
+	if detail := s.GetJobDetails()[0]; detail.Tripped || detail.ConsecutiveFailures != 0 {
+		t.Errorf("job detail after success = %+v, want closed", detail)
+	}
+}
+
+func TestHandler_RateLimitDoesNotTripBreaker(t *testing.T) {
+	mock := &mockClient{dispatchErr: fmt.Errorf("failed to dispatch workflow: %w", github.ErrRateLimited)}
+	s := newTestScheduler(mock, defaultConfig())
+	s.breaker = newBreaker(2, time.Hour)
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+	handler := s.createJobHandler(annotation)
+
+	for range 3 {
+		handler()
+	}
+
+	if mock.dispatchCalls != 3 {
+		t.Errorf("DispatchWorkflow call count: got %d, want 3 (not tripped)", mock.dispatchCalls)
+	}
+	if detail := s.GetJobDetails()[0]; detail.Tripped || detail.ConsecutiveFailures != 0 {
+		t.Errorf("job detail = %+v, want rate limits not counted", detail)
+	}
+}
+
+func TestResetJob_NotFound(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+
+	err := s.ResetJob(context.Background(), "unknown")
+	if !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("ResetJob error = %v, want ErrJobNotFound", err)
+	}
+}
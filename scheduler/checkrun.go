@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// checkRunName returns the name of the check run reporting a job's dispatches,
+// e.g. "ghacron/nightly-build" (the workflow file name without extension when
+// the job is unnamed).
+func checkRunName(annotation github.CronAnnotation) string {
+	name := annotation.Name
+	if name == "" {
+		name = strings.TrimSuffix(annotation.WorkflowFile, path.Ext(annotation.WorkflowFile))
+	}
+	return "ghacron/" + name
+}
+
+// reportCheckRun posts a check run with the result of a dispatch on the head
+// commit of ref. Failures are only logged; they never affect the dispatch.
+func (s *Scheduler) reportCheckRun(ctx context.Context, annotation github.CronAnnotation, ref, trigger string, dispatchErr error) {
+	sha, err := s.client.GetHeadSHA(ctx, annotation.Owner, annotation.Repo, ref, "")
+	if err != nil {
+		slog.WarnContext(ctx, "failed to resolve ref for check run",
+			append(annotationLogArgs(annotation), "ref", ref, "error", err)...,
+		)
+		return
+	}
+
+	summary := fmt.Sprintf("Schedule: `%s`\nTrigger: %s\nAnnotation: `%s:%d`",
+		annotation.CronExpr, trigger, annotation.Path, annotation.Line)
+	check := github.CheckRun{
+		Name:       checkRunName(annotation),
+		HeadSHA:    sha,
+		Conclusion: "success",
+		Title:      fmt.Sprintf("Dispatched %s on %s", annotation.WorkflowFile, ref),
+		Summary:    summary,
+	}
+	if dispatchErr != nil {
+		check.Conclusion = "failure"
+		check.Title = fmt.Sprintf("Failed to dispatch %s on %s", annotation.WorkflowFile, ref)
+		check.Summary = fmt.Sprintf("%s\n\nError:\n```\n%s\n```", summary, dispatchErr)
+	}
+
+	if err := s.client.CreateCheckRun(ctx, annotation.Owner, annotation.Repo, check); err != nil {
+		slog.WarnContext(ctx, "failed to create check run",
+			append(annotationLogArgs(annotation), "ref", ref, "error", err)...,
+		)
+	}
+}
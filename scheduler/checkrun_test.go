@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckRunName(t *testing.T) {
+	annotation := testAnnotation()
+	if got := checkRunName(annotation); got != "ghacron/ci" {
+		t.Errorf("unnamed job: got %q, want %q", got, "ghacron/ci")
+	}
+	annotation.Name = "nightly-build"
+	if got := checkRunName(annotation); got != "ghacron/nightly-build" {
+		t.Errorf("named job: got %q, want %q", got, "ghacron/nightly-build")
+	}
+}
+
+func TestHandler_CheckRuns(t *testing.T) {
+	tests := map[string]struct {
+		dispatchErr    error
+		wantConclusion string
+	}{
+		"success": {wantConclusion: "success"},
+		"failure": {dispatchErr: errors.New("API error"), wantConclusion: "failure"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := &mockClient{dispatchErr: tt.dispatchErr}
+			cfg := defaultConfig()
+			cfg.CheckRuns = true
+			s := newTestScheduler(mock, cfg)
+
+			s.createJobHandler(testAnnotation())()
+
+			if len(mock.checkRuns) != 1 {
+				t.Fatalf("check runs: got %d, want 1", len(mock.checkRuns))
+			}
+			check := mock.checkRuns[0]
+			if check.Name != "ghacron/ci" || check.Conclusion != tt.wantConclusion {
+				t.Errorf("check run = %s/%s, want ghacron/ci/%s", check.Name, check.Conclusion, tt.wantConclusion)
+			}
+			if tt.dispatchErr != nil && !strings.Contains(check.Summary, "API error") {
+				t.Errorf("summary %q should contain the dispatch error", check.Summary)
+			}
+		})
+	}
+}
+
+func TestHandler_CheckRunsDisabled(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+
+	s.createJobHandler(testAnnotation())()
+
+	if len(mock.checkRuns) != 0 {
+		t.Errorf("check runs: got %d, want 0 when disabled", len(mock.checkRuns))
+	}
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"sort"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// conflictHorizon is how far ahead fire times are compared. One week
+	// covers every weekday-based schedule.
+	conflictHorizon = 7 * 24 * time.Hour
+	// maxConflictOccurrences bounds the fire times computed per annotation
+	// (e.g. "* * * * *" fires 10080 times a week).
+	maxConflictOccurrences = 2000
+)
+
+// ScheduleConflict describes two annotations that dispatch the same workflow
+// within the configured conflict window of each other.
+type ScheduleConflict struct {
+	Owner        string    `json:"owner"`
+	Repo         string    `json:"repo"`
+	WorkflowFile string    `json:"workflow_file"`
+	CronExprs    [2]string `json:"cron_exprs"`
+	FirstOverlap time.Time `json:"first_overlap"`
+	GapSeconds   float64   `json:"gap_seconds"`
+}
+
+// id identifies a conflict independently of when it was detected.
+func (c ScheduleConflict) id() string {
+	return c.Owner + "/" + c.Repo + "/" + c.WorkflowFile + ":" + c.CronExprs[0] + "|" + c.CronExprs[1]
+}
+
+// workflowKey groups annotations that dispatch the same workflow.
+type workflowKey struct {
+	owner, repo, workflowFile string
+}
+
+// detectConflicts returns pairs of annotations for the same workflow whose
+// fire times within conflictHorizon of now come within window of each other.
+// Bare expressions are evaluated in loc. A non-positive window disables detection.
+func detectConflicts(annotations []github.CronAnnotation, window time.Duration, now time.Time, loc *time.Location) []ScheduleConflict {
+	if window <= 0 {
+		return nil
+	}
+
+	groups := make(map[workflowKey][]github.CronAnnotation)
+	for _, a := range annotations {
+		k := workflowKey{a.Owner, a.Repo, a.WorkflowFile}
+		groups[k] = append(groups[k], a)
+	}
+
+	var conflicts []ScheduleConflict
+	for k, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		conflicts = append(conflicts, groupConflicts(k, group, window, now.In(loc))...)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].id() < conflicts[j].id()
+	})
+	return conflicts
+}
+
+// groupConflicts compares every pair of annotations for a single workflow.
+func groupConflicts(k workflowKey, group []github.CronAnnotation, window time.Duration, now time.Time) []ScheduleConflict {
+	times := make([][]time.Time, len(group))
+	for i, a := range group {
+		times[i] = fireTimes(a.CronExpr, now, now.Add(conflictHorizon))
+	}
+
+	var conflicts []ScheduleConflict
+	for i := 0; i < len(group); i++ {
+		for j := i + 1; j < len(group); j++ {
+			at, gap, ok := firstWithin(times[i], times[j], window)
+			if !ok {
+				continue
+			}
+			exprs := [2]string{group[i].CronExpr, group[j].CronExpr}
+			if exprs[1] < exprs[0] {
+				exprs[0], exprs[1] = exprs[1], exprs[0]
+			}
+			conflicts = append(conflicts, ScheduleConflict{
+				Owner:        k.owner,
+				Repo:         k.repo,
+				WorkflowFile: k.workflowFile,
+				CronExprs:    exprs,
+				FirstOverlap: at,
+				GapSeconds:   gap.Seconds(),
+			})
+		}
+	}
+	return conflicts
+}
+
+// fireTimes returns the fire times of expr in (from, until], capped at
+// maxConflictOccurrences. Invalid expressions yield no times.
+func fireTimes(expr string, from, until time.Time) []time.Time {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil
+	}
+
+	var times []time.Time
+	for t := schedule.Next(from); !t.IsZero() && !t.After(until); t = schedule.Next(t) {
+		times = append(times, t)
+		if len(times) >= maxConflictOccurrences {
+			break
+		}
+	}
+	return times
+}
+
+// firstWithin walks two sorted time lists and returns the earliest pair whose
+// distance is at most window.
+func firstWithin(a, b []time.Time, window time.Duration) (time.Time, time.Duration, bool) {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		gap := a[i].Sub(b[j]).Abs()
+		if gap <= window {
+			if a[i].Before(b[j]) {
+				return a[i], gap, true
+			}
+			return b[j], gap, true
+		}
+		if a[i].Before(b[j]) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return time.Time{}, 0, false
+}
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestDetectConflicts(t *testing.T) {
+	now := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC) // Monday
+	window := 5 * time.Minute
+
+	tests := []struct {
+		name      string
+		exprs     []string
+		wantCount int
+		wantGap   float64
+	}{
+		{"single annotation", []string{"0 8 * * *"}, 0, 0},
+		{"far apart", []string{"0 8 * * *", "0 20 * * *"}, 0, 0},
+		{"within window", []string{"0 8 * * *", "3 8 * * *"}, 1, 180},
+		{"exact duplicate", []string{"0 8 * * *", "0 8 * * *"}, 1, 0},
+		{"different timezone same instant", []string{"0 9 * * *", "CRON_TZ=Asia/Tokyo 0 18 * * *"}, 1, 0},
+		{"weekly overlaps daily", []string{"0 8 * * 5", "2 8 * * *"}, 1, 120},
+		{"three way", []string{"0 8 * * *", "1 8 * * *", "2 8 * * *"}, 3, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var annotations []github.CronAnnotation
+			for _, expr := range tt.exprs {
+				annotations = append(annotations, github.CronAnnotation{
+					Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: expr,
+				})
+			}
+
+			conflicts := detectConflicts(annotations, window, now, time.UTC)
+			if len(conflicts) != tt.wantCount {
+				t.Fatalf("got %d conflicts, want %d: %v", len(conflicts), tt.wantCount, conflicts)
+			}
+			if tt.wantCount > 0 && conflicts[0].GapSeconds != tt.wantGap {
+				t.Errorf("GapSeconds = %v, want %v", conflicts[0].GapSeconds, tt.wantGap)
+			}
+		})
+	}
+}
+
+func TestDetectConflicts_DifferentWorkflows(t *testing.T) {
+	annotations := []github.CronAnnotation{
+		{Owner: "o", Repo: "r", WorkflowFile: "a.yml", CronExpr: "0 8 * * *"},
+		{Owner: "o", Repo: "r", WorkflowFile: "b.yml", CronExpr: "0 8 * * *"},
+	}
+
+	if conflicts := detectConflicts(annotations, 5*time.Minute, time.Now(), time.UTC); len(conflicts) != 0 {
+		t.Errorf("got %d conflicts, want 0 for different workflows", len(conflicts))
+	}
+}
+
+func TestDetectConflicts_Disabled(t *testing.T) {
+	annotations := []github.CronAnnotation{
+		{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 8 * * *"},
+		{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 8 * * *"},
+	}
+
+	if conflicts := detectConflicts(annotations, 0, time.Now(), time.UTC); conflicts != nil {
+		t.Errorf("got %v, want nil when window is 0", conflicts)
+	}
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// ErrJobNotFound is returned when no registered job has the requested ID.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrReconcileInProgress is returned when a reconcile is requested while
+// another one is running.
+var ErrReconcileInProgress = errors.New("reconcile already in progress")
+
+// errShuttingDown is returned when an action is requested during shutdown.
+var errShuttingDown = errors.New("scheduler is shutting down")
+
+// PauseJob suspends dispatches of a registered job. The paused state is
+// persisted before it takes effect, so it survives reconciles and restarts.
+func (s *Scheduler) PauseJob(ctx context.Context, id string) error {
+	return s.setPaused(ctx, id, true)
+}
+
+// ResumeJob re-enables dispatches of a paused job.
+func (s *Scheduler) ResumeJob(ctx context.Context, id string) error {
+	return s.setPaused(ctx, id, false)
+}
+
+func (s *Scheduler) setPaused(ctx context.Context, id string, paused bool) error {
+	annotation, ok := s.findJob(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+
+	if err := s.stateManager().SetPaused(ctx, annotation, paused); err != nil {
+		return fmt.Errorf("failed to persist paused state: %w", err)
+	}
+
+	s.mu.Lock()
+	if paused {
+		s.paused[annotation.Key()] = struct{}{}
+	} else {
+		delete(s.paused, annotation.Key())
+	}
+	s.mu.Unlock()
+
+	slog.InfoContext(ctx, "updated job paused state",
+		append(annotationLogArgs(annotation), "cron_expr", annotation.CronExpr, "paused", paused)...,
+	)
+	return nil
+}
+
+// findJob returns the annotation of the registered job with the given ID.
+func (s *Scheduler) findJob(id string) (github.CronAnnotation, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for key, job := range s.registeredJobs {
+		if key.ID() == id {
+			return job.annotation, true
+		}
+	}
+	return github.CronAnnotation{}, false
+}
+
+// TriggerJob runs a registered job now, outside its schedule. The dispatch
+// runs in the background and goes through the same duplicate guard and state
+// handling as a cron tick; splay, jitter and the paused state do not apply.
+func (s *Scheduler) TriggerJob(_ context.Context, id string) error {
+	annotation, ok := s.findJob(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+
+	if !s.drain.begin() {
+		return errShuttingDown
+	}
+
+	slog.Info("manually triggering job", append(annotationLogArgs(annotation), "cron_expr", annotation.CronExpr)...)
+	go func() {
+		defer s.drain.end()
+		// Unlike cron ticks, manual runs are not wrapped by cron.Recover.
+		defer recoverRun(annotation)
+		defer s.reportPanic(annotation)
+		s.runJob(annotation, triggerManual, time.Time{})
+	}()
+	return nil
+}
+
+// recoverRun logs a panic of a run started outside the cron engine instead
+// of letting it crash the process. Deferred by TriggerJob and RunOnce.
+func recoverRun(annotation github.CronAnnotation) {
+	if r := recover(); r != nil {
+		slog.Error("dispatch panicked",
+			append(annotationLogArgs(annotation), "panic", r, "stack", string(debug.Stack()))...,
+		)
+	}
+}
+
+// ResetJob closes the circuit breaker of a job so it is attempted again on
+// its next tick. Resetting a job that is not tripped is a no-op.
+func (s *Scheduler) ResetJob(_ context.Context, id string) error {
+	annotation, ok := s.findJob(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	if s.breaker == nil {
+		return nil
+	}
+
+	if s.breaker.reset(annotation.Key()) {
+		slog.Info("reset circuit breaker", append(annotationLogArgs(annotation), "cron_expr", annotation.CronExpr)...)
+	}
+	return nil
+}
+
+// loadJobDispatchState restores the persisted dispatch state of a newly
+// registered job, so /jobs shows its last dispatch after a restart.
+func (s *Scheduler) loadJobDispatchState(ctx context.Context, annotation github.CronAnnotation) {
+	state, err := s.stateManager().GetDispatchState(ctx, annotation)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load dispatch state",
+			append(annotationLogArgs(annotation), "error", err)...,
+		)
+		return
+	}
+	s.rememberDispatchState(annotation.Key(), state)
+}
+
+// isPaused reports whether dispatches of a job are suspended.
+func (s *Scheduler) isPaused(key github.CronJobKey) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, paused := s.paused[key]
+	return paused
+}
+
+// forgetPaused drops the paused state of a removed job. Its persisted state
+// is deleted with it, so a job re-added later starts unpaused.
+func (s *Scheduler) forgetPaused(key github.CronJobKey) {
+	s.mu.Lock()
+	delete(s.paused, key)
+	s.mu.Unlock()
+}
+
+// loadPausedState restores the persisted paused state of a newly registered job.
+// On failure the job stays unpaused (fail-open, consistent with dispatch state).
+func (s *Scheduler) loadPausedState(ctx context.Context, annotation github.CronAnnotation) {
+	paused, err := s.stateManager().GetPaused(ctx, annotation)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load paused state",
+			append(annotationLogArgs(annotation), "error", err)...,
+		)
+		return
+	}
+	if !paused {
+		return
+	}
+
+	s.mu.Lock()
+	s.paused[annotation.Key()] = struct{}{}
+	s.mu.Unlock()
+	slog.InfoContext(ctx, "job is paused", append(annotationLogArgs(annotation), "cron_expr", annotation.CronExpr)...)
+}
+
+// migratePausedState moves the persisted pause flag of a paused job whose
+// state variable was renamed by an option change (name= added, changed or
+// removed), so the job stays paused after a restart and the flag is not
+// garbage-collected under its old name.
+func (s *Scheduler) migratePausedState(ctx context.Context, previous, annotation github.CronAnnotation) {
+	if !s.isPaused(annotation.Key()) {
+		return
+	}
+	sm := s.stateManager()
+	oldName := sm.variableName(pausedKind, previous)
+	if oldName == sm.variableName(pausedKind, annotation) {
+		return
+	}
+
+	if err := sm.SetPaused(ctx, annotation, true); err != nil {
+		slog.ErrorContext(ctx, "failed to migrate paused state",
+			append(annotationLogArgs(annotation), "error", err)...,
+		)
+		return
+	}
+	if err := sm.deleteVariable(ctx, previous, oldName); err != nil {
+		slog.WarnContext(ctx, "failed to delete previous paused state",
+			append(annotationLogArgs(annotation), "variable", oldName, "error", err)...,
+		)
+	}
+}
+
+// ReconcileNow runs a full reconcile immediately and returns the changes it
+// made. It fails with ErrReconcileInProgress instead of waiting if a reconcile
+// is already running.
+func (s *Scheduler) ReconcileNow(ctx context.Context) (ReconcileSummary, error) {
+	ctx, id := withReconcileID(ctx)
+	slog.InfoContext(ctx, "manual reconciliation requested")
+	start := time.Now()
+	summary, ran, err := s.reconciler.TryReconcile(ctx)
+	if !ran {
+		return ReconcileSummary{}, ErrReconcileInProgress
+	}
+	s.finishReconcile(ctx, start, err == nil)
+	if err != nil {
+		return ReconcileSummary{}, fmt.Errorf("reconciliation failed (reconcile_id %s): %w", id, err)
+	}
+	summary.ReconcileID = id
+	return summary, nil
+}
@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/metrics"
+)
+
+// deadmanCheckInterval is how often overdue jobs are checked for.
+const deadmanCheckInterval = time.Minute
+
+var missedSchedules = metrics.Default.NewCounter(
+	"ghacron_missed_schedules_total",
+	"Scheduled runs that did not complete within the dead-man grace period.",
+	"owner", "repo", "workflow_file",
+)
+
+// deadman tracks when each job last ran and alerts when a scheduled run has
+// not completed within the grace period of its expected time.
+type deadman struct {
+	grace      time.Duration
+	webhookURL string
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	lastRun map[github.CronJobKey]time.Time // last completed run of each job
+	alerted map[github.CronJobKey]time.Time // expected time already alerted for
+}
+
+func newDeadman(grace time.Duration, webhookURL string) *deadman {
+	return &deadman{
+		grace:      grace,
+		webhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		lastRun:    make(map[github.CronJobKey]time.Time),
+		alerted:    make(map[github.CronJobKey]time.Time),
+	}
+}
+
+// markRun records that a job ran to completion (dispatched, or deliberately
+// skipped because the run was already covered).
+func (d *deadman) markRun(key github.CronJobKey, at time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastRun[key] = at
+}
+
+// forget drops the state of a removed job.
+func (d *deadman) forget(key github.CronJobKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.lastRun, key)
+	delete(d.alerted, key)
+}
+
+// overdue returns the expected run time of a job if it is past the grace
+// period and has not been alerted yet. since is when the job was registered.
+func (d *deadman) overdue(key github.CronJobKey, next func(time.Time) time.Time, since, now time.Time) (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok := d.lastRun[key]; ok && last.After(since) {
+		since = last
+	}
+	expected := next(since)
+	if expected.IsZero() || now.Before(expected.Add(d.grace)) {
+		return time.Time{}, false
+	}
+	if d.alerted[key].Equal(expected) {
+		return time.Time{}, false
+	}
+	d.alerted[key] = expected
+	return expected, true
+}
+
+// MissedSchedule is the payload posted to the dead-man webhook.
+type MissedSchedule struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name,omitempty"`
+	Owner        string    `json:"owner"`
+	Repo         string    `json:"repo"`
+	WorkflowFile string    `json:"workflow_file"`
+	CronExpr     string    `json:"cron_expr"`
+	ExpectedAt   time.Time `json:"expected_at"`
+	GraceSeconds int       `json:"grace_seconds"`
+}
+
+// RunDeadmanLoop periodically checks for jobs that missed their schedule.
+// It returns immediately when dead-man alerting is disabled.
+func (s *Scheduler) RunDeadmanLoop(ctx context.Context) {
+	if s.deadman == nil {
+		return
+	}
+
+	ticker := time.NewTicker(deadmanCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkMissedSchedules(ctx, time.Now())
+		}
+	}
+}
+
+// checkMissedSchedules alerts once for every unpaused job whose expected run
+// is older than the grace period without a completed run.
+func (s *Scheduler) checkMissedSchedules(ctx context.Context, now time.Time) {
+	type candidate struct {
+		annotation   github.CronAnnotation
+		next         func(time.Time) time.Time
+		registeredAt time.Time
+	}
+
+	s.mu.RLock()
+	var candidates []candidate
+	for key, job := range s.registeredJobs {
+		if _, paused := s.paused[key]; paused {
+			continue
+		}
+		entry := s.cron.Entry(job.entryID)
+		if entry.Schedule == nil {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			annotation:   job.annotation,
+			next:         func(t time.Time) time.Time { return entry.Schedule.Next(s.inLocation(t)) },
+			registeredAt: job.registeredAt,
+		})
+	}
+	s.mu.RUnlock()
+
+	for _, c := range candidates {
+		expected, ok := s.deadman.overdue(c.annotation.Key(), c.next, c.registeredAt, now)
+		if !ok {
+			continue
+		}
+		s.alertMissedSchedule(ctx, c.annotation, expected)
+	}
+}
+
+// alertMissedSchedule logs, counts and (optionally) posts a missed schedule.
+func (s *Scheduler) alertMissedSchedule(ctx context.Context, annotation github.CronAnnotation, expected time.Time) {
+	slog.ErrorContext(ctx, "job missed its schedule",
+		append(annotationLogArgs(annotation),
+			"cron_expr", annotation.CronExpr,
+			"expected_at", expected.Format(time.RFC3339),
+			"grace", s.deadman.grace.String(),
+		)...,
+	)
+	missedSchedules.Inc(annotation.Owner, annotation.Repo, annotation.WorkflowFile)
+
+	if s.deadman.webhookURL == "" {
+		return
+	}
+	key := annotation.Key()
+	payload := MissedSchedule{
+		ID:           key.ID(),
+		Name:         annotation.Name,
+		Owner:        annotation.Owner,
+		Repo:         annotation.Repo,
+		WorkflowFile: annotation.WorkflowFile,
+		CronExpr:     annotation.CronExpr,
+		ExpectedAt:   expected,
+		GraceSeconds: int(s.deadman.grace.Seconds()),
+	}
+	if err := s.deadman.post(ctx, payload); err != nil {
+		slog.ErrorContext(ctx, "failed to send missed schedule webhook",
+			append(annotationLogArgs(annotation), "error", err)...,
+		)
+	}
+}
+
+// post sends a missed schedule to the webhook URL as JSON.
+func (d *deadman) post(ctx context.Context, payload MissedSchedule) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// markRun records a completed run for dead-man tracking.
+func (s *Scheduler) markRun(annotation github.CronAnnotation) {
+	if s.deadman == nil {
+		return
+	}
+	s.deadman.markRun(annotation.Key(), time.Now())
+}
+
+// inLocation converts t to the scheduler's location, in which bare cron
+// expressions are evaluated.
+func (s *Scheduler) inLocation(t time.Time) time.Time {
+	if s.location == nil {
+		return t
+	}
+	return t.In(s.location)
+}
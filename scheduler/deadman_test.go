@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func newDeadmanTestScheduler(t *testing.T, webhookURL string) (*Scheduler, github.CronAnnotation) {
+	t.Helper()
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	s.location = time.UTC
+	s.deadman = newDeadman(5*time.Minute, webhookURL)
+
+	annotation := testAnnotation() // 0 9 * * *
+	registerTestJob(t, s, annotation)
+
+	// Pretend the job was registered the day before yesterday.
+	key := annotation.Key()
+	job := s.registeredJobs[key]
+	job.registeredAt = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	s.registeredJobs[key] = job
+	return s, annotation
+}
+
+func TestDeadman_Overdue(t *testing.T) {
+	d := newDeadman(5*time.Minute, "")
+	annotation := testAnnotation()
+	key := annotation.Key()
+	next := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 9, 0, 0, 0, time.UTC)
+	}
+	since := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	expected := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		lastRun time.Time
+		now     time.Time
+		want    bool
+	}{
+		{"within grace", time.Time{}, expected.Add(4 * time.Minute), false},
+		{"past grace", time.Time{}, expected.Add(6 * time.Minute), true},
+		{"already alerted", time.Time{}, expected.Add(7 * time.Minute), false},
+		{"ran since", expected.Add(time.Minute), expected.Add(8 * time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.lastRun.IsZero() {
+				d.markRun(key, tt.lastRun)
+			}
+			got, ok := d.overdue(key, next, since, tt.now)
+			if ok != tt.want {
+				t.Fatalf("overdue = %v, want %v", ok, tt.want)
+			}
+			if ok && !got.Equal(expected) {
+				t.Errorf("expected time = %v, want %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestCheckMissedSchedules_Webhook(t *testing.T) {
+	received := make(chan MissedSchedule, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload MissedSchedule
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer srv.Close()
+
+	s, annotation := newDeadmanTestScheduler(t, srv.URL)
+	now := time.Date(2026, 3, 1, 9, 10, 0, 0, time.UTC)
+
+	s.checkMissedSchedules(context.Background(), now)
+	s.checkMissedSchedules(context.Background(), now.Add(time.Minute))
+
+	if len(received) != 1 {
+		t.Fatalf("webhook call count: got %d, want 1", len(received))
+	}
+	payload := <-received
+	if payload.ID != annotation.Key().ID() {
+		t.Errorf("ID = %q, want %q", payload.ID, annotation.Key().ID())
+	}
+	if want := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC); !payload.ExpectedAt.Equal(want) {
+		t.Errorf("ExpectedAt = %v, want %v", payload.ExpectedAt, want)
+	}
+	if payload.GraceSeconds != 300 {
+		t.Errorf("GraceSeconds = %d, want 300", payload.GraceSeconds)
+	}
+}
+
+func TestCheckMissedSchedules_SkipsPausedAndRecentRuns(t *testing.T) {
+	received := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		received <- struct{}{}
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 3, 1, 9, 10, 0, 0, time.UTC)
+
+	s, annotation := newDeadmanTestScheduler(t, srv.URL)
+	s.paused[annotation.Key()] = struct{}{}
+	s.checkMissedSchedules(context.Background(), now)
+
+	s, annotation = newDeadmanTestScheduler(t, srv.URL)
+	s.deadman.markRun(annotation.Key(), time.Date(2026, 3, 1, 9, 0, 5, 0, time.UTC))
+	s.checkMissedSchedules(context.Background(), now)
+
+	if len(received) != 0 {
+		t.Errorf("webhook call count: got %d, want 0", len(received))
+	}
+}
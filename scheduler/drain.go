@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// drainer tracks in-flight job runs so shutdown can wait for them to finish
+// (including any state rollback) before the process exits.
+type drainer struct {
+	mu       sync.Mutex
+	stopped  bool
+	stopping chan struct{} // closed when shutdown begins
+	wg       sync.WaitGroup
+}
+
+func newDrainer() *drainer {
+	return &drainer{stopping: make(chan struct{})}
+}
+
+// begin registers a run. It returns false once shutdown has begun, in which
+// case the run must not start.
+func (d *drainer) begin() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return false
+	}
+	d.wg.Add(1)
+	return true
+}
+
+// end marks a run started by begin as finished.
+func (d *drainer) end() {
+	d.wg.Done()
+}
+
+// done returns a channel closed when shutdown begins.
+func (d *drainer) done() <-chan struct{} {
+	return d.stopping
+}
+
+// isStopping reports whether shutdown has begun.
+func (d *drainer) isStopping() bool {
+	select {
+	case <-d.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// drain stops accepting runs and waits up to timeout for in-flight runs to
+// finish. It reports whether all runs finished in time.
+func (d *drainer) drain(timeout time.Duration) bool {
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.stopping)
+	}
+	d.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// sleepOrStop waits for d or until cancel is closed. It reports whether the
+// full delay elapsed.
+func sleepOrStop(d time.Duration, cancel <-chan struct{}) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-cancel:
+		return false
+	}
+}
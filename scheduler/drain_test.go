@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrainer_WaitsForInflight(t *testing.T) {
+	d := newDrainer()
+	if !d.begin() {
+		t.Fatal("begin returned false before shutdown")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		d.end()
+	}()
+
+	if !d.drain(time.Second) {
+		t.Error("drain timed out, want in-flight run to finish")
+	}
+	if d.begin() {
+		t.Error("begin returned true after shutdown")
+	}
+}
+
+func TestDrainer_Timeout(t *testing.T) {
+	d := newDrainer()
+	d.begin()
+	defer d.end()
+
+	if d.drain(10 * time.Millisecond) {
+		t.Error("drain reported success with a run still in flight")
+	}
+}
+
+func TestSleepOrStop(t *testing.T) {
+	cancel := make(chan struct{})
+	close(cancel)
+	if sleepOrStop(time.Hour, cancel) {
+		t.Error("sleepOrStop returned true after cancel")
+	}
+	if !sleepOrStop(time.Millisecond, make(chan struct{})) {
+		t.Error("sleepOrStop returned false without cancel")
+	}
+}
+
+func TestHandler_DroppedDuringShutdown(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.Jitter = time.Hour
+
+	done := make(chan struct{})
+	go func() {
+		s.createJobHandler(annotation)()
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond) // let the handler start its jitter delay
+	if !s.drain.drain(time.Second) {
+		t.Fatal("drain timed out waiting for the delayed handler")
+	}
+	<-done
+
+	if mock.dispatchCalls != 0 || mock.setVarCalls != 0 {
+		t.Errorf("dispatch/SetVariable calls: got %d/%d, want 0/0", mock.dispatchCalls, mock.setVarCalls)
+	}
+}
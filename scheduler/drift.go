@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/metrics"
+)
+
+// driftSampleSize is the number of recent drift samples kept per job for the
+// statistics of JobDetail.
+const driftSampleSize = 100
+
+// driftBuckets are the histogram buckets of drift metrics, in seconds. Splay
+// and jitter delays count as drift, so they extend to several minutes.
+var driftBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+var (
+	dispatchDrift = metrics.Default.NewHistogram(
+		"ghacron_dispatch_drift_seconds",
+		"Delay between the scheduled time of a cron tick and its dispatch request.",
+		driftBuckets,
+		"owner", "repo", "workflow_file",
+	)
+	runStartDrift = metrics.Default.NewHistogram(
+		"ghacron_run_start_drift_seconds",
+		"Delay between the scheduled time of a cron tick and the creation of the dispatched workflow run.",
+		driftBuckets,
+		"owner", "repo", "workflow_file",
+	)
+)
+
+// DriftStats summarizes the recent drift of a job, in seconds.
+type DriftStats struct {
+	Samples     int     `json:"samples"`
+	LastSeconds float64 `json:"last_seconds"`
+	MeanSeconds float64 `json:"mean_seconds"`
+	P50Seconds  float64 `json:"p50_seconds"`
+	P95Seconds  float64 `json:"p95_seconds"`
+	MaxSeconds  float64 `json:"max_seconds"`
+}
+
+// driftSamples is a ring buffer of the most recent drift samples.
+type driftSamples struct {
+	values []float64
+	next   int // index of the oldest sample once full
+}
+
+func (d *driftSamples) add(v float64) {
+	if len(d.values) < driftSampleSize {
+		d.values = append(d.values, v)
+		return
+	}
+	d.values[d.next] = v
+	d.next = (d.next + 1) % driftSampleSize
+}
+
+// stats returns the statistics of the samples, or nil if there are none.
+func (d *driftSamples) stats() *DriftStats {
+	n := len(d.values)
+	if n == 0 {
+		return nil
+	}
+	last := d.values[n-1]
+	if n == driftSampleSize {
+		last = d.values[(d.next+n-1)%n]
+	}
+	sorted := slices.Clone(d.values)
+	slices.Sort(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return &DriftStats{
+		Samples:     n,
+		LastSeconds: last,
+		MeanSeconds: sum / float64(n),
+		P50Seconds:  percentile(sorted, 0.50),
+		P95Seconds:  percentile(sorted, 0.95),
+		MaxSeconds:  sorted[n-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	i := int(p*float64(len(sorted))+0.5) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// driftTracker keeps the recent dispatch and run start drift of each job.
+type driftTracker struct {
+	mu       sync.Mutex
+	dispatch map[github.CronJobKey]*driftSamples
+	runStart map[github.CronJobKey]*driftSamples
+}
+
+func newDriftTracker() *driftTracker {
+	return &driftTracker{
+		dispatch: make(map[github.CronJobKey]*driftSamples),
+		runStart: make(map[github.CronJobKey]*driftSamples),
+	}
+}
+
+func (d *driftTracker) add(samples map[github.CronJobKey]*driftSamples, key github.CronJobKey, drift time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, ok := samples[key]
+	if !ok {
+		s = &driftSamples{}
+		samples[key] = s
+	}
+	s.add(drift.Seconds())
+}
+
+// stats returns the dispatch and run start drift statistics of a job (nil
+// when there are no samples).
+func (d *driftTracker) stats(key github.CronJobKey) (*DriftStats, *DriftStats) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var dispatch, runStart *DriftStats
+	if s, ok := d.dispatch[key]; ok {
+		dispatch = s.stats()
+	}
+	if s, ok := d.runStart[key]; ok {
+		runStart = s.stats()
+	}
+	return dispatch, runStart
+}
+
+// forget drops the samples of a removed job.
+func (d *driftTracker) forget(key github.CronJobKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.dispatch, key)
+	delete(d.runStart, key)
+}
+
+// scheduledTime returns the time the cron tick of a job was scheduled for: the
+// previous fire time of its cron entry, which the cron run loop updates before
+// serving Entry (see countDueAt). Unregistered jobs fall back to the current
+// minute.
+func (s *Scheduler) scheduledTime(key github.CronJobKey) time.Time {
+	s.mu.RLock()
+	job, ok := s.registeredJobs[key]
+	s.mu.RUnlock()
+	if ok {
+		if prev := s.cron.Entry(job.entryID).Prev; !prev.IsZero() {
+			return prev
+		}
+	}
+	return time.Now().Truncate(time.Minute)
+}
+
+// recordDispatchDrift records the delay of a dispatch after its scheduled
+// time. Manual runs have no scheduled time and are not recorded.
+func (s *Scheduler) recordDispatchDrift(annotation github.CronAnnotation, scheduledAt, dispatchedAt time.Time) {
+	if scheduledAt.IsZero() {
+		return
+	}
+	drift := dispatchedAt.Sub(scheduledAt)
+	dispatchDrift.Observe(drift.Seconds(), annotation.Owner, annotation.Repo, annotation.WorkflowFile)
+	s.drift.add(s.drift.dispatch, annotation.Key(), drift)
+}
+
+// recordRunStartDrift records the delay of the creation of a dispatched
+// workflow run after its scheduled time.
+func (s *Scheduler) recordRunStartDrift(annotation github.CronAnnotation, scheduledAt, createdAt time.Time) {
+	if scheduledAt.IsZero() || createdAt.IsZero() {
+		return
+	}
+	drift := createdAt.Sub(scheduledAt)
+	runStartDrift.Observe(drift.Seconds(), annotation.Owner, annotation.Repo, annotation.WorkflowFile)
+	s.drift.add(s.drift.runStart, annotation.Key(), drift)
+}
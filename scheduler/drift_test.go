@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestDriftSamples_Stats(t *testing.T) {
+	var d driftSamples
+	if d.stats() != nil {
+		t.Fatal("stats without samples should be nil")
+	}
+	for i := 1; i <= driftSampleSize+10; i++ {
+		d.add(float64(i))
+	}
+
+	stats := d.stats()
+	// The 10 oldest samples (1..10) were dropped.
+	if stats.Samples != driftSampleSize || stats.LastSeconds != 110 || stats.MaxSeconds != 110 {
+		t.Errorf("stats = %+v, want 100 samples, last and max 110", stats)
+	}
+	if stats.MeanSeconds != 60.5 || stats.P50Seconds != 60 || stats.P95Seconds != 105 {
+		t.Errorf("stats = %+v, want mean 60.5, p50 60, p95 105", stats)
+	}
+}
+
+func TestHandler_RecordsDrift(t *testing.T) {
+	fastVerify(t)
+
+	mock := &mockClient{
+		dispatchedRun: github.WorkflowRun{ID: 1, Status: "completed", Conclusion: "success"},
+	}
+	cfg := defaultConfig()
+	cfg.VerifyTimeoutMinutes = 1
+	s := newTestScheduler(mock, cfg)
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+
+	s.createJobHandler(annotation)()
+
+	detail := s.GetJobDetails()[0]
+	if detail.DispatchDrift == nil || detail.DispatchDrift.Samples != 1 {
+		t.Fatalf("DispatchDrift = %+v, want 1 sample", detail.DispatchDrift)
+	}
+	if d := detail.DispatchDrift.LastSeconds; d < 0 || d > 60 {
+		t.Errorf("dispatch drift = %vs, want within the tick's minute", d)
+	}
+	rec := s.GetHistory("")[0]
+	if rec.ScheduledAt == nil || rec.ScheduledAt.Second() != 0 {
+		t.Errorf("ScheduledAt = %v, want the start of a minute", rec.ScheduledAt)
+	}
+
+	// The run was created 30s after the scheduled time.
+	scheduledAt := *rec.ScheduledAt
+	mock.dispatchedRun.CreatedAt = scheduledAt.Add(30 * time.Second)
+	s.verifyDispatch(context.Background(), annotation, "main", rec.ID, scheduledAt, scheduledAt.Add(time.Second))
+	if got := s.GetJobDetails()[0].RunStartDrift; got == nil || got.LastSeconds != 30 {
+		t.Errorf("RunStartDrift = %+v, want 30s", got)
+	}
+}
+
+func TestTriggerJob_NoDrift(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+
+	s.runJob(annotation, triggerManual, time.Time{})
+
+	if d := s.GetJobDetails()[0].DispatchDrift; d != nil {
+		t.Errorf("DispatchDrift = %+v, want none for a manual dispatch", d)
+	}
+	if rec := s.GetHistory("")[0]; rec.ScheduledAt != nil {
+		t.Errorf("ScheduledAt = %v, want nil for a manual dispatch", rec.ScheduledAt)
+	}
+}
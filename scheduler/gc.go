@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// stateGCDue reports whether state garbage collection should run at now, and
+// if so records now as the time of the last run.
+func (r *Reconciler) stateGCDue(now time.Time) bool {
+	if r.config.StateGCIntervalHours <= 0 {
+		return false
+	}
+	interval := time.Duration(r.config.StateGCIntervalHours) * time.Hour
+	if !r.lastStateGC.IsZero() && now.Sub(r.lastStateGC) < interval {
+		return false
+	}
+	r.lastStateGC = now
+	return true
+}
+
+// collectStateGarbage deletes ghacron state variables (GHACRON_LAST_*,
+// GHACRON_PAUSED_*) that no longer belong to a registered job. Only the
+// variables of the given repositories are examined; they must have been
+// scanned successfully, so a failed scan never causes the state of its jobs
+// to be deleted.
+func (r *Reconciler) collectStateGarbage(ctx context.Context, repos []github.Repository) {
+	sm := r.scheduler.stateManager()
+
+	// Names of the variables in use, qualified by owner/repo.
+	inUse := make(map[string]struct{})
+	for _, key := range r.scheduler.GetRegisteredKeys() {
+		annotation, ok := r.scheduler.GetRegisteredAnnotation(key)
+		if !ok {
+			continue
+		}
+		for _, kind := range stateKinds {
+			inUse[key.Owner+"/"+key.Repo+"/"+sm.variableName(kind, annotation)] = struct{}{}
+		}
+	}
+	isStale := func(owner, repo, name string) bool {
+		_, ok := inUse[owner+"/"+repo+"/"+name]
+		return !ok
+	}
+
+	var deleted int
+	if sm.orgScope {
+		deleted = r.collectOrgStateGarbage(ctx, sm, repos, isStale)
+	} else {
+		deleted = r.collectRepoStateGarbage(ctx, sm, repos, isStale)
+	}
+
+	slog.InfoContext(ctx, "state garbage collection completed", "repo_count", len(repos), "deleted", deleted)
+}
+
+// deleteRemovedJobState deletes the state variables of removed jobs, so their
+// repositories are not left with orphaned variables. Variables still used by a
+// desired job, e.g. by a named job whose schedule changed, are kept.
+func (r *Reconciler) deleteRemovedJobState(ctx context.Context, removed, desired []github.CronAnnotation) {
+	if len(removed) == 0 {
+		return
+	}
+	sm := r.scheduler.stateManager()
+
+	inUse := make(map[string]struct{})
+	for _, annotation := range desired {
+		for _, kind := range stateKinds {
+			inUse[annotation.Owner+"/"+annotation.Repo+"/"+sm.variableName(kind, annotation)] = struct{}{}
+		}
+	}
+	for _, annotation := range removed {
+		for _, kind := range stateKinds {
+			name := sm.variableName(kind, annotation)
+			if _, ok := inUse[annotation.Owner+"/"+annotation.Repo+"/"+name]; ok {
+				continue
+			}
+			r.deleteStateVariable(ctx, annotation.Owner, annotation.Repo, name, func() error {
+				return sm.deleteVariable(ctx, annotation, name)
+			})
+		}
+	}
+}
+
+// collectRepoStateGarbage deletes stale state variables stored as repository variables.
+func (r *Reconciler) collectRepoStateGarbage(ctx context.Context, sm *StateManager, repos []github.Repository, isStale func(owner, repo, name string) bool) int {
+	deleted := 0
+	for _, repo := range repos {
+		names, err := sm.client.ListVariables(ctx, repo.Owner, repo.Name)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to list variables for state garbage collection",
+				"owner", repo.Owner, "repo", repo.Name, "error", err)
+			continue
+		}
+
+		for _, name := range names {
+			if !sm.isStateVariable(name) || !isStale(repo.Owner, repo.Name, name) {
+				continue
+			}
+			if r.deleteStateVariable(ctx, repo.Owner, repo.Name, name, func() error {
+				return sm.deleteVariable(ctx, github.CronAnnotation{Owner: repo.Owner, Repo: repo.Name}, name)
+			}) {
+				deleted++
+			}
+		}
+	}
+	return deleted
+}
+
+// collectOrgStateGarbage deletes stale state variables stored as organization
+// variables. A variable is attributed to a repository by its repository token,
+// so variables of repositories that were not scanned are left alone.
+func (r *Reconciler) collectOrgStateGarbage(ctx context.Context, sm *StateManager, repos []github.Repository, isStale func(owner, repo, name string) bool) int {
+	byOwner := make(map[string][]string)
+	for _, repo := range repos {
+		byOwner[repo.Owner] = append(byOwner[repo.Owner], repo.Name)
+	}
+
+	deleted := 0
+	for owner, repoNames := range byOwner {
+		names, err := sm.client.ListOrgVariables(ctx, owner)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to list organization variables for state garbage collection",
+				"owner", owner, "error", err)
+			continue
+		}
+
+		for _, name := range names {
+			repo, ok := orgVariableRepo(sm, owner, repoNames, name)
+			if !ok || !isStale(owner, repo, name) {
+				continue
+			}
+			if r.deleteStateVariable(ctx, owner, repo, name, func() error {
+				return sm.deleteVariable(ctx, github.CronAnnotation{Owner: owner, Repo: repo}, name)
+			}) {
+				deleted++
+			}
+		}
+	}
+	return deleted
+}
+
+// orgVariableRepo returns which of the repositories an org-scope state
+// variable belongs to.
+func orgVariableRepo(sm *StateManager, owner string, repos []string, name string) (string, bool) {
+	for _, repo := range repos {
+		for _, kind := range stateKinds {
+			if strings.HasPrefix(name, repoPrefix(sm.kindPrefix(kind), owner, repo)) {
+				return repo, true
+			}
+		}
+	}
+	return "", false
+}
+
+// deleteStateVariable deletes a stale state variable (only logging it in
+// dry-run mode) and reports whether it was deleted.
+func (r *Reconciler) deleteStateVariable(ctx context.Context, owner, repo, name string, del func() error) bool {
+	if r.config.DryRun {
+		slog.InfoContext(ctx, "[DRY-RUN] stale state variable", "owner", owner, "repo", repo, "variable", name)
+		return false
+	}
+	if err := del(); err != nil {
+		slog.ErrorContext(ctx, "failed to delete stale state variable",
+			"owner", owner, "repo", repo, "variable", name, "error", err)
+		return false
+	}
+	slog.InfoContext(ctx, "deleted stale state variable", "owner", owner, "repo", repo, "variable", name)
+	return true
+}
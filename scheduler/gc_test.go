@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestCollectStateGarbage(t *testing.T) {
+	annotation := testAnnotation()
+	sm := NewStateManager(nil)
+	live := sm.variableName(lastDispatchKind, annotation)
+	stale := sm.kindPrefix(lastDispatchKind) + "DEADBEEF"
+
+	mock := &mockClient{vars: map[string]string{
+		live:                   "2026-03-01T09:00:00Z",
+		stale:                  "2026-03-01T09:00:00Z",
+		"GHACRON_PAUSED_OLD":   "true",
+		"UNRELATED_VARIABLE":   "keep",
+		"GHACRONISH_NOT_STATE": "keep",
+	}}
+	s := newTestScheduler(mock, defaultConfig())
+	r := NewReconciler(mock, s, s.config)
+	registerTestJob(t, s, annotation)
+
+	r.collectStateGarbage(context.Background(), []github.Repository{{Owner: annotation.Owner, Name: annotation.Repo}})
+
+	slices.Sort(mock.deletedVars)
+	want := []string{stale, "GHACRON_PAUSED_OLD"}
+	if !slices.Equal(mock.deletedVars, want) {
+		t.Errorf("deleted variables = %v, want %v", mock.deletedVars, want)
+	}
+}
+
+func TestCollectStateGarbage_DryRun(t *testing.T) {
+	mock := &mockClient{vars: map[string]string{"GHACRON_LAST_DEADBEEF": "2026-03-01T09:00:00Z"}}
+	cfg := defaultConfig()
+	cfg.DryRun = true
+	s := newTestScheduler(mock, cfg)
+	r := NewReconciler(mock, s, cfg)
+
+	r.collectStateGarbage(context.Background(), []github.Repository{{Owner: "o", Name: "r"}})
+
+	if len(mock.deletedVars) != 0 {
+		t.Errorf("deleted variables in dry-run: %v", mock.deletedVars)
+	}
+}
+
+func TestStateGCDue(t *testing.T) {
+	cfg := defaultConfig()
+	r := NewReconciler(&mockClient{}, newTestScheduler(&mockClient{}, cfg), cfg)
+	now := time.Now()
+
+	if r.stateGCDue(now) {
+		t.Error("due with GC disabled")
+	}
+
+	cfg.StateGCIntervalHours = 24
+	if !r.stateGCDue(now) {
+		t.Error("first run not due")
+	}
+	if r.stateGCDue(now.Add(time.Hour)) {
+		t.Error("due again within the interval")
+	}
+	if !r.stateGCDue(now.Add(24 * time.Hour)) {
+		t.Error("not due after the interval")
+	}
+}
+
+func TestCollectStateGarbage_OrgScope(t *testing.T) {
+	annotation := testAnnotation()
+	cfg := defaultConfig()
+	cfg.StateScope = stateScopeOrg
+	sm := newScopedStateManager(nil, stateScopeOrg, "")
+	live := sm.variableName(lastDispatchKind, annotation)
+	stale := repoPrefix(sm.kindPrefix(lastDispatchKind), annotation.Owner, annotation.Repo) + "DEADBEEF"
+	unscanned := repoPrefix(sm.kindPrefix(lastDispatchKind), annotation.Owner, "unscanned") + "DEADBEEF"
+
+	mock := &mockClient{orgVars: map[string]string{
+		live:      "2026-03-01T09:00:00Z",
+		stale:     "2026-03-01T09:00:00Z",
+		unscanned: "2026-03-01T09:00:00Z",
+	}}
+	s := newTestScheduler(mock, cfg)
+	r := NewReconciler(mock, s, cfg)
+	registerTestJob(t, s, annotation)
+
+	r.collectStateGarbage(context.Background(), []github.Repository{{Owner: annotation.Owner, Name: annotation.Repo}})
+
+	if !slices.Equal(mock.deletedOrgVars, []string{stale}) {
+		t.Errorf("deleted organization variables = %v, want [%s]", mock.deletedOrgVars, stale)
+	}
+}
+
+func TestReconcileRepo_DeletesRemovedJobState(t *testing.T) {
+	mock := &mockClient{
+		workflowFiles: []github.WorkflowFile{{
+			Name:    "ci.yml",
+			Path:    ".github/workflows/ci.yml",
+			Content: "on:\n  # ghacron: \"0 8 * * *\" name=nightly\n  workflow_dispatch:\n",
+		}},
+	}
+	s := newTestScheduler(mock, defaultConfig())
+	s.reconciler = NewReconciler(mock, s, s.config)
+
+	removed := github.CronAnnotation{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 7 * * *"}
+	// Same name, so the rescheduled job keeps using its state variables.
+	rescheduled := github.CronAnnotation{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 6 * * *", Name: "nightly"}
+	registerTestJob(t, s, removed)
+	registerTestJob(t, s, rescheduled)
+
+	repo := github.Repository{Owner: "o", Name: "r", DefaultBranch: "main"}
+	if err := s.ReconcileRepo(context.Background(), repo); err != nil {
+		t.Fatalf("ReconcileRepo: %v", err)
+	}
+
+	sm := s.stateManager()
+	want := []string{sm.variableName(lastDispatchKind, removed), sm.variableName(pausedKind, removed)}
+	slices.Sort(want)
+	slices.Sort(mock.deletedVars)
+	if !slices.Equal(mock.deletedVars, want) {
+		t.Errorf("deleted variables = %v, want %v", mock.deletedVars, want)
+	}
+}
@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// stateScopeGit stores state in a JSON file committed to a branch of a
+// dedicated repository (GHACRON_STATE_SCOPE=git).
+const stateScopeGit = "git"
+
+// gitStateMaxAttempts is how often a state file update is attempted when it
+// conflicts with a concurrent update.
+const gitStateMaxAttempts = 5
+
+// gitFileClient is the GitHub API interface used by gitStateStore.
+type gitFileClient interface {
+	GetFile(ctx context.Context, owner, repo, path, branch string) (content []byte, sha string, found bool, err error)
+	PutFile(ctx context.Context, owner, repo, path, branch, message string, content []byte, sha string) error
+	CreateOrphanBranch(ctx context.Context, owner, repo, branch, path, message string, content []byte) error
+}
+
+// gitStateStore is a StateClient keeping every state variable in one JSON
+// file on a branch of a repository, so each change is a commit: the branch
+// history is an audit trail of the dispatch state, and no Actions variables
+// API quota is used. The file maps "owner/repo" (or "owner" for organization
+// variables) to the variables of that scope:
+//
+//	{
+//	  "acme/app": {
+//	    "GHACRON_LAST_...": "{\"time\":...}"
+//	  }
+//	}
+//
+// Updates are compare-and-swap writes on the file's blob SHA, retried on
+// conflicts, so replicas sharing the file do not lose each other's writes.
+type gitStateStore struct {
+	client gitFileClient
+	owner  string
+	repo   string
+	branch string
+	path   string
+
+	mu sync.Mutex // serializes the updates of this process
+}
+
+// newGitStateStore creates a store for the file at path on branch of
+// repository ("owner/repo").
+func newGitStateStore(client gitFileClient, repository, branch, path string) *gitStateStore {
+	owner, repo, _ := strings.Cut(repository, "/")
+	return &gitStateStore{client: client, owner: owner, repo: repo, branch: branch, path: path}
+}
+
+// gitStateFile is the content of the state file.
+type gitStateFile map[string]map[string]string
+
+// load reads the state file and its blob SHA; a missing file (or branch) is
+// empty, with no SHA.
+func (g *gitStateStore) load(ctx context.Context) (gitStateFile, string, error) {
+	content, sha, found, err := g.client.GetFile(ctx, g.owner, g.repo, g.path, g.branch)
+	if err != nil {
+		return nil, "", err
+	}
+	state := make(gitStateFile)
+	if !found {
+		return state, "", nil
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, "", fmt.Errorf("invalid state file %s/%s/%s@%s: %w", g.owner, g.repo, g.path, g.branch, err)
+	}
+	return state, sha, nil
+}
+
+// update applies change to the state file and commits it with message,
+// unless change reports that nothing changed. On a conflict with another
+// writer the file is read again and change reapplied.
+func (g *gitStateStore) update(ctx context.Context, message string, change func(gitStateFile) bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var err error
+	for range gitStateMaxAttempts {
+		var state gitStateFile
+		var sha string
+		state, sha, err = g.load(ctx)
+		if err != nil {
+			return err
+		}
+		if !change(state) {
+			return nil
+		}
+		content, _ := json.MarshalIndent(state, "", "  ") // cannot fail for this type; keys are sorted
+		content = append(content, '\n')
+
+		err = g.client.PutFile(ctx, g.owner, g.repo, g.path, g.branch, message, content, sha)
+		if sha == "" && errors.Is(err, github.ErrNotFound) {
+			// The branch does not exist yet.
+			err = g.client.CreateOrphanBranch(ctx, g.owner, g.repo, g.branch, g.path, message, content)
+		}
+		if !errors.Is(err, github.ErrConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("state file %s/%s/%s@%s kept changing: %w", g.owner, g.repo, g.path, g.branch, err)
+}
+
+// get returns a variable of a scope ("" if it does not exist).
+func (g *gitStateStore) get(ctx context.Context, scope, name string) (string, error) {
+	state, _, err := g.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	return state[scope][name], nil
+}
+
+// set writes a variable of a scope.
+func (g *gitStateStore) set(ctx context.Context, scope, name, value string) error {
+	return g.update(ctx, fmt.Sprintf("Set %s %s", scope, name), func(state gitStateFile) bool {
+		if v, ok := state[scope][name]; ok && v == value {
+			return false
+		}
+		if state[scope] == nil {
+			state[scope] = make(map[string]string)
+		}
+		state[scope][name] = value
+		return true
+	})
+}
+
+// delete deletes a variable of a scope. Deleting a variable that does not
+// exist is not an error.
+func (g *gitStateStore) delete(ctx context.Context, scope, name string) error {
+	return g.update(ctx, fmt.Sprintf("Delete %s %s", scope, name), func(state gitStateFile) bool {
+		if _, ok := state[scope][name]; !ok {
+			return false
+		}
+		delete(state[scope], name)
+		if len(state[scope]) == 0 {
+			delete(state, scope)
+		}
+		return true
+	})
+}
+
+// list returns the names of the variables of a scope.
+func (g *gitStateStore) list(ctx context.Context, scope string) ([]string, error) {
+	state, _, err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(state[scope]))
+	for name := range state[scope] {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (g *gitStateStore) GetVariable(ctx context.Context, owner, repo, name string) (string, error) {
+	return g.get(ctx, owner+"/"+repo, name)
+}
+
+func (g *gitStateStore) SetVariable(ctx context.Context, owner, repo, name, value string) error {
+	return g.set(ctx, owner+"/"+repo, name, value)
+}
+
+func (g *gitStateStore) DeleteVariable(ctx context.Context, owner, repo, name string) error {
+	return g.delete(ctx, owner+"/"+repo, name)
+}
+
+func (g *gitStateStore) ListVariables(ctx context.Context, owner, repo string) ([]string, error) {
+	return g.list(ctx, owner+"/"+repo)
+}
+
+func (g *gitStateStore) GetOrgVariable(ctx context.Context, org, name string) (string, error) {
+	return g.get(ctx, org, name)
+}
+
+func (g *gitStateStore) SetOrgVariable(ctx context.Context, org, name, value string) error {
+	return g.set(ctx, org, name, value)
+}
+
+func (g *gitStateStore) DeleteOrgVariable(ctx context.Context, org, name string) error {
+	return g.delete(ctx, org, name)
+}
+
+func (g *gitStateStore) ListOrgVariables(ctx context.Context, org string) ([]string, error) {
+	return g.list(ctx, org)
+}
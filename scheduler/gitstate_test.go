@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// fakeStateRepo is an in-memory repository holding a state file.
+type fakeStateRepo struct {
+	mu       sync.Mutex
+	branch   bool   // whether the branch exists
+	content  []byte // file content
+	sha      int    // blob SHA of content, as a counter
+	commits  []string
+	conflict int // number of writes to reject as if another writer won
+}
+
+func (f *fakeStateRepo) GetFile(_ context.Context, _, _, _, _ string) ([]byte, string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.branch {
+		return nil, "", false, nil
+	}
+	return f.content, fmt.Sprint(f.sha), true, nil
+}
+
+func (f *fakeStateRepo) PutFile(_ context.Context, _, _, _, _, message string, content []byte, sha string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.branch {
+		return &github.APIError{StatusCode: 404, Kind: github.ErrNotFound, Err: fmt.Errorf("branch not found")}
+	}
+	if f.conflict > 0 {
+		f.conflict--
+		f.sha++ // the other writer's commit
+	}
+	if sha != fmt.Sprint(f.sha) {
+		return &github.APIError{StatusCode: 409, Kind: github.ErrConflict, Err: fmt.Errorf("sha mismatch")}
+	}
+	f.content = content
+	f.sha++
+	f.commits = append(f.commits, message)
+	return nil
+}
+
+func (f *fakeStateRepo) CreateOrphanBranch(_ context.Context, _, _, _, _, message string, content []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.branch = true
+	f.content = content
+	f.sha++
+	f.commits = append(f.commits, message)
+	return nil
+}
+
+func TestGitStateStore(t *testing.T) {
+	repo := &fakeStateRepo{}
+	store := newGitStateStore(repo, "acme/ghacron-state", "ghacron-state", "state.json")
+	sm := newScopedStateManager(store, stateScopeGit, "")
+	ctx := context.Background()
+	annotation := testAnnotation()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	if got, err := sm.GetLastDispatchTime(ctx, annotation); err != nil || !got.IsZero() {
+		t.Fatalf("before the branch exists: %v, %v; want the zero time", got, err)
+	}
+	if err := sm.SetLastDispatchTime(ctx, annotation, now); err != nil {
+		t.Fatalf("SetLastDispatchTime: %v", err)
+	}
+	if !repo.branch {
+		t.Fatal("the first write should create the branch")
+	}
+	got, err := sm.GetLastDispatchTime(ctx, annotation)
+	if err != nil || !got.Equal(now) {
+		t.Errorf("GetLastDispatchTime = %v, %v; want %v", got, err, now)
+	}
+
+	var file gitStateFile
+	if err := json.Unmarshal(repo.content, &file); err != nil {
+		t.Fatalf("state file: %v", err)
+	}
+	name := sm.variableName(lastDispatchKind, annotation)
+	if _, ok := file["test-owner/test-repo"][name]; !ok {
+		t.Errorf("state file = %s, want %s under test-owner/test-repo", repo.content, name)
+	}
+
+	// Rewriting the same value does not commit.
+	if err := sm.SetLastDispatchTime(ctx, annotation, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.deleteVariable(ctx, annotation, name); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.commits) != 2 || !strings.HasPrefix(repo.commits[1], "Delete test-owner/test-repo ") {
+		t.Errorf("commits = %q, want a set and a delete", repo.commits)
+	}
+	if strings.TrimSpace(string(repo.content)) != "{}" {
+		t.Errorf("state file after delete = %s, want empty", repo.content)
+	}
+}
+
+func TestGitStateStore_Conflict(t *testing.T) {
+	repo := &fakeStateRepo{branch: true, content: []byte(`{"acme/app":{"OTHER":"1"}}`)}
+	store := newGitStateStore(repo, "acme/ghacron-state", "ghacron-state", "state.json")
+	ctx := context.Background()
+
+	repo.conflict = 2
+	if err := store.SetVariable(ctx, "acme", "app", "NAME", "value"); err != nil {
+		t.Fatalf("SetVariable after conflicts: %v", err)
+	}
+	names, err := store.ListVariables(ctx, "acme", "app")
+	if err != nil || strings.Join(names, ",") != "NAME,OTHER" {
+		t.Errorf("ListVariables = %v, %v; want the existing and the new variable", names, err)
+	}
+
+	repo.conflict = gitStateMaxAttempts
+	if err := store.SetVariable(ctx, "acme", "app", "NAME", "changed"); err == nil {
+		t.Error("expected an error when every attempt conflicts")
+	}
+}
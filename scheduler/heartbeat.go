@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// heartbeatInterval is how often the cron engine runs the heartbeat entry.
+const heartbeatInterval = 30 * time.Second
+
+// startHeartbeat registers an internal cron entry recording when the cron
+// engine last ran it, so a stuck engine can be detected (see CronHeartbeat).
+// The entry is not a job: it is not listed in registeredJobs.
+func (s *Scheduler) startHeartbeat() {
+	s.lastHeartbeat.Store(time.Now().UnixNano())
+	s.heartbeatID = s.cron.Schedule(cron.Every(heartbeatInterval), cron.FuncJob(func() {
+		s.lastHeartbeat.Store(time.Now().UnixNano())
+	}))
+}
+
+// CronHeartbeat returns when the cron engine last ran its heartbeat entry
+// (the scheduler's creation before the first run), and the interval it runs
+// at (StatusProvider).
+func (s *Scheduler) CronHeartbeat() (time.Time, time.Duration) {
+	return time.Unix(0, s.lastHeartbeat.Load()), heartbeatInterval
+}
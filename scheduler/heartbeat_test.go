@@ -0,0 +1,19 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronHeartbeat(t *testing.T) {
+	s := New(&mockClient{}, defaultConfig(), time.UTC)
+	t.Cleanup(s.Stop)
+
+	last, interval := s.CronHeartbeat()
+	if time.Since(last) > time.Minute || interval != heartbeatInterval {
+		t.Errorf("CronHeartbeat() = %v, %v; want the creation time and %v", last, interval, heartbeatInterval)
+	}
+	if s.GetRegisteredJobCount() != 0 || len(s.GetJobDetails()) != 0 {
+		t.Error("the heartbeat entry is listed as a job")
+	}
+}
@@ -0,0 +1,213 @@
+package scheduler
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// historySize is the number of dispatch records kept in memory.
+const historySize = 1000
+
+// Dispatch triggers recorded in history.
+const (
+	triggerSchedule = "schedule"
+	triggerManual   = "manual"
+)
+
+// DispatchRecord is a single dispatch attempt of a job to one ref.
+type DispatchRecord struct {
+	ID           int64  `json:"id"`
+	JobID        string `json:"job_id"`
+	Name         string `json:"name,omitempty"`
+	Owner        string `json:"owner"`
+	Repo         string `json:"repo"`
+	WorkflowFile string `json:"workflow_file"`
+	CronExpr     string `json:"cron_expr"`
+	Ref          string `json:"ref"`
+	Trigger      string `json:"trigger"` // "schedule" or "manual"
+	// ScheduledAt is the time of the cron tick (nil for manual dispatches).
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty"`
+	DispatchedAt time.Time  `json:"dispatched_at"`
+	Error        string     `json:"error,omitempty"`
+
+	// Outcome of the created workflow run (set by dispatch verification).
+	RunID      int64  `json:"run_id,omitempty"`
+	RunURL     string `json:"run_url,omitempty"`
+	RunStatus  string `json:"run_status,omitempty"`
+	Conclusion string `json:"conclusion,omitempty"`
+}
+
+// HistoryQuery selects dispatch records.
+type HistoryQuery struct {
+	JobID string    // "" selects every job
+	Since time.Time // earliest DispatchedAt (zero = unbounded)
+	Until time.Time // latest DispatchedAt (zero = unbounded)
+	Limit int       // maximum number of records (0 = unlimited)
+}
+
+// matches reports whether q selects rec, ignoring the limit.
+func (q HistoryQuery) matches(rec DispatchRecord) bool {
+	return (q.JobID == "" || rec.JobID == q.JobID) &&
+		(q.Since.IsZero() || !rec.DispatchedAt.Before(q.Since)) &&
+		(q.Until.IsZero() || !rec.DispatchedAt.After(q.Until))
+}
+
+// history is a bounded in-memory log of dispatch records. The oldest records
+// are dropped once historySize is exceeded and, with a store, once they are
+// older than its retention period; the store still has them.
+type history struct {
+	mu      sync.Mutex
+	size    int
+	records []DispatchRecord // oldest first
+	nextID  int64
+	store   *HistoryStore // nil when history is not persisted
+	spilled bool          // the store has records older than records
+}
+
+func newHistory(size int) *history {
+	return &history{size: size, nextID: 1}
+}
+
+// add appends a record, assigning its ID, and returns the ID.
+func (h *history) add(rec DispatchRecord) int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rec.ID = h.nextID
+	h.nextID++
+	h.records = append(h.records, rec)
+	if len(h.records) > h.size {
+		h.records = h.records[len(h.records)-h.size:]
+		h.spilled = h.store != nil
+	}
+	if h.store == nil {
+		return rec.ID
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-h.store.retention)
+	expired := 0
+	for expired < len(h.records) && h.records[expired].DispatchedAt.Before(cutoff) {
+		expired++
+	}
+	h.records = slices.Delete(h.records, 0, expired)
+	h.store.write(rec)
+	if h.store.needsCompaction(now) {
+		if _, _, err := h.store.compact(now); err != nil {
+			slog.Error("failed to compact history file", "error", err)
+		}
+	}
+	return rec.ID
+}
+
+// setStore persists history to store, replacing the records with the most
+// recent ones loaded from it.
+func (h *history) setStore(store *HistoryStore) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.store = store
+	h.records = store.loaded
+	h.spilled = store.spilled
+	store.loaded = nil
+	if n := len(h.records); n > 0 {
+		h.nextID = h.records[n-1].ID + 1
+	}
+}
+
+// update applies fn to the record with the given ID, if it is still kept in
+// memory. Run outcomes arrive minutes after a dispatch, long before its record
+// is dropped.
+func (h *history) update(id int64, fn func(*DispatchRecord)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// IDs are ascending, though not contiguous after a reload.
+	i, ok := slices.BinarySearchFunc(h.records, id, func(r DispatchRecord, id int64) int {
+		return cmp.Compare(r.ID, id)
+	})
+	if !ok {
+		return
+	}
+	fn(&h.records[i])
+	if h.store != nil {
+		h.store.write(h.records[i])
+	}
+}
+
+// list returns the records selected by q, newest first. Records older than
+// those kept in memory are read from the store.
+func (h *history) list(q HistoryQuery) []DispatchRecord {
+	h.mu.Lock()
+	records := make([]DispatchRecord, 0, min(len(h.records), historySize))
+	for i := len(h.records) - 1; i >= 0; i-- {
+		if q.Limit > 0 && len(records) == q.Limit {
+			break
+		}
+		if rec := h.records[i]; q.matches(rec) {
+			records = append(records, rec)
+		}
+	}
+	store, before := h.store, h.nextID
+	if len(h.records) > 0 {
+		before = h.records[0].ID
+	}
+	older := h.spilled && (q.Limit == 0 || len(records) < q.Limit) &&
+		(len(h.records) == 0 || q.Since.IsZero() || q.Since.Before(h.records[0].DispatchedAt))
+	h.mu.Unlock()
+
+	if !older {
+		return records
+	}
+	if q.Limit > 0 {
+		q.Limit -= len(records)
+	}
+	stored, err := store.query(q, before, time.Now())
+	if err != nil {
+		slog.Error("failed to read history file", "error", err)
+		return records
+	}
+	return append(records, stored...)
+}
+
+// recordDispatch adds a dispatch attempt to history and returns its record ID.
+func (s *Scheduler) recordDispatch(annotation github.CronAnnotation, ref, trigger string, scheduledAt, at time.Time, err error) int64 {
+	key := annotation.Key()
+	rec := DispatchRecord{
+		JobID:        key.ID(),
+		Name:         annotation.Name,
+		Owner:        annotation.Owner,
+		Repo:         annotation.Repo,
+		WorkflowFile: annotation.WorkflowFile,
+		CronExpr:     annotation.CronExpr,
+		Ref:          ref,
+		Trigger:      trigger,
+		DispatchedAt: at,
+	}
+	if !scheduledAt.IsZero() {
+		rec.ScheduledAt = &scheduledAt
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	} else {
+		s.recordDispatchDrift(annotation, scheduledAt, at)
+	}
+	return s.history.add(rec)
+}
+
+// GetHistory returns recent dispatch records, newest first, optionally
+// filtered by job ID.
+func (s *Scheduler) GetHistory(jobID string) []DispatchRecord {
+	return s.history.list(HistoryQuery{JobID: jobID})
+}
+
+// QueryHistory returns the dispatch records selected by q, newest first
+// (StatusProvider).
+func (s *Scheduler) QueryHistory(q HistoryQuery) []DispatchRecord {
+	return s.history.list(q)
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHistory_BoundedAndNewestFirst(t *testing.T) {
+	h := newHistory(3)
+	for _, job := range []string{"a", "b", "a", "b"} {
+		h.add(DispatchRecord{JobID: job})
+	}
+
+	all := h.list(HistoryQuery{})
+	if len(all) != 3 {
+		t.Fatalf("record count: got %d, want 3", len(all))
+	}
+	if all[0].ID != 4 || all[2].ID != 2 {
+		t.Errorf("record IDs = %d..%d, want 4..2", all[0].ID, all[2].ID)
+	}
+
+	onlyA := h.list(HistoryQuery{JobID: "a"})
+	if len(onlyA) != 1 || onlyA[0].ID != 3 {
+		t.Errorf("filtered records = %+v, want only ID 3", onlyA)
+	}
+}
+
+func TestHistory_Update(t *testing.T) {
+	h := newHistory(2)
+	first := h.add(DispatchRecord{})
+	second := h.add(DispatchRecord{})
+	h.add(DispatchRecord{}) // evicts first
+
+	h.update(first, func(r *DispatchRecord) { r.RunID = 1 }) // no-op
+	h.update(second, func(r *DispatchRecord) { r.RunID = 2 })
+
+	for _, r := range h.list(HistoryQuery{}) {
+		if r.ID == second && r.RunID != 2 {
+			t.Errorf("RunID of record %d = %d, want 2", r.ID, r.RunID)
+		}
+		if r.ID != second && r.RunID != 0 {
+			t.Errorf("RunID of record %d = %d, want 0", r.ID, r.RunID)
+		}
+	}
+}
+
+func TestHandler_RecordsHistory(t *testing.T) {
+	mock := &mockClient{dispatchErr: errors.New("API error")}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+
+	s.createJobHandler(annotation)()
+
+	records := s.GetHistory(annotation.Key().ID())
+	if len(records) != 1 {
+		t.Fatalf("record count: got %d, want 1", len(records))
+	}
+	rec := records[0]
+	if rec.Ref != "main" || rec.Trigger != triggerSchedule || rec.Error == "" {
+		t.Errorf("record = %+v, want failed scheduled dispatch to main", rec)
+	}
+
+	registerTestJob(t, s, annotation)
+	mock.dispatchErr = nil
+	mock.getVarValue = ""
+	if err := s.TriggerJob(context.Background(), annotation.Key().ID()); err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(s.GetHistory("")) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("manual dispatch was not recorded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if rec := s.GetHistory("")[0]; rec.Trigger != triggerManual || rec.Error != "" {
+		t.Errorf("latest record = %+v, want successful manual dispatch", rec)
+	}
+}
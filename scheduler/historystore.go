@@ -0,0 +1,290 @@
+package scheduler
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HistoryStore persists dispatch history as JSON lines in a file, so it
+// survives restarts. Added and updated records are appended, and the last
+// line of a record ID wins when the file is read back. Only the most recent
+// records are kept in memory (see history); queries reaching further back
+// read the file. The file is rewritten with only the records within the
+// retention period when it is opened and whenever most of its lines are
+// outdated or expired.
+//
+// A plain file rather than an embedded database keeps ghacron free of cgo and
+// of extra dependencies: history is append-only apart from run outcomes, and
+// reads past the in-memory records are limited to /history queries and
+// exports of old ranges.
+type HistoryStore struct {
+	path      string
+	retention time.Duration
+	memory    int              // maximum number of records kept in memory
+	loaded    []DispatchRecord // most recent records read by OpenHistoryStore, oldest first
+	spilled   bool             // the file holds records older than loaded
+
+	mu      sync.Mutex
+	f       *os.File
+	lines   int       // lines in the file
+	records int       // records in the file
+	maxID   int64     // highest record ID written
+	oldest  time.Time // dispatch time of the oldest record in the file
+	closed  bool
+}
+
+// OpenHistoryStore opens (or creates) the history file at path, keeping the
+// records dispatched within retention.
+func OpenHistoryStore(path string, retention time.Duration) (*HistoryStore, error) {
+	return openHistoryStore(path, retention, historySize)
+}
+
+// openHistoryStore is OpenHistoryStore keeping at most memory records in
+// memory.
+func openHistoryStore(path string, retention time.Duration, memory int) (*HistoryStore, error) {
+	hs := &HistoryStore{path: path, retention: retention, memory: memory}
+	records, spilled, err := hs.compact(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	hs.loaded, hs.spilled = records, spilled
+	return hs, nil
+}
+
+// scan calls fn with each line of the file and its record, in file order,
+// and returns the number of invalid lines skipped. A missing file has no
+// lines.
+func (hs *HistoryStore) scan(fn func(line []byte, rec DispatchRecord)) (int, error) {
+	f, err := os.Open(hs.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	invalid := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var rec DispatchRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			invalid++
+			continue
+		}
+		fn(scanner.Bytes(), rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return invalid, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return invalid, nil
+}
+
+// compact replaces the file atomically with the last line of each record
+// within the retention period, and reopens it for appending. It returns the
+// most recent records (up to memory), oldest first, and whether the file
+// holds older ones. The file is read twice instead of being loaded.
+func (hs *HistoryStore) compact(now time.Time) ([]DispatchRecord, bool, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.closed {
+		return nil, false, nil
+	}
+
+	last, err := hs.lastLines(now.Add(-hs.retention))
+	if err != nil {
+		return nil, false, err
+	}
+	ids := slices.Sorted(maps.Keys(last))
+	var recentFrom int64
+	if len(ids) > hs.memory {
+		recentFrom = ids[len(ids)-hs.memory]
+	}
+
+	recent, oldest, err := hs.rewrite(last, recentFrom)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to compact history file: %w", err)
+	}
+
+	if hs.f != nil {
+		hs.f.Close()
+	}
+	hs.f, err = os.OpenFile(hs.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open history file: %w", err)
+	}
+	hs.lines, hs.records, hs.oldest = len(ids), len(ids), oldest
+	if len(ids) > 0 {
+		hs.maxID = max(hs.maxID, ids[len(ids)-1])
+	}
+	slices.SortFunc(recent, func(a, b DispatchRecord) int { return cmp.Compare(a.ID, b.ID) })
+	return recent, len(ids) > len(recent), nil
+}
+
+// lastLines returns the index of the last line of each record dispatched at
+// or after cutoff, by record ID. Dispatch times never change, so any line
+// tells whether a record is past the retention period. The caller must hold
+// hs.mu.
+func (hs *HistoryStore) lastLines(cutoff time.Time) (map[int64]int, error) {
+	last := make(map[int64]int)
+	n := 0
+	invalid, err := hs.scan(func(_ []byte, rec DispatchRecord) {
+		if !rec.DispatchedAt.Before(cutoff) {
+			last[rec.ID] = n
+		}
+		n++
+	})
+	if err != nil {
+		return nil, err
+	}
+	if invalid > 0 {
+		// Lines cut short by a crash are dropped rather than losing the history.
+		slog.Warn("skipping invalid history lines", "path", hs.path, "lines", invalid)
+	}
+	return last, nil
+}
+
+// rewrite replaces the file with the lines found by lastLines. It returns
+// the kept records with an ID of at least recentFrom, and the dispatch time
+// of the oldest kept record. The caller must hold hs.mu.
+func (hs *HistoryStore) rewrite(last map[int64]int, recentFrom int64) ([]DispatchRecord, time.Time, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(hs.path), filepath.Base(hs.path)+".*.tmp")
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+	kept := keptLines{w: bufio.NewWriter(tmp), recentFrom: recentFrom}
+	n := 0
+	_, scanErr := hs.scan(func(line []byte, rec DispatchRecord) {
+		if i, ok := last[rec.ID]; ok && i == n {
+			kept.add(line, rec)
+		}
+		n++
+	})
+	if err := cmp.Or(scanErr, kept.err, kept.w.Flush()); err != nil {
+		tmp.Close()
+		return nil, time.Time{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, time.Time{}, err
+	}
+	if err := os.Rename(tmp.Name(), hs.path); err != nil {
+		return nil, time.Time{}, err
+	}
+	return kept.recent, kept.oldest, nil
+}
+
+// keptLines writes the lines kept by a compaction, and collects the records
+// with an ID of at least recentFrom and the oldest dispatch time.
+type keptLines struct {
+	w          *bufio.Writer
+	recentFrom int64
+	recent     []DispatchRecord
+	oldest     time.Time
+	err        error // first write error; later lines are dropped
+}
+
+// add writes the line of rec. line is the scanner's buffer, so the newline is
+// written separately.
+func (k *keptLines) add(line []byte, rec DispatchRecord) {
+	if k.err != nil {
+		return
+	}
+	if _, k.err = k.w.Write(line); k.err == nil {
+		k.err = k.w.WriteByte('\n')
+	}
+	if rec.ID >= k.recentFrom {
+		k.recent = append(k.recent, rec)
+	}
+	if k.oldest.IsZero() || rec.DispatchedAt.Before(k.oldest) {
+		k.oldest = rec.DispatchedAt
+	}
+}
+
+// write appends a record. Failures are logged, and do not stop dispatches.
+func (hs *HistoryStore) write(rec DispatchRecord) {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		slog.Error("failed to encode history record", "error", err)
+		return
+	}
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.closed {
+		return
+	}
+	if _, err := hs.f.Write(append(line, '\n')); err != nil {
+		slog.Error("failed to write history record", "id", rec.ID, "job_id", rec.JobID, "error", err)
+		return
+	}
+	hs.lines++
+	if rec.ID > hs.maxID {
+		hs.maxID = rec.ID
+		hs.records++
+	}
+	if hs.oldest.IsZero() {
+		hs.oldest = rec.DispatchedAt
+	}
+}
+
+// needsCompaction reports whether most lines of the file are outdated
+// versions of records, or its records expired more than a tenth of the
+// retention period ago.
+func (hs *HistoryStore) needsCompaction(now time.Time) bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.lines > 2*hs.records+historySize ||
+		!hs.oldest.IsZero() && hs.oldest.Before(now.Add(-hs.retention-hs.retention/10))
+}
+
+// query returns the records of the file with IDs below before selected by
+// q, newest first. It serves the part of a query older than the records kept
+// in memory.
+func (hs *HistoryStore) query(q HistoryQuery, before int64, now time.Time) ([]DispatchRecord, error) {
+	cutoff := now.Add(-hs.retention)
+	byID := make(map[int64]DispatchRecord)
+	if _, err := hs.scan(func(_ []byte, rec DispatchRecord) {
+		if rec.ID < before && !rec.DispatchedAt.Before(cutoff) && q.matches(rec) {
+			byID[rec.ID] = rec
+		}
+	}); err != nil {
+		return nil, err
+	}
+	records := slices.Collect(maps.Values(byID))
+	slices.SortFunc(records, func(a, b DispatchRecord) int { return cmp.Compare(b.ID, a.ID) })
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[:q.Limit]
+	}
+	return records, nil
+}
+
+// Close closes the history file.
+func (hs *HistoryStore) Close() error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.closed {
+		return nil
+	}
+	hs.closed = true
+	return hs.f.Close()
+}
+
+// SetHistoryStore makes dispatch history persistent, starting from the
+// records loaded from store. History queries then cover the retention period
+// of the store instead of the records kept in memory. It must be called
+// before jobs run.
+func (s *Scheduler) SetHistoryStore(store *HistoryStore) {
+	s.history.setStore(store)
+}
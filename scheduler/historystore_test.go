@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryStore_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now()
+
+	store, err := OpenHistoryStore(path, 7*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHistory(historySize)
+	h.setStore(store)
+	h.add(DispatchRecord{JobID: "old", DispatchedAt: now.Add(-8 * 24 * time.Hour)})
+	id := h.add(DispatchRecord{JobID: "a", DispatchedAt: now.Add(-time.Hour)})
+	h.add(DispatchRecord{JobID: "b", DispatchedAt: now})
+	h.update(id, func(r *DispatchRecord) { r.RunID = 42 })
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A line cut short by a crash is skipped.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"id":4,"job_`)
+	f.Close()
+
+	store, err = OpenHistoryStore(path, 7*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	h = newHistory(historySize)
+	h.setStore(store)
+
+	records := h.list(HistoryQuery{})
+	if len(records) != 2 || records[0].JobID != "b" || records[1].JobID != "a" {
+		t.Fatalf("records = %+v, want b and a (old is past retention)", records)
+	}
+	if records[1].RunID != 42 {
+		t.Errorf("RunID = %d, want the updated value 42", records[1].RunID)
+	}
+	if next := h.add(DispatchRecord{JobID: "c", DispatchedAt: now}); next != 4 {
+		t.Errorf("next ID = %d, want 4", next)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("history file has %d lines, want 3 after compaction and an append", lines)
+	}
+}
+
+func TestHistory_Query(t *testing.T) {
+	h := newHistory(historySize)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		h.add(DispatchRecord{JobID: "a", DispatchedAt: start.Add(time.Duration(i) * 24 * time.Hour)})
+	}
+
+	got := h.list(HistoryQuery{Since: start.Add(24 * time.Hour), Until: start.Add(3 * 24 * time.Hour), Limit: 2})
+	if len(got) != 2 || got[0].ID != 4 || got[1].ID != 3 {
+		t.Errorf("records = %+v, want IDs 4 and 3", got)
+	}
+}
+
+func TestHistoryStore_ReadsOlderRecordsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	start := time.Now().Add(-5 * time.Hour)
+
+	store, err := openHistoryStore(path, 7*24*time.Hour, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHistory(2)
+	h.setStore(store)
+	for i := range 5 {
+		h.add(DispatchRecord{JobID: string(rune('a' + i%2)), DispatchedAt: start.Add(time.Duration(i) * time.Hour)})
+	}
+	if len(h.records) != 2 {
+		t.Fatalf("%d records in memory, want 2", len(h.records))
+	}
+
+	ids := func(records []DispatchRecord) []int64 {
+		var ids []int64
+		for _, rec := range records {
+			ids = append(ids, rec.ID)
+		}
+		return ids
+	}
+	check := func(t *testing.T, h *history) {
+		t.Helper()
+		if got := ids(h.list(HistoryQuery{})); !slices.Equal(got, []int64{5, 4, 3, 2, 1}) {
+			t.Errorf("all records = %v, want 5..1", got)
+		}
+		if got := ids(h.list(HistoryQuery{Limit: 3})); !slices.Equal(got, []int64{5, 4, 3}) {
+			t.Errorf("limited records = %v, want 5, 4, 3", got)
+		}
+		if got := ids(h.list(HistoryQuery{JobID: "a", Until: start.Add(3 * time.Hour)})); !slices.Equal(got, []int64{3, 1}) {
+			t.Errorf("records of a = %v, want 3, 1", got)
+		}
+		if got := ids(h.list(HistoryQuery{Since: start.Add(4 * time.Hour)})); !slices.Equal(got, []int64{5}) {
+			t.Errorf("recent records = %v, want 5", got)
+		}
+	}
+	check(t, h)
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening compacts the file and loads only the most recent records.
+	store, err = openHistoryStore(path, 7*24*time.Hour, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	h = newHistory(2)
+	h.setStore(store)
+	if got := ids(h.records); !slices.Equal(got, []int64{4, 5}) {
+		t.Fatalf("records in memory = %v, want 4, 5", got)
+	}
+	check(t, h)
+}
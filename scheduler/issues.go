@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// failureIssueLabel marks the issues opened by ghacron, so an open one can be
+// found again and updated instead of opening a duplicate.
+const failureIssueLabel = "ghacron"
+
+// failureCounter counts consecutive failed dispatches per job and decides when
+// a failure is reported (as an issue, or to Sentry): when a job reaches
+// threshold failures, and again after every further threshold failures while
+// it keeps failing.
+type failureCounter struct {
+	threshold int
+
+	mu       sync.Mutex
+	failures map[github.CronJobKey]int // consecutive failed dispatches
+}
+
+func newFailureCounter(threshold int) *failureCounter {
+	return &failureCounter{threshold: threshold, failures: make(map[github.CronJobKey]int)}
+}
+
+// recordSuccess resets the failure count of a job.
+func (f *failureCounter) recordSuccess(key github.CronJobKey) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.failures, key)
+}
+
+// recordFailure counts a failed dispatch and returns the consecutive failure
+// count and whether it should be reported now.
+func (f *failureCounter) recordFailure(key github.CronJobKey) (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[key]++
+	n := f.failures[key]
+	return n, n%f.threshold == 0
+}
+
+// failureIssueTitle returns the title of the issue reporting a failing job.
+// It identifies the job, so each job gets its own issue.
+func failureIssueTitle(annotation github.CronAnnotation) string {
+	job := annotation.Name
+	if job == "" {
+		job = annotation.WorkflowFile
+	}
+	return fmt.Sprintf("ghacron: scheduled dispatch of %s (%s) is failing", job, annotation.CronExpr)
+}
+
+// failureIssueBody describes the failures of a job, linking to its annotation.
+func failureIssueBody(annotation github.CronAnnotation, failures int, dispatchErr error) string {
+	annotationURL := fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s#L%d",
+		annotation.Owner, annotation.Repo, annotation.Ref, annotation.Path, annotation.Line)
+	return fmt.Sprintf("ghacron failed to dispatch `%s` %d times in a row.\n\n"+
+		"- Schedule: `%s`\n- Annotation: %s\n\nLast error:\n```\n%v\n```\n",
+		annotation.WorkflowFile, failures, annotation.CronExpr, annotationURL, dispatchErr)
+}
+
+// recordIssueResult feeds a dispatch outcome to the failure issue tracker,
+// filing an issue in the target repository when the threshold is reached.
+func (s *Scheduler) recordIssueResult(ctx context.Context, annotation github.CronAnnotation, dispatchErr error) {
+	if s.issues == nil {
+		return
+	}
+	if dispatchErr == nil {
+		s.issues.recordSuccess(annotation.Key())
+		return
+	}
+	failures, file := s.issues.recordFailure(annotation.Key())
+	if !file {
+		return
+	}
+	s.fileFailureIssue(ctx, annotation, failures, dispatchErr)
+}
+
+// fileFailureIssue comments on the open issue of a failing job, or opens one.
+// Failures are only logged.
+func (s *Scheduler) fileFailureIssue(ctx context.Context, annotation github.CronAnnotation, failures int, dispatchErr error) {
+	logArgs := append(annotationLogArgs(annotation), "consecutive_failures", failures)
+	title := failureIssueTitle(annotation)
+	body := failureIssueBody(annotation, failures, dispatchErr)
+
+	issue, found, err := s.client.FindOpenIssue(ctx, annotation.Owner, annotation.Repo, failureIssueLabel, title)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to look up failure issue", append(logArgs, "error", err)...)
+		return
+	}
+	if found {
+		if err := s.client.CommentOnIssue(ctx, annotation.Owner, annotation.Repo, issue.Number, body); err != nil {
+			slog.WarnContext(ctx, "failed to update failure issue", append(logArgs, "error", err)...)
+			return
+		}
+		slog.InfoContext(ctx, "updated failure issue", append(logArgs, "issue_url", issue.HTMLURL)...)
+		return
+	}
+
+	issue, err = s.client.CreateIssue(ctx, annotation.Owner, annotation.Repo, title, body, []string{failureIssueLabel})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to open failure issue", append(logArgs, "error", err)...)
+		return
+	}
+	slog.InfoContext(ctx, "opened failure issue", append(logArgs, "issue_url", issue.HTMLURL)...)
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFailureCounter(t *testing.T) {
+	f := newFailureCounter(2)
+	annotation := testAnnotation()
+	key := annotation.Key()
+
+	var filed []int
+	for range 5 {
+		if n, file := f.recordFailure(key); file {
+			filed = append(filed, n)
+		}
+	}
+	if len(filed) != 2 || filed[0] != 2 || filed[1] != 4 {
+		t.Errorf("filed at failures %v, want [2 4]", filed)
+	}
+
+	f.recordSuccess(key)
+	if _, file := f.recordFailure(key); file {
+		t.Error("filed after the first failure following a success")
+	}
+}
+
+func TestHandler_FailureIssue(t *testing.T) {
+	mock := &mockClient{dispatchErr: errors.New("workflow not found")}
+	s := newTestScheduler(mock, defaultConfig())
+	s.issues = newFailureCounter(2)
+	annotation := testAnnotation()
+	annotation.Path = ".github/workflows/ci.yml"
+	annotation.Line = 3
+	handler := s.createJobHandler(annotation)
+
+	handler()
+	if len(mock.issues) != 0 {
+		t.Fatalf("issues after 1 failure: got %d, want 0", len(mock.issues))
+	}
+
+	handler()
+	if len(mock.issues) != 1 {
+		t.Fatalf("issues after 2 failures: got %d, want 1", len(mock.issues))
+	}
+	if title := mock.issues[0].Title; !strings.Contains(title, "ci.yml") || !strings.Contains(title, annotation.CronExpr) {
+		t.Errorf("issue title %q should identify the job", title)
+	}
+
+	// Further failures update the open issue instead of opening another.
+	handler()
+	handler()
+	if len(mock.issues) != 1 || len(mock.issueComments) != 1 {
+		t.Errorf("issues = %d, comments = %d; want 1 issue with 1 comment", len(mock.issues), len(mock.issueComments))
+	}
+}
+
+func TestFailureIssueBody(t *testing.T) {
+	annotation := testAnnotation()
+	annotation.Path = ".github/workflows/ci.yml"
+	annotation.Line = 3
+
+	body := failureIssueBody(annotation, 3, errors.New("status=404"))
+
+	for _, want := range []string{
+		"https://github.com/test-owner/test-repo/blob/main/.github/workflows/ci.yml#L3",
+		"3 times",
+		"status=404",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body does not contain %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import "sync"
+
+// dispatchLimiter bounds the number of job handlers talking to the GitHub API
+// concurrently, overall and per repository.
+type dispatchLimiter struct {
+	global  chan struct{} // nil = unlimited
+	perRepo int           // 0 = unlimited
+
+	mu    sync.Mutex
+	repos map[string]chan struct{} // "owner/repo" -> semaphore
+}
+
+// newDispatchLimiter creates a limiter. A non-positive limit means unlimited.
+func newDispatchLimiter(global, perRepo int) *dispatchLimiter {
+	l := &dispatchLimiter{
+		perRepo: max(perRepo, 0),
+		repos:   make(map[string]chan struct{}),
+	}
+	if global > 0 {
+		l.global = make(chan struct{}, global)
+	}
+	return l
+}
+
+// acquire blocks until a slot is available for the repository and returns a
+// function releasing it. The per-repo slot is taken first so a handler never
+// holds a global slot while waiting on its own repository.
+func (l *dispatchLimiter) acquire(owner, repo string) func() {
+	repoSem := l.repoSemaphore(owner + "/" + repo)
+	if repoSem != nil {
+		repoSem <- struct{}{}
+	}
+	if l.global != nil {
+		l.global <- struct{}{}
+	}
+
+	return func() {
+		if l.global != nil {
+			<-l.global
+		}
+		if repoSem != nil {
+			<-repoSem
+		}
+	}
+}
+
+// repoSemaphore returns the semaphore for a repository, or nil if unlimited.
+func (l *dispatchLimiter) repoSemaphore(key string) chan struct{} {
+	if l.perRepo == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sem, ok := l.repos[key]
+	if !ok {
+		sem = make(chan struct{}, l.perRepo)
+		l.repos[key] = sem
+	}
+	return sem
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// runConcurrently starts n handlers on the given repos and returns the peak
+// number of handlers holding a slot at the same time.
+func runConcurrently(l *dispatchLimiter, repos []string) int32 {
+	var current, peak atomic.Int32
+	var wg sync.WaitGroup
+	for _, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := l.acquire("o", repo)
+			defer release()
+			n := current.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			current.Add(-1)
+		}()
+	}
+	wg.Wait()
+	return peak.Load()
+}
+
+func TestDispatchLimiter_Global(t *testing.T) {
+	l := newDispatchLimiter(2, 0)
+	if peak := runConcurrently(l, []string{"a", "b", "c", "d", "e"}); peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+}
+
+func TestDispatchLimiter_PerRepo(t *testing.T) {
+	l := newDispatchLimiter(0, 1)
+	if peak := runConcurrently(l, []string{"a", "a", "a", "a"}); peak != 1 {
+		t.Errorf("peak concurrency = %d, want 1 for a single repo", peak)
+	}
+}
+
+func TestDispatchLimiter_Unlimited(t *testing.T) {
+	l := newDispatchLimiter(0, 0)
+	release := l.acquire("o", "r")
+	release()
+	if l.global != nil || len(l.repos) != 0 {
+		t.Error("unlimited limiter should not allocate semaphores")
+	}
+}
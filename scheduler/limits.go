@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/metrics"
+	"github.com/korosuke613/ghacron/scanner"
+)
+
+var jobLimitSkipped = metrics.Default.NewCounter(
+	"ghacron_job_limit_skipped_total",
+	"Annotations skipped by a reconcile because a registered job limit was exceeded.",
+	"limit",
+)
+
+// enforceJobLimits drops the annotations beyond the per-repository and global
+// registered job limits and returns them as skipped. Annotations of jobs that
+// are already registered are kept first, so a burst of new annotations cannot
+// displace existing jobs; the rest are kept in repository and file order.
+// otherJobs is the number of registered jobs outside desired that stay
+// registered, e.g. jobs of repositories that were not scanned.
+func (r *Reconciler) enforceJobLimits(ctx context.Context, desired []github.CronAnnotation, otherJobs int) ([]github.CronAnnotation, []scanner.SkippedAnnotation) {
+	maxJobs, maxPerRepo := r.config.MaxJobs, r.config.MaxJobsPerRepo
+	if maxJobs <= 0 && maxPerRepo <= 0 {
+		return desired, nil
+	}
+
+	registered := make(map[github.CronJobKey]bool)
+	for _, key := range r.scheduler.GetRegisteredKeys() {
+		registered[key] = true
+	}
+	ordered := slices.Clone(desired)
+	slices.SortStableFunc(ordered, func(a, b github.CronAnnotation) int {
+		ra, rb := registered[a.Key()], registered[b.Key()]
+		if ra != rb {
+			if ra {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(
+			cmp.Compare(a.Owner, b.Owner),
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Line, b.Line),
+		)
+	})
+
+	var kept []github.CronAnnotation
+	var skipped []scanner.SkippedAnnotation
+	perRepo := make(map[string]int)
+	total := otherJobs
+	for _, a := range ordered {
+		repo := a.Owner + "/" + a.Repo
+		var limit, reason string
+		switch {
+		case maxPerRepo > 0 && perRepo[repo] >= maxPerRepo:
+			limit = "per_repo"
+			reason = fmt.Sprintf("job limit exceeded: %s already has %d jobs (GHACRON_MAX_JOBS_PER_REPO)", repo, maxPerRepo)
+		case maxJobs > 0 && total >= maxJobs:
+			limit = "global"
+			reason = fmt.Sprintf("job limit exceeded: %d jobs are already registered (GHACRON_MAX_JOBS)", maxJobs)
+		}
+		if reason != "" {
+			jobLimitSkipped.Inc(limit)
+			skipped = append(skipped, scanner.SkippedAnnotation{
+				Owner:        a.Owner,
+				Repo:         a.Repo,
+				WorkflowFile: a.WorkflowFile,
+				Path:         a.Path,
+				Line:         a.Line,
+				CronExpr:     a.CronExpr,
+				Category:     scanner.SkipJobLimit,
+				Reason:       reason,
+			})
+			continue
+		}
+		perRepo[repo]++
+		total++
+		kept = append(kept, a)
+	}
+
+	if len(skipped) > 0 {
+		slog.ErrorContext(ctx, "registered job limit exceeded, skipping annotations",
+			"skipped_count", len(skipped),
+			"max_jobs", maxJobs,
+			"max_jobs_per_repo", maxPerRepo,
+		)
+	}
+	return kept, skipped
+}
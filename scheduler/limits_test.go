@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestEnforceJobLimits(t *testing.T) {
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	cfg.MaxJobs = 3
+	cfg.MaxJobsPerRepo = 2
+	s := newTestScheduler(mock, cfg)
+	r := NewReconciler(mock, s, cfg)
+
+	annotation := func(repo string, line int) github.CronAnnotation {
+		return github.CronAnnotation{
+			Owner: "o", Repo: repo, WorkflowFile: "ci.yml",
+			CronExpr: fmt.Sprintf("0 %d * * *", line),
+			Path:     ".github/workflows/ci.yml", Line: line,
+		}
+	}
+	existing := annotation("a", 9)
+	registerTestJob(t, s, existing)
+
+	desired := []github.CronAnnotation{
+		annotation("c", 1),
+		annotation("a", 1),
+		annotation("a", 2),
+		annotation("b", 1),
+		existing,
+	}
+	kept, skipped := r.enforceJobLimits(context.Background(), desired, 0)
+
+	wantKept := []github.CronAnnotation{existing, annotation("a", 1), annotation("b", 1)}
+	if len(kept) != len(wantKept) {
+		t.Fatalf("kept = %v, want %v", kept, wantKept)
+	}
+	for i := range wantKept {
+		if kept[i].Key() != wantKept[i].Key() {
+			t.Errorf("kept[%d] = %v, want %v", i, kept[i].Key(), wantKept[i].Key())
+		}
+	}
+
+	if len(skipped) != 2 {
+		t.Fatalf("skipped = %+v, want 2 entries", skipped)
+	}
+	if skipped[0].Repo != "a" || !strings.Contains(skipped[0].Reason, "GHACRON_MAX_JOBS_PER_REPO") {
+		t.Errorf("skipped[0] = %+v, want o/a over the per-repository limit", skipped[0])
+	}
+	if skipped[1].Repo != "c" || !strings.Contains(skipped[1].Reason, "(GHACRON_MAX_JOBS)") {
+		t.Errorf("skipped[1] = %+v, want o/c over the global limit", skipped[1])
+	}
+}
+
+func TestEnforceJobLimits_CountsOtherJobs(t *testing.T) {
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	cfg.MaxJobs = 2
+	s := newTestScheduler(mock, cfg)
+	r := NewReconciler(mock, s, cfg)
+
+	desired := []github.CronAnnotation{
+		{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 1 * * *", Line: 1},
+		{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 2 * * *", Line: 2},
+	}
+	kept, skipped := r.enforceJobLimits(context.Background(), desired, 1)
+	if len(kept) != 1 || len(skipped) != 1 {
+		t.Errorf("kept %d, skipped %d; want 1 each with 1 other registered job", len(kept), len(skipped))
+	}
+}
+
+func TestEnforceJobLimits_Unlimited(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	r := NewReconciler(mock, s, s.config)
+
+	desired := []github.CronAnnotation{{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 1 * * *"}}
+	kept, skipped := r.enforceJobLimits(context.Background(), desired, 100)
+	if len(kept) != 1 || len(skipped) != 0 {
+		t.Errorf("kept %d, skipped %d; want all kept without limits", len(kept), len(skipped))
+	}
+}
@@ -0,0 +1,20 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/korosuke613/ghacron/logging"
+)
+
+// withReconcileID attaches a new reconcile ID to the log records of ctx, so
+// the output of a reconcile cycle (scan, diff, job changes) can be grouped.
+func withReconcileID(ctx context.Context) (context.Context, string) {
+	id := newNonce()
+	return logging.WithAttrs(ctx, "reconcile_id", id), id
+}
+
+// withDispatchID attaches a new dispatch ID to the log records of ctx, tying
+// together the logs of a single job run, including its verification.
+func withDispatchID(ctx context.Context) context.Context {
+	return logging.WithAttrs(ctx, "dispatch_id", newNonce())
+}
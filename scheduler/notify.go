@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/notify"
+	"github.com/korosuke613/ghacron/scanner"
+)
+
+// SetNotifier enables notifications. It must be called before the loops start.
+func (s *Scheduler) SetNotifier(notifier *notify.Notifier) {
+	s.notifier = notifier
+}
+
+// jobName identifies a job in notifications.
+func jobName(annotation github.CronAnnotation) string {
+	name := annotation.WorkflowFile
+	if annotation.Name != "" {
+		name = annotation.Name
+	}
+	return fmt.Sprintf("%s/%s %s (%s)", annotation.Owner, annotation.Repo, name, annotation.CronExpr)
+}
+
+// notifyDispatchFailed reports a job run in which every dispatch failed. Rate
+// limits are reported as warnings, since they are not the job's fault.
+func (s *Scheduler) notifyDispatchFailed(annotation github.CronAnnotation, trigger string, err error) {
+	severity := notify.SeverityError
+	if errors.Is(err, github.ErrRateLimited) {
+		severity = notify.SeverityWarning
+	}
+	s.notifier.Notify(notify.Event{
+		Severity: severity,
+		Kind:     notify.KindDispatchFailed,
+		Title:    "Dispatch failed: " + jobName(annotation),
+		Text:     fmt.Sprintf("Trigger: %s\nError: %v", trigger, err),
+		Owner:    annotation.Owner,
+		Repo:     annotation.Repo,
+	})
+}
+
+// notifyBreakerTripped reports a job suspended by the circuit breaker.
+func (s *Scheduler) notifyBreakerTripped(annotation github.CronAnnotation) {
+	s.notifier.Notify(notify.Event{
+		Severity: notify.SeverityError,
+		Kind:     notify.KindBreakerTripped,
+		Title:    "Circuit breaker tripped: " + jobName(annotation),
+		Text: fmt.Sprintf("Dispatches are suspended for %s after %d consecutive failures.",
+			s.breaker.cooldown, s.breaker.threshold),
+		Owner: annotation.Owner,
+		Repo:  annotation.Repo,
+	})
+}
+
+// notifyReconcileFailed reports a failed reconcile, of every repository when
+// repo is empty.
+func (s *Scheduler) notifyReconcileFailed(owner, repo string, err error) {
+	title := "Reconciliation failed"
+	if repo != "" {
+		title = fmt.Sprintf("Reconciliation of %s/%s failed", owner, repo)
+	}
+	s.notifier.Notify(notify.Event{
+		Severity: notify.SeverityError,
+		Kind:     notify.KindReconcileFailed,
+		Title:    title,
+		Text:     fmt.Sprintf("Error: %v", err),
+		Owner:    owner,
+		Repo:     repo,
+	})
+}
+
+// notifySkippedChanges reports, per repository, annotations that started to
+// be skipped (warning) and skipped annotations that were fixed or removed
+// (info) between two scans.
+func (s *Scheduler) notifySkippedChanges(before, after []scanner.SkippedAnnotation) {
+	if s.notifier == nil {
+		return
+	}
+	added := skippedDifference(after, before)
+	fixed := skippedDifference(before, after)
+	for _, repo := range skippedRepos(added) {
+		s.notifier.Notify(notify.Event{
+			Severity: notify.SeverityWarning,
+			Kind:     notify.KindAnnotationsSkipped,
+			Title:    fmt.Sprintf("Annotations skipped in %s/%s", repo[0].Owner, repo[0].Repo),
+			Text:     formatSkipped(repo),
+			Owner:    repo[0].Owner,
+			Repo:     repo[0].Repo,
+		})
+	}
+	for _, repo := range skippedRepos(fixed) {
+		s.notifier.Notify(notify.Event{
+			Severity: notify.SeverityInfo,
+			Kind:     notify.KindAnnotationsFixed,
+			Title:    fmt.Sprintf("Skipped annotations resolved in %s/%s", repo[0].Owner, repo[0].Repo),
+			Text:     formatSkipped(repo),
+			Owner:    repo[0].Owner,
+			Repo:     repo[0].Repo,
+		})
+	}
+}
+
+// skippedDifference returns the skipped annotations of a that are not in b.
+func skippedDifference(a, b []scanner.SkippedAnnotation) []scanner.SkippedAnnotation {
+	seen := make(map[scanner.SkippedAnnotation]struct{}, len(b))
+	for _, sk := range b {
+		seen[sk] = struct{}{}
+	}
+	var diff []scanner.SkippedAnnotation
+	for _, sk := range a {
+		if _, ok := seen[sk]; !ok {
+			diff = append(diff, sk)
+		}
+	}
+	return diff
+}
+
+// skippedRepos groups skipped annotations by repository, in order of first
+// appearance.
+func skippedRepos(skipped []scanner.SkippedAnnotation) [][]scanner.SkippedAnnotation {
+	var groups [][]scanner.SkippedAnnotation
+	index := make(map[string]int)
+	for _, sk := range skipped {
+		name := sk.Owner + "/" + sk.Repo
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], sk)
+	}
+	return groups
+}
+
+// formatSkipped lists skipped annotations, one per line.
+func formatSkipped(skipped []scanner.SkippedAnnotation) string {
+	lines := make([]string, 0, len(skipped))
+	for _, sk := range skipped {
+		lines = append(lines, fmt.Sprintf("- %s:%d `%s`: %s", sk.Path, sk.Line, sk.CronExpr, sk.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/notify"
+	"github.com/korosuke613/ghacron/scanner"
+)
+
+// notifyReceiver is a generic webhook endpoint recording the notified events.
+type notifyReceiver struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+// newTestNotifier returns a Notifier posting every event to a test endpoint.
+func newTestNotifier(t *testing.T) (*notify.Notifier, *notifyReceiver) {
+	t.Helper()
+	r := &notifyReceiver{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var raw struct {
+			Kind  string `json:"kind"`
+			Title string `json:"title"`
+			Repo  string `json:"repo"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&raw); err != nil {
+			t.Errorf("decode event: %v", err)
+		}
+		r.mu.Lock()
+		r.events = append(r.events, notify.Event{Kind: raw.Kind, Title: raw.Title, Repo: raw.Repo})
+		r.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return notify.New([]notify.Target{{Kind: notify.TargetWebhook, URL: srv.URL}}, notify.SeverityInfo, nil), r
+}
+
+func (r *notifyReceiver) kinds() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kinds := make(map[string]int)
+	for _, e := range r.events {
+		kinds[e.Kind]++
+	}
+	return kinds
+}
+
+func TestNotify_DispatchFailureAndBreaker(t *testing.T) {
+	mock := &mockClient{dispatchErr: errors.New("API error")}
+	cfg := defaultConfig()
+	cfg.DuplicateGuardSeconds = 0
+	s := newTestScheduler(mock, cfg)
+	s.breaker = newBreaker(2, time.Hour)
+	notifier, received := newTestNotifier(t)
+	s.SetNotifier(notifier)
+	handler := s.createJobHandler(testAnnotation())
+
+	handler()
+	handler()
+	notifier.Wait(5 * time.Second)
+
+	kinds := received.kinds()
+	if kinds[notify.KindDispatchFailed] != 2 || kinds[notify.KindBreakerTripped] != 1 {
+		t.Errorf("notified kinds = %v, want 2 dispatch failures and 1 breaker trip", kinds)
+	}
+}
+
+func TestNotify_SkippedChanges(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	notifier, received := newTestNotifier(t)
+	s.SetNotifier(notifier)
+
+	broken := scanner.SkippedAnnotation{Owner: "o", Repo: "a", Path: ".github/workflows/ci.yml", Line: 3, Reason: "invalid cron"}
+	other := scanner.SkippedAnnotation{Owner: "o", Repo: "b", Path: ".github/workflows/ci.yml", Line: 5, Reason: "invalid cron"}
+
+	s.SetSkippedAnnotations([]scanner.SkippedAnnotation{broken}) // baseline
+	s.SetSkippedAnnotations([]scanner.SkippedAnnotation{broken, other})
+	s.replaceRepoSkipped("o", "a", nil)
+	notifier.Wait(5 * time.Second)
+
+	received.mu.Lock()
+	defer received.mu.Unlock()
+	if len(received.events) != 2 {
+		t.Fatalf("got %d events %+v, want 2", len(received.events), received.events)
+	}
+	got := map[string]string{}
+	for _, e := range received.events {
+		got[e.Kind] = e.Repo
+	}
+	if got[notify.KindAnnotationsSkipped] != "b" || got[notify.KindAnnotationsFixed] != "a" {
+		t.Errorf("events = %+v, want b skipped and a fixed", received.events)
+	}
+}
+
+func TestNotify_Disabled(t *testing.T) {
+	mock := &mockClient{dispatchErr: errors.New("API error")}
+	s := newTestScheduler(mock, defaultConfig())
+
+	// Without a notifier, failures are only logged.
+	s.createJobHandler(testAnnotation())()
+	s.SetSkippedAnnotations(nil)
+	s.SetSkippedAnnotations([]scanner.SkippedAnnotation{{Owner: "o", Repo: "a"}})
+}
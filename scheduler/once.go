@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/robfig/cron/v3"
+)
+
+// onceFireMargin is how long RunOnce keeps the cron engine running after the
+// last due fire time, so the engine's timer has fired for it.
+const onceFireMargin = time.Second
+
+// RunOnce reconciles once and lets the cron engine dispatch the jobs due
+// within lookahead of the start, then waits for their handlers (including
+// jitter and splay delays) to finish. Ticks that passed during the reconcile,
+// before their job was registered, are dispatched right away. It returns the
+// number of due fire times. The duplicate guard keeps overlapping windows of
+// successive invocations from dispatching a tick twice. Stop must still be
+// called afterwards.
+func (s *Scheduler) RunOnce(ctx context.Context, lookahead time.Duration) (int, error) {
+	reconcileCtx, _ := withReconcileID(ctx)
+	slog.InfoContext(reconcileCtx, "reconciliation started")
+	start := time.Now()
+	if err := s.reconciler.Reconcile(reconcileCtx); err != nil {
+		slog.ErrorContext(reconcileCtx, "reconciliation failed", "error", err)
+		s.notifyReconcileFailed("", "", err)
+		s.reportReconcileError("", "", err)
+		s.finishReconcile(reconcileCtx, start, false)
+		return 0, err
+	}
+	s.finishReconcile(reconcileCtx, start, true)
+
+	runs := s.missedRuns(start)
+	var missed sync.WaitGroup
+	for _, run := range runs {
+		slog.InfoContext(ctx, "dispatching tick passed during reconciliation",
+			append(annotationLogArgs(run.annotation), "scheduled_at", run.scheduledAt)...,
+		)
+		missed.Add(1)
+		go func() {
+			defer missed.Done()
+			defer recoverRun(run.annotation)
+			s.handleTick(run.annotation, run.scheduledAt)
+		}()
+	}
+
+	deadline := start.Add(lookahead)
+	due, last := s.dueRuns(start, deadline)
+	if due == 0 && len(runs) == 0 {
+		slog.InfoContext(ctx, "no jobs due", "until", deadline)
+		return 0, nil
+	}
+	slog.InfoContext(ctx, "waiting for due jobs", "due", due, "last_run", last)
+
+	timer := time.NewTimer(time.Until(last) + onceFireMargin)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return due, ctx.Err()
+	case <-timer.C:
+	}
+
+	// Stop firing and wait for the handlers of the window to return.
+	select {
+	case <-ctx.Done():
+		return due, ctx.Err()
+	case <-s.cron.Stop().Done():
+	}
+
+	done := make(chan struct{})
+	go func() {
+		missed.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return due, ctx.Err()
+	case <-done:
+	}
+	return due, nil
+}
+
+// missedRun is a tick the cron engine will not fire.
+type missedRun struct {
+	annotation  github.CronAnnotation
+	scheduledAt time.Time
+}
+
+// missedRuns returns the ticks of registered jobs after from that precede the
+// first tick the cron engine fires for them, because the job was registered
+// only after the tick had passed.
+func (s *Scheduler) missedRuns(from time.Time) []missedRun {
+	if s.location != nil {
+		from = from.In(s.location)
+	}
+	entries := make(map[cron.EntryID]cron.Entry)
+	for _, entry := range s.cron.Entries() {
+		entries[entry.ID] = entry
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var missed []missedRun
+	for _, job := range s.registeredJobs {
+		entry, ok := entries[job.entryID]
+		if !ok || entry.Schedule == nil {
+			continue
+		}
+		first := entry.Next
+		if !entry.Prev.IsZero() {
+			first = entry.Prev
+		}
+		for next := entry.Schedule.Next(from); !next.IsZero() && next.Before(first); next = entry.Schedule.Next(next) {
+			missed = append(missed, missedRun{annotation: job.annotation, scheduledAt: next})
+		}
+	}
+	return missed
+}
+
+// dueRuns returns the number of fire times of registered jobs after from and
+// up to deadline, including missed ones, and the last of them.
+func (s *Scheduler) dueRuns(from, deadline time.Time) (int, time.Time) {
+	if s.location != nil {
+		from = from.In(s.location)
+	}
+	due := 0
+	var last time.Time
+	for _, entry := range s.cron.Entries() {
+		if entry.ID == s.heartbeatID || entry.Schedule == nil {
+			continue
+		}
+		for next := entry.Schedule.Next(from); !next.IsZero() && !next.After(deadline); next = entry.Schedule.Next(next) {
+			due++
+			if next.After(last) {
+				last = next
+			}
+		}
+	}
+	return due, last
+}
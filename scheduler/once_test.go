@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDueRuns(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	daily := testAnnotation() // 0 9 * * *
+	registerTestJob(t, s, daily)
+	every5 := testAnnotation()
+	every5.WorkflowFile = "every5.yml"
+	every5.CronExpr = "*/5 * * * *"
+	registerTestJob(t, s, every5)
+
+	from := time.Date(2026, 1, 1, 8, 58, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		deadline time.Time
+		wantDue  int
+		wantLast time.Time
+	}{
+		{"none due", from.Add(time.Minute), 0, time.Time{}},
+		{"deadline inclusive", from.Add(2 * time.Minute), 2, time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{"several runs of a job", from.Add(12 * time.Minute), 4, time.Date(2026, 1, 1, 9, 10, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, last := s.dueRuns(from, tt.deadline)
+			if due != tt.wantDue || !last.Equal(tt.wantLast) {
+				t.Errorf("dueRuns = (%d, %v), want (%d, %v)", due, last, tt.wantDue, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestRunOnce_NothingDue(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	s.reconciler = NewReconciler(mock, s, s.config)
+
+	due, err := s.RunOnce(context.Background(), 5*time.Minute)
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if due != 0 {
+		t.Errorf("due: got %d, want 0", due)
+	}
+	if !s.HasReconciled() {
+		t.Error("HasReconciled = false, want true")
+	}
+}
+
+func TestMissedRuns(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	s.cron.Start()
+	defer s.cron.Stop()
+	annotation := testAnnotation() // 0 9 * * *
+	registerTestJob(t, s, annotation)
+
+	entries := s.cron.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("entries: got %d, want 1", len(entries))
+	}
+	first := entries[0].Next
+
+	if missed := s.missedRuns(time.Now()); len(missed) != 0 {
+		t.Errorf("missedRuns from now: got %v, want none", missed)
+	}
+
+	missed := s.missedRuns(first.Add(-48*time.Hour - time.Minute))
+	if len(missed) != 2 {
+		t.Fatalf("missedRuns: got %d runs, want 2", len(missed))
+	}
+	for i, want := range []time.Time{first.Add(-48 * time.Hour), first.Add(-24 * time.Hour)} {
+		if !missed[i].scheduledAt.Equal(want) || missed[i].annotation.Key() != annotation.Key() {
+			t.Errorf("missed[%d] = %v at %v, want %v at %v", i, missed[i].annotation.Key(), missed[i].scheduledAt, annotation.Key(), want)
+		}
+	}
+}
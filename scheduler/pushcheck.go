@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// pushCheckRunName is the name of the check run validating the annotations of
+// a push.
+const pushCheckRunName = "ghacron"
+
+// maxCheckAnnotations is the number of annotations GitHub accepts per check
+// run request.
+const maxCheckAnnotations = 50
+
+// CheckPush posts a check run on the pushed commit sha with the validation
+// results of the annotations in paths, the workflow files changed by the push.
+// It reads the results of the last scan, so it must be called after the
+// repository is reconciled. It is a no-op unless push check runs are enabled,
+// for repositories of other shards and when the files have no annotations.
+func (s *Scheduler) CheckPush(ctx context.Context, repo github.Repository, sha string, paths []string) error {
+	if !s.config.PushCheckRuns || !s.reconciler.scanner.Owns(repo) {
+		return nil
+	}
+	check, ok := s.pushCheckRun(repo, sha, paths)
+	if !ok {
+		return nil
+	}
+	if err := s.client.CreateCheckRun(ctx, repo.Owner, repo.Name, check); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "posted annotation check run",
+		"owner", repo.Owner,
+		"repo", repo.Name,
+		"sha", sha,
+		"conclusion", check.Conclusion,
+	)
+	return nil
+}
+
+// pushCheckRun builds the check run of a push from the registered and skipped
+// annotations of the changed files, and false if they have none.
+func (s *Scheduler) pushCheckRun(repo github.Repository, sha string, paths []string) (github.CheckRun, bool) {
+	changed := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		changed[p] = true
+	}
+
+	var registered, skipped []github.CheckAnnotation
+	for _, key := range s.GetRegisteredKeys() {
+		a, ok := s.GetRegisteredAnnotation(key)
+		if !ok || a.Owner != repo.Owner || a.Repo != repo.Name || !changed[a.Path] {
+			continue
+		}
+		registered = append(registered, github.CheckAnnotation{
+			Path:    a.Path,
+			Line:    a.Line,
+			Level:   "notice",
+			Title:   "Scheduled: " + a.CronExpr,
+			Message: fmt.Sprintf("Registered: %s of %s on this schedule.", dispatchType(a), a.WorkflowFile),
+		})
+	}
+	for _, sk := range s.GetSkippedAnnotations() {
+		if sk.Owner != repo.Owner || sk.Repo != repo.Name || !changed[sk.Path] {
+			continue
+		}
+		skipped = append(skipped, github.CheckAnnotation{
+			Path:    sk.Path,
+			Line:    sk.Line,
+			Level:   "failure",
+			Title:   "Skipped: " + sk.CronExpr,
+			Message: sk.Reason,
+		})
+	}
+	if len(registered) == 0 && len(skipped) == 0 {
+		return github.CheckRun{}, false
+	}
+	sortCheckAnnotations(registered)
+	sortCheckAnnotations(skipped)
+
+	check := github.CheckRun{
+		Name:       pushCheckRunName,
+		HeadSHA:    sha,
+		Conclusion: "success",
+		Title:      fmt.Sprintf("%d schedules registered", len(registered)),
+		Summary:    pushCheckSummary(registered, skipped),
+	}
+	if len(skipped) > 0 {
+		check.Conclusion = "failure"
+		check.Title = fmt.Sprintf("%d annotations skipped, %d schedules registered", len(skipped), len(registered))
+	}
+	// Skipped annotations come first, so they are kept if there are too many.
+	check.Annotations = append(skipped, registered...)
+	if len(check.Annotations) > maxCheckAnnotations {
+		check.Annotations = check.Annotations[:maxCheckAnnotations]
+	}
+	return check, true
+}
+
+// pushCheckSummary lists the skipped and registered annotations in Markdown.
+func pushCheckSummary(registered, skipped []github.CheckAnnotation) string {
+	var b strings.Builder
+	for _, section := range []struct {
+		heading     string
+		annotations []github.CheckAnnotation
+	}{
+		{"Skipped annotations", skipped},
+		{"Registered schedules", registered},
+	} {
+		if len(section.annotations) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s\n\n", section.heading)
+		for _, a := range section.annotations {
+			fmt.Fprintf(&b, "- `%s:%d` %s", a.Path, a.Line, a.Title)
+			if a.Level == "failure" {
+				fmt.Fprintf(&b, ": %s", a.Message)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// sortCheckAnnotations orders annotations by file and line.
+func sortCheckAnnotations(annotations []github.CheckAnnotation) {
+	slices.SortFunc(annotations, func(a, b github.CheckAnnotation) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), cmp.Compare(a.Line, b.Line))
+	})
+}
@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/scanner"
+)
+
+func TestCheckPush(t *testing.T) {
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	cfg.PushCheckRuns = true
+	s := newTestScheduler(mock, cfg)
+	s.reconciler = NewReconciler(mock, s, cfg)
+
+	changed := testAnnotation()
+	changed.Path = ".github/workflows/ci.yml"
+	changed.Line = 3
+	registerTestJob(t, s, changed)
+	unchanged := testAnnotation()
+	unchanged.WorkflowFile = "other.yml"
+	unchanged.Path = ".github/workflows/other.yml"
+	registerTestJob(t, s, unchanged)
+	s.SetSkippedAnnotations([]scanner.SkippedAnnotation{
+		{Owner: "test-owner", Repo: "test-repo", WorkflowFile: "ci.yml", Path: ".github/workflows/ci.yml", Line: 1, CronExpr: "0 25 * * *", Reason: "invalid cron"},
+		{Owner: "test-owner", Repo: "other-repo", WorkflowFile: "ci.yml", Path: ".github/workflows/ci.yml", Line: 1, CronExpr: "bad", Reason: "invalid cron"},
+	})
+
+	repo := github.Repository{Owner: "test-owner", Name: "test-repo"}
+	if err := s.CheckPush(context.Background(), repo, "abc123", []string{".github/workflows/ci.yml"}); err != nil {
+		t.Fatalf("CheckPush: %v", err)
+	}
+
+	if len(mock.checkRuns) != 1 {
+		t.Fatalf("check runs: got %d, want 1", len(mock.checkRuns))
+	}
+	check := mock.checkRuns[0]
+	if check.Name != "ghacron" || check.HeadSHA != "abc123" || check.Conclusion != "failure" {
+		t.Errorf("check run = %s@%s/%s, want ghacron@abc123/failure", check.Name, check.HeadSHA, check.Conclusion)
+	}
+	if len(check.Annotations) != 2 {
+		t.Fatalf("annotations = %+v, want the skipped and the registered annotation of ci.yml", check.Annotations)
+	}
+	if a := check.Annotations[0]; a.Level != "failure" || a.Line != 1 || a.Message != "invalid cron" {
+		t.Errorf("first annotation = %+v, want the skipped annotation", a)
+	}
+	if a := check.Annotations[1]; a.Level != "notice" || a.Line != 3 {
+		t.Errorf("second annotation = %+v, want the registered annotation", a)
+	}
+	if !strings.Contains(check.Summary, "invalid cron") {
+		t.Errorf("summary %q should contain the skip reason", check.Summary)
+	}
+}
+
+func TestCheckPush_NoAnnotations(t *testing.T) {
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	cfg.PushCheckRuns = true
+	s := newTestScheduler(mock, cfg)
+	s.reconciler = NewReconciler(mock, s, cfg)
+	registerTestJob(t, s, testAnnotation())
+
+	repo := github.Repository{Owner: "test-owner", Name: "test-repo"}
+	if err := s.CheckPush(context.Background(), repo, "abc123", []string{".github/workflows/docs.yml"}); err != nil {
+		t.Fatalf("CheckPush: %v", err)
+	}
+	if len(mock.checkRuns) != 0 {
+		t.Errorf("check runs: got %d, want 0 without annotations in the changed files", len(mock.checkRuns))
+	}
+}
+
+func TestCheckPush_Disabled(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.Path = ".github/workflows/ci.yml"
+	registerTestJob(t, s, annotation)
+
+	repo := github.Repository{Owner: "test-owner", Name: "test-repo"}
+	if err := s.CheckPush(context.Background(), repo, "abc123", []string{".github/workflows/ci.yml"}); err != nil {
+		t.Fatalf("CheckPush: %v", err)
+	}
+	if len(mock.checkRuns) != 0 {
+		t.Errorf("check runs: got %d, want 0 when disabled", len(mock.checkRuns))
+	}
+}
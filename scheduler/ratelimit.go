@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// RateLimitProvider reports the GitHub API rate limit, including pauses after
+// secondary rate limits.
+type RateLimitProvider interface {
+	RateLimit() (github.RateLimit, bool)
+}
+
+// SetRateLimitProvider makes job runs wait while GitHub API requests are
+// paused by a rate limit, instead of each run failing during the pause.
+func (s *Scheduler) SetRateLimitProvider(provider RateLimitProvider) {
+	s.rateLimits = provider
+}
+
+// waitForRateLimit blocks while requests are paused by a rate limit, or
+// until shutdown.
+func (s *Scheduler) waitForRateLimit(ctx context.Context, annotation github.CronAnnotation) {
+	if s.rateLimits == nil {
+		return
+	}
+	rateLimit, ok := s.rateLimits.RateLimit()
+	if !ok || rateLimit.PausedUntil == nil {
+		return
+	}
+	wait := time.Until(*rateLimit.PausedUntil)
+	if wait <= 0 {
+		return
+	}
+	slog.InfoContext(ctx, "GitHub API rate limited, holding dispatch",
+		append(annotationLogArgs(annotation), "until", rateLimit.PausedUntil.Format(time.RFC3339))...)
+	sleep(wait, s.drain.done())
+}
@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+type fakeRateLimits struct {
+	pausedUntil *time.Time
+}
+
+func (f fakeRateLimits) RateLimit() (github.RateLimit, bool) {
+	return github.RateLimit{PausedUntil: f.pausedUntil}, true
+}
+
+func TestRunJob_WaitsForRateLimitPause(t *testing.T) {
+	var slept []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration, _ <-chan struct{}) bool {
+		slept = append(slept, d)
+		return true
+	}
+	t.Cleanup(func() { sleep = orig })
+
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	cfg.DuplicateGuardSeconds = 0
+	s := newTestScheduler(mock, cfg)
+
+	annotation := testAnnotation()
+	s.SetRateLimitProvider(fakeRateLimits{})
+	s.runJob(annotation, triggerManual, time.Time{})
+	if len(slept) != 0 {
+		t.Errorf("slept = %v without a pause, want no wait", slept)
+	}
+
+	until := time.Now().Add(time.Minute)
+	s.SetRateLimitProvider(fakeRateLimits{pausedUntil: &until})
+	s.runJob(annotation, triggerManual, time.Time{})
+
+	if mock.dispatchCalls != 2 {
+		t.Errorf("dispatch calls = %d, want 2", mock.dispatchCalls)
+	}
+	if len(slept) != 1 || slept[0] < 55*time.Second || slept[0] > time.Minute {
+		t.Errorf("slept = %v, want one wait of about a minute", slept)
+	}
+}
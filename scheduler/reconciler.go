@@ -3,10 +3,13 @@ package scheduler
 import (
 	"context"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/korosuke613/ghacron/config"
 	"github.com/korosuke613/ghacron/github"
 	"github.com/korosuke613/ghacron/scanner"
+	"github.com/korosuke613/ghacron/tracing"
 )
 
 // Reconciler applies diffs between desired state and actual state.
@@ -15,77 +18,278 @@ type Reconciler struct {
 	scheduler *Scheduler
 	scanner   *scanner.Scanner
 	config    *config.ReconcileConfig
+
+	mu          sync.Mutex // serializes full and per-repository reconciles
+	lastStateGC time.Time
+}
+
+// ReconcileSummary describes the job changes made by a reconcile.
+type ReconcileSummary struct {
+	// ReconcileID is attached to the log records of the reconcile.
+	ReconcileID    string   `json:"reconcile_id"`
+	Added          []string `json:"added"` // job IDs
+	Removed        []string `json:"removed"`
+	Updated        []string `json:"updated"`
+	RegisteredJobs int      `json:"registered_jobs"`
+	// FailedRepos is the number of repositories that could not be scanned;
+	// their jobs were left unchanged.
+	FailedRepos int `json:"failed_repos"`
 }
 
 // NewReconciler creates a new Reconciler.
 func NewReconciler(client GitHubClient, sched *Scheduler, cfg *config.ReconcileConfig) *Reconciler {
+	sc := scanner.New(client)
+	sc.SetBatchFetch(cfg.GraphQLScan)
+	sc.SetScheduleAliases(cfg.ScheduleAliases)
+	sc.SetOwnerTimezones(cfg.OwnerTimezones)
+	sc.SetShard(cfg.ShardIndex, cfg.ShardTotal)
+	sc.SetTopicFilter(cfg.RepoTopicFilter)
+	sc.SetSkipForks(cfg.SkipForks)
+	sc.SetScanBackoff(cfg.ScanBackoffMaxSkips)
 	return &Reconciler{
 		client:    client,
 		scheduler: sched,
-		scanner:   scanner.New(client),
+		scanner:   sc,
 		config:    cfg,
 	}
 }
 
 // Reconcile applies diffs between desired state (annotations) and actual state (registered cron jobs).
 func (r *Reconciler) Reconcile(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.reconcile(ctx)
+	return err
+}
+
+// TryReconcile runs a full reconcile unless another reconcile is running, in
+// which case it returns false without waiting.
+func (r *Reconciler) TryReconcile(ctx context.Context) (ReconcileSummary, bool, error) {
+	if !r.mu.TryLock() {
+		return ReconcileSummary{}, false, nil
+	}
+	defer r.mu.Unlock()
+
+	summary, err := r.reconcile(ctx)
+	return summary, true, err
+}
+
+// reconcile runs a full reconcile. The caller must hold r.mu.
+func (r *Reconciler) reconcile(ctx context.Context) (ReconcileSummary, error) {
+	ctx, span := tracing.Start(ctx, "reconcile")
+	defer span.End()
+
 	// 1. Discovery + Scan: collect annotations from all repositories
 	result, err := r.scanner.ScanAll(ctx)
 	if err != nil {
-		return err
+		span.SetError(err)
+		return ReconcileSummary{}, err
 	}
 
+	// Jobs of repositories that could not be scanned are kept as they are.
+	registeredKeys := r.scheduler.GetRegisteredKeys()
+	actualKeys := keysExcludingRepos(registeredKeys, result.FailedRepos)
+	annotations, limited := r.enforceJobLimits(ctx, result.Annotations, len(registeredKeys)-len(actualKeys))
+	annotations = r.staggerJobs(annotations, r.scheduler.registeredAnnotationsExcept(actualKeys))
+
 	// Update skipped annotations
-	r.scheduler.SetSkippedAnnotations(result.Skipped)
+	r.scheduler.SetSkippedAnnotations(append(result.Skipped, limited...))
+
+	r.updateConflicts(ctx, annotations)
+
+	summary := r.apply(ctx, annotations, actualKeys)
+	summary.RegisteredJobs = r.scheduler.GetRegisteredJobCount()
+	summary.FailedRepos = len(result.FailedRepos)
+	span.SetAttributes(
+		tracing.Int("ghacron.jobs.added", len(summary.Added)),
+		tracing.Int("ghacron.jobs.removed", len(summary.Removed)),
+		tracing.Int("ghacron.jobs.updated", len(summary.Updated)),
+		tracing.Int("ghacron.jobs.registered", summary.RegisteredJobs),
+		tracing.Int("ghacron.repos.failed", summary.FailedRepos),
+	)
+
+	if r.stateGCDue(time.Now()) {
+		r.collectStateGarbage(ctx, result.ScannedRepos)
+	}
+	return summary, nil
+}
 
+// ReconcileRepo re-scans a single repository and applies the diff to its jobs
+// only, leaving jobs of other repositories untouched. Repositories of other
+// shards are ignored.
+func (r *Reconciler) ReconcileRepo(ctx context.Context, repo github.Repository) error {
+	if !r.scanner.Owns(repo) {
+		slog.DebugContext(ctx, "repository belongs to another shard, skipping reconcile", "owner", repo.Owner, "repo", repo.Name)
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ctx, span := tracing.Start(ctx, "reconcile_repo",
+		tracing.String("ghacron.owner", repo.Owner),
+		tracing.String("ghacron.repo", repo.Name),
+	)
+	defer span.End()
+
+	result, err := r.scanner.ScanRepo(ctx, repo)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	var repoKeys []github.CronJobKey
+	var others []github.CronAnnotation
+	for _, key := range r.scheduler.GetRegisteredKeys() {
+		if key.Owner == repo.Owner && key.Repo == repo.Name {
+			repoKeys = append(repoKeys, key)
+			continue
+		}
+		if a, ok := r.scheduler.GetRegisteredAnnotation(key); ok {
+			others = append(others, a)
+		}
+	}
+	annotations, limited := r.enforceJobLimits(ctx, result.Annotations, len(others))
+	annotations = r.staggerJobs(annotations, others)
+
+	r.scheduler.replaceRepoSkipped(repo.Owner, repo.Name, append(result.Skipped, limited...))
+	r.updateConflicts(ctx, append(others, annotations...))
+
+	r.apply(ctx, annotations, repoKeys)
+	return nil
+}
+
+// keysExcludingRepos returns the keys that do not belong to any of repos.
+func keysExcludingRepos(keys []github.CronJobKey, repos []github.Repository) []github.CronJobKey {
+	if len(repos) == 0 {
+		return keys
+	}
+	excluded := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		excluded[repo.Owner+"/"+repo.Name] = true
+	}
+	var kept []github.CronJobKey
+	for _, key := range keys {
+		if !excluded[key.Owner+"/"+key.Repo] {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
+
+// apply registers, updates and removes jobs so that the jobs identified
+// by actualKeys match the desired annotations, and returns the changes made.
+func (r *Reconciler) apply(ctx context.Context, desired []github.CronAnnotation, actualKeys []github.CronJobKey) ReconcileSummary {
+	toAdd, toUpdate, toRemove := r.diff(desired, actualKeys)
+
+	// 4. Apply
+	summary := ReconcileSummary{Added: []string{}, Removed: []string{}, Updated: []string{}}
+	for _, annotation := range toUpdate {
+		previous, _ := r.scheduler.GetRegisteredAnnotation(annotation.Key())
+		if err := r.scheduler.UpdateJob(ctx, annotation); err != nil {
+			slog.ErrorContext(ctx, "failed to update job", "error", err)
+			continue
+		}
+		r.scheduler.migratePausedState(ctx, previous, annotation)
+		summary.Updated = append(summary.Updated, annotation.Key().ID())
+	}
+
+	for _, annotation := range toAdd {
+		if err := r.scheduler.AddJob(ctx, annotation); err != nil {
+			slog.ErrorContext(ctx, "failed to add job", "error", err)
+			continue
+		}
+		r.scheduler.loadPausedState(ctx, annotation)
+		r.scheduler.loadJobDispatchState(ctx, annotation)
+		summary.Added = append(summary.Added, annotation.Key().ID())
+	}
+
+	var removed []github.CronAnnotation
+	for _, key := range toRemove {
+		if annotation, ok := r.scheduler.GetRegisteredAnnotation(key); ok {
+			removed = append(removed, annotation)
+		}
+		r.scheduler.RemoveJob(ctx, key)
+		r.scheduler.forgetPaused(key)
+		summary.Removed = append(summary.Removed, key.ID())
+	}
+	r.deleteRemovedJobState(ctx, removed, desired)
+
+	r.scheduler.setWorkflowIDs(desired)
+
+	// 5. Log summary
+	if len(toAdd) > 0 || len(toRemove) > 0 || len(toUpdate) > 0 {
+		slog.InfoContext(ctx, "reconcile result",
+			"added", len(toAdd),
+			"removed", len(toRemove),
+			"updated", len(toUpdate),
+			"desired_total", len(desired),
+		)
+	}
+	return summary
+}
+
+// diff compares the desired annotations with the jobs identified by
+// actualKeys: toAdd are desired but not registered, toUpdate are registered
+// with other options (e.g. name) under the same key, and toRemove are
+// registered but no longer desired.
+func (r *Reconciler) diff(desired []github.CronAnnotation, actualKeys []github.CronJobKey) (toAdd, toUpdate []github.CronAnnotation, toRemove []github.CronJobKey) {
 	// 2. Build desired state map
 	desiredMap := make(map[github.CronJobKey]github.CronAnnotation)
-	for _, a := range result.Annotations {
+	for _, a := range desired {
 		desiredMap[a.Key()] = a
 	}
 
-	// 3. Get actual state (registered jobs)
-	actualKeys := r.scheduler.GetRegisteredKeys()
-
-	// 4. Diff: toAdd = desired - actual, toRemove = actual - desired
+	// 3. Diff: toAdd = desired - actual, toRemove = actual - desired
 	actualSet := make(map[github.CronJobKey]struct{})
 	for _, key := range actualKeys {
 		actualSet[key] = struct{}{}
 	}
 
-	var toAdd []github.CronAnnotation
 	for key, annotation := range desiredMap {
 		if _, exists := actualSet[key]; !exists {
 			toAdd = append(toAdd, annotation)
 		}
 	}
 
-	var toRemove []github.CronJobKey
 	for _, key := range actualKeys {
-		if _, exists := desiredMap[key]; !exists {
+		annotation, exists := desiredMap[key]
+		if !exists {
 			toRemove = append(toRemove, key)
+			continue
 		}
-	}
-
-	// 5. Apply
-	for _, annotation := range toAdd {
-		if err := r.scheduler.AddJob(annotation); err != nil {
-			slog.Error("failed to add job", "error", err)
+		// Options changed without changing the key: update in place.
+		if registered, ok := r.scheduler.GetRegisteredAnnotation(key); ok && !registered.SameConfig(annotation) {
+			toUpdate = append(toUpdate, annotation)
 		}
 	}
+	return toAdd, toUpdate, toRemove
+}
 
-	for _, key := range toRemove {
-		r.scheduler.RemoveJob(key)
-	}
+// updateConflicts detects schedule conflicts among the desired annotations,
+// warning about conflicts that were not present in the previous reconcile.
+func (r *Reconciler) updateConflicts(ctx context.Context, annotations []github.CronAnnotation) {
+	window := time.Duration(r.config.ConflictWindowSeconds) * time.Second
+	conflicts := detectConflicts(annotations, window, time.Now(), r.scheduler.location)
 
-	// 6. Log summary
-	if len(toAdd) > 0 || len(toRemove) > 0 {
-		slog.Info("reconcile result",
-			"added", len(toAdd),
-			"removed", len(toRemove),
-			"desired_total", len(desiredMap),
+	known := make(map[string]struct{})
+	for _, c := range r.scheduler.GetConflicts() {
+		known[c.id()] = struct{}{}
+	}
+	for _, c := range conflicts {
+		if _, exists := known[c.id()]; exists {
+			continue
+		}
+		slog.WarnContext(ctx, "conflicting schedules for the same workflow",
+			"owner", c.Owner,
+			"repo", c.Repo,
+			"workflow_file", c.WorkflowFile,
+			"cron_exprs", c.CronExprs[:],
+			"first_overlap", c.FirstOverlap.Format(time.RFC3339),
+			"gap", time.Duration(c.GapSeconds*float64(time.Second)).String(),
 		)
 	}
 
-	return nil
+	r.scheduler.SetConflicts(conflicts)
 }
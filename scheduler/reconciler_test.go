@@ -0,0 +1,177 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestReconcileRepo_OnlyTouchesRepo(t *testing.T) {
+	mock := &mockClient{
+		workflowFiles: []github.WorkflowFile{{
+			Name:    "ci.yml",
+			Path:    ".github/workflows/ci.yml",
+			Content: "on:\n  # ghacron: \"0 8 * * *\"\n  workflow_dispatch:\n",
+		}},
+	}
+	s := newTestScheduler(mock, defaultConfig())
+	s.reconciler = NewReconciler(mock, s, s.config)
+
+	other := github.CronAnnotation{Owner: "other", Repo: "repo", WorkflowFile: "ci.yml", CronExpr: "0 9 * * *"}
+	stale := github.CronAnnotation{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 7 * * *"}
+	registerTestJob(t, s, other)
+	registerTestJob(t, s, stale)
+
+	repo := github.Repository{Owner: "o", Name: "r", DefaultBranch: "main"}
+	if err := s.ReconcileRepo(context.Background(), repo); err != nil {
+		t.Fatalf("ReconcileRepo: %v", err)
+	}
+
+	want := map[github.CronJobKey]bool{
+		other.Key(): true,
+		{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 8 * * *"}: true,
+	}
+	keys := s.GetRegisteredKeys()
+	if len(keys) != len(want) {
+		t.Fatalf("registered keys = %v, want %d keys", keys, len(want))
+	}
+	for _, key := range keys {
+		if !want[key] {
+			t.Errorf("unexpected registered key %+v", key)
+		}
+	}
+}
+
+func TestKeysExcludingRepos(t *testing.T) {
+	keep := github.CronJobKey{Owner: "o", Repo: "a", WorkflowFile: "ci.yml", CronExpr: "0 8 * * *"}
+	drop := github.CronJobKey{Owner: "o", Repo: "b", WorkflowFile: "ci.yml", CronExpr: "0 8 * * *"}
+
+	got := keysExcludingRepos([]github.CronJobKey{keep, drop}, []github.Repository{{Owner: "o", Name: "b"}})
+	if len(got) != 1 || got[0] != keep {
+		t.Errorf("keysExcludingRepos = %v, want [%v]", got, keep)
+	}
+}
+
+func TestReconcileNow(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	s.reconciler = NewReconciler(mock, s, s.config)
+	stale := github.CronAnnotation{Owner: "o", Repo: "r", WorkflowFile: "ci.yml", CronExpr: "0 7 * * *"}
+	registerTestJob(t, s, stale)
+
+	// Another reconcile is running.
+	s.reconciler.mu.Lock()
+	if _, err := s.ReconcileNow(context.Background()); !errors.Is(err, ErrReconcileInProgress) {
+		t.Errorf("err = %v, want ErrReconcileInProgress", err)
+	}
+	s.reconciler.mu.Unlock()
+
+	if s.HasReconciled() {
+		t.Error("HasReconciled before any reconcile")
+	}
+	summary, err := s.ReconcileNow(context.Background())
+	if err != nil {
+		t.Fatalf("ReconcileNow: %v", err)
+	}
+	if !s.HasReconciled() {
+		t.Error("HasReconciled = false after a successful reconcile")
+	}
+	if len(summary.Removed) != 1 || summary.Removed[0] != stale.Key().ID() || summary.RegisteredJobs != 0 {
+		t.Errorf("summary = %+v, want the stale job removed", summary)
+	}
+	if summary.ReconcileID == "" {
+		t.Error("summary has no reconcile ID")
+	}
+}
+
+func TestApply_ReAddedJobIsNotPaused(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	s.reconciler = NewReconciler(mock, s, s.config)
+	annotation := testAnnotation()
+	ctx := context.Background()
+
+	s.reconciler.apply(ctx, []github.CronAnnotation{annotation}, nil)
+	if err := s.PauseJob(ctx, annotation.Key().ID()); err != nil {
+		t.Fatalf("PauseJob: %v", err)
+	}
+
+	// The annotation is removed, then added back.
+	s.reconciler.apply(ctx, nil, s.GetRegisteredKeys())
+	s.reconciler.apply(ctx, []github.CronAnnotation{annotation}, s.GetRegisteredKeys())
+
+	details := s.GetJobDetails()
+	if len(details) != 1 || details[0].Paused {
+		t.Errorf("details = %+v, want one unpaused job", details)
+	}
+}
+
+func TestApply_OptionChangeKeepsJobState(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	s.reconciler = NewReconciler(mock, s, s.config)
+	s.breaker = newBreaker(3, time.Hour)
+	annotation := testAnnotation()
+	ctx := context.Background()
+
+	s.reconciler.apply(ctx, []github.CronAnnotation{annotation}, nil)
+	dispatched := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	s.rememberDispatchState(annotation.Key(), DispatchState{Time: dispatched, LastAttempt: dispatched, Outcome: "success"})
+	s.breaker.recordFailure(annotation.Key(), dispatched)
+
+	changed := annotation
+	changed.Priority = github.PriorityHigh
+	summary := s.reconciler.apply(ctx, []github.CronAnnotation{changed}, s.GetRegisteredKeys())
+	if len(summary.Updated) != 1 {
+		t.Fatalf("summary = %+v, want the job updated", summary)
+	}
+
+	details := s.GetJobDetails()
+	if len(details) != 1 {
+		t.Fatalf("details = %+v, want one job", details)
+	}
+	if details[0].Priority != github.PriorityHigh {
+		t.Errorf("priority = %q, want %q", details[0].Priority, github.PriorityHigh)
+	}
+	if details[0].LastDispatch == nil || !details[0].LastDispatch.Time.Equal(dispatched) {
+		t.Errorf("last dispatch = %+v, want the state before the update", details[0].LastDispatch)
+	}
+	if details[0].ConsecutiveFailures != 1 {
+		t.Errorf("consecutive failures = %d, want 1", details[0].ConsecutiveFailures)
+	}
+	if got := len(s.cron.Entries()); got != 1 {
+		t.Errorf("cron entries = %d, want 1", got)
+	}
+}
+
+func TestApply_RenameKeepsPausedState(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	s.reconciler = NewReconciler(mock, s, s.config)
+	annotation := testAnnotation()
+	ctx := context.Background()
+
+	s.reconciler.apply(ctx, []github.CronAnnotation{annotation}, nil)
+	if err := s.PauseJob(ctx, annotation.Key().ID()); err != nil {
+		t.Fatalf("PauseJob: %v", err)
+	}
+
+	renamed := annotation
+	renamed.Name = "nightly"
+	s.reconciler.apply(ctx, []github.CronAnnotation{renamed}, s.GetRegisteredKeys())
+
+	sm := s.stateManager()
+	oldName, newName := sm.variableName(pausedKind, annotation), sm.variableName(pausedKind, renamed)
+	if _, ok := mock.vars[oldName]; ok {
+		t.Errorf("variable %s still exists after the rename", oldName)
+	}
+	if got := mock.vars[newName]; got != "true" {
+		t.Errorf("%s = %q, want true", newName, got)
+	}
+	if details := s.GetJobDetails(); len(details) != 1 || !details[0].Paused {
+		t.Errorf("details = %+v, want one paused job", details)
+	}
+}
@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/notify"
+)
+
+// runMatchWindow is how long after a dispatch a run created by a bot is
+// attributed to it when its run ID was not recorded (see matchRun).
+const runMatchWindow = time.Minute
+
+// CompletedRun is a completed workflow run reported by a workflow_run webhook.
+type CompletedRun struct {
+	Owner        string
+	Repo         string
+	WorkflowPath string // e.g. ".github/workflows/ci.yml"
+	ByBot        bool   // whether a bot (such as the App) triggered the run
+	Run          github.WorkflowRun
+}
+
+// RecordCompletedRun records the outcome of a completed run on the dispatch
+// that created it: its history record, the persisted dispatch state of the
+// job, and a notification if it did not succeed. It reports whether the run
+// was matched to a dispatch.
+func (s *Scheduler) RecordCompletedRun(ctx context.Context, run CompletedRun) bool {
+	rec, ok := s.history.matchRun(run)
+	if !ok {
+		return false
+	}
+	s.recordRun(rec.ID, run.Run)
+
+	slog.InfoContext(ctx, "recorded dispatched workflow run outcome",
+		"owner", rec.Owner,
+		"repo", rec.Repo,
+		"workflow_file", rec.WorkflowFile,
+		"ref", rec.Ref,
+		"run_id", run.Run.ID,
+		"conclusion", run.Run.Conclusion,
+	)
+
+	annotation, registered := s.findJob(rec.JobID)
+	if registered && !s.guardsByRuns(annotation) {
+		s.recordStateConclusion(ctx, annotation, rec.DispatchedAt, run.Run)
+	}
+	if s.notifier != nil {
+		s.notifyRunFailed(rec, run.Run)
+	}
+	return true
+}
+
+// matchRun returns the dispatch record of a completed run: the record with
+// its run ID or, if dispatch verification has not recorded it, the newest
+// successful dispatch of the workflow and ref without a run, made shortly
+// before a bot created the run.
+func (h *history) matchRun(run CompletedRun) (DispatchRecord, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := len(h.records) - 1; i >= 0; i-- {
+		if h.records[i].RunID == run.Run.ID {
+			return h.records[i], true
+		}
+	}
+	if !run.ByBot {
+		return DispatchRecord{}, false
+	}
+	workflowFile := path.Base(run.WorkflowPath)
+	for i := len(h.records) - 1; i >= 0; i-- {
+		rec := h.records[i]
+		if rec.Owner != run.Owner || rec.Repo != run.Repo || rec.WorkflowFile != workflowFile ||
+			rec.Error != "" || rec.RunID != 0 || (rec.Ref != "" && rec.Ref != run.Run.HeadBranch) {
+			continue
+		}
+		delay := run.Run.CreatedAt.Sub(rec.DispatchedAt)
+		if delay >= -runCreationSkew && delay <= runMatchWindow {
+			return rec, true
+		}
+	}
+	return DispatchRecord{}, false
+}
+
+// recordStateConclusion stores the run and its conclusion in the persisted
+// dispatch state, unless the state belongs to another dispatch or run.
+func (s *Scheduler) recordStateConclusion(ctx context.Context, annotation github.CronAnnotation, dispatchedAt time.Time, run github.WorkflowRun) {
+	sm := s.stateManager()
+	state, err := sm.GetDispatchState(ctx, annotation)
+	if err != nil || state.Outcome != outcomeSuccess || state.Time.After(dispatchedAt) ||
+		(state.RunID != 0 && state.RunID != run.ID) || state.RunConclusion == run.Conclusion {
+		return
+	}
+	state.RunID = run.ID
+	state.RunConclusion = run.Conclusion
+	if err := sm.SetDispatchState(ctx, annotation, state); err != nil {
+		slog.WarnContext(ctx, "failed to record run conclusion in dispatch state",
+			append(annotationLogArgs(annotation), "run_id", run.ID, "error", err)...,
+		)
+		return
+	}
+	s.rememberDispatchState(annotation.Key(), state)
+}
+
+// notifyRunFailed reports a dispatched run that did not succeed. Runs that
+// were cancelled or need approval are warnings.
+func (s *Scheduler) notifyRunFailed(rec DispatchRecord, run github.WorkflowRun) {
+	var severity notify.Severity
+	switch run.Conclusion {
+	case "success", "neutral", "skipped":
+		return
+	case "failure", "timed_out", "startup_failure":
+		severity = notify.SeverityError
+	default:
+		severity = notify.SeverityWarning
+	}
+	annotation := github.CronAnnotation{
+		Owner:        rec.Owner,
+		Repo:         rec.Repo,
+		WorkflowFile: rec.WorkflowFile,
+		Name:         rec.Name,
+		CronExpr:     rec.CronExpr,
+	}
+	s.notifier.Notify(notify.Event{
+		Severity: severity,
+		Kind:     notify.KindRunFailed,
+		Title:    "Workflow run did not succeed: " + jobName(annotation),
+		Text:     fmt.Sprintf("Conclusion: %s\nRef: %s\nTrigger: %s\nRun: %s", run.Conclusion, rec.Ref, rec.Trigger, run.HTMLURL),
+		Owner:    rec.Owner,
+		Repo:     rec.Repo,
+	})
+}
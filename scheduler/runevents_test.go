@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/notify"
+)
+
+func TestRecordCompletedRun(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	notifier, received := newTestNotifier(t)
+	s.SetNotifier(notifier)
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+	ctx := context.Background()
+
+	dispatchedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	if err := s.stateManager().SetDispatchState(ctx, annotation, successState(dispatchedAt)); err != nil {
+		t.Fatal(err)
+	}
+	id := s.recordDispatch(annotation, "main", triggerSchedule, time.Time{}, dispatchedAt, nil)
+
+	run := CompletedRun{
+		Owner:        "test-owner",
+		Repo:         "test-repo",
+		WorkflowPath: ".github/workflows/ci.yml",
+		ByBot:        true,
+		Run: github.WorkflowRun{
+			ID:         99,
+			HeadBranch: "main",
+			Status:     "completed",
+			Conclusion: "failure",
+			CreatedAt:  dispatchedAt.Add(2 * time.Second),
+		},
+	}
+	// Runs of other refs, or not started by a bot, are not attributed.
+	other := run
+	other.Run.HeadBranch = "develop"
+	if s.RecordCompletedRun(ctx, other) {
+		t.Error("a run of another ref should not match")
+	}
+	other = run
+	other.ByBot = false
+	if s.RecordCompletedRun(ctx, other) {
+		t.Error("a run triggered by a user should not match")
+	}
+
+	if !s.RecordCompletedRun(ctx, run) {
+		t.Fatal("the run should match the dispatch")
+	}
+	rec := s.GetHistory("")[0]
+	if rec.ID != id || rec.RunID != 99 || rec.Conclusion != "failure" {
+		t.Errorf("record = %+v, want run 99 concluded with failure", rec)
+	}
+	state, err := s.stateManager().GetDispatchState(ctx, annotation)
+	if err != nil || state.RunID != 99 || state.RunConclusion != "failure" {
+		t.Errorf("state = %+v, %v; want run 99 concluded with failure", state, err)
+	}
+
+	// The run ID now matches directly, e.g. for a redelivered event.
+	run.ByBot = false
+	run.Run.Conclusion = "success"
+	if !s.RecordCompletedRun(ctx, run) {
+		t.Error("the run should match its recorded run ID")
+	}
+
+	notifier.Wait(5 * time.Second)
+	if got := received.kinds()[notify.KindRunFailed]; got != 1 {
+		t.Errorf("run_failed notifications = %d, want 1", got)
+	}
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// duplicateGuardRuns is the GHACRON_RECONCILE_DUPLICATE_GUARD_MODE that
+// detects recent dispatches from workflow runs instead of state variables.
+const duplicateGuardRuns = "runs"
+
+// guardsByRuns reports whether the duplicate guard of a job looks at recent
+// workflow runs. In that mode dispatches write no state variables, so claims
+// and rollbacks do not apply. Runs created by repository_dispatch cannot be
+// attributed to a workflow file, so those jobs keep the variable guard.
+func (s *Scheduler) guardsByRuns(annotation github.CronAnnotation) bool {
+	return s.config.DuplicateGuardMode == duplicateGuardRuns && !annotation.IsRepositoryDispatch()
+}
+
+// dropRecentlyDispatchedRefs returns the refs on which ghacron has not
+// created a workflow_dispatch run within the duplicate guard. Refs whose runs
+// cannot be listed are kept, like a state variable that cannot be read.
+//
+// A run does not say which job dispatched it, so any run of the workflow on
+// the ref not started by a user counts: that of another annotation of the
+// same workflow or of a manual dispatch skips this job too.
+func (s *Scheduler) dropRecentlyDispatchedRefs(ctx context.Context, annotation github.CronAnnotation, refs []string) []string {
+	guard := time.Duration(s.config.DuplicateGuardSeconds) * time.Second
+	since := time.Now().Add(-guard)
+
+	var due []string
+	for _, ref := range refs {
+		run, found, err := s.client.FindDispatchedRun(ctx, annotation.Owner, annotation.Repo, annotation.WorkflowFile, ref, since)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to list recent workflow runs for the duplicate guard",
+				append(annotationLogArgs(annotation), "ref", ref, "error", err)...,
+			)
+			due = append(due, ref)
+			continue
+		}
+		if found {
+			slog.InfoContext(ctx, "duplicate guard: already dispatched",
+				append(annotationLogArgs(annotation),
+					"ref", ref,
+					"run_id", run.ID,
+					"guard", guard.String(),
+				)...,
+			)
+			continue
+		}
+		due = append(due, ref)
+	}
+	return due
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+func TestHandler_RunsGuard(t *testing.T) {
+	tests := map[string]struct {
+		recentRun    github.WorkflowRun
+		wantDispatch int
+	}{
+		"no recent run": {wantDispatch: 1},
+		"recent run":    {recentRun: github.WorkflowRun{ID: 42}, wantDispatch: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := &mockClient{dispatchedRun: tt.recentRun}
+			cfg := defaultConfig()
+			cfg.DuplicateGuardMode = duplicateGuardRuns
+			s := newTestScheduler(mock, cfg)
+
+			s.createJobHandler(testAnnotation())()
+
+			if mock.dispatchCalls != tt.wantDispatch {
+				t.Errorf("DispatchWorkflow call count: got %d, want %d", mock.dispatchCalls, tt.wantDispatch)
+			}
+			if mock.getVarCalls != 0 || mock.setVarCalls != 0 {
+				t.Errorf("variable calls: got %d reads and %d writes, want none with the run-based guard", mock.getVarCalls, mock.setVarCalls)
+			}
+		})
+	}
+}
+
+func TestHandler_RunsGuard_RepositoryDispatch(t *testing.T) {
+	mock := &mockClient{dispatchedRun: github.WorkflowRun{ID: 42}}
+	cfg := defaultConfig()
+	cfg.DuplicateGuardMode = duplicateGuardRuns
+	s := newTestScheduler(mock, cfg)
+	annotation := testAnnotation()
+	annotation.DispatchType = github.DispatchTypeRepository
+	annotation.EventType = "nightly"
+
+	s.createJobHandler(annotation)()
+
+	// repository_dispatch runs cannot be matched, so the variable guard is used.
+	if mock.setVarCalls != 1 {
+		t.Errorf("SetVariable call count: got %d, want 1", mock.setVarCalls)
+	}
+}
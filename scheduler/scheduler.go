@@ -1,27 +1,61 @@
 package scheduler
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"path"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/korosuke613/ghacron/config"
 	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/notify"
 	"github.com/korosuke613/ghacron/scanner"
+	"github.com/korosuke613/ghacron/sentry"
+	"github.com/korosuke613/ghacron/tracing"
 
 	"github.com/robfig/cron/v3"
 )
 
+// sleep is the delay function used for splay and jitter (replaced in tests).
+// It returns false if the delay was cut short by shutdown.
+var sleep = sleepOrStop
+
 // GitHubClient is the GitHub API interface used by the scheduler.
 type GitHubClient interface {
-	DispatchWorkflow(ctx context.Context, owner, repo, workflowFile, ref string) error
+	DispatchWorkflow(ctx context.Context, owner, repo, workflowFile, ref, inputs string) error
+	DispatchWorkflowByID(ctx context.Context, owner, repo string, workflowID int64, ref, inputs string) error
+	RepositoryDispatch(ctx context.Context, owner, repo, eventType, payload string) error
 	GetVariable(ctx context.Context, owner, repo, name string) (string, error)
 	SetVariable(ctx context.Context, owner, repo, name, value string) error
+	ListVariables(ctx context.Context, owner, repo string) ([]string, error)
+	DeleteVariable(ctx context.Context, owner, repo, name string) error
+	GetOrgVariable(ctx context.Context, org, name string) (string, error)
+	SetOrgVariable(ctx context.Context, org, name, value string) error
+	ListOrgVariables(ctx context.Context, org string) ([]string, error)
+	DeleteOrgVariable(ctx context.Context, org, name string) error
+	GetFile(ctx context.Context, owner, repo, path, branch string) (content []byte, sha string, found bool, err error)
+	PutFile(ctx context.Context, owner, repo, path, branch, message string, content []byte, sha string) error
+	CreateOrphanBranch(ctx context.Context, owner, repo, branch, path, message string, content []byte) error
 	GetInstallationRepos(ctx context.Context) ([]github.Repository, error)
-	GetWorkflowFiles(ctx context.Context, owner, repo string) ([]github.WorkflowFile, error)
-	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	GetWorkflowContents(ctx context.Context, owner, repo, ref string) ([]github.WorkflowFile, error)
+	ListBranches(ctx context.Context, owner, repo string) ([]string, error)
+	HasActiveRun(ctx context.Context, owner, repo, workflowFile, ref string) (bool, error)
+	FindDispatchedRun(ctx context.Context, owner, repo, workflowFile, ref string, since time.Time) (github.WorkflowRun, bool, error)
+	GetWorkflowRun(ctx context.Context, owner, repo string, runID int64) (github.WorkflowRun, error)
+	ListWorkflows(ctx context.Context, owner, repo string) ([]github.Workflow, error)
+	GetHeadSHA(ctx context.Context, owner, repo, ref, lastSHA string) (string, error)
+	GetWorkflowContentsBatch(ctx context.Context, repos []github.Repository) (map[string]github.RepoWorkflows, error)
+	CreateCheckRun(ctx context.Context, owner, repo string, check github.CheckRun) error
+	FindOpenIssue(ctx context.Context, owner, repo, label, title string) (github.Issue, bool, error)
+	CreateIssue(ctx context.Context, owner, repo, title, body string, labels []string) (github.Issue, error)
+	CommentOnIssue(ctx context.Context, owner, repo string, number int, body string) error
 }
 
 // Scheduler manages cron jobs.
@@ -30,35 +64,141 @@ type Scheduler struct {
 	reconciler *Reconciler
 	cron       *cron.Cron
 	config     *config.ReconcileConfig
+	location   *time.Location
+	splay      *splayer          // nil when splay is disabled
+	limiter    *dispatchLimiter  // nil when concurrency is unlimited
+	throttle   *dispatchThrottle // nil when the dispatch rate is unlimited
+	deadman    *deadman          // nil when dead-man alerting is disabled
+	breaker    *breaker          // nil when the circuit breaker is disabled
+	issues     *failureCounter   // nil when failure issues are disabled
+	stateCache *stateCache       // nil when state caching is disabled
+	gitState   *gitStateStore    // nil unless state is stored in a state file
+	drain      *drainer
+	history    *history
+	drift      *driftTracker
+
+	// Cron engine heartbeat (see startHeartbeat).
+	heartbeatID   cron.EntryID
+	lastHeartbeat atomic.Int64 // UnixNano
+
+	audit    *AuditLog        // nil when the audit log is disabled
+	notifier *notify.Notifier // nil when notifications are disabled
+
+	sentry         *sentry.Client  // nil when error reporting is disabled
+	sentryFailures *failureCounter // consecutive failures reported to Sentry
+
+	rateLimits RateLimitProvider // nil when rate limit pauses are not followed
 
 	mu                 sync.RWMutex
-	registeredJobs     map[github.CronJobKey]cron.EntryID
+	registeredJobs     map[github.CronJobKey]registeredJob
+	paused             map[github.CronJobKey]struct{}
+	dispatchStates     map[github.CronJobKey]DispatchState // last known persisted state
+	workflowIDs        map[github.CronJobKey]int64         // workflow IDs resolved by the last scan
 	lastReconcile      time.Time
+	reconciled         bool // a full reconcile has succeeded
 	skippedAnnotations []scanner.SkippedAnnotation
+	skippedKnown       bool // skippedAnnotations holds the result of a full scan
+	conflicts          []ScheduleConflict
+}
+
+// registeredJob is a cron entry together with the annotation it was created from.
+type registeredJob struct {
+	entryID      cron.EntryID
+	annotation   github.CronAnnotation
+	registeredAt time.Time
 }
 
 // New creates a new Scheduler.
 func New(client GitHubClient, cfg *config.ReconcileConfig, loc *time.Location) *Scheduler {
-	// 5-field standard cron (no WithSeconds)
-	c := cron.New(cron.WithLocation(loc))
+	c := newCron(loc)
 
 	s := &Scheduler{
 		client:         client,
 		cron:           c,
 		config:         cfg,
-		registeredJobs: make(map[github.CronJobKey]cron.EntryID),
+		location:       loc,
+		registeredJobs: make(map[github.CronJobKey]registeredJob),
+		paused:         make(map[github.CronJobKey]struct{}),
+		dispatchStates: make(map[github.CronJobKey]DispatchState),
+		workflowIDs:    make(map[github.CronJobKey]int64),
+		drain:          newDrainer(),
+		history:        newHistory(historySize),
+		drift:          newDriftTracker(),
+	}
+
+	if cfg.DispatchSplaySeconds > 0 {
+		s.splay = newSplayer(time.Duration(cfg.DispatchSplaySeconds) * time.Second)
+	}
+
+	if cfg.DeadmanGraceSeconds > 0 {
+		s.deadman = newDeadman(time.Duration(cfg.DeadmanGraceSeconds)*time.Second, cfg.DeadmanWebhookURL)
+	}
+
+	if cfg.StateCacheSeconds > 0 {
+		s.stateCache = newStateCache(time.Duration(cfg.StateCacheSeconds) * time.Second)
+	}
+
+	if cfg.StateScope == stateScopeGit {
+		s.gitState = newGitStateStore(client, cfg.StateGitRepo, cfg.StateGitBranch, cfg.StateGitPath)
+	}
+
+	if cfg.BreakerThreshold > 0 {
+		s.breaker = newBreaker(cfg.BreakerThreshold, time.Duration(cfg.BreakerCooldownMinutes)*time.Minute)
+	}
+
+	if cfg.FailureIssueThreshold > 0 {
+		s.issues = newFailureCounter(cfg.FailureIssueThreshold)
+	}
+
+	if cfg.MaxConcurrentDispatches > 0 || cfg.MaxConcurrentDispatchesPerRepo > 0 {
+		s.limiter = newDispatchLimiter(cfg.MaxConcurrentDispatches, cfg.MaxConcurrentDispatchesPerRepo)
+	}
+	if cfg.DispatchRatePerMinute > 0 {
+		s.throttle = newDispatchThrottle(cfg.DispatchRatePerMinute, cfg.DispatchRateBurst, cfg.DispatchQueueMax)
 	}
 
 	s.reconciler = NewReconciler(client, s, cfg)
 
+	s.startHeartbeat()
 	c.Start()
 	slog.Info("cron scheduler started")
 
 	return s
 }
 
+// newCron creates a 5-field standard cron engine (no WithSeconds). Handlers are
+// wrapped so a panic is logged instead of crashing the process, and a tick is
+// skipped while the previous run of the same entry (e.g. one sleeping for
+// jitter or waiting on a slow API) is still in progress.
+func newCron(loc *time.Location) *cron.Cron {
+	return cron.New(
+		cron.WithLocation(loc),
+		cron.WithLogger(cronLogger{}),
+		cron.WithChain(jobWrappers()...),
+	)
+}
+
+// jobWrappers returns the wrappers applied to every cron job handler.
+func jobWrappers() []cron.JobWrapper {
+	return []cron.JobWrapper{
+		cron.Recover(cronLogger{}),
+		cron.SkipIfStillRunning(cronLogger{}),
+	}
+}
+
+// cronLogger adapts slog to the cron.Logger interface.
+type cronLogger struct{}
+
+func (cronLogger) Info(msg string, keysAndValues ...any) {
+	slog.Debug("cron: "+msg, keysAndValues...)
+}
+
+func (cronLogger) Error(err error, msg string, keysAndValues ...any) {
+	slog.Error("cron: "+msg, append([]any{"error", err}, keysAndValues...)...)
+}
+
 // AddJob registers a cron job.
-func (s *Scheduler) AddJob(annotation github.CronAnnotation) error {
+func (s *Scheduler) AddJob(ctx context.Context, annotation github.CronAnnotation) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -71,36 +211,79 @@ func (s *Scheduler) AddJob(annotation github.CronAnnotation) error {
 
 	handler := s.createJobHandler(annotation)
 
-	entryID, err := s.cron.AddFunc(annotation.CronExpr, handler)
+	entryID, err := s.scheduleJob(annotation, handler)
 	if err != nil {
 		return fmt.Errorf("failed to add cron job (%s/%s/%s %q): %w",
 			annotation.Owner, annotation.Repo, annotation.WorkflowFile, annotation.CronExpr, err)
 	}
 
-	s.registeredJobs[key] = entryID
-	slog.Info("registered cron job",
-		"owner", annotation.Owner,
-		"repo", annotation.Repo,
-		"workflow_file", annotation.WorkflowFile,
-		"cron_expr", annotation.CronExpr,
-	)
+	s.registeredJobs[key] = registeredJob{entryID: entryID, annotation: annotation, registeredAt: time.Now()}
+	logArgs := append(annotationLogArgs(annotation), "cron_expr", annotation.CronExpr)
+	if annotation.Stagger > 0 {
+		logArgs = append(logArgs, "stagger", annotation.Stagger.String())
+	}
+	slog.InfoContext(ctx, "registered cron job", logArgs...)
+
+	return nil
+}
+
+// scheduleJob adds the cron entry of a job, offset by its stagger.
+func (s *Scheduler) scheduleJob(annotation github.CronAnnotation, handler func()) (cron.EntryID, error) {
+	if annotation.Stagger <= 0 {
+		return s.cron.AddFunc(annotation.CronExpr, handler)
+	}
+	schedule, err := cron.ParseStandard(annotation.CronExpr)
+	if err != nil {
+		return 0, err
+	}
+	return s.cron.Schedule(staggeredSchedule{schedule: schedule, offset: annotation.Stagger}, cron.FuncJob(handler)), nil
+}
+
+// UpdateJob applies option changes to a registered job whose key is
+// unchanged. The cron entry is replaced in place, so the job keeps its
+// dispatch, pause, circuit breaker, drift and deadman state.
+func (s *Scheduler) UpdateJob(ctx context.Context, annotation github.CronAnnotation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := annotation.Key()
+	job, exists := s.registeredJobs[key]
+	if !exists {
+		return fmt.Errorf("job not registered: %s", key.ID())
+	}
+
+	entryID, err := s.scheduleJob(annotation, s.createJobHandler(annotation))
+	if err != nil {
+		return fmt.Errorf("failed to update cron job (%s/%s/%s %q): %w",
+			annotation.Owner, annotation.Repo, annotation.WorkflowFile, annotation.CronExpr, err)
+	}
+	s.cron.Remove(job.entryID)
+
+	s.registeredJobs[key] = registeredJob{entryID: entryID, annotation: annotation, registeredAt: job.registeredAt}
+	slog.InfoContext(ctx, "updated cron job", append(annotationLogArgs(annotation), "cron_expr", annotation.CronExpr)...)
 
 	return nil
 }
 
 // RemoveJob removes a cron job.
-func (s *Scheduler) RemoveJob(key github.CronJobKey) {
+func (s *Scheduler) RemoveJob(ctx context.Context, key github.CronJobKey) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if entryID, exists := s.registeredJobs[key]; exists {
-		s.cron.Remove(entryID)
+	if job, exists := s.registeredJobs[key]; exists {
+		s.cron.Remove(job.entryID)
 		delete(s.registeredJobs, key)
-		slog.Info("removed cron job",
-			"owner", key.Owner,
-			"repo", key.Repo,
-			"workflow_file", key.WorkflowFile,
-			"cron_expr", key.CronExpr,
+		delete(s.dispatchStates, key)
+		delete(s.workflowIDs, key)
+		if s.deadman != nil {
+			s.deadman.forget(key)
+		}
+		s.drift.forget(key)
+		if s.breaker != nil {
+			s.breaker.reset(key)
+		}
+		slog.InfoContext(ctx, "removed cron job",
+			append(annotationLogArgs(job.annotation), "cron_expr", key.CronExpr)...,
 		)
 	}
 }
@@ -112,6 +295,13 @@ func (s *Scheduler) GetRegisteredJobCount() int {
 	return len(s.registeredJobs)
 }
 
+// CronEntryCount returns the number of entries of the cron engine, including
+// the heartbeat entry (StatusProvider). It exceeds the registered job count
+// by one unless entries leak.
+func (s *Scheduler) CronEntryCount() int {
+	return len(s.cron.Entries())
+}
+
 // GetLastReconcileTime returns the last reconcile timestamp (StatusProvider).
 func (s *Scheduler) GetLastReconcileTime() time.Time {
 	s.mu.RLock()
@@ -119,13 +309,51 @@ func (s *Scheduler) GetLastReconcileTime() time.Time {
 	return s.lastReconcile
 }
 
+// HasReconciled reports whether a full reconcile has succeeded, i.e. the
+// registered jobs reflect the annotations (StatusProvider).
+func (s *Scheduler) HasReconciled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.reconciled
+}
+
 // JobDetail holds detailed information about a registered job.
 type JobDetail struct {
-	Owner        string    `json:"owner"`
-	Repo         string    `json:"repo"`
-	WorkflowFile string    `json:"workflow_file"`
-	CronExpr     string    `json:"cron_expr"`
-	NextRun      time.Time `json:"next_run"`
+	ID           string      `json:"id"`
+	Name         string      `json:"name,omitempty"`
+	Owner        string      `json:"owner"`
+	Repo         string      `json:"repo"`
+	WorkflowFile string      `json:"workflow_file"`
+	CronExpr     string      `json:"cron_expr"`
+	Alias        string      `json:"schedule_alias,omitempty"`
+	HashExpr     string      `json:"hash_expr,omitempty"`
+	RefPattern   string      `json:"refs,omitempty"`
+	Jitter       string      `json:"jitter,omitempty"`
+	Stagger      string      `json:"stagger,omitempty"`
+	Overlap      string      `json:"overlap"`
+	Timeout      string      `json:"timeout"`
+	DispatchType string      `json:"type"`
+	EventType    string      `json:"event,omitempty"`
+	Priority     string      `json:"priority"`
+	NextRun      time.Time   `json:"next_run"`
+	NextRuns     []time.Time `json:"next_runs"`
+	// Timezone is the time zone the schedule is evaluated in (its CRON_TZ=
+	// prefix, else the scheduler's), and NextRunUTC and NextRunLocal are the
+	// next run in UTC and in that time zone.
+	Timezone     string    `json:"timezone"`
+	NextRunUTC   time.Time `json:"next_run_utc"`
+	NextRunLocal time.Time `json:"next_run_local"`
+	Paused       bool      `json:"paused"`
+	// LastDispatch is the persisted state of the last dispatch (nil if never dispatched).
+	LastDispatch *DispatchState `json:"last_dispatch,omitempty"`
+	// Circuit breaker state (see breaker).
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Tripped             bool       `json:"tripped"`
+	TrippedUntil        *time.Time `json:"tripped_until,omitempty"`
+	// Recent delays of dispatches and of the creation of their workflow runs
+	// after the scheduled time (nil without samples).
+	DispatchDrift *DriftStats `json:"dispatch_drift,omitempty"`
+	RunStartDrift *DriftStats `json:"run_start_drift,omitempty"`
 }
 
 // GetJobDetails returns details of all registered jobs (StatusProvider).
@@ -134,19 +362,80 @@ func (s *Scheduler) GetJobDetails() []JobDetail {
 	defer s.mu.RUnlock()
 
 	details := make([]JobDetail, 0, len(s.registeredJobs))
-	for key, entryID := range s.registeredJobs {
-		entry := s.cron.Entry(entryID)
-		details = append(details, JobDetail{
+	for key, job := range s.registeredJobs {
+		entry := s.cron.Entry(job.entryID)
+		_, paused := s.paused[key]
+		detail := JobDetail{
+			ID:           key.ID(),
+			Name:         job.annotation.Name,
 			Owner:        key.Owner,
 			Repo:         key.Repo,
 			WorkflowFile: key.WorkflowFile,
 			CronExpr:     key.CronExpr,
+			Alias:        job.annotation.Alias,
+			HashExpr:     job.annotation.HashExpr,
+			RefPattern:   job.annotation.RefPattern,
+			Jitter:       formatDelay(job.annotation.Jitter),
+			Stagger:      formatDelay(job.annotation.Stagger),
+			Overlap:      s.overlapPolicy(job.annotation),
+			Timeout:      s.handlerTimeout(job.annotation).String(),
+			DispatchType: dispatchType(job.annotation),
+			EventType:    job.annotation.EventType,
+			Priority:     cmp.Or(job.annotation.Priority, github.PriorityNormal),
 			NextRun:      entry.Next,
-		})
+			NextRuns:     s.upcomingRuns(entry, upcomingRunCount),
+			Paused:       paused,
+		}
+		loc := s.jobLocation(job.annotation)
+		detail.Timezone = loc.String()
+		if len(detail.NextRuns) > 0 {
+			detail.NextRunUTC = detail.NextRuns[0].UTC()
+			detail.NextRunLocal = detail.NextRuns[0].In(loc)
+		}
+		if state, ok := s.dispatchStates[key]; ok && !state.LastAttempt.IsZero() {
+			state.Nonce = ""
+			detail.LastDispatch = &state
+		}
+		if s.breaker != nil {
+			failures, until := s.breaker.state(key)
+			detail.ConsecutiveFailures = failures
+			if !until.IsZero() {
+				detail.Tripped = true
+				detail.TrippedUntil = &until
+			}
+		}
+		detail.DispatchDrift, detail.RunStartDrift = s.drift.stats(key)
+		details = append(details, detail)
 	}
 	return details
 }
 
+// upcomingRunCount is the number of upcoming fire times listed per job.
+const upcomingRunCount = 5
+
+// upcomingRuns returns the next n fire times of a cron entry, starting at its
+// scheduled next run (or from now if the cron engine has not started yet).
+func (s *Scheduler) upcomingRuns(entry cron.Entry, n int) []time.Time {
+	if entry.Schedule == nil {
+		return nil
+	}
+
+	next := entry.Next
+	if next.IsZero() {
+		now := time.Now()
+		if s.location != nil {
+			now = now.In(s.location)
+		}
+		next = entry.Schedule.Next(now)
+	}
+
+	runs := make([]time.Time, 0, n)
+	for ; !next.IsZero() && len(runs) < n; next = entry.Schedule.Next(next) {
+		runs = append(runs, next)
+	}
+	return runs
+}
+
 // GetRegisteredKeys returns all registered job keys.
 func (s *Scheduler) GetRegisteredKeys() []github.CronJobKey {
 	s.mu.RLock()
@@ -159,11 +448,27 @@ func (s *Scheduler) GetRegisteredKeys() []github.CronJobKey {
 	return keys
 }
 
+// GetRegisteredAnnotation returns the annotation a registered job was created from.
+func (s *Scheduler) GetRegisteredAnnotation(key github.CronJobKey) (github.CronAnnotation, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, exists := s.registeredJobs[key]
+	return job.annotation, exists
+}
+
 // SetSkippedAnnotations updates the skipped annotations from the last scan.
+// Changes are notified, except for the first scan, which sets the baseline.
 func (s *Scheduler) SetSkippedAnnotations(skipped []scanner.SkippedAnnotation) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	before, known := s.skippedAnnotations, s.skippedKnown
 	s.skippedAnnotations = skipped
+	s.skippedKnown = true
+	s.mu.Unlock()
+
+	if known {
+		s.notifySkippedChanges(before, skipped)
+	}
 }
 
 // GetSkippedAnnotations returns annotations that failed validation (StatusProvider).
@@ -173,10 +478,75 @@ func (s *Scheduler) GetSkippedAnnotations() []scanner.SkippedAnnotation {
 	return s.skippedAnnotations
 }
 
+// GetDegradedRepos returns the repositories whose last scan failed
+// (StatusProvider).
+func (s *Scheduler) GetDegradedRepos() []scanner.DegradedRepo {
+	if s.reconciler == nil {
+		return []scanner.DegradedRepo{}
+	}
+	return s.reconciler.scanner.DegradedRepos()
+}
+
+// GetRepoStatuses returns the scan status of the scanned repositories
+// (StatusProvider).
+func (s *Scheduler) GetRepoStatuses() []scanner.RepoStatus {
+	if s.reconciler == nil {
+		return []scanner.RepoStatus{}
+	}
+	return s.reconciler.scanner.RepoStatuses()
+}
+
+// replaceRepoSkipped replaces the skipped annotations of a single repository.
+func (s *Scheduler) replaceRepoSkipped(owner, repo string, skipped []scanner.SkippedAnnotation) {
+	s.mu.Lock()
+	kept := make([]scanner.SkippedAnnotation, 0, len(s.skippedAnnotations)+len(skipped))
+	var before []scanner.SkippedAnnotation
+	for _, sk := range s.skippedAnnotations {
+		if sk.Owner != owner || sk.Repo != repo {
+			kept = append(kept, sk)
+		} else {
+			before = append(before, sk)
+		}
+	}
+	s.skippedAnnotations = append(kept, skipped...)
+	known := s.skippedKnown
+	s.mu.Unlock()
+
+	if known {
+		s.notifySkippedChanges(before, skipped)
+	}
+}
+
+// SetConflicts updates the schedule conflicts detected in the last reconcile.
+func (s *Scheduler) SetConflicts(conflicts []ScheduleConflict) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conflicts = conflicts
+}
+
+// GetConflicts returns schedule conflicts from the last reconcile (StatusProvider).
+func (s *Scheduler) GetConflicts() []ScheduleConflict {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conflicts
+}
+
 // RunReconcileLoop runs the reconciliation loop.
 func (s *Scheduler) RunReconcileLoop(ctx context.Context, interval time.Duration) {
-	// Run immediately on startup
-	s.runReconcile(ctx)
+	switch s.config.StartupReconcile {
+	case "delay":
+		delay := rand.N(interval)
+		slog.InfoContext(ctx, "delaying the first reconciliation", "delay", delay.String())
+		if !sleep(delay, ctx.Done()) {
+			slog.InfoContext(ctx, "reconciliation loop stopped")
+			return
+		}
+		s.runReconcile(ctx)
+	case "skip":
+		slog.InfoContext(ctx, "skipping the startup reconciliation, waiting for the first tick", "interval", interval.String())
+	default:
+		s.runReconcile(ctx)
+	}
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -184,7 +554,7 @@ func (s *Scheduler) RunReconcileLoop(ctx context.Context, interval time.Duration
 	for {
 		select {
 		case <-ctx.Done():
-			slog.Info("reconciliation loop stopped")
+			slog.InfoContext(ctx, "reconciliation loop stopped")
 			return
 		case <-ticker.C:
 			s.runReconcile(ctx)
@@ -192,75 +562,420 @@ func (s *Scheduler) RunReconcileLoop(ctx context.Context, interval time.Duration
 	}
 }
 
+// ReconcileRepo immediately re-scans a single repository and applies changes
+// to its jobs (e.g. after a push to its workflow files).
+func (s *Scheduler) ReconcileRepo(ctx context.Context, repo github.Repository) error {
+	ctx, _ = withReconcileID(ctx)
+	slog.InfoContext(ctx, "repository reconciliation started", "owner", repo.Owner, "repo", repo.Name)
+	if err := s.reconciler.ReconcileRepo(ctx, repo); err != nil {
+		s.notifyReconcileFailed(repo.Owner, repo.Name, err)
+		s.reportReconcileError(repo.Owner, repo.Name, err)
+		return fmt.Errorf("failed to reconcile %s/%s: %w", repo.Owner, repo.Name, err)
+	}
+	s.saveSnapshot()
+	return nil
+}
+
 func (s *Scheduler) runReconcile(ctx context.Context) {
-	slog.Info("reconciliation started")
+	ctx, _ = withReconcileID(ctx)
+	slog.InfoContext(ctx, "reconciliation started")
 	start := time.Now()
 
-	if err := s.reconciler.Reconcile(ctx); err != nil {
-		slog.Error("reconciliation failed", "error", err)
+	err := s.reconciler.Reconcile(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "reconciliation failed", "error", err)
+		s.notifyReconcileFailed("", "", err)
+		s.reportReconcileError("", "", err)
 	}
+	s.finishReconcile(ctx, start, err == nil)
+}
 
+// finishReconcile records the time of a full reconcile that began at start.
+func (s *Scheduler) finishReconcile(ctx context.Context, start time.Time, succeeded bool) {
 	s.mu.Lock()
 	s.lastReconcile = time.Now()
+	if succeeded {
+		s.reconciled = true
+	}
 	s.mu.Unlock()
+	if succeeded {
+		s.saveSnapshot()
+	}
 
-	slog.Info("reconciliation completed",
+	slog.InfoContext(ctx, "reconciliation completed",
 		"duration", time.Since(start).String(),
 		"registered_jobs", s.GetRegisteredJobCount(),
 	)
 }
 
 // Stop stops the scheduler.
+// Pending splay/jitter delays are abandoned, and runs already talking to the
+// GitHub API get up to the shutdown timeout to finish (including rollback).
 func (s *Scheduler) Stop() {
-	ctx := s.cron.Stop()
-	<-ctx.Done()
+	s.cron.Stop()
+
+	timeout := time.Duration(s.config.ShutdownTimeoutSeconds) * time.Second
+	if !s.drain.drain(timeout) {
+		slog.Warn("shutdown timeout exceeded, abandoning in-flight dispatches",
+			"timeout", timeout.String(),
+		)
+	}
 	slog.Info("cron scheduler stopped")
 }
 
 // createJobHandler creates a job handler for dispatching workflows.
 func (s *Scheduler) createJobHandler(annotation github.CronAnnotation) func() {
 	return func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+		s.handleTick(annotation, s.scheduledTime(annotation.Key()))
+	}
+}
 
-		stateManager := NewStateManager(s.client)
+// handleTick handles the cron tick of a job scheduled at scheduledAt.
+func (s *Scheduler) handleTick(annotation github.CronAnnotation, scheduledAt time.Time) {
+	if !s.drain.begin() {
+		return
+	}
+	defer s.drain.end()
+	defer s.reportPanic(annotation)
+
+	if s.isPaused(annotation.Key()) {
+		slog.Info("job is paused, skipping dispatch", annotationLogArgs(annotation)...)
+		s.auditSkipped(annotation, triggerSchedule, skipPaused)
+		return
+	}
+
+	if !s.applySplay(annotation) || !s.applyJitter(annotation) {
+		slog.Info("shutting down, dropping delayed dispatch", annotationLogArgs(annotation)...)
+		s.auditSkipped(annotation, triggerSchedule, skipShutdown)
+		return
+	}
+
+	s.runJob(annotation, triggerSchedule, scheduledAt)
+}
+
+// runJob dispatches a job subject to the concurrency limits and the duplicate
+// guard. It is shared by cron ticks and manual triggers (see trigger* constants);
+// scheduledAt is the time of the cron tick (zero for manual runs).
+func (s *Scheduler) runJob(annotation github.CronAnnotation, trigger string, scheduledAt time.Time) {
+	logCtx := withDispatchID(context.Background())
+	release, ok := s.admitRun(logCtx, annotation, trigger)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(logCtx, s.handlerTimeout(annotation))
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, "dispatch",
+		tracing.String("ghacron.job_id", annotation.Key().ID()),
+		tracing.String("ghacron.owner", annotation.Owner),
+		tracing.String("ghacron.repo", annotation.Repo),
+		tracing.String("ghacron.workflow_file", annotation.WorkflowFile),
+		tracing.String("ghacron.trigger", trigger),
+	)
+	defer span.End()
 
-		lastDispatch, canRollback := s.loadLastDispatchTime(ctx, stateManager, annotation)
-		if s.isWithinDuplicateGuard(annotation, lastDispatch) {
+	stateManager := s.stateManager()
+
+	var lastDispatch DispatchState
+	var canRollback bool
+	if !s.guardsByRuns(annotation) {
+		lastDispatch, canRollback = s.loadDispatchState(ctx, stateManager, annotation)
+		if s.isWithinDuplicateGuard(ctx, annotation, lastDispatch.Time) {
+			s.skipRun(span, annotation, trigger, "duplicate_guard", skipDuplicateGuard, true)
 			return
 		}
+	}
+
+	refs, ok := s.guardedRefs(ctx, span, annotation, trigger)
+	if !ok {
+		return
+	}
+
+	if s.config.DryRun {
+		slog.InfoContext(ctx, "[DRY-RUN] dispatch target",
+			append(annotationLogArgs(annotation),
+				"refs", refs,
+				"cron_expr", annotation.CronExpr,
+			)...,
+		)
+		s.skipRun(span, annotation, trigger, "dry_run", skipDryRun, true)
+		return
+	}
+
+	switch s.dispatchWithRollback(ctx, stateManager, annotation, refs, lastDispatch, canRollback, trigger, scheduledAt) {
+	case dispatchClaimed:
+		span.SetAttributes(tracing.String("ghacron.outcome", "skipped_claimed"))
+	case dispatchFailed:
+		span.SetAttributes(tracing.String("ghacron.outcome", "failed"))
+		span.SetError(errDispatchFailed)
+	default:
+		span.SetAttributes(tracing.String("ghacron.outcome", "dispatched"))
+		s.markRun(annotation)
+	}
+}
+
+// admitRun waits until a run may talk to the GitHub API: for a GitHub rate
+// limit, the dispatch rate and a concurrency slot. It returns false if the
+// run is shed, dropped for shutdown or held by the circuit breaker; otherwise
+// the caller must call release when the run is done.
+func (s *Scheduler) admitRun(ctx context.Context, annotation github.CronAnnotation, trigger string) (release func(), ok bool) {
+	// Runs held by a GitHub rate limit or queued by the dispatch rate do not
+	// hold concurrency slots.
+	s.waitForRateLimit(ctx, annotation)
+	if s.throttle != nil && !s.throttle.wait(priorityRank(annotation), s.drain.done()) && !s.drain.isStopping() {
+		slog.WarnContext(ctx, "dispatch queue full, shedding run", annotationLogArgs(annotation)...)
+		s.auditSkipped(annotation, trigger, skipQueueFull)
+		return nil, false
+	}
+	release = func() {}
+	if s.limiter != nil {
+		release = s.limiter.acquire(annotation.Owner, annotation.Repo)
+	}
+
+	// Nothing has been saved yet, so a run that waited for a rate limit or
+	// a slot during shutdown can be dropped safely.
+	if s.drain.isStopping() {
+		release()
+		slog.InfoContext(ctx, "shutting down, dropping queued dispatch", annotationLogArgs(annotation)...)
+		s.auditSkipped(annotation, trigger, skipShutdown)
+		return nil, false
+	}
+
+	// Tripped jobs are skipped quietly to keep the logs clean.
+	if s.breaker != nil && !s.breaker.allow(annotation.Key(), time.Now()) {
+		release()
+		slog.DebugContext(ctx, "circuit breaker open, skipping dispatch", annotationLogArgs(annotation)...)
+		s.auditSkipped(annotation, trigger, skipCircuitOpen)
+		return nil, false
+	}
+	return release, true
+}
+
+// guardedRefs returns the refs of a run that pass the runs duplicate guard
+// and the overlap policy. It returns false if none is left.
+func (s *Scheduler) guardedRefs(ctx context.Context, span *tracing.Span, annotation github.CronAnnotation, trigger string) ([]string, bool) {
+	refs, ok := s.resolveRefs(ctx, annotation)
+	if !ok {
+		s.skipRun(span, annotation, trigger, "no_refs", skipNoRefs, false)
+		return nil, false
+	}
+
+	if s.guardsByRuns(annotation) {
+		if refs = s.dropRecentlyDispatchedRefs(ctx, annotation, refs); len(refs) == 0 {
+			s.skipRun(span, annotation, trigger, "duplicate_guard", skipDuplicateGuard, true)
+			return nil, false
+		}
+	}
+
+	if s.overlapPolicy(annotation) == "skip" {
+		if refs = s.dropActiveRefs(ctx, annotation, refs); len(refs) == 0 {
+			s.skipRun(span, annotation, trigger, "overlap_skip", skipOverlap, true)
+			return nil, false
+		}
+	}
+	return refs, true
+}
+
+// skipRun records a run skipped for reason with outcome on its span. Runs
+// skipped on purpose (ran) still count as run for the dead-man alerts.
+func (s *Scheduler) skipRun(span *tracing.Span, annotation github.CronAnnotation, trigger, outcome, reason string, ran bool) {
+	span.SetAttributes(tracing.String("ghacron.outcome", outcome))
+	s.auditSkipped(annotation, trigger, reason)
+	if ran {
+		s.markRun(annotation)
+	}
+}
+
+// dispatchResult is the result of dispatchWithRollback.
+type dispatchResult int
+
+const (
+	dispatchSucceeded dispatchResult = iota // at least one ref was dispatched
+	dispatchFailed
+	dispatchClaimed // another replica claimed the dispatch; nothing was sent
+)
+
+// errDispatchFailed marks the span of a failed dispatch; the cause is logged
+// by dispatchWithRollback.
+var errDispatchFailed = errors.New("dispatch failed")
 
-		if s.config.DryRun {
-			slog.Info("[DRY-RUN] dispatch target",
-				append(annotationLogArgs(annotation),
-					"ref", annotation.Ref,
-					"cron_expr", annotation.CronExpr,
-				)...,
+// resolveRefs returns the refs to dispatch to. Without a refs= pattern this is
+// the annotation's default branch; otherwise every branch matching the pattern
+// at trigger time. Returns false if there is nothing to dispatch.
+func (s *Scheduler) resolveRefs(ctx context.Context, annotation github.CronAnnotation) ([]string, bool) {
+	if annotation.RefPattern == "" {
+		return []string{annotation.Ref}, true
+	}
+
+	branches, err := s.client.ListBranches(ctx, annotation.Owner, annotation.Repo)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list branches",
+			append(annotationLogArgs(annotation), "error", err)...,
+		)
+		return nil, false
+	}
+
+	var refs []string
+	for _, branch := range branches {
+		// Pattern is validated by the scanner, so Match cannot fail here.
+		if matched, _ := path.Match(annotation.RefPattern, branch); matched {
+			refs = append(refs, branch)
+		}
+	}
+	if len(refs) == 0 {
+		slog.WarnContext(ctx, "no branches match refs pattern",
+			append(annotationLogArgs(annotation), "refs_pattern", annotation.RefPattern)...,
+		)
+		return nil, false
+	}
+	return refs, true
+}
+
+// defaultHandlerTimeout applies when neither the annotation nor the config sets a timeout.
+const defaultHandlerTimeout = 30 * time.Second
+
+// handlerTimeout returns the timeout for the GitHub API calls of a job run.
+func (s *Scheduler) handlerTimeout(annotation github.CronAnnotation) time.Duration {
+	if annotation.Timeout > 0 {
+		return annotation.Timeout
+	}
+	if s.config.DispatchTimeoutSeconds > 0 {
+		return time.Duration(s.config.DispatchTimeoutSeconds) * time.Second
+	}
+	return defaultHandlerTimeout
+}
+
+// overlapPolicy returns the effective overlap policy of a job. Active runs are
+// looked up among workflow_dispatch runs, so repository_dispatch jobs always
+// allow overlap.
+func (s *Scheduler) overlapPolicy(annotation github.CronAnnotation) string {
+	if annotation.IsRepositoryDispatch() {
+		return "allow"
+	}
+	if annotation.Overlap != "" {
+		return annotation.Overlap
+	}
+	if s.config.OverlapPolicy != "" {
+		return s.config.OverlapPolicy
+	}
+	return "allow"
+}
+
+// dropActiveRefs removes refs on which a previous workflow_dispatch run not
+// started by a user is still queued or in progress. On lookup failure the ref
+// is kept (fail-open).
+func (s *Scheduler) dropActiveRefs(ctx context.Context, annotation github.CronAnnotation, refs []string) []string {
+	var idle []string
+	for _, ref := range refs {
+		active, err := s.client.HasActiveRun(ctx, annotation.Owner, annotation.Repo, annotation.WorkflowFile, ref)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to check active workflow runs",
+				append(annotationLogArgs(annotation), "ref", ref, "error", err)...,
 			)
-			return
+			idle = append(idle, ref)
+			continue
+		}
+		if active {
+			slog.InfoContext(ctx, "previous run still active, skipping dispatch",
+				append(annotationLogArgs(annotation), "ref", ref)...,
+			)
+			continue
 		}
+		idle = append(idle, ref)
+	}
+	return idle
+}
+
+// applySplay delays the dispatch according to its slot among the jobs due on
+// the same tick, spreading them over the configured splay window. It returns
+// false if shutdown began during the delay.
+func (s *Scheduler) applySplay(annotation github.CronAnnotation) bool {
+	if s.splay == nil {
+		return true
+	}
+	tick := time.Now().Truncate(time.Minute)
+	delay := s.splay.delay(tick, s.countDueAt(tick))
+	if delay <= 0 {
+		return true
+	}
+	slog.Debug("delaying dispatch by splay",
+		append(annotationLogArgs(annotation), "delay", delay.Round(time.Millisecond).String())...,
+	)
+	return sleep(delay, s.drain.done())
+}
 
-		s.dispatchWithRollback(ctx, stateManager, annotation, lastDispatch, canRollback)
+// countDueAt returns the number of cron entries that fired on the given
+// minute. Entries() is served by the cron run loop after it has launched the
+// whole batch, so Prev is already updated for every job of the tick.
+func (s *Scheduler) countDueAt(tick time.Time) int {
+	due := 0
+	for _, entry := range s.cron.Entries() {
+		if entry.ID != s.heartbeatID && entry.Prev.Truncate(time.Minute).Equal(tick) {
+			due++
+		}
 	}
+	return due
 }
 
-// loadLastDispatchTime returns the last dispatch time and whether a rollback is
+// applyJitter delays the dispatch by a random offset within the annotation's
+// jitter window, spreading load for popular schedules. It returns false if
+// shutdown began during the delay.
+func (s *Scheduler) applyJitter(annotation github.CronAnnotation) bool {
+	if annotation.Jitter <= 0 {
+		return true
+	}
+	delay := rand.N(annotation.Jitter)
+	slog.Debug("delaying dispatch by jitter",
+		append(annotationLogArgs(annotation), "delay", delay.Round(time.Millisecond).String())...,
+	)
+	return sleep(delay, s.drain.done())
+}
+
+// dispatchType returns the event a job sends, for JobDetail.
+func dispatchType(annotation github.CronAnnotation) string {
+	if annotation.IsRepositoryDispatch() {
+		return github.DispatchTypeRepository
+	}
+	return github.DispatchTypeWorkflow
+}
+
+// formatDelay renders a jitter or stagger delay for JobDetail ("" when unset).
+func formatDelay(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// loadDispatchState returns the dispatch state and whether a rollback is
 // possible. On retrieval failure it fails open (rollback disabled) so dispatch
 // can still proceed.
-func (s *Scheduler) loadLastDispatchTime(ctx context.Context, sm *StateManager, annotation github.CronAnnotation) (time.Time, bool) {
-	lastDispatch, err := sm.GetLastDispatchTime(ctx, annotation)
+func (s *Scheduler) loadDispatchState(ctx context.Context, sm *StateManager, annotation github.CronAnnotation) (DispatchState, bool) {
+	state, err := sm.GetDispatchState(ctx, annotation)
 	if err != nil {
-		slog.Error("failed to get last dispatch time",
+		slog.ErrorContext(ctx, "failed to get last dispatch time",
 			append(annotationLogArgs(annotation), "error", err)...,
 		)
-		return time.Time{}, false
+		return DispatchState{}, false
+	}
+	s.rememberDispatchState(annotation.Key(), state)
+	return state, true
+}
+
+// rememberDispatchState records the last known persisted state of a job for /jobs.
+func (s *Scheduler) rememberDispatchState(key github.CronJobKey, state DispatchState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, registered := s.registeredJobs[key]; registered {
+		s.dispatchStates[key] = state
 	}
-	return lastDispatch, true
 }
 
 // isWithinDuplicateGuard reports whether a dispatch happened too recently to
 // fire again, logging when the guard blocks.
-func (s *Scheduler) isWithinDuplicateGuard(annotation github.CronAnnotation, lastDispatch time.Time) bool {
+func (s *Scheduler) isWithinDuplicateGuard(ctx context.Context, annotation github.CronAnnotation, lastDispatch time.Time) bool {
 	if lastDispatch.IsZero() {
 		return false
 	}
@@ -269,7 +984,7 @@ func (s *Scheduler) isWithinDuplicateGuard(annotation github.CronAnnotation, las
 	if elapsed >= guard {
 		return false
 	}
-	slog.Info("duplicate guard: already dispatched",
+	slog.InfoContext(ctx, "duplicate guard: already dispatched",
 		append(annotationLogArgs(annotation),
 			"elapsed", elapsed.Round(time.Second).String(),
 			"guard", guard.String(),
@@ -278,44 +993,232 @@ func (s *Scheduler) isWithinDuplicateGuard(annotation github.CronAnnotation, las
 	return true
 }
 
-// dispatchWithRollback persists the dispatch time, fires the workflow, and rolls
-// back the saved time if the dispatch fails and a rollback is possible.
-func (s *Scheduler) dispatchWithRollback(ctx context.Context, sm *StateManager, annotation github.CronAnnotation, lastDispatch time.Time, canRollback bool) {
-	// Persist dispatch time before dispatching (to prevent races).
+// dispatchWithRollback persists a successful dispatch, fires the workflow on
+// each ref, and rolls back to the previous state (recording the failure) if
+// every dispatch fails and a rollback is possible. Every attempt is recorded
+// in history.
+func (s *Scheduler) dispatchWithRollback(ctx context.Context, sm *StateManager, annotation github.CronAnnotation, refs []string, lastDispatch DispatchState, canRollback bool, trigger string, scheduledAt time.Time) dispatchResult {
+	// Persist dispatch time before dispatching (to prevent races). With the
+	// run-based guard the created runs are the record.
 	now := time.Now()
-	if err := sm.SetLastDispatchTime(ctx, annotation, now); err != nil {
-		slog.Error("failed to save dispatch time",
-			append(annotationLogArgs(annotation), "error", err)...,
-		)
-		// Skip dispatch to avoid potential duplicates.
-		return
+	won := true
+	if !s.guardsByRuns(annotation) {
+		var err error
+		if won, err = s.saveDispatchTime(ctx, sm, annotation, now); err != nil {
+			slog.ErrorContext(ctx, "failed to save dispatch time",
+				append(annotationLogArgs(annotation), "error", err)...,
+			)
+			// Skip dispatch to avoid potential duplicates.
+			s.auditEvent(annotation, auditSkip, trigger, skipStateSaveError, "", err)
+			return dispatchFailed
+		}
+	}
+	if !won {
+		slog.InfoContext(ctx, "another instance claimed this dispatch, skipping", annotationLogArgs(annotation)...)
+		s.auditSkipped(annotation, trigger, skipClaimedByOther)
+		s.markRun(annotation)
+		return dispatchClaimed
 	}
 
-	err := s.client.DispatchWorkflow(ctx, annotation.Owner, annotation.Repo,
-		annotation.WorkflowFile, annotation.Ref)
-	if err == nil {
-		return
+	failed := 0
+	var lastErr error
+	for _, ref := range refs {
+		dispatchedAt := time.Now()
+		err := s.dispatch(ctx, annotation, ref, trigger, scheduledAt, dispatchedAt)
+		recordID := s.recordDispatch(annotation, ref, trigger, scheduledAt, dispatchedAt, err)
+		s.auditDispatched(annotation, trigger, ref, err)
+		if s.config.CheckRuns {
+			s.reportCheckRun(ctx, annotation, ref, trigger, err)
+		}
+		if err != nil {
+			failed++
+			lastErr = err
+			slog.ErrorContext(ctx, "dispatch failed",
+				append(annotationLogArgs(annotation), "ref", ref, "error", err)...,
+			)
+			continue
+		}
+		// Runs started by repository_dispatch cannot be told apart by ref.
+		if s.config.VerifyDispatches && !annotation.IsRepositoryDispatch() && s.drain.begin() {
+			go func() {
+				defer s.drain.end()
+				s.verifyDispatch(context.WithoutCancel(ctx), annotation, ref, recordID, scheduledAt, dispatchedAt)
+			}()
+		}
 	}
-	slog.Error("dispatch failed",
-		append(annotationLogArgs(annotation), "error", err)...,
-	)
+	// Keep the saved state if at least one ref was dispatched.
+	if failed < len(refs) {
+		s.recordBreakerResult(ctx, annotation, true)
+		s.recordIssueResult(ctx, annotation, nil)
+		s.recordSentryResult(annotation, trigger, nil)
+		s.rememberDispatchState(annotation.Key(), successState(now))
+		return dispatchSucceeded
+	}
+	// Rate limits are not the job's fault: they neither trip the circuit
+	// breaker nor file failure issues.
+	if !errors.Is(lastErr, github.ErrRateLimited) {
+		s.recordBreakerResult(ctx, annotation, false)
+		s.recordIssueResult(ctx, annotation, lastErr)
+		s.recordSentryResult(annotation, trigger, lastErr)
+	}
+	s.notifyDispatchFailed(annotation, trigger, lastErr)
+
+	rollback := lastDispatch
+	rollback.LastAttempt = now
+	rollback.Outcome = outcomeFailure
+	rollback.Nonce = ""
 
-	// Phantom guard prevention: rollback only if a previous time was retrieved.
+	// Phantom guard prevention: rollback only if a previous state was
+	// retrieved. Otherwise the failure is only known in memory.
 	if !canRollback {
-		return
+		failure := successState(now)
+		failure.Outcome = outcomeFailure
+		s.rememberDispatchState(annotation.Key(), failure)
+		return dispatchFailed
 	}
-	if rbErr := sm.SetLastDispatchTime(ctx, annotation, lastDispatch); rbErr != nil {
-		slog.Error("failed to rollback dispatch time",
+	rbErr := sm.SetDispatchState(ctx, annotation, rollback)
+	if rbErr != nil {
+		slog.ErrorContext(ctx, "failed to rollback dispatch time",
 			append(annotationLogArgs(annotation), "error", rbErr)...,
 		)
 	}
+	s.auditEvent(annotation, auditRollback, trigger, "", "", rbErr)
+	s.rememberDispatchState(annotation.Key(), rollback)
+	return dispatchFailed
+}
+
+// dispatch triggers the workflow of a job on ref, by workflow ID if the last
+// scan resolved it (so a renamed workflow file keeps working until the next
+// reconcile), otherwise by file name. repository_dispatch jobs send their
+// event to the repository instead. The placeholders of the inputs or payload
+// are substituted for this run.
+func (s *Scheduler) dispatch(ctx context.Context, annotation github.CronAnnotation, ref, trigger string, scheduledAt, dispatchedAt time.Time) error {
+	data := s.payloadData(annotation, ref, trigger, scheduledAt, dispatchedAt)
+	if annotation.IsRepositoryDispatch() {
+		payload, err := github.RenderPayload(annotation.Payload, data)
+		if err != nil {
+			return fmt.Errorf("failed to render payload: %w", err)
+		}
+		return s.client.RepositoryDispatch(ctx, annotation.Owner, annotation.Repo, annotation.EventType, payload)
+	}
+	inputs, err := github.RenderPayload(annotation.Inputs, data)
+	if err != nil {
+		return fmt.Errorf("failed to render inputs: %w", err)
+	}
+	s.mu.RLock()
+	id := s.workflowIDs[annotation.Key()]
+	s.mu.RUnlock()
+	if id != 0 {
+		return s.client.DispatchWorkflowByID(ctx, annotation.Owner, annotation.Repo, id, ref, inputs)
+	}
+	return s.client.DispatchWorkflow(ctx, annotation.Owner, annotation.Repo, annotation.WorkflowFile, ref, inputs)
+}
+
+// payloadData returns the placeholder values of a run of a job. Manual runs
+// have no cron tick, and use the dispatch time as their scheduled time.
+func (s *Scheduler) payloadData(annotation github.CronAnnotation, ref, trigger string, scheduledAt, dispatchedAt time.Time) github.PayloadData {
+	if scheduledAt.IsZero() {
+		scheduledAt = dispatchedAt
+	}
+	return github.PayloadData{
+		ScheduledTime: scheduledAt.UTC().Format(time.RFC3339),
+		RunDate:       s.inJobLocation(annotation, scheduledAt).Format(time.DateOnly),
+		DispatchTime:  dispatchedAt.UTC().Format(time.RFC3339),
+		Owner:         annotation.Owner,
+		Repo:          annotation.Repo,
+		WorkflowFile:  annotation.WorkflowFile,
+		Name:          annotation.Name,
+		Ref:           ref,
+		Trigger:       trigger,
+	}
+}
+
+// inJobLocation converts t to the time zone of a job's schedule.
+func (s *Scheduler) inJobLocation(annotation github.CronAnnotation, t time.Time) time.Time {
+	return t.In(s.jobLocation(annotation))
+}
+
+// jobLocation returns the time zone of a job's schedule: that of its
+// CRON_TZ= (or TZ=) prefix, or the scheduler's.
+func (s *Scheduler) jobLocation(annotation github.CronAnnotation) *time.Location {
+	prefix, _, _ := strings.Cut(annotation.CronExpr, " ")
+	for _, key := range []string{"CRON_TZ=", "TZ="} {
+		if name, ok := strings.CutPrefix(prefix, key); ok {
+			if loc, err := time.LoadLocation(name); err == nil {
+				return loc
+			}
+		}
+	}
+	if s.location == nil {
+		return time.UTC
+	}
+	return s.location
+}
+
+// setWorkflowIDs records the workflow IDs resolved by a scan for registered
+// jobs. IDs are kept for jobs whose workflows could not be listed this time.
+func (s *Scheduler) setWorkflowIDs(annotations []github.CronAnnotation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range annotations {
+		if _, registered := s.registeredJobs[a.Key()]; registered && a.WorkflowID != 0 {
+			s.workflowIDs[a.Key()] = a.WorkflowID
+		}
+	}
+}
+
+// recordBreakerResult feeds a dispatch outcome to the circuit breaker,
+// logging when the job trips.
+func (s *Scheduler) recordBreakerResult(ctx context.Context, annotation github.CronAnnotation, ok bool) {
+	if s.breaker == nil {
+		return
+	}
+	if ok {
+		s.breaker.recordSuccess(annotation.Key())
+		return
+	}
+	if s.breaker.recordFailure(annotation.Key(), time.Now()) {
+		slog.WarnContext(ctx, "circuit breaker tripped, suspending dispatches",
+			append(annotationLogArgs(annotation),
+				"cron_expr", annotation.CronExpr,
+				"threshold", s.breaker.threshold,
+				"cooldown", s.breaker.cooldown.String(),
+			)...,
+		)
+		s.notifyBreakerTripped(annotation)
+	}
+}
+
+// saveDispatchTime persists the dispatch time. With a claim settle delay
+// configured it claims the dispatch with compare-after-write, so concurrent
+// replicas cannot both dispatch; it reports whether this instance won.
+func (s *Scheduler) saveDispatchTime(ctx context.Context, sm *StateManager, annotation github.CronAnnotation, now time.Time) (bool, error) {
+	if s.config.ClaimSettleSeconds <= 0 {
+		return true, sm.SetLastDispatchTime(ctx, annotation, now)
+	}
+	return sm.ClaimDispatch(ctx, annotation, now, time.Duration(s.config.ClaimSettleSeconds)*time.Second)
 }
 
-// annotationLogArgs returns the slog attributes shared by dispatch log lines.
+// stateManager returns a StateManager for the configured state scope.
+func (s *Scheduler) stateManager() *StateManager {
+	var client StateClient = s.client
+	if s.gitState != nil {
+		client = s.gitState
+	}
+	sm := newScopedStateManager(client, s.config.StateScope, s.config.StateVariablePrefix)
+	sm.cache = s.stateCache
+	return sm
+}
+
+// annotationLogArgs returns the slog attributes shared by job log lines.
 func annotationLogArgs(annotation github.CronAnnotation) []any {
-	return []any{
+	args := []any{
 		"owner", annotation.Owner,
 		"repo", annotation.Repo,
 		"workflow_file", annotation.WorkflowFile,
 	}
+	if annotation.Name != "" {
+		args = append(args, "name", annotation.Name)
+	}
+	return args
 }
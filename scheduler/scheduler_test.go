@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,13 +19,42 @@ type mockClient struct {
 	getVarValue string
 	getVarErr   error
 	getVarCalls int
+	vars        map[string]string // values written by SetVariable (override getVarValue)
 
 	setVarErr   error
 	setVarCalls int
 	setVarArgs  []setVarCall
 
-	dispatchErr   error
-	dispatchCalls int
+	listVarsErr error
+	deletedVars []string
+
+	orgVars        map[string]string // organization variables by name
+	deletedOrgVars []string
+
+	dispatchErr      error
+	dispatchCalls    int
+	dispatchRefs     []string
+	dispatchIDs      []int64  // workflow IDs passed to DispatchWorkflowByID
+	dispatchInputs   []string // inputs passed to DispatchWorkflow(ByID)
+	dispatchEvents   []string // event types passed to RepositoryDispatch
+	dispatchPayloads []string // payloads passed to RepositoryDispatch
+
+	branches    []string
+	branchesErr error
+
+	activeRefs    map[string]bool
+	activeRunsErr error
+
+	workflowFiles []github.WorkflowFile // returned by GetWorkflowContents
+
+	dispatchedRun github.WorkflowRun // returned by FindDispatchedRun (not found if ID is 0)
+	runsErr       error
+	run           github.WorkflowRun // returned by GetWorkflowRun
+
+	checkRuns []github.CheckRun
+
+	issues        []github.Issue // opened by CreateIssue
+	issueComments []int          // issue numbers commented on
 
 	mu sync.Mutex
 }
@@ -33,10 +63,13 @@ type setVarCall struct {
 	owner, repo, name, value string
 }
 
-func (m *mockClient) GetVariable(_ context.Context, _, _, _ string) (string, error) {
+func (m *mockClient) GetVariable(_ context.Context, _, _, name string) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.getVarCalls++
+	if v, ok := m.vars[name]; ok && m.getVarErr == nil {
+		return v, nil
+	}
 	return m.getVarValue, m.getVarErr
 }
 
@@ -45,34 +78,194 @@ func (m *mockClient) SetVariable(_ context.Context, owner, repo, name, value str
 	defer m.mu.Unlock()
 	m.setVarCalls++
 	m.setVarArgs = append(m.setVarArgs, setVarCall{owner, repo, name, value})
+	if m.setVarErr == nil {
+		if m.vars == nil {
+			m.vars = make(map[string]string)
+		}
+		m.vars[name] = value
+	}
 	return m.setVarErr
 }
 
-func (m *mockClient) DispatchWorkflow(_ context.Context, _, _, _, _ string) error {
+func (m *mockClient) ListVariables(_ context.Context, _, _ string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.vars))
+	for name := range m.vars {
+		names = append(names, name)
+	}
+	return names, m.listVarsErr
+}
+
+func (m *mockClient) DeleteVariable(_ context.Context, _, _, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deletedVars = append(m.deletedVars, name)
+	delete(m.vars, name)
+	return nil
+}
+
+func (m *mockClient) GetOrgVariable(_ context.Context, _, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.orgVars[name], nil
+}
+
+func (m *mockClient) SetOrgVariable(_ context.Context, _, name, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.orgVars == nil {
+		m.orgVars = make(map[string]string)
+	}
+	m.orgVars[name] = value
+	return nil
+}
+
+func (m *mockClient) ListOrgVariables(_ context.Context, _ string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.orgVars))
+	for name := range m.orgVars {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (m *mockClient) DeleteOrgVariable(_ context.Context, _, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deletedOrgVars = append(m.deletedOrgVars, name)
+	delete(m.orgVars, name)
+	return nil
+}
+
+func (m *mockClient) DispatchWorkflow(_ context.Context, _, _, _, ref, inputs string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dispatchCalls++
+	m.dispatchRefs = append(m.dispatchRefs, ref)
+	m.dispatchInputs = append(m.dispatchInputs, inputs)
+	return m.dispatchErr
+}
+
+func (m *mockClient) DispatchWorkflowByID(_ context.Context, _, _ string, workflowID int64, ref, inputs string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.dispatchCalls++
+	m.dispatchRefs = append(m.dispatchRefs, ref)
+	m.dispatchInputs = append(m.dispatchInputs, inputs)
+	m.dispatchIDs = append(m.dispatchIDs, workflowID)
 	return m.dispatchErr
 }
 
+func (m *mockClient) RepositoryDispatch(_ context.Context, _, _, eventType, payload string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dispatchCalls++
+	m.dispatchEvents = append(m.dispatchEvents, eventType)
+	m.dispatchPayloads = append(m.dispatchPayloads, payload)
+	return m.dispatchErr
+}
+
+func (m *mockClient) ListBranches(_ context.Context, _, _ string) ([]string, error) {
+	return m.branches, m.branchesErr
+}
+
+func (m *mockClient) HasActiveRun(_ context.Context, _, _, _, ref string) (bool, error) {
+	return m.activeRefs[ref], m.activeRunsErr
+}
+
+func (m *mockClient) FindDispatchedRun(_ context.Context, _, _, _, _ string, _ time.Time) (github.WorkflowRun, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.dispatchedRun, m.dispatchedRun.ID != 0, m.runsErr
+}
+
+func (m *mockClient) GetWorkflowRun(_ context.Context, _, _ string, _ int64) (github.WorkflowRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.run, nil
+}
+
+func (m *mockClient) GetFile(_ context.Context, _, _, _, _ string) ([]byte, string, bool, error) {
+	return nil, "", false, nil
+}
+
+func (m *mockClient) PutFile(_ context.Context, _, _, _, _, _ string, _ []byte, _ string) error {
+	return nil
+}
+
+func (m *mockClient) CreateOrphanBranch(_ context.Context, _, _, _, _, _ string, _ []byte) error {
+	return nil
+}
+
 func (m *mockClient) GetInstallationRepos(_ context.Context) ([]github.Repository, error) {
 	return nil, nil
 }
 
-func (m *mockClient) GetWorkflowFiles(_ context.Context, _, _ string) ([]github.WorkflowFile, error) {
-	return nil, nil
+func (m *mockClient) GetWorkflowContents(_ context.Context, _, _, _ string) ([]github.WorkflowFile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.workflowFiles, nil
 }
 
-func (m *mockClient) GetFileContent(_ context.Context, _, _, _, _ string) (string, error) {
+func (m *mockClient) GetHeadSHA(_ context.Context, _, _, _, _ string) (string, error) {
 	return "", nil
 }
 
+func (m *mockClient) CreateCheckRun(_ context.Context, _, _ string, check github.CheckRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkRuns = append(m.checkRuns, check)
+	return nil
+}
+
+func (m *mockClient) FindOpenIssue(_ context.Context, _, _, _, title string) (github.Issue, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, issue := range m.issues {
+		if issue.Title == title {
+			return issue, true, nil
+		}
+	}
+	return github.Issue{}, false, nil
+}
+
+func (m *mockClient) CreateIssue(_ context.Context, _, _, title, _ string, _ []string) (github.Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	issue := github.Issue{Number: len(m.issues) + 1, Title: title}
+	m.issues = append(m.issues, issue)
+	return issue, nil
+}
+
+func (m *mockClient) CommentOnIssue(_ context.Context, _, _ string, number int, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.issueComments = append(m.issueComments, number)
+	return nil
+}
+
+func (m *mockClient) GetWorkflowContentsBatch(_ context.Context, _ []github.Repository) (map[string]github.RepoWorkflows, error) {
+	return nil, nil
+}
+
+func (m *mockClient) ListWorkflows(_ context.Context, _, _ string) ([]github.Workflow, error) {
+	return nil, nil
+}
+
 func newTestScheduler(client GitHubClient, cfg *config.ReconcileConfig) *Scheduler {
 	return &Scheduler{
 		client:         client,
-		cron:           cron.New(cron.WithLocation(time.UTC)),
+		cron:           newCron(time.UTC),
 		config:         cfg,
-		registeredJobs: make(map[github.CronJobKey]cron.EntryID),
+		registeredJobs: make(map[github.CronJobKey]registeredJob),
+		paused:         make(map[github.CronJobKey]struct{}),
+		dispatchStates: make(map[github.CronJobKey]DispatchState),
+		workflowIDs:    make(map[github.CronJobKey]int64),
+		drain:          newDrainer(),
+		history:        newHistory(historySize),
+		drift:          newDriftTracker(),
 	}
 }
 
@@ -108,6 +301,72 @@ func TestHandler_NormalDispatch(t *testing.T) {
 	}
 }
 
+func TestHandler_DispatchesByWorkflowID(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+
+	// The ID resolved by a later scan is used by the registered handler.
+	resolved := annotation
+	resolved.WorkflowID = 42
+	s.setWorkflowIDs([]github.CronAnnotation{resolved})
+	s.createJobHandler(annotation)()
+
+	if len(mock.dispatchIDs) != 1 || mock.dispatchIDs[0] != 42 {
+		t.Errorf("dispatched workflow IDs = %v, want [42]", mock.dispatchIDs)
+	}
+}
+
+func TestHandler_RepositoryDispatch(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.DispatchType = github.DispatchTypeRepository
+	annotation.EventType = "nightly"
+
+	s.createJobHandler(annotation)()
+
+	if len(mock.dispatchEvents) != 1 || mock.dispatchEvents[0] != "nightly" {
+		t.Errorf("repository_dispatch events = %v, want [nightly]", mock.dispatchEvents)
+	}
+	if len(mock.dispatchRefs) != 0 {
+		t.Errorf("workflow_dispatch refs = %v, want none", mock.dispatchRefs)
+	}
+}
+
+func TestRunJob_PayloadPlaceholders(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.CronExpr = "CRON_TZ=Asia/Tokyo 30 0 * * *"
+	annotation.DispatchType = github.DispatchTypeRepository
+	annotation.EventType = "nightly"
+	annotation.Payload = `{"date": "{{ .RunDate }}", "at": "{{ .ScheduledTime }}", "repo": "{{ .Owner }}/{{ .Repo }}", "trigger": "{{ .Trigger }}"}`
+
+	// 00:30 in Tokyo is still the previous day in UTC.
+	s.runJob(annotation, triggerSchedule, time.Date(2026, 3, 1, 15, 30, 0, 0, time.UTC))
+
+	want := `{"date": "2026-03-02", "at": "2026-03-01T15:30:00Z", "repo": "test-owner/test-repo", "trigger": "schedule"}`
+	if len(mock.dispatchPayloads) != 1 || mock.dispatchPayloads[0] != want {
+		t.Errorf("payloads = %q, want [%q]", mock.dispatchPayloads, want)
+	}
+}
+
+func TestRunJob_InputPlaceholders(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.Inputs = `{"date": "{{ .RunDate }}", "ref": "{{ .Ref }}", "dry_run": false}`
+
+	s.runJob(annotation, triggerSchedule, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+
+	want := `{"date": "2026-03-01", "ref": "main", "dry_run": false}`
+	if len(mock.dispatchInputs) != 1 || mock.dispatchInputs[0] != want {
+		t.Errorf("inputs = %q, want [%q]", mock.dispatchInputs, want)
+	}
+}
+
 func TestHandler_DispatchFailure_Rollback(t *testing.T) {
 	mock := &mockClient{
 		dispatchErr: errors.New("API error"),
@@ -126,10 +385,12 @@ func TestHandler_DispatchFailure_Rollback(t *testing.T) {
 	}
 	// Rollback value should be zero time (GetVariable returns empty string)
 	if len(mock.setVarArgs) >= 2 {
-		rollbackValue := mock.setVarArgs[1].value
-		zeroTime := time.Time{}.Format(time.RFC3339)
-		if rollbackValue != zeroTime {
-			t.Errorf("rollback value: got %q, want %q (zero time)", rollbackValue, zeroTime)
+		rollback, err := decodeDispatchState(mock.setVarArgs[1].value)
+		if err != nil || !rollback.Time.IsZero() {
+			t.Errorf("rollback value: got %q (%v), want zero time", mock.setVarArgs[1].value, err)
+		}
+		if rollback.Outcome != outcomeFailure {
+			t.Errorf("rollback outcome: got %q, want %q", rollback.Outcome, outcomeFailure)
 		}
 	}
 }
@@ -153,10 +414,33 @@ func TestHandler_DispatchFailure_RollbackToPrevious(t *testing.T) {
 		t.Fatalf("SetVariable call count: got %d, want 2", mock.setVarCalls)
 	}
 	// Rollback value should be previous dispatch time
-	rollbackValue := mock.setVarArgs[1].value
-	expectedValue := prevTime.Format(time.RFC3339)
-	if rollbackValue != expectedValue {
-		t.Errorf("rollback value: got %q, want %q", rollbackValue, expectedValue)
+	rollback, err := decodeDispatchState(mock.setVarArgs[1].value)
+	if err != nil || !rollback.Time.Equal(prevTime) {
+		t.Errorf("rollback value: got %q (%v), want time %v", mock.setVarArgs[1].value, err, prevTime)
+	}
+}
+
+func TestGetJobDetails_LastDispatch(t *testing.T) {
+	mock := &mockClient{dispatchErr: errors.New("API error")}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+
+	if detail := s.GetJobDetails()[0]; detail.LastDispatch != nil {
+		t.Fatalf("LastDispatch before any dispatch = %+v, want nil", detail.LastDispatch)
+	}
+
+	s.createJobHandler(annotation)()
+	last := s.GetJobDetails()[0].LastDispatch
+	if last == nil || last.Outcome != outcomeFailure || !last.Time.IsZero() {
+		t.Fatalf("LastDispatch after failure = %+v, want failure without success time", last)
+	}
+
+	mock.dispatchErr = nil
+	s.createJobHandler(annotation)()
+	last = s.GetJobDetails()[0].LastDispatch
+	if last == nil || last.Outcome != outcomeSuccess || last.Time.IsZero() {
+		t.Errorf("LastDispatch after success = %+v, want success", last)
 	}
 }
 
@@ -293,3 +577,435 @@ func (m *dynamicMockClient) SetVariable(_ context.Context, owner, repo, name, va
 	}
 	return m.setVarErr
 }
+
+func TestStateManager_VariableName(t *testing.T) {
+	sm := NewStateManager(&mockClient{})
+
+	unnamed := testAnnotation()
+	if got := sm.variableName(lastDispatchKind, unnamed); len(got) != len("GHACRON_LAST_")+8 {
+		t.Errorf("unnamed variable name = %q, want GHACRON_LAST_ + 8 hex chars", got)
+	}
+
+	named := testAnnotation()
+	named.Name = "nightly-build"
+	if got, want := sm.variableName(lastDispatchKind, named), "GHACRON_LAST_NIGHTLY_BUILD"; got != want {
+		t.Errorf("named variable name = %q, want %q", got, want)
+	}
+}
+
+func TestHandler_RefPattern_FansOut(t *testing.T) {
+	mock := &mockClient{
+		branches: []string{"main", "release/1.0", "release/2.0", "feature/x"},
+	}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.RefPattern = "release/*"
+	handler := s.createJobHandler(annotation)
+
+	handler()
+
+	if mock.dispatchCalls != 2 {
+		t.Fatalf("DispatchWorkflow call count: got %d, want 2", mock.dispatchCalls)
+	}
+	if mock.dispatchRefs[0] != "release/1.0" || mock.dispatchRefs[1] != "release/2.0" {
+		t.Errorf("dispatched refs = %v, want [release/1.0 release/2.0]", mock.dispatchRefs)
+	}
+	if mock.setVarCalls != 1 {
+		t.Errorf("SetVariable call count: got %d, want 1 (pre-save only)", mock.setVarCalls)
+	}
+}
+
+func TestHandler_RefPattern_NoMatch(t *testing.T) {
+	mock := &mockClient{
+		branches: []string{"main"},
+	}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.RefPattern = "release/*"
+	handler := s.createJobHandler(annotation)
+
+	handler()
+
+	if mock.dispatchCalls != 0 {
+		t.Errorf("DispatchWorkflow call count: got %d, want 0", mock.dispatchCalls)
+	}
+	if mock.setVarCalls != 0 {
+		t.Errorf("SetVariable call count: got %d, want 0", mock.setVarCalls)
+	}
+}
+
+func TestHandler_RefPattern_AllFail_Rollback(t *testing.T) {
+	mock := &mockClient{
+		branches:    []string{"release/1.0", "release/2.0"},
+		dispatchErr: errors.New("API error"),
+	}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.RefPattern = "release/*"
+	handler := s.createJobHandler(annotation)
+
+	handler()
+
+	if mock.dispatchCalls != 2 {
+		t.Errorf("DispatchWorkflow call count: got %d, want 2 (failures should not stop fan-out)", mock.dispatchCalls)
+	}
+	// SetVariable: pre-save + rollback = 2 calls
+	if mock.setVarCalls != 2 {
+		t.Errorf("SetVariable call count: got %d, want 2", mock.setVarCalls)
+	}
+}
+
+func TestHandler_Jitter(t *testing.T) {
+	var slept []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration, _ <-chan struct{}) bool {
+		slept = append(slept, d)
+		return true
+	}
+	t.Cleanup(func() { sleep = orig })
+
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	annotation.Jitter = 5 * time.Minute
+	handler := s.createJobHandler(annotation)
+
+	handler()
+
+	if len(slept) != 1 {
+		t.Fatalf("sleep call count: got %d, want 1", len(slept))
+	}
+	if slept[0] < 0 || slept[0] >= annotation.Jitter {
+		t.Errorf("jitter delay = %v, want within [0, %v)", slept[0], annotation.Jitter)
+	}
+	if mock.dispatchCalls != 1 {
+		t.Errorf("DispatchWorkflow call count: got %d, want 1", mock.dispatchCalls)
+	}
+}
+
+// registerTestJob adds a job to the scheduler without starting the cron engine.
+func registerTestJob(t *testing.T, s *Scheduler, annotation github.CronAnnotation) {
+	t.Helper()
+	if err := s.AddJob(context.Background(), annotation); err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+}
+
+func TestPauseJob_SkipsDispatch(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+
+	if err := s.PauseJob(context.Background(), annotation.Key().ID()); err != nil {
+		t.Fatalf("PauseJob: %v", err)
+	}
+	if len(mock.setVarArgs) != 1 || mock.setVarArgs[0].value != "true" {
+		t.Fatalf("paused state not persisted: %v", mock.setVarArgs)
+	}
+	if !strings.HasPrefix(mock.setVarArgs[0].name, "GHACRON_PAUSED_") {
+		t.Errorf("variable name = %q, want GHACRON_PAUSED_ prefix", mock.setVarArgs[0].name)
+	}
+
+	s.createJobHandler(annotation)()
+	if mock.dispatchCalls != 0 {
+		t.Errorf("DispatchWorkflow call count: got %d, want 0 (job is paused)", mock.dispatchCalls)
+	}
+
+	if err := s.ResumeJob(context.Background(), annotation.Key().ID()); err != nil {
+		t.Fatalf("ResumeJob: %v", err)
+	}
+	s.createJobHandler(annotation)()
+	if mock.dispatchCalls != 1 {
+		t.Errorf("DispatchWorkflow call count: got %d, want 1 (job resumed)", mock.dispatchCalls)
+	}
+}
+
+func TestPauseJob_NotFound(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+
+	err := s.PauseJob(context.Background(), "unknown")
+	if !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("err = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestPauseJob_PersistFailure(t *testing.T) {
+	mock := &mockClient{setVarErr: errors.New("API error")}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+
+	if err := s.PauseJob(context.Background(), annotation.Key().ID()); err == nil {
+		t.Fatal("expected error when persisting fails")
+	}
+	if s.isPaused(annotation.Key()) {
+		t.Error("job should not be paused when persisting fails")
+	}
+}
+
+func TestLoadPausedState(t *testing.T) {
+	mock := &mockClient{getVarValue: "true"}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+
+	s.loadPausedState(context.Background(), annotation)
+
+	if !s.isPaused(annotation.Key()) {
+		t.Error("job should be paused after loading persisted state")
+	}
+}
+
+func TestTriggerJob(t *testing.T) {
+	mock := &mockClient{}
+	s := newTestScheduler(mock, defaultConfig())
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+
+	if err := s.TriggerJob(context.Background(), annotation.Key().ID()); err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mock.mu.Lock()
+		calls := mock.dispatchCalls
+		mock.mu.Unlock()
+		if calls == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("DispatchWorkflow call count: got %d, want 1", calls)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTriggerJob_NotFound(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+
+	err := s.TriggerJob(context.Background(), "unknown")
+	if !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("err = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestGetJobDetails_NextRuns(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	registerTestJob(t, s, testAnnotation()) // 0 9 * * *
+
+	details := s.GetJobDetails()
+	if len(details) != 1 {
+		t.Fatalf("details count: got %d, want 1", len(details))
+	}
+	runs := details[0].NextRuns
+	if len(runs) != upcomingRunCount {
+		t.Fatalf("next runs count: got %d, want %d", len(runs), upcomingRunCount)
+	}
+	for i, run := range runs {
+		if run.Hour() != 9 || run.Minute() != 0 {
+			t.Errorf("runs[%d] = %v, want 09:00", i, run)
+		}
+		if i > 0 && run.Sub(runs[i-1]) != 24*time.Hour {
+			t.Errorf("runs[%d] - runs[%d] = %v, want 24h", i, i-1, run.Sub(runs[i-1]))
+		}
+	}
+}
+
+func TestGetJobDetails_Timezone(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	annotation := testAnnotation()
+	annotation.CronExpr = "CRON_TZ=Asia/Tokyo 0 9 * * *"
+	registerTestJob(t, s, annotation)
+	registerTestJob(t, s, testAnnotation()) // 0 9 * * *, scheduler time zone (UTC)
+
+	for _, d := range s.GetJobDetails() {
+		wantTZ, wantUTCHour := "UTC", 9
+		if d.CronExpr == annotation.CronExpr {
+			wantTZ, wantUTCHour = "Asia/Tokyo", 0
+		}
+		if d.Timezone != wantTZ {
+			t.Errorf("%s: Timezone = %q, want %q", d.CronExpr, d.Timezone, wantTZ)
+		}
+		if d.NextRunLocal.Hour() != 9 || d.NextRunLocal.Location().String() != wantTZ {
+			t.Errorf("%s: NextRunLocal = %v, want 09:00 %s", d.CronExpr, d.NextRunLocal, wantTZ)
+		}
+		if d.NextRunUTC.Hour() != wantUTCHour || d.NextRunUTC.Location() != time.UTC || !d.NextRunUTC.Equal(d.NextRunLocal) {
+			t.Errorf("%s: NextRunUTC = %v, want %02d:00 UTC", d.CronExpr, d.NextRunUTC, wantUTCHour)
+		}
+	}
+}
+
+func TestHandler_OverlapSkip(t *testing.T) {
+	tests := []struct {
+		name          string
+		global        string
+		overlap       string
+		activeRefs    map[string]bool
+		activeRunsErr error
+		wantRefs      []string
+	}{
+		{"allow ignores active run", "allow", "", map[string]bool{"main": true}, nil, []string{"main"}},
+		{"annotation skip", "allow", "skip", map[string]bool{"main": true}, nil, nil},
+		{"global skip", "skip", "", map[string]bool{"main": true}, nil, nil},
+		{"annotation allow overrides global skip", "skip", "allow", map[string]bool{"main": true}, nil, []string{"main"}},
+		{"skip without active run", "skip", "", nil, nil, []string{"main"}},
+		{"lookup failure fails open", "skip", "", nil, errors.New("API error"), []string{"main"}},
+		{"per-ref with refs pattern", "skip", "", map[string]bool{"release/1": true}, nil, []string{"release/2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockClient{
+				branches:      []string{"main", "release/1", "release/2"},
+				activeRefs:    tt.activeRefs,
+				activeRunsErr: tt.activeRunsErr,
+			}
+			cfg := defaultConfig()
+			cfg.OverlapPolicy = tt.global
+			s := newTestScheduler(mock, cfg)
+
+			annotation := testAnnotation()
+			annotation.Overlap = tt.overlap
+			if strings.HasPrefix(tt.name, "per-ref") {
+				annotation.RefPattern = "release/*"
+			}
+			s.createJobHandler(annotation)()
+
+			if strings.Join(mock.dispatchRefs, ",") != strings.Join(tt.wantRefs, ",") {
+				t.Errorf("dispatched refs = %v, want %v", mock.dispatchRefs, tt.wantRefs)
+			}
+		})
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		global  int
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{"default", 0, 0, 30 * time.Second},
+		{"global", 90, 0, 90 * time.Second},
+		{"annotation overrides global", 90, 2 * time.Minute, 2 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.DispatchTimeoutSeconds = tt.global
+			s := newTestScheduler(&mockClient{}, cfg)
+
+			annotation := testAnnotation()
+			annotation.Timeout = tt.timeout
+			if got := s.handlerTimeout(annotation); got != tt.want {
+				t.Errorf("handlerTimeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobWrappers_RecoverPanic(t *testing.T) {
+	job := cron.NewChain(jobWrappers()...).Then(cron.FuncJob(func() {
+		panic("boom")
+	}))
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("panic escaped job wrappers: %v", r)
+		}
+	}()
+	job.Run()
+}
+
+func TestJobWrappers_SkipIfStillRunning(t *testing.T) {
+	var mu sync.Mutex
+	runs := 0
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	job := cron.NewChain(jobWrappers()...).Then(cron.FuncJob(func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		close(started)
+		<-release
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		job.Run()
+		close(done)
+	}()
+	<-started
+
+	job.Run() // skipped: the first run is still in progress
+	close(release)
+	<-done
+
+	if runs != 1 {
+		t.Errorf("run count: got %d, want 1", runs)
+	}
+}
+
+func TestRunReconcileLoop_Startup(t *testing.T) {
+	var slept []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration, _ <-chan struct{}) bool {
+		slept = append(slept, d)
+		return true
+	}
+	t.Cleanup(func() { sleep = orig })
+
+	tests := []struct {
+		mode       string
+		reconciled bool
+		delayed    bool
+	}{
+		{"immediate", true, false},
+		{"delay", true, true},
+		{"skip", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			slept = nil
+			mock := &mockClient{}
+			cfg := defaultConfig()
+			cfg.StartupReconcile = tt.mode
+			s := newTestScheduler(mock, cfg)
+			s.reconciler = NewReconciler(mock, s, cfg)
+
+			// The loop returns at its first wait for a tick.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			s.RunReconcileLoop(ctx, time.Hour)
+
+			if got := !s.lastReconcile.IsZero(); got != tt.reconciled {
+				t.Errorf("reconciled = %v, want %v", got, tt.reconciled)
+			}
+			if tt.delayed && (len(slept) != 1 || slept[0] >= time.Hour) {
+				t.Errorf("slept = %v, want one delay under the interval", slept)
+			} else if !tt.delayed && len(slept) != 0 {
+				t.Errorf("slept = %v, want no delay", slept)
+			}
+		})
+	}
+}
+
+func TestDispatchWithRollback_ClaimedByOther(t *testing.T) {
+	noSleep(t)
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	cfg.ClaimSettleSeconds = 1
+	s := newTestScheduler(overwritingClient{mock}, cfg)
+	annotation := testAnnotation()
+
+	got := s.dispatchWithRollback(context.Background(), s.stateManager(), annotation, []string{"main"}, DispatchState{}, false, triggerSchedule, time.Time{})
+	if got != dispatchClaimed {
+		t.Errorf("result = %v, want dispatchClaimed", got)
+	}
+	if mock.dispatchCalls != 0 {
+		t.Errorf("dispatch calls = %d, want 0", mock.dispatchCalls)
+	}
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/sentry"
+)
+
+// SetErrorReporter enables error reporting to Sentry. Dispatch failures are
+// reported once a job has failed failureThreshold times in a row, and again
+// after every further failureThreshold failures. It must be called before the
+// loops start.
+func (s *Scheduler) SetErrorReporter(client *sentry.Client, failureThreshold int) {
+	s.sentry = client
+	s.sentryFailures = newFailureCounter(failureThreshold)
+}
+
+// jobTags identify a job in error reports.
+func jobTags(annotation github.CronAnnotation) map[string]string {
+	return map[string]string{
+		"job_id":        annotation.Key().ID(),
+		"owner":         annotation.Owner,
+		"repo":          annotation.Repo,
+		"workflow_file": annotation.WorkflowFile,
+	}
+}
+
+// jobExtra is the context of a job attached to error reports.
+func jobExtra(annotation github.CronAnnotation) map[string]any {
+	return map[string]any{
+		"name":      annotation.Name,
+		"cron_expr": annotation.CronExpr,
+		"path":      annotation.Path,
+		"line":      annotation.Line,
+	}
+}
+
+// recordSentryResult feeds a dispatch outcome to the failure counter,
+// reporting the job when the threshold is reached.
+func (s *Scheduler) recordSentryResult(annotation github.CronAnnotation, trigger string, dispatchErr error) {
+	if s.sentry == nil {
+		return
+	}
+	if dispatchErr == nil {
+		s.sentryFailures.recordSuccess(annotation.Key())
+		return
+	}
+	failures, report := s.sentryFailures.recordFailure(annotation.Key())
+	if !report {
+		return
+	}
+	tags := jobTags(annotation)
+	tags["trigger"] = trigger
+	extra := jobExtra(annotation)
+	extra["consecutive_failures"] = failures
+	s.sentry.Capture(sentry.Event{
+		Level:       sentry.LevelError,
+		Message:     fmt.Sprintf("dispatch of %s failed %d times in a row", annotation.WorkflowFile, failures),
+		Err:         dispatchErr,
+		Tags:        tags,
+		Extra:       extra,
+		Fingerprint: []string{"dispatch-failure", annotation.Key().ID()},
+	})
+}
+
+// reportReconcileError reports a failed reconcile, of every repository when
+// repo is empty.
+func (s *Scheduler) reportReconcileError(owner, repo string, err error) {
+	if s.sentry == nil {
+		return
+	}
+	event := sentry.Event{
+		Level:       sentry.LevelError,
+		Message:     "reconciliation failed",
+		Err:         err,
+		Tags:        map[string]string{"component": "reconcile"},
+		Fingerprint: []string{"reconcile-failure"},
+	}
+	if repo != "" {
+		event.Message = fmt.Sprintf("reconciliation of %s/%s failed", owner, repo)
+		event.Tags["owner"] = owner
+		event.Tags["repo"] = repo
+		event.Fingerprint = append(event.Fingerprint, owner+"/"+repo)
+	}
+	s.sentry.Capture(event)
+}
+
+// reportPanic reports a panic of a job handler, then panics again so the
+// cron engine's recovery (or recoverRun) logs it as before. Deferred by
+// handleTick and TriggerJob.
+func (s *Scheduler) reportPanic(annotation github.CronAnnotation) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if s.sentry != nil {
+		s.sentry.Capture(sentry.Event{
+			Level:       sentry.LevelFatal,
+			Message:     fmt.Sprintf("job handler panicked: %v", r),
+			Stack:       string(debug.Stack()),
+			Tags:        jobTags(annotation),
+			Extra:       jobExtra(annotation),
+			Fingerprint: []string{"handler-panic", annotation.Key().ID()},
+		})
+	}
+	panic(r)
+}
@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/sentry"
+)
+
+// sentryEvent is the part of a reported event checked by tests.
+type sentryEvent struct {
+	Level   string                     `json:"level"`
+	Message struct{ Formatted string } `json:"message"`
+	Tags    map[string]string          `json:"tags"`
+}
+
+// newTestSentry returns a client reporting to a test endpoint, and the
+// reported events.
+func newTestSentry(t *testing.T) (*sentry.Client, func() []sentryEvent) {
+	t.Helper()
+	var mu sync.Mutex
+	var events []sentryEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		var last []byte
+		for scanner.Scan() {
+			last = scanner.Bytes()
+		}
+		var event sentryEvent
+		if err := json.Unmarshal(last, &event); err != nil {
+			t.Errorf("decode event: %v", err)
+		}
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	client, err := sentry.New(strings.Replace(srv.URL, "http://", "http://key@", 1)+"/1", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, func() []sentryEvent {
+		client.Flush(5 * time.Second)
+		mu.Lock()
+		defer mu.Unlock()
+		return events
+	}
+}
+
+func TestSentry_RepeatedDispatchFailures(t *testing.T) {
+	mock := &mockClient{dispatchErr: errors.New("workflow not found")}
+	cfg := defaultConfig()
+	cfg.DuplicateGuardSeconds = 0
+	s := newTestScheduler(mock, cfg)
+	client, events := newTestSentry(t)
+	s.SetErrorReporter(client, 2)
+	handler := s.createJobHandler(testAnnotation())
+
+	for range 3 {
+		handler()
+	}
+
+	got := events()
+	if len(got) != 1 {
+		t.Fatalf("got %d events, want 1 after 3 failures with threshold 2", len(got))
+	}
+	if got[0].Level != sentry.LevelError || got[0].Tags["repo"] != "test-repo" || got[0].Tags["trigger"] != triggerSchedule {
+		t.Errorf("event = %+v", got[0])
+	}
+}
+
+// panickingClient panics when dispatching.
+type panickingClient struct{ *mockClient }
+
+func (panickingClient) DispatchWorkflow(_ context.Context, _, _, _, _, _ string) error {
+	panic("boom")
+}
+
+func TestSentry_HandlerPanic(t *testing.T) {
+	s := newTestScheduler(panickingClient{&mockClient{}}, defaultConfig())
+	client, events := newTestSentry(t)
+	s.SetErrorReporter(client, 3)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want the panic to be re-raised", r)
+			}
+		}()
+		s.createJobHandler(testAnnotation())()
+	}()
+
+	got := events()
+	if len(got) != 1 || got[0].Level != sentry.LevelFatal || !strings.Contains(got[0].Message.Formatted, "boom") {
+		t.Errorf("events = %+v, want the panic", got)
+	}
+}
+
+func TestSentry_ReconcileError(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	client, events := newTestSentry(t)
+	s.SetErrorReporter(client, 3)
+
+	s.reportReconcileError("o", "r", errors.New("rate limited"))
+
+	got := events()
+	if len(got) != 1 || got[0].Tags["component"] != "reconcile" || got[0].Tags["repo"] != "r" {
+		t.Errorf("events = %+v", got)
+	}
+}
+
+func TestSentry_ManualRunPanic(t *testing.T) {
+	s := newTestScheduler(panickingClient{&mockClient{}}, defaultConfig())
+	client, events := newTestSentry(t)
+	s.SetErrorReporter(client, 3)
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+
+	// The panic is recovered in the background run instead of crashing.
+	if err := s.TriggerJob(context.Background(), annotation.Key().ID()); err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+	var got []sentryEvent
+	for deadline := time.Now().Add(5 * time.Second); len(got) == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		got = events()
+	}
+	if len(got) != 1 || got[0].Level != sentry.LevelFatal || !strings.Contains(got[0].Message.Formatted, "boom") {
+		t.Errorf("events = %+v, want the panic", got)
+	}
+}
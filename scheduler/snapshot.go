@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/scanner"
+)
+
+// snapshotVersion is the format version of job snapshots; snapshots of other
+// versions are ignored.
+const snapshotVersion = 1
+
+// jobSnapshot is the registered job set saved after each reconcile, so a
+// restarted scheduler can register the jobs before its first scan completes.
+type jobSnapshot struct {
+	Version int                         `json:"version"`
+	SavedAt time.Time                   `json:"saved_at"`
+	Jobs    []github.CronAnnotation     `json:"jobs"`
+	Skipped []scanner.SkippedAnnotation `json:"skipped"`
+}
+
+// saveSnapshot writes the registered jobs and skipped annotations to the
+// snapshot file, if one is configured. Failures are logged only.
+func (s *Scheduler) saveSnapshot() {
+	path := s.config.SnapshotPath
+	if path == "" {
+		return
+	}
+
+	snap := jobSnapshot{Version: snapshotVersion, SavedAt: time.Now()}
+	s.mu.RLock()
+	for _, job := range s.registeredJobs {
+		snap.Jobs = append(snap.Jobs, job.annotation)
+	}
+	snap.Skipped = s.skippedAnnotations
+	s.mu.RUnlock()
+
+	if err := writeSnapshot(path, snap); err != nil {
+		slog.Error("failed to save job snapshot", "path", path, "error", err)
+		return
+	}
+	slog.Debug("saved job snapshot", "path", path, "jobs", len(snap.Jobs))
+}
+
+// writeSnapshot replaces the snapshot file atomically, so a crash while
+// writing never leaves a truncated snapshot behind.
+func writeSnapshot(path string, snap jobSnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// RestoreSnapshot registers the jobs of the snapshot file, if one is
+// configured and exists, and loads their paused and dispatch states. It must
+// be called before the first reconcile, which then corrects any job that
+// changed while ghacron was down. Until it succeeds, the scheduler is still
+// not ready (HasReconciled).
+func (s *Scheduler) RestoreSnapshot(ctx context.Context) error {
+	path := s.config.SnapshotPath
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read job snapshot: %w", err)
+	}
+	var snap jobSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode job snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported job snapshot version %d", snap.Version)
+	}
+
+	var restored []github.CronAnnotation
+	for _, annotation := range snap.Jobs {
+		if err := s.AddJob(ctx, annotation); err != nil {
+			slog.ErrorContext(ctx, "failed to restore job", "error", err)
+			continue
+		}
+		restored = append(restored, annotation)
+	}
+	s.setWorkflowIDs(restored)
+	s.SetSkippedAnnotations(snap.Skipped)
+	slog.InfoContext(ctx, "restored jobs from snapshot",
+		"path", path,
+		"jobs", len(restored),
+		"saved_at", snap.SavedAt,
+	)
+
+	for _, annotation := range restored {
+		s.loadPausedState(ctx, annotation)
+		s.loadJobDispatchState(ctx, annotation)
+	}
+	return nil
+}
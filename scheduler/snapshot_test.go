@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/scanner"
+)
+
+func TestSnapshot_SaveAndRestore(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.SnapshotPath = filepath.Join(t.TempDir(), "jobs.json")
+
+	s := newTestScheduler(&mockClient{}, cfg)
+	annotation := testAnnotation()
+	annotation.Name = "nightly"
+	annotation.Jitter = 30 * time.Second
+	annotation.Stagger = 7 * time.Second
+	annotation.WorkflowID = 42
+	registerTestJob(t, s, annotation)
+	skipped := []scanner.SkippedAnnotation{{Owner: "o", Repo: "r", Path: ".github/workflows/x.yml", Line: 3, CronExpr: "bad", Reason: "invalid"}}
+	s.SetSkippedAnnotations(skipped)
+	s.finishReconcile(context.Background(), time.Now(), true)
+
+	restored := newTestScheduler(&mockClient{}, cfg)
+	if err := restored.RestoreSnapshot(context.Background()); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+
+	got, ok := restored.GetRegisteredAnnotation(annotation.Key())
+	if !ok {
+		t.Fatal("job not restored")
+	}
+	if !got.SameConfig(annotation) || got.WorkflowID != 42 {
+		t.Errorf("restored job = %+v, want %+v", got, annotation)
+	}
+	if ids := restored.workflowIDs[annotation.Key()]; ids != 42 {
+		t.Errorf("workflow ID = %d, want 42", ids)
+	}
+	if sk := restored.GetSkippedAnnotations(); len(sk) != 1 || sk[0] != skipped[0] {
+		t.Errorf("skipped = %+v, want %+v", sk, skipped)
+	}
+	if restored.HasReconciled() {
+		t.Error("HasReconciled = true after restore, want false until the first reconcile")
+	}
+}
+
+func TestRestoreSnapshot_MissingFile(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.SnapshotPath = filepath.Join(t.TempDir(), "jobs.json")
+	s := newTestScheduler(&mockClient{}, cfg)
+
+	if err := s.RestoreSnapshot(context.Background()); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if n := s.GetRegisteredJobCount(); n != 0 {
+		t.Errorf("registered jobs = %d, want 0", n)
+	}
+}
+
+func TestRestoreSnapshot_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"corrupt":             "{",
+		"unsupported version": `{"version": 99, "jobs": []}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.SnapshotPath = filepath.Join(t.TempDir(), "jobs.json")
+			if err := os.WriteFile(cfg.SnapshotPath, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			s := newTestScheduler(&mockClient{}, cfg)
+			if err := s.RestoreSnapshot(context.Background()); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// splayer spreads dispatches that fire on the same cron tick evenly over a
+// window, so hundreds of jobs sharing a schedule don't hit the API at once.
+type splayer struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	tick    time.Time // minute of the tick currently being spread
+	started int       // handlers already assigned a slot for tick
+}
+
+func newSplayer(window time.Duration) *splayer {
+	return &splayer{window: window}
+}
+
+// delay returns the offset for the next handler of tick, given the number of
+// jobs due on that tick. Slots are assigned in arrival order: the first handler
+// runs immediately and the last one by the end of the window.
+func (sp *splayer) delay(tick time.Time, due int) time.Duration {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if !tick.Equal(sp.tick) {
+		sp.tick = tick
+		sp.started = 0
+	}
+	slot := sp.started
+	sp.started++
+
+	if due <= 1 {
+		return 0
+	}
+	// Handlers beyond the expected count (e.g. late registrations) share the last slot.
+	slot = min(slot, due-1)
+	return time.Duration(slot) * (sp.window / time.Duration(due))
+}
@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplayer_Delay(t *testing.T) {
+	sp := newSplayer(60 * time.Second)
+	tick := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	want := []time.Duration{0, 15 * time.Second, 30 * time.Second, 45 * time.Second, 45 * time.Second}
+	for i, w := range want {
+		if got := sp.delay(tick, 4); got != w {
+			t.Errorf("delay #%d = %v, want %v", i, got, w)
+		}
+	}
+
+	// A new tick starts from the first slot again.
+	if got := sp.delay(tick.Add(time.Minute), 4); got != 0 {
+		t.Errorf("delay on new tick = %v, want 0", got)
+	}
+}
+
+func TestSplayer_SingleJob(t *testing.T) {
+	sp := newSplayer(60 * time.Second)
+	if got := sp.delay(time.Now().Truncate(time.Minute), 1); got != 0 {
+		t.Errorf("delay = %v, want 0 for a single due job", got)
+	}
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+
+	"github.com/robfig/cron/v3"
+)
+
+// staggeredSchedule fires offset after each fire time of schedule.
+type staggeredSchedule struct {
+	schedule cron.Schedule
+	offset   time.Duration
+}
+
+func (s staggeredSchedule) Next(t time.Time) time.Time {
+	next := s.schedule.Next(t.Add(-s.offset))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(s.offset)
+}
+
+// staggerOffset returns the offset of a job within window, derived from its
+// ID like Jenkins' H so it is the same across reconciles and restarts.
+func staggerOffset(key github.CronJobKey, window time.Duration) time.Duration {
+	seconds := int64(window / time.Second)
+	if seconds <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key.ID()))
+	return time.Duration(h.Sum64()%uint64(seconds)) * time.Second
+}
+
+// staggerJobs sets the Stagger offset of the desired annotations whose cron
+// expression is shared by at least the configured number of jobs, so their
+// cron entries do not all fire at the same instant. others are the jobs that
+// stay registered outside desired; they count towards the threshold.
+func (r *Reconciler) staggerJobs(desired, others []github.CronAnnotation) []github.CronAnnotation {
+	window := time.Duration(r.config.DispatchStaggerSeconds) * time.Second
+	if window <= 0 {
+		return desired
+	}
+
+	shared := make(map[string]int)
+	for _, a := range desired {
+		shared[a.CronExpr]++
+	}
+	for _, a := range others {
+		shared[a.CronExpr]++
+	}
+
+	staggered := make([]github.CronAnnotation, len(desired))
+	for i, a := range desired {
+		a.Stagger = 0
+		if shared[a.CronExpr] >= r.config.DispatchStaggerThreshold {
+			a.Stagger = staggerOffset(a.Key(), window)
+		}
+		staggered[i] = a
+	}
+	return staggered
+}
+
+// registeredAnnotationsExcept returns the annotations of the registered jobs
+// whose keys are not in keys.
+func (s *Scheduler) registeredAnnotationsExcept(keys []github.CronJobKey) []github.CronAnnotation {
+	excluded := make(map[github.CronJobKey]struct{}, len(keys))
+	for _, key := range keys {
+		excluded[key] = struct{}{}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var annotations []github.CronAnnotation
+	for key, job := range s.registeredJobs {
+		if _, ok := excluded[key]; !ok {
+			annotations = append(annotations, job.annotation)
+		}
+	}
+	return annotations
+}
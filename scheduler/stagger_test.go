@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestStaggeredSchedule_Next(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := staggeredSchedule{schedule: schedule, offset: 42 * time.Second}
+
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 9, 0, 42, 0, time.UTC)},
+		// Between the unstaggered and the staggered fire time.
+		{time.Date(2026, 1, 1, 9, 0, 10, 0, time.UTC), time.Date(2026, 1, 1, 9, 0, 42, 0, time.UTC)},
+		// Right after firing, as the cron engine asks.
+		{time.Date(2026, 1, 1, 9, 0, 42, 1000, time.UTC), time.Date(2026, 1, 2, 9, 0, 42, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := s.Next(tt.now); !got.Equal(tt.want) {
+			t.Errorf("Next(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestStaggerOffset(t *testing.T) {
+	window := 30 * time.Second
+	seen := make(map[time.Duration]bool)
+	for _, file := range []string{"a.yml", "b.yml", "c.yml", "d.yml", "e.yml"} {
+		a := testAnnotation()
+		a.WorkflowFile = file
+		offset := staggerOffset(a.Key(), window)
+		if offset < 0 || offset >= window || offset%time.Second != 0 {
+			t.Errorf("offset of %s = %v, want whole seconds in [0, %v)", file, offset, window)
+		}
+		if again := staggerOffset(a.Key(), window); again != offset {
+			t.Errorf("offset of %s is not stable: %v, then %v", file, offset, again)
+		}
+		seen[offset] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("offsets are not spread: %v", seen)
+	}
+}
+
+func TestStaggerJobs(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.DispatchStaggerSeconds = 60
+	cfg.DispatchStaggerThreshold = 3
+	s := newTestScheduler(&mockClient{}, cfg)
+	r := NewReconciler(&mockClient{}, s, cfg)
+
+	var desired []github.CronAnnotation
+	for _, file := range []string{"a.yml", "b.yml"} {
+		a := testAnnotation()
+		a.WorkflowFile = file
+		desired = append(desired, a)
+	}
+	lone := testAnnotation()
+	lone.WorkflowFile = "lone.yml"
+	lone.CronExpr = "0 10 * * *"
+	lone.Stagger = 5 * time.Second // left from an earlier reconcile
+	desired = append(desired, lone)
+	other := testAnnotation()
+	other.Repo = "other-repo"
+
+	got := r.staggerJobs(desired, []github.CronAnnotation{other})
+	for _, a := range got[:2] {
+		if want := staggerOffset(a.Key(), time.Minute); a.Stagger != want {
+			t.Errorf("stagger of %s = %v, want %v", a.WorkflowFile, a.Stagger, want)
+		}
+	}
+	if got[2].Stagger != 0 {
+		t.Errorf("stagger of lone job = %v, want 0", got[2].Stagger)
+	}
+
+	// Without the others, the schedule is below the threshold.
+	for _, a := range r.staggerJobs(desired[:2], nil) {
+		if a.Stagger != 0 {
+			t.Errorf("stagger of %s below threshold = %v, want 0", a.WorkflowFile, a.Stagger)
+		}
+	}
+}
+
+func TestAddJob_Staggered(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	a := testAnnotation() // 0 9 * * *
+	a.Stagger = 17 * time.Second
+	registerTestJob(t, s, a)
+
+	details := s.GetJobDetails()
+	if len(details) != 1 {
+		t.Fatalf("details count: got %d, want 1", len(details))
+	}
+	if details[0].Stagger != "17s" {
+		t.Errorf("Stagger = %q, want 17s", details[0].Stagger)
+	}
+	for _, run := range details[0].NextRuns {
+		if run.Hour() != 9 || run.Minute() != 0 || run.Second() != 17 {
+			t.Errorf("next run %v, want 09:00:17", run)
+		}
+	}
+}
@@ -2,61 +2,323 @@ package scheduler
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/korosuke613/ghacron/github"
 )
 
+// defaultVariablePrefix is the default prefix of state variable names
+// (GHACRON_STATE_VARIABLE_PREFIX).
+const defaultVariablePrefix = "GHACRON_"
+
+// Kinds of per-job state, each stored in its own variable named
+// <prefix><kind><suffix>.
+const (
+	lastDispatchKind = "LAST_"
+	pausedKind       = "PAUSED_"
+)
+
+// stateKinds lists every kind of per-job state.
+var stateKinds = []string{lastDispatchKind, pausedKind}
+
+// Dispatch outcomes recorded in DispatchState.
+const (
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+// DispatchState is the persisted state of a job's dispatches, stored as JSON
+// in its GHACRON_LAST_* variable.
+type DispatchState struct {
+	// Time is the last successful dispatch, used by the duplicate guard.
+	Time time.Time `json:"time"`
+	// LastAttempt is when the last dispatch was attempted, and Outcome how it
+	// ended ("success" or "failure").
+	LastAttempt time.Time `json:"last_attempt"`
+	Outcome     string    `json:"outcome,omitempty"`
+	// RunID is the workflow run created by the last successful dispatch
+	// (known with dispatch verification or workflow_run webhooks), and
+	// RunConclusion how it ended (known from workflow_run webhooks).
+	RunID         int64  `json:"run_id,omitempty"`
+	RunConclusion string `json:"run_conclusion,omitempty"`
+	// Nonce identifies the claim that wrote the state (see ClaimDispatch).
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// successState returns the state of a successful dispatch at t.
+func successState(t time.Time) DispatchState {
+	return DispatchState{Time: t, LastAttempt: t, Outcome: outcomeSuccess}
+}
+
+// encodeDispatchState renders a state as a variable value.
+func encodeDispatchState(state DispatchState) string {
+	b, _ := json.Marshal(state) // cannot fail for this type
+	return string(b)
+}
+
+// decodeDispatchState parses a variable value. Values written by older
+// versions are a bare RFC3339 time, optionally followed by ";<nonce>".
+func decodeDispatchState(value string) (DispatchState, error) {
+	if strings.HasPrefix(value, "{") {
+		var state DispatchState
+		if err := json.Unmarshal([]byte(value), &state); err != nil {
+			return DispatchState{}, err
+		}
+		return state, nil
+	}
+
+	timestamp, nonce, _ := strings.Cut(value, ";")
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return DispatchState{}, err
+	}
+	state := successState(t)
+	state.Nonce = nonce
+	return state, nil
+}
+
+// State scopes (GHACRON_STATE_SCOPE); see also stateScopeGit.
+const (
+	stateScopeRepo = "repo" // repository variables
+	stateScopeOrg  = "org"  // organization variables of the repository owner
+)
+
 // StateClient is the interface used by StateManager.
 type StateClient interface {
 	GetVariable(ctx context.Context, owner, repo, name string) (string, error)
 	SetVariable(ctx context.Context, owner, repo, name, value string) error
+	DeleteVariable(ctx context.Context, owner, repo, name string) error
+	ListVariables(ctx context.Context, owner, repo string) ([]string, error)
+	GetOrgVariable(ctx context.Context, org, name string) (string, error)
+	SetOrgVariable(ctx context.Context, org, name, value string) error
+	DeleteOrgVariable(ctx context.Context, org, name string) error
+	ListOrgVariables(ctx context.Context, org string) ([]string, error)
 }
 
-// StateManager manages state via GitHub Actions Variables.
+// StateManager manages state via GitHub Actions Variables, or variables kept
+// in a state file by gitStateStore.
 type StateManager struct {
-	client StateClient
+	client   StateClient
+	orgScope bool
+	prefix   string      // variable name prefix, e.g. "GHACRON_"
+	cache    *stateCache // nil when caching is disabled
 }
 
-// NewStateManager creates a new StateManager.
+// NewStateManager creates a new StateManager storing state in repository
+// variables with the default name prefix.
 func NewStateManager(client StateClient) *StateManager {
-	return &StateManager{client: client}
+	return &StateManager{client: client, prefix: defaultVariablePrefix}
 }
 
-// GetLastDispatchTime retrieves the last dispatch time.
-func (sm *StateManager) GetLastDispatchTime(ctx context.Context, annotation github.CronAnnotation) (time.Time, error) {
-	varName := sm.variableName(annotation)
+// newScopedStateManager creates a StateManager for the given state scope and
+// variable name prefix ("" selects the default prefix).
+func newScopedStateManager(client StateClient, scope, prefix string) *StateManager {
+	if prefix == "" {
+		prefix = defaultVariablePrefix
+	}
+	return &StateManager{client: client, orgScope: scope == stateScopeOrg, prefix: prefix}
+}
+
+// getVariable reads a state variable of the annotation's repository, from
+// the cache if possible.
+func (sm *StateManager) getVariable(ctx context.Context, annotation github.CronAnnotation, name string) (string, error) {
+	if sm.cache != nil {
+		if value, ok := sm.cache.get(sm.cacheKey(annotation, name), time.Now()); ok {
+			return value, nil
+		}
+	}
+	return sm.fetchVariable(ctx, annotation, name)
+}
+
+// fetchVariable reads a state variable from GitHub, bypassing (and refreshing) the cache.
+func (sm *StateManager) fetchVariable(ctx context.Context, annotation github.CronAnnotation, name string) (string, error) {
+	var value string
+	var err error
+	if sm.orgScope {
+		value, err = sm.client.GetOrgVariable(ctx, annotation.Owner, name)
+	} else {
+		value, err = sm.client.GetVariable(ctx, annotation.Owner, annotation.Repo, name)
+	}
+	if err == nil && sm.cache != nil {
+		sm.cache.set(sm.cacheKey(annotation, name), value, time.Now())
+	}
+	return value, err
+}
+
+// setVariable writes a state variable of the annotation's repository.
+func (sm *StateManager) setVariable(ctx context.Context, annotation github.CronAnnotation, name, value string) error {
+	var err error
+	if sm.orgScope {
+		err = sm.client.SetOrgVariable(ctx, annotation.Owner, name, value)
+	} else {
+		err = sm.client.SetVariable(ctx, annotation.Owner, annotation.Repo, name, value)
+	}
+	if sm.cache != nil {
+		key := sm.cacheKey(annotation, name)
+		if err != nil {
+			sm.cache.invalidate(key) // the stored value is unknown now
+		} else {
+			sm.cache.set(key, value, time.Now())
+		}
+	}
+	return err
+}
+
+// deleteVariable deletes a state variable of the annotation's repository.
+func (sm *StateManager) deleteVariable(ctx context.Context, annotation github.CronAnnotation, name string) error {
+	var err error
+	if sm.orgScope {
+		err = sm.client.DeleteOrgVariable(ctx, annotation.Owner, name)
+	} else {
+		err = sm.client.DeleteVariable(ctx, annotation.Owner, annotation.Repo, name)
+	}
+	if sm.cache != nil {
+		sm.cache.invalidate(sm.cacheKey(annotation, name))
+	}
+	return err
+}
+
+// cacheKey identifies a state variable in the cache.
+func (sm *StateManager) cacheKey(annotation github.CronAnnotation, name string) string {
+	if sm.orgScope {
+		return annotation.Owner + "/" + name
+	}
+	return annotation.Owner + "/" + annotation.Repo + "/" + name
+}
+
+// GetDispatchState retrieves the dispatch state. A job that was never
+// dispatched has the zero state.
+func (sm *StateManager) GetDispatchState(ctx context.Context, annotation github.CronAnnotation) (DispatchState, error) {
+	varName := sm.variableName(lastDispatchKind, annotation)
 
-	value, err := sm.client.GetVariable(ctx, annotation.Owner, annotation.Repo, varName)
+	value, err := sm.getVariable(ctx, annotation, varName)
 	if err != nil {
-		return time.Time{}, err
+		return DispatchState{}, err
 	}
 	if value == "" {
-		return time.Time{}, nil // variable does not exist = never dispatched
+		return DispatchState{}, nil // variable does not exist = never dispatched
 	}
 
-	t, err := time.Parse(time.RFC3339, value)
+	state, err := decodeDispatchState(value)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse dispatch time (%q): %w", value, err)
+		return DispatchState{}, fmt.Errorf("failed to parse dispatch state (%q): %w", value, err)
 	}
+	return state, nil
+}
+
+// SetDispatchState persists the dispatch state.
+func (sm *StateManager) SetDispatchState(ctx context.Context, annotation github.CronAnnotation, state DispatchState) error {
+	varName := sm.variableName(lastDispatchKind, annotation)
 
-	return t, nil
+	return sm.setVariable(ctx, annotation, varName, encodeDispatchState(state))
 }
 
-// SetLastDispatchTime persists the dispatch time.
+// GetLastDispatchTime retrieves the last dispatch time.
+func (sm *StateManager) GetLastDispatchTime(ctx context.Context, annotation github.CronAnnotation) (time.Time, error) {
+	state, err := sm.GetDispatchState(ctx, annotation)
+	return state.Time, err
+}
+
+// SetLastDispatchTime persists a successful dispatch at t.
 func (sm *StateManager) SetLastDispatchTime(ctx context.Context, annotation github.CronAnnotation, t time.Time) error {
-	varName := sm.variableName(annotation)
-	value := t.Format(time.RFC3339)
+	return sm.SetDispatchState(ctx, annotation, successState(t))
+}
+
+// ClaimDispatch persists a successful dispatch at t together with a random nonce,
+// waits for settle and reads the value back. It reports false if another
+// instance overwrote the value in the meantime, i.e. that instance won the
+// dispatch (last writer wins). A failed read-back fails open and reports true.
+func (sm *StateManager) ClaimDispatch(ctx context.Context, annotation github.CronAnnotation, t time.Time, settle time.Duration) (bool, error) {
+	varName := sm.variableName(lastDispatchKind, annotation)
+	state := successState(t)
+	state.Nonce = newNonce()
+	value := encodeDispatchState(state)
+
+	if err := sm.setVariable(ctx, annotation, varName, value); err != nil {
+		return false, err
+	}
+
+	if !sleep(settle, ctx.Done()) {
+		return false, ctx.Err()
+	}
+
+	// Read back from GitHub: the cache holds the value just written.
+	current, err := sm.fetchVariable(ctx, annotation, varName)
+	if err != nil {
+		return true, nil
+	}
+	return current == value, nil
+}
+
+// newNonce returns a random token identifying a single claim.
+func newNonce() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// GetPaused reports whether the job has been paused by an operator.
+func (sm *StateManager) GetPaused(ctx context.Context, annotation github.CronAnnotation) (bool, error) {
+	varName := sm.variableName(pausedKind, annotation)
+
+	value, err := sm.getVariable(ctx, annotation, varName)
+	if err != nil {
+		return false, err
+	}
+	return value == "true", nil // variable does not exist = not paused
+}
+
+// SetPaused persists the paused state of the job.
+func (sm *StateManager) SetPaused(ctx context.Context, annotation github.CronAnnotation, paused bool) error {
+	varName := sm.variableName(pausedKind, annotation)
 
-	return sm.client.SetVariable(ctx, annotation.Owner, annotation.Repo, varName, value)
+	return sm.setVariable(ctx, annotation, varName, strconv.FormatBool(paused))
 }
 
-// variableName generates a variable name from an annotation.
-// Format: GHACRON_LAST_<first 8 hex chars of SHA256>
-func (sm *StateManager) variableName(annotation github.CronAnnotation) string {
+// variableName generates a variable name from a state kind and an annotation.
+// Format: <prefix><kind><NAME> for named jobs, otherwise
+// <prefix><kind><first 8 hex chars of SHA256>. Organization variables are
+// shared by all repositories of the owner, so in org scope the kind is
+// followed by the repository token (see repoPrefix).
+func (sm *StateManager) variableName(kind string, annotation github.CronAnnotation) string {
+	prefix := sm.kindPrefix(kind)
+	if sm.orgScope {
+		prefix = repoPrefix(prefix, annotation.Owner, annotation.Repo)
+	}
+	if token := annotation.NameToken(); token != "" {
+		return prefix + token
+	}
 	input := annotation.WorkflowFile + ":" + annotation.CronExpr
 	hash := sha256.Sum256([]byte(input))
-	return fmt.Sprintf("GHACRON_LAST_%X", hash[:4])
+	return fmt.Sprintf("%s%X", prefix, hash[:4])
+}
+
+// kindPrefix returns the variable name prefix of a state kind, e.g. "GHACRON_LAST_".
+func (sm *StateManager) kindPrefix(kind string) string {
+	return sm.prefix + kind
+}
+
+// isStateVariable reports whether a variable name belongs to this instance's state.
+func (sm *StateManager) isStateVariable(name string) bool {
+	for _, kind := range stateKinds {
+		if strings.HasPrefix(name, sm.kindPrefix(kind)) {
+			return true
+		}
+	}
+	return false
+}
+
+// repoPrefix returns the org-scope variable name prefix of a repository:
+// <prefix><first 8 hex chars of SHA256 of owner/repo>_. The fixed-length
+// token keeps the variables of different repositories apart.
+func repoPrefix(prefix, owner, repo string) string {
+	hash := sha256.Sum256([]byte(owner + "/" + repo))
+	return fmt.Sprintf("%s%X_", prefix, hash[:4])
 }
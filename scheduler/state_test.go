@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noSleep makes delays return immediately (unless cancelled).
+func noSleep(t *testing.T) {
+	t.Helper()
+	orig := sleep
+	sleep = func(_ time.Duration, cancel <-chan struct{}) bool {
+		select {
+		case <-cancel:
+			return false
+		default:
+			return true
+		}
+	}
+	t.Cleanup(func() { sleep = orig })
+}
+
+// overwritingClient simulates another replica writing after our claim.
+type overwritingClient struct {
+	*mockClient
+}
+
+func (c overwritingClient) GetVariable(ctx context.Context, owner, repo, name string) (string, error) {
+	c.mockClient.mu.Lock()
+	if c.mockClient.vars != nil {
+		c.mockClient.vars[name] = "2026-03-01T09:00:00Z;other"
+	}
+	c.mockClient.mu.Unlock()
+	return c.mockClient.GetVariable(ctx, owner, repo, name)
+}
+
+func TestDecodeDispatchState(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	want := DispatchState{Time: at, LastAttempt: at.Add(time.Hour), Outcome: outcomeFailure, RunID: 42}
+
+	tests := map[string]struct {
+		value string
+		want  DispatchState
+	}{
+		"json":              {encodeDispatchState(want), want},
+		"legacy time":       {"2026-03-01T09:00:00Z", successState(at)},
+		"legacy with nonce": {"2026-03-01T09:00:00Z;abc", DispatchState{Time: at, LastAttempt: at, Outcome: outcomeSuccess, Nonce: "abc"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeDispatchState(tt.value)
+			if err != nil {
+				t.Fatalf("decodeDispatchState(%q): %v", tt.value, err)
+			}
+			if !got.Time.Equal(tt.want.Time) || !got.LastAttempt.Equal(tt.want.LastAttempt) ||
+				got.Outcome != tt.want.Outcome || got.RunID != tt.want.RunID || got.Nonce != tt.want.Nonce {
+				t.Errorf("decodeDispatchState(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := decodeDispatchState("not a time"); err == nil {
+		t.Error("expected error for invalid value")
+	}
+}
+
+func TestClaimDispatch(t *testing.T) {
+	noSleep(t)
+	annotation := testAnnotation()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	mock := &mockClient{}
+	won, err := NewStateManager(mock).ClaimDispatch(context.Background(), annotation, now, time.Second)
+	if err != nil || !won {
+		t.Fatalf("ClaimDispatch = %v, %v; want true, nil", won, err)
+	}
+	if state, err := decodeDispatchState(mock.setVarArgs[0].value); err != nil || !state.Time.Equal(now) || state.Nonce == "" {
+		t.Errorf("claimed value = %q, want time with nonce", mock.setVarArgs[0].value)
+	}
+
+	// The claimed value still parses as the last dispatch time.
+	got, err := NewStateManager(mock).GetLastDispatchTime(context.Background(), annotation)
+	if err != nil || !got.Equal(now) {
+		t.Errorf("GetLastDispatchTime = %v, %v; want %v", got, err, now)
+	}
+
+	lost, err := NewStateManager(overwritingClient{&mockClient{}}).ClaimDispatch(context.Background(), annotation, now, time.Second)
+	if err != nil || lost {
+		t.Errorf("ClaimDispatch after overwrite = %v, %v; want false, nil", lost, err)
+	}
+}
+
+func TestHandler_LostClaim_SkipsDispatch(t *testing.T) {
+	noSleep(t)
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	cfg.ClaimSettleSeconds = 2
+	s := newTestScheduler(overwritingClient{mock}, cfg)
+
+	s.createJobHandler(testAnnotation())()
+
+	if mock.dispatchCalls != 0 {
+		t.Errorf("DispatchWorkflow call count: got %d, want 0 (claim lost)", mock.dispatchCalls)
+	}
+	if mock.setVarCalls != 1 {
+		t.Errorf("SetVariable call count: got %d, want 1 (no rollback)", mock.setVarCalls)
+	}
+}
+
+func TestStateManager_OrgScope(t *testing.T) {
+	mock := &mockClient{}
+	sm := newScopedStateManager(mock, stateScopeOrg, "")
+	annotation := testAnnotation()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	if err := sm.SetLastDispatchTime(context.Background(), annotation, now); err != nil {
+		t.Fatalf("SetLastDispatchTime: %v", err)
+	}
+	if mock.setVarCalls != 0 || len(mock.orgVars) != 1 {
+		t.Fatalf("repo/org variable writes = %d/%d, want 0/1", mock.setVarCalls, len(mock.orgVars))
+	}
+	got, err := sm.GetLastDispatchTime(context.Background(), annotation)
+	if err != nil || !got.Equal(now) {
+		t.Errorf("GetLastDispatchTime = %v, %v; want %v", got, err, now)
+	}
+
+	// Jobs with the same file and schedule in different repositories of the
+	// organization must not share a variable.
+	other := annotation
+	other.Repo = "other-repo"
+	if a, b := sm.variableName(lastDispatchKind, annotation), sm.variableName(lastDispatchKind, other); a == b {
+		t.Errorf("org-scope variable names collide across repositories: %s", a)
+	}
+	if name := sm.variableName(lastDispatchKind, annotation); !strings.HasPrefix(name, repoPrefix(sm.kindPrefix(lastDispatchKind), annotation.Owner, annotation.Repo)) {
+		t.Errorf("org-scope variable name %s lacks repository prefix", name)
+	}
+}
+
+func TestStateManager_VariablePrefix(t *testing.T) {
+	annotation := testAnnotation()
+	annotation.Name = "nightly"
+	sm := newScopedStateManager(nil, stateScopeRepo, "STAGING_")
+
+	if got, want := sm.variableName(lastDispatchKind, annotation), "STAGING_LAST_NIGHTLY"; got != want {
+		t.Errorf("variableName = %q, want %q", got, want)
+	}
+	if sm.isStateVariable("GHACRON_LAST_NIGHTLY") {
+		t.Error("variable of another prefix treated as own state")
+	}
+	if !sm.isStateVariable("STAGING_PAUSED_NIGHTLY") {
+		t.Error("own paused variable not treated as state")
+	}
+}
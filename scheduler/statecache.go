@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// stateCache is an in-memory read-through cache of state variable values, so
+// duplicate-guard checks of frequently firing jobs do not consume API rate
+// limit. Writes made through the cache update it; values changed by anyone
+// else (another instance, an operator) are seen once the entry expires.
+type stateCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]stateCacheEntry
+}
+
+type stateCacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+func newStateCache(ttl time.Duration) *stateCache {
+	return &stateCache{ttl: ttl, entries: make(map[string]stateCacheEntry)}
+}
+
+// get returns the cached value of key if it has not expired at now.
+func (c *stateCache) get(key string, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !now.Before(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+// set caches the value of key from now on.
+func (c *stateCache) set(key, value string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = stateCacheEntry{value: value, expires: now.Add(c.ttl)}
+}
+
+// invalidate drops the cached value of key.
+func (c *stateCache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
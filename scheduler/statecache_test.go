@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStateCache_Expires(t *testing.T) {
+	c := newStateCache(time.Minute)
+	now := time.Now()
+
+	c.set("k", "v", now)
+	if v, ok := c.get("k", now.Add(59*time.Second)); !ok || v != "v" {
+		t.Errorf("get before expiry = %q, %v; want v, true", v, ok)
+	}
+	if _, ok := c.get("k", now.Add(time.Minute)); ok {
+		t.Error("get after expiry hit")
+	}
+}
+
+func TestStateManager_CachesReads(t *testing.T) {
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	s := newTestScheduler(mock, cfg)
+	s.stateCache = newStateCache(time.Hour)
+	annotation := testAnnotation()
+	ctx := context.Background()
+
+	if _, err := s.stateManager().GetLastDispatchTime(ctx, annotation); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.stateManager().GetLastDispatchTime(ctx, annotation); err != nil {
+		t.Fatal(err)
+	}
+	if mock.getVarCalls != 1 {
+		t.Errorf("GetVariable call count: got %d, want 1 (second read cached)", mock.getVarCalls)
+	}
+
+	// Writes update the cache.
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := s.stateManager().SetLastDispatchTime(ctx, annotation, now); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.stateManager().GetLastDispatchTime(ctx, annotation)
+	if err != nil || !got.Equal(now) || mock.getVarCalls != 1 {
+		t.Errorf("read after write = %v (%v, %d API reads), want %v from cache", got, err, mock.getVarCalls, now)
+	}
+}
+
+func TestClaimDispatch_ReadsBackBypassingCache(t *testing.T) {
+	noSleep(t)
+	mock := &mockClient{}
+	s := newTestScheduler(overwritingClient{mock}, defaultConfig())
+	s.stateCache = newStateCache(time.Hour)
+
+	won, err := s.stateManager().ClaimDispatch(context.Background(), testAnnotation(), time.Now(), time.Second)
+	if err != nil || won {
+		t.Errorf("ClaimDispatch = %v, %v; want false (overwritten), nil", won, err)
+	}
+}
@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// JobStats summarizes the dispatches of a job within a window, computed from
+// history.
+type JobStats struct {
+	JobID string    `json:"job_id"`
+	Since time.Time `json:"since"`
+	// Dispatches counts the dispatch attempts (one per ref), Succeeded and
+	// Failed how many of them GitHub accepted or rejected.
+	Dispatches int `json:"dispatches"`
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+	// SuccessRate is Succeeded / Dispatches (nil without dispatches).
+	SuccessRate *float64 `json:"success_rate"`
+	// RunConclusions counts the conclusions of the runs found by dispatch
+	// verification, and RunSuccessRate is the share of "success" among them
+	// (nil without concluded runs).
+	RunConclusions map[string]int `json:"run_conclusions,omitempty"`
+	RunSuccessRate *float64       `json:"run_success_rate"`
+	// AverageDriftSeconds is the mean delay between the scheduled time of a
+	// cron tick and its successful dispatch (nil without scheduled dispatches).
+	AverageDriftSeconds *float64 `json:"average_drift_seconds"`
+	// LastFailure is the latest failed dispatch or run that did not succeed.
+	LastFailure *DispatchRecord `json:"last_failure"`
+}
+
+// JobStats returns the statistics of a job over the window ending now. Jobs
+// no longer registered are found as long as they have records in history.
+func (s *Scheduler) JobStats(id string, window time.Duration) (JobStats, error) {
+	since := time.Now().Add(-window)
+	records := s.history.list(HistoryQuery{JobID: id, Since: since})
+	if _, ok := s.findJob(id); !ok && len(records) == 0 {
+		return JobStats{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	stats := computeJobStats(records)
+	stats.JobID = id
+	stats.Since = since
+	return stats, nil
+}
+
+// computeJobStats summarizes records, given newest first.
+func computeJobStats(records []DispatchRecord) JobStats {
+	var stats JobStats
+	var drift time.Duration
+	var drifts, concluded, runSuccesses int
+	for _, rec := range records {
+		stats.Dispatches++
+		failed := rec.Error != ""
+		if failed {
+			stats.Failed++
+		} else {
+			stats.Succeeded++
+			if rec.ScheduledAt != nil {
+				drift += rec.DispatchedAt.Sub(*rec.ScheduledAt)
+				drifts++
+			}
+		}
+		if rec.Conclusion != "" {
+			if stats.RunConclusions == nil {
+				stats.RunConclusions = make(map[string]int)
+			}
+			stats.RunConclusions[rec.Conclusion]++
+			concluded++
+			if rec.Conclusion == outcomeSuccess {
+				runSuccesses++
+			} else {
+				failed = true
+			}
+		}
+		if failed && stats.LastFailure == nil {
+			stats.LastFailure = &rec
+		}
+	}
+
+	ratio := func(n, total int) *float64 {
+		if total == 0 {
+			return nil
+		}
+		r := float64(n) / float64(total)
+		return &r
+	}
+	stats.SuccessRate = ratio(stats.Succeeded, stats.Dispatches)
+	stats.RunSuccessRate = ratio(runSuccesses, concluded)
+	if drifts > 0 {
+		mean := drift.Seconds() / float64(drifts)
+		stats.AverageDriftSeconds = &mean
+	}
+	return stats
+}
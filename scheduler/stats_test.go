@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestComputeJobStats(t *testing.T) {
+	tick := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := tick.Add(d)
+		return &t
+	}
+	// Newest first.
+	records := []DispatchRecord{
+		{ID: 4, ScheduledAt: at(72 * time.Hour), DispatchedAt: tick.Add(72*time.Hour + 3*time.Second), Conclusion: "success"},
+		{ID: 3, ScheduledAt: at(48 * time.Hour), DispatchedAt: tick.Add(48 * time.Hour), Error: "API error"},
+		{ID: 2, ScheduledAt: at(24 * time.Hour), DispatchedAt: tick.Add(24*time.Hour + time.Second), Conclusion: "failure"},
+		{ID: 1, DispatchedAt: tick, Trigger: triggerManual},
+	}
+
+	stats := computeJobStats(records)
+	if stats.Dispatches != 4 || stats.Succeeded != 3 || stats.Failed != 1 {
+		t.Errorf("counts = %d/%d/%d, want 4 dispatches, 3 succeeded, 1 failed", stats.Dispatches, stats.Succeeded, stats.Failed)
+	}
+	if stats.SuccessRate == nil || *stats.SuccessRate != 0.75 {
+		t.Errorf("SuccessRate = %v, want 0.75", stats.SuccessRate)
+	}
+	if stats.RunSuccessRate == nil || *stats.RunSuccessRate != 0.5 || stats.RunConclusions["failure"] != 1 {
+		t.Errorf("run stats = %v, %v; want a rate of 0.5", stats.RunSuccessRate, stats.RunConclusions)
+	}
+	// Only successful scheduled dispatches count.
+	if stats.AverageDriftSeconds == nil || *stats.AverageDriftSeconds != 2 {
+		t.Errorf("AverageDriftSeconds = %v, want 2", stats.AverageDriftSeconds)
+	}
+	if stats.LastFailure == nil || stats.LastFailure.ID != 3 {
+		t.Errorf("LastFailure = %+v, want record 3", stats.LastFailure)
+	}
+
+	empty := computeJobStats(nil)
+	if empty.SuccessRate != nil || empty.AverageDriftSeconds != nil || empty.LastFailure != nil {
+		t.Errorf("stats without records = %+v, want nil rates", empty)
+	}
+}
+
+func TestJobStats_Window(t *testing.T) {
+	s := newTestScheduler(&mockClient{}, defaultConfig())
+	annotation := testAnnotation()
+	registerTestJob(t, s, annotation)
+	id := annotation.Key().ID()
+	s.history.add(DispatchRecord{JobID: id, DispatchedAt: time.Now().Add(-48 * time.Hour), Error: "old"})
+	s.history.add(DispatchRecord{JobID: id, DispatchedAt: time.Now()})
+
+	stats, err := s.JobStats(id, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Dispatches != 1 || stats.LastFailure != nil {
+		t.Errorf("stats = %+v, want only the dispatch within the window", stats)
+	}
+
+	if _, err := s.JobStats("unknown", time.Hour); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("unknown job: %v, want ErrJobNotFound", err)
+	}
+}
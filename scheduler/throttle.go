@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/metrics"
+)
+
+var dispatchQueueDepth = metrics.Default.NewGauge(
+	"ghacron_dispatch_queue_depth",
+	"Job runs waiting for the dispatch rate limit.",
+)
+
+// dispatchThrottle limits job runs to a rate, so bursts of runs (many jobs
+// sharing a minute, catch-ups, manual backfills) are spread out instead of
+// reaching the GitHub API at once. Up to burst runs pass without waiting;
+// the others queue and are let through one per interval, by priority and
+// then in arrival order. When the queue is full, the lowest priority run is
+// shed.
+type dispatchThrottle struct {
+	interval time.Duration
+	burst    int
+	maxQueue int // 0 = unlimited
+
+	mu        sync.Mutex
+	tat       time.Time         // theoretical arrival time of the next run (GCRA)
+	queue     []*throttleWaiter // by priority, then arrival
+	releasing bool              // a release goroutine is running
+}
+
+// throttleWaiter is a run waiting in the throttle queue.
+type throttleWaiter struct {
+	priority int
+	ready    chan bool // receives true when the run may proceed, false when it is shed
+}
+
+// newDispatchThrottle creates a throttle letting perMinute runs through per
+// minute, with bursts of up to burst runs and at most maxQueue waiting runs
+// (0 = unlimited).
+func newDispatchThrottle(perMinute, burst, maxQueue int) *dispatchThrottle {
+	return &dispatchThrottle{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    max(burst, 1),
+		maxQueue: max(maxQueue, 0),
+	}
+}
+
+// delay returns how long until the next slot is free. The caller must hold mu.
+func (t *dispatchThrottle) delay(now time.Time) time.Duration {
+	return max(t.tat.Add(-time.Duration(t.burst-1)*t.interval).Sub(now), 0)
+}
+
+// take uses the next slot. The caller must hold mu.
+func (t *dispatchThrottle) take(now time.Time) {
+	if t.tat.Before(now) {
+		t.tat = now
+	}
+	t.tat = t.tat.Add(t.interval)
+}
+
+// wait blocks until a run of the given priority (see priorityRank) may
+// proceed, it is shed from a full queue, or cancel is closed. It reports
+// whether the run may proceed.
+func (t *dispatchThrottle) wait(priority int, cancel <-chan struct{}) bool {
+	t.mu.Lock()
+	now := time.Now()
+	if len(t.queue) == 0 && t.delay(now) == 0 {
+		t.take(now)
+		t.mu.Unlock()
+		return true
+	}
+	w := &throttleWaiter{priority: priority, ready: make(chan bool, 1)}
+	if !t.enqueue(w) {
+		t.mu.Unlock()
+		return false
+	}
+	if !t.releasing {
+		t.releasing = true
+		go t.release(cancel)
+	}
+	t.mu.Unlock()
+
+	select {
+	case ok := <-w.ready:
+		return ok
+	case <-cancel:
+		t.mu.Lock()
+		t.remove(w)
+		t.mu.Unlock()
+		return false
+	}
+}
+
+// enqueue adds a waiter behind those of the same or a higher priority. If
+// the queue is full, the last waiter of a lower priority is shed to make
+// room; it reports false if there is none and w itself is shed. The caller
+// must hold mu.
+func (t *dispatchThrottle) enqueue(w *throttleWaiter) bool {
+	if t.maxQueue > 0 && len(t.queue) >= t.maxQueue {
+		last := t.queue[len(t.queue)-1]
+		if last.priority >= w.priority {
+			return false
+		}
+		t.remove(last)
+		last.ready <- false
+	}
+	i := slices.IndexFunc(t.queue, func(q *throttleWaiter) bool { return q.priority < w.priority })
+	if i < 0 {
+		i = len(t.queue)
+	}
+	t.queue = slices.Insert(t.queue, i, w)
+	dispatchQueueDepth.Set(float64(len(t.queue)))
+	return true
+}
+
+// remove drops a waiter from the queue, if it is still queued. The caller
+// must hold mu.
+func (t *dispatchThrottle) remove(w *throttleWaiter) {
+	if i := slices.Index(t.queue, w); i >= 0 {
+		t.queue = slices.Delete(t.queue, i, i+1)
+		dispatchQueueDepth.Set(float64(len(t.queue)))
+	}
+}
+
+// release lets queued runs through, one per free slot, until the queue is
+// empty or cancel is closed (queued runs then stop waiting on their own).
+func (t *dispatchThrottle) release(cancel <-chan struct{}) {
+	for {
+		t.mu.Lock()
+		if len(t.queue) == 0 {
+			t.releasing = false
+			t.mu.Unlock()
+			return
+		}
+		delay := t.delay(time.Now())
+		t.mu.Unlock()
+
+		if delay > 0 && !sleep(delay, cancel) {
+			t.mu.Lock()
+			t.releasing = false
+			t.mu.Unlock()
+			return
+		}
+
+		t.mu.Lock()
+		if len(t.queue) > 0 {
+			w := t.queue[0]
+			t.remove(w)
+			t.take(time.Now())
+			w.ready <- true
+		}
+		t.mu.Unlock()
+	}
+}
+
+// priorityRank orders the priorities of the priority= option: high runs go
+// first, low runs are shed first.
+func priorityRank(annotation github.CronAnnotation) int {
+	switch annotation.Priority {
+	case github.PriorityHigh:
+		return 1
+	case github.PriorityLow:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatchThrottle_Delay(t *testing.T) {
+	th := newDispatchThrottle(60, 2, 0)
+	now := time.Now()
+
+	// Two runs pass at once, then one per second.
+	var delays []time.Duration
+	for range 4 {
+		delays = append(delays, th.delay(now))
+		th.take(now.Add(th.delay(now)))
+	}
+	want := []time.Duration{0, 0, time.Second, 2 * time.Second}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("delays = %v, want %v", delays, want)
+		}
+	}
+
+	// The rate is restored after an idle period.
+	if d := th.delay(now.Add(time.Minute)); d != 0 {
+		t.Errorf("delay after idle = %v, want 0", d)
+	}
+}
+
+func TestDispatchThrottle_WaitStopsOnShutdown(t *testing.T) {
+	th := newDispatchThrottle(1, 1, 0)
+	stop := make(chan struct{})
+	if !th.wait(0, stop) {
+		t.Fatal("first run should not wait")
+	}
+	close(stop)
+	if th.wait(0, stop) {
+		t.Error("a queued run should stop waiting on shutdown")
+	}
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	if len(th.queue) != 0 {
+		t.Errorf("queue = %d, want 0", len(th.queue))
+	}
+}
+
+// queueRuns starts a waiting run per priority, in order, once each previous
+// run has queued, and returns a channel receiving the priorities of the runs
+// let through, and how many were shed.
+func queueRuns(t *testing.T, th *dispatchThrottle, priorities ...int) (<-chan int, *atomic.Int32) {
+	t.Helper()
+	released := make(chan int, len(priorities))
+	var shed atomic.Int32
+	for i, p := range priorities {
+		go func() {
+			if th.wait(p, nil) {
+				released <- p
+			} else {
+				shed.Add(1)
+			}
+		}()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			th.mu.Lock()
+			n := len(th.queue)
+			th.mu.Unlock()
+			if n+int(shed.Load()) > i || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return released, &shed
+}
+
+func TestDispatchThrottle_Priority(t *testing.T) {
+	gate := make(chan struct{})
+	orig := sleep
+	sleep = func(time.Duration, <-chan struct{}) bool {
+		<-gate
+		return true
+	}
+	t.Cleanup(func() { sleep = orig })
+
+	th := newDispatchThrottle(1, 1, 0)
+	th.wait(0, nil) // use the burst
+
+	released, _ := queueRuns(t, th, -1, 0, 1, 0)
+	// Release the runs one at a time to observe their order.
+	var order []int
+	for range 4 {
+		gate <- struct{}{}
+		order = append(order, <-released)
+	}
+	if want := []int{1, 0, 0, -1}; !slices.Equal(order, want) {
+		t.Errorf("release order = %v, want %v", order, want)
+	}
+}
+
+func TestDispatchThrottle_ShedsLowestPriority(t *testing.T) {
+	gate := make(chan struct{})
+	orig := sleep
+	sleep = func(time.Duration, <-chan struct{}) bool {
+		<-gate
+		return true
+	}
+	t.Cleanup(func() { sleep = orig })
+
+	th := newDispatchThrottle(1, 1, 2)
+	th.wait(0, nil)
+
+	// The low run makes room for the high one; the last normal run finds
+	// the queue full of runs of its priority or higher.
+	released, shed := queueRuns(t, th, -1, 0, 1, 0)
+	var order []int
+	for range 2 {
+		gate <- struct{}{}
+		order = append(order, <-released)
+	}
+	if want := []int{1, 0}; !slices.Equal(order, want) {
+		t.Errorf("release order = %v, want %v", order, want)
+	}
+	if shed.Load() != 2 {
+		t.Errorf("shed = %d, want 2", shed.Load())
+	}
+}
+
+func TestRunJob_Throttled(t *testing.T) {
+	var slept []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration, _ <-chan struct{}) bool {
+		slept = append(slept, d)
+		return true
+	}
+	t.Cleanup(func() { sleep = orig })
+
+	mock := &mockClient{}
+	cfg := defaultConfig()
+	cfg.DuplicateGuardSeconds = 0
+	s := newTestScheduler(mock, cfg)
+	s.throttle = newDispatchThrottle(6, 1, 0)
+
+	annotation := testAnnotation()
+	s.runJob(annotation, triggerManual, time.Time{})
+	s.runJob(annotation, triggerManual, time.Time{})
+
+	if mock.dispatchCalls != 2 {
+		t.Errorf("dispatch calls = %d, want 2", mock.dispatchCalls)
+	}
+	if len(slept) != 1 || slept[0] < 9*time.Second || slept[0] > 10*time.Second {
+		t.Errorf("slept = %v, want one wait of about 10s", slept)
+	}
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// Intervals of dispatch verification (replaced in tests).
+var (
+	// runLookupInterval is the wait between attempts to find the created run.
+	runLookupInterval = 10 * time.Second
+	// runLookupAttempts bounds how long a run that never appears is looked for.
+	runLookupAttempts = 6
+	// runPollInterval is the wait between status checks of a found run.
+	runPollInterval = time.Minute
+)
+
+// runCreationSkew tolerates clock differences between ghacron and GitHub when
+// matching a run to its dispatch.
+const runCreationSkew = 5 * time.Second
+
+// verifyDispatch finds the workflow run created by a dispatch and follows it
+// until it completes or the verify timeout passes, recording the outcome in
+// history. It stops early on shutdown.
+func (s *Scheduler) verifyDispatch(ctx context.Context, annotation github.CronAnnotation, ref string, recordID int64, scheduledAt, dispatchedAt time.Time) {
+	timeout := time.Duration(s.config.VerifyTimeoutMinutes) * time.Minute
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	stop := s.drain.done()
+
+	logArgs := append(annotationLogArgs(annotation), "ref", ref)
+
+	run, ok := s.findDispatchedRun(ctx, annotation, ref, dispatchedAt, stop)
+	if !ok {
+		slog.WarnContext(ctx, "workflow run for dispatch not found", logArgs...)
+		s.history.update(recordID, func(r *DispatchRecord) { r.RunStatus = "not_found" })
+		return
+	}
+	s.recordRun(recordID, run)
+	s.recordRunStartDrift(annotation, scheduledAt, run.CreatedAt)
+	if !s.guardsByRuns(annotation) {
+		s.recordStateRun(ctx, annotation, dispatchedAt, run.ID)
+	}
+	logArgs = append(logArgs, "run_id", run.ID)
+	slog.InfoContext(ctx, "dispatched workflow run started", append(logArgs, "run_url", run.HTMLURL)...)
+
+	for !run.IsCompleted() {
+		if !sleep(runPollInterval, stop) || ctx.Err() != nil {
+			return
+		}
+		latest, err := s.client.GetWorkflowRun(ctx, annotation.Owner, annotation.Repo, run.ID)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to get workflow run", append(logArgs, "error", err)...)
+			continue
+		}
+		run = latest
+		s.recordRun(recordID, run)
+	}
+
+	if run.Conclusion == "success" {
+		slog.InfoContext(ctx, "dispatched workflow run succeeded", logArgs...)
+		return
+	}
+	slog.WarnContext(ctx, "dispatched workflow run did not succeed", append(logArgs, "conclusion", run.Conclusion)...)
+}
+
+// findDispatchedRun looks for the workflow run created by the dispatch,
+// retrying while GitHub creates it.
+func (s *Scheduler) findDispatchedRun(ctx context.Context, annotation github.CronAnnotation, ref string, dispatchedAt time.Time, stop <-chan struct{}) (github.WorkflowRun, bool) {
+	since := dispatchedAt.Add(-runCreationSkew)
+	for attempt := 0; attempt < runLookupAttempts; attempt++ {
+		if !sleep(runLookupInterval, stop) || ctx.Err() != nil {
+			return github.WorkflowRun{}, false
+		}
+		run, found, err := s.client.FindDispatchedRun(ctx, annotation.Owner, annotation.Repo, annotation.WorkflowFile, ref, since)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to list workflow runs",
+				append(annotationLogArgs(annotation), "ref", ref, "error", err)...,
+			)
+			continue
+		}
+		if found {
+			return run, true
+		}
+	}
+	return github.WorkflowRun{}, false
+}
+
+// recordStateRun stores the run ID in the persisted dispatch state, unless
+// the state has been replaced by a later dispatch or already has a run (the
+// first run found wins when several refs were dispatched).
+func (s *Scheduler) recordStateRun(ctx context.Context, annotation github.CronAnnotation, dispatchedAt time.Time, runID int64) {
+	sm := s.stateManager()
+	state, err := sm.GetDispatchState(ctx, annotation)
+	if err != nil || state.Outcome != outcomeSuccess || state.RunID != 0 || state.Time.After(dispatchedAt) {
+		return
+	}
+	state.RunID = runID
+	if err := sm.SetDispatchState(ctx, annotation, state); err != nil {
+		slog.WarnContext(ctx, "failed to record run ID in dispatch state",
+			append(annotationLogArgs(annotation), "run_id", runID, "error", err)...,
+		)
+		return
+	}
+	s.rememberDispatchState(annotation.Key(), state)
+}
+
+// recordRun stores the state of a workflow run on its dispatch record.
+func (s *Scheduler) recordRun(recordID int64, run github.WorkflowRun) {
+	s.history.update(recordID, func(r *DispatchRecord) {
+		r.RunID = run.ID
+		r.RunURL = run.HTMLURL
+		r.RunStatus = run.Status
+		r.Conclusion = run.Conclusion
+	})
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/korosuke613/ghacron/github"
+)
+
+// fastVerify makes dispatch verification poll without delay.
+func fastVerify(t *testing.T) {
+	t.Helper()
+	orig := sleep
+	sleep = func(_ time.Duration, cancel <-chan struct{}) bool {
+		select {
+		case <-cancel:
+			return false
+		default:
+			return true
+		}
+	}
+	t.Cleanup(func() { sleep = orig })
+}
+
+func TestVerifyDispatch_RecordsOutcome(t *testing.T) {
+	fastVerify(t)
+
+	dispatchedAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	mock := &mockClient{
+		dispatchedRun: github.WorkflowRun{ID: 2, Status: "in_progress", HTMLURL: "https://example.com/runs/2", CreatedAt: dispatchedAt.Add(time.Second)},
+		run:           github.WorkflowRun{ID: 2, Status: "completed", Conclusion: "failure", HTMLURL: "https://example.com/runs/2"},
+	}
+	cfg := defaultConfig()
+	cfg.VerifyTimeoutMinutes = 1
+	s := newTestScheduler(mock, cfg)
+	annotation := testAnnotation()
+	id := s.recordDispatch(annotation, "main", triggerSchedule, time.Time{}, dispatchedAt, nil)
+
+	s.verifyDispatch(context.Background(), annotation, "main", id, time.Time{}, dispatchedAt)
+
+	rec := s.GetHistory("")[0]
+	if rec.RunID != 2 {
+		t.Errorf("RunID = %d, want 2", rec.RunID)
+	}
+	if rec.RunStatus != "completed" || rec.Conclusion != "failure" {
+		t.Errorf("status/conclusion = %q/%q, want completed/failure", rec.RunStatus, rec.Conclusion)
+	}
+	if rec.RunURL != "https://example.com/runs/2" {
+		t.Errorf("RunURL = %q", rec.RunURL)
+	}
+}
+
+func TestVerifyDispatch_RecordsRunIDInState(t *testing.T) {
+	fastVerify(t)
+
+	dispatchedAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	mock := &mockClient{
+		dispatchedRun: github.WorkflowRun{ID: 7, Status: "completed", Conclusion: "success", CreatedAt: dispatchedAt},
+	}
+	cfg := defaultConfig()
+	cfg.VerifyTimeoutMinutes = 1
+	s := newTestScheduler(mock, cfg)
+	annotation := testAnnotation()
+	if err := s.stateManager().SetLastDispatchTime(context.Background(), annotation, dispatchedAt); err != nil {
+		t.Fatal(err)
+	}
+	id := s.recordDispatch(annotation, "main", triggerSchedule, time.Time{}, dispatchedAt, nil)
+
+	s.verifyDispatch(context.Background(), annotation, "main", id, time.Time{}, dispatchedAt)
+
+	state, err := s.stateManager().GetDispatchState(context.Background(), annotation)
+	if err != nil || state.RunID != 7 {
+		t.Errorf("persisted state = %+v (%v), want run ID 7", state, err)
+	}
+}
+
+func TestVerifyDispatch_RunNotFound(t *testing.T) {
+	fastVerify(t)
+
+	cfg := defaultConfig()
+	cfg.VerifyTimeoutMinutes = 1
+	s := newTestScheduler(&mockClient{}, cfg)
+	annotation := testAnnotation()
+	now := time.Now()
+	id := s.recordDispatch(annotation, "main", triggerSchedule, time.Time{}, now, nil)
+
+	s.verifyDispatch(context.Background(), annotation, "main", id, time.Time{}, now)
+
+	if rec := s.GetHistory("")[0]; rec.RunStatus != "not_found" {
+		t.Errorf("RunStatus = %q, want not_found", rec.RunStatus)
+	}
+}
@@ -0,0 +1,70 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Endpoints of GCP Secret Manager and the metadata server (replaced in tests).
+var (
+	gcpEndpoint      = "https://secretmanager.googleapis.com/v1/"
+	gcpMetadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// fetchGCP reads a secret version from GCP Secret Manager. name is
+// "projects/<p>/secrets/<s>", optionally followed by "/versions/<v>" (latest
+// by default). The access token is taken from GOOGLE_OAUTH_ACCESS_TOKEN, or
+// from the metadata server of the instance (GCE, GKE Workload Identity, Cloud
+// Run).
+func fetchGCP(ctx context.Context, name string) (string, error) {
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	token, err := gcpAccessToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get GCP access token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpEndpoint+name+":access", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCP Secret Manager request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var result struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := doJSON(req, &result); err != nil {
+		return "", fmt.Errorf("failed to access secret %s in GCP Secret Manager: %w", name, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+func gcpAccessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataToken, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(req, &result); err != nil {
+		return "", err
+	}
+	return result.AccessToken, nil
+}
@@ -0,0 +1,108 @@
+// Package secrets resolves secret references such as
+// "awssm://ghacron/private-key" to their values in an external secret
+// manager, so secrets do not have to be passed in environment variables or
+// files.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// httpClient fetches secrets; requests are bounded by the caller's context.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// credentialEnv are the environment variables holding the credentials the
+// secret managers are accessed with.
+var credentialEnv = []string{"VAULT_TOKEN", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "GOOGLE_OAUTH_ACCESS_TOKEN"}
+
+// Credentials returns the secret manager credentials set in the environment,
+// to be kept out of log output.
+func Credentials() []string {
+	var values []string
+	for _, key := range credentialEnv {
+		if v := os.Getenv(key); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// IsRef reports whether value is a secret reference rather than a literal
+// secret.
+func IsRef(value string) bool {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return false
+	}
+	switch scheme {
+	case "awssm", "gcpsm", "vault":
+		return true
+	}
+	return false
+}
+
+// Resolve returns the secret referenced by ref, or ref itself if it is not a
+// secret reference. Supported references:
+//
+//	awssm://<secret-id>[#<json-key>]                          AWS Secrets Manager
+//	gcpsm://projects/<p>/secrets/<s>[/versions/<v>]           GCP Secret Manager
+//	vault://<path>#<key>                                      HashiCorp Vault (KV v1 or v2)
+func Resolve(ctx context.Context, ref string) (string, error) {
+	if !IsRef(ref) {
+		return ref, nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret reference: %w", err)
+	}
+	// The host is the first path segment of the reference.
+	name := strings.TrimSuffix(u.Host+u.Path, "/")
+	if name == "" {
+		return "", fmt.Errorf("invalid secret reference %s: missing secret name", redact(u))
+	}
+
+	var value string
+	switch u.Scheme {
+	case "awssm":
+		value, err = fetchAWS(ctx, name)
+	case "gcpsm":
+		value, err = fetchGCP(ctx, name)
+	case "vault":
+		if u.Fragment == "" {
+			return "", fmt.Errorf("invalid secret reference %s: missing #<key>", redact(u))
+		}
+		return fetchVault(ctx, name, u.Fragment)
+	}
+	if err != nil {
+		return "", err
+	}
+	if u.Fragment != "" {
+		return jsonField(value, u.Fragment)
+	}
+	return value, nil
+}
+
+// jsonField returns a string field of a secret holding a JSON object.
+func jsonField(secret, key string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, cannot select key %q", key)
+	}
+	v, ok := fields[key].(string)
+	if !ok {
+		return "", fmt.Errorf("secret has no string key %q", key)
+	}
+	return v, nil
+}
+
+// redact returns the reference without its fragment, for error messages.
+func redact(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + u.Path
+}
@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// fetchVault reads a key of a secret from HashiCorp Vault, addressed by
+// VAULT_ADDR and authenticated with VAULT_TOKEN (and VAULT_NAMESPACE, if
+// set). path is the API path of the secret, e.g. "secret/data/ghacron" for
+// the KV v2 engine mounted at secret/.
+func fetchVault(ctx context.Context, path, key string) (string, error) {
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("vault requires VAULT_ADDR and VAULT_TOKEN")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	var result struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := doJSON(req, &result); err != nil {
+		return "", fmt.Errorf("failed to read secret %s from Vault: %w", path, err)
+	}
+
+	data := result.Data
+	// KV v2 nests the secret under data.data.
+	if nested, ok := data["data"]; ok && data["metadata"] != nil {
+		if err := json.Unmarshal(nested, &data); err != nil {
+			return "", fmt.Errorf("unexpected Vault response for %s: %w", path, err)
+		}
+	}
+	var value string
+	if raw, ok := data[key]; !ok || json.Unmarshal(raw, &value) != nil {
+		return "", fmt.Errorf("secret %s has no string key %q", path, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,208 @@
+// Package sentry reports errors to Sentry, or a service accepting Sentry
+// envelopes (e.g. GlitchTip), without depending on the Sentry SDK.
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sendTimeout bounds a single envelope request.
+const sendTimeout = 10 * time.Second
+
+// Levels of events.
+const (
+	LevelError   = "error"
+	LevelFatal   = "fatal"
+	LevelWarning = "warning"
+)
+
+// Event is an error report.
+type Event struct {
+	Level   string
+	Message string
+	// Err, if set, is reported as the exception of the event.
+	Err error
+	// Stack is an optional stack trace (e.g. of a recovered panic).
+	Stack string
+	// Tags are indexed by Sentry for searching; Extra is additional context.
+	Tags  map[string]string
+	Extra map[string]any
+	// Fingerprint groups events into issues (default: by message).
+	Fingerprint []string
+}
+
+// Client sends events to the project of a DSN in the background.
+type Client struct {
+	envelopeURL string
+	dsn         string
+	publicKey   string
+	environment string
+	release     string
+	serverName  string
+	httpClient  *http.Client
+
+	wg sync.WaitGroup
+}
+
+// New creates a client for a DSN of the form
+// "https://<public key>@<host>/<project id>".
+func New(dsn, environment, release string) (*Client, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	projectID := strings.TrimPrefix(u.Path, "/")
+	if (u.Scheme != "http" && u.Scheme != "https") || u.User == nil || u.User.Username() == "" || projectID == "" {
+		return nil, errors.New("invalid DSN: expected https://<public key>@<host>/<project id>")
+	}
+	// Sentry instances under a path prefix put it before the project ID.
+	prefix := ""
+	if i := strings.LastIndex(projectID, "/"); i >= 0 {
+		prefix, projectID = "/"+projectID[:i], projectID[i+1:]
+	}
+	serverName, _ := os.Hostname()
+	return &Client{
+		envelopeURL: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, projectID),
+		dsn:         dsn,
+		publicKey:   u.User.Username(),
+		environment: environment,
+		release:     release,
+		serverName:  serverName,
+		httpClient:  &http.Client{Timeout: sendTimeout},
+	}, nil
+}
+
+// Capture sends an event without waiting for the response. It is a no-op on
+// a nil Client.
+func (c *Client) Capture(event Event) {
+	if c == nil {
+		return
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		if err := c.send(ctx, event, time.Now()); err != nil {
+			slog.Warn("failed to report error to Sentry", "message", event.Message, "error", err)
+		}
+	}()
+}
+
+// Flush waits until captured events are sent, or the timeout elapses.
+func (c *Client) Flush(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("timed out sending error reports to Sentry")
+	}
+}
+
+// send posts an event as an envelope.
+func (c *Client) send(ctx context.Context, event Event, now time.Time) error {
+	body, err := c.envelope(event, now)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.envelopeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=ghacron, sentry_key=%s", c.publicKey))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// envelope encodes an event as an envelope: a header line, an item header
+// line and the event payload.
+func (c *Client) envelope(event Event, now time.Time) ([]byte, error) {
+	id, err := eventID()
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]any{
+		"event_id":    id,
+		"timestamp":   now.UTC().Format(time.RFC3339Nano),
+		"platform":    "go",
+		"logger":      "ghacron",
+		"level":       event.Level,
+		"message":     map[string]string{"formatted": event.Message},
+		"server_name": c.serverName,
+	}
+	if c.environment != "" {
+		payload["environment"] = c.environment
+	}
+	if c.release != "" {
+		payload["release"] = c.release
+	}
+	if len(event.Tags) > 0 {
+		payload["tags"] = event.Tags
+	}
+	extra := map[string]any{}
+	for k, v := range event.Extra {
+		extra[k] = v
+	}
+	if event.Stack != "" {
+		extra["stack"] = event.Stack
+	}
+	if len(extra) > 0 {
+		payload["extra"] = extra
+	}
+	if len(event.Fingerprint) > 0 {
+		payload["fingerprint"] = event.Fingerprint
+	}
+	if event.Err != nil {
+		payload["exception"] = map[string]any{
+			"values": []map[string]string{{"type": fmt.Sprintf("%T", event.Err), "value": event.Err.Error()}},
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // Encode terminates each line with '\n'
+	for _, v := range []any{
+		map[string]string{"event_id": id, "dsn": c.dsn, "sent_at": now.UTC().Format(time.RFC3339Nano)},
+		map[string]string{"type": "event"},
+		payload,
+	} {
+		if err := enc.Encode(v); err != nil {
+			return nil, fmt.Errorf("failed to encode event: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// eventID returns a random event ID (a UUID in hex without dashes).
+func eventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate event ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,105 @@
+package sentry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew_DSN(t *testing.T) {
+	tests := map[string]string{
+		"https://key@o1.ingest.sentry.io/42":  "https://o1.ingest.sentry.io/api/42/envelope/",
+		"http://key@sentry.internal/sentry/7": "http://sentry.internal/sentry/api/7/envelope/",
+	}
+	for dsn, want := range tests {
+		c, err := New(dsn, "", "")
+		if err != nil {
+			t.Fatalf("New(%q): %v", dsn, err)
+		}
+		if c.envelopeURL != want || c.publicKey != "key" {
+			t.Errorf("New(%q): envelope URL %q, key %q; want %q, key", dsn, c.envelopeURL, c.publicKey, want)
+		}
+	}
+
+	for _, dsn := range []string{"", "https://sentry.io/42", "https://key@sentry.io/", "ftp://key@sentry.io/42"} {
+		if _, err := New(dsn, "", ""); err == nil {
+			t.Errorf("New(%q): expected error", dsn)
+		}
+	}
+}
+
+func TestCapture(t *testing.T) {
+	var auth string
+	var lines [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/envelope/" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		auth = r.Header.Get("X-Sentry-Auth")
+		body, _ := io.ReadAll(r.Body)
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			lines = append(lines, bytes.Clone(scanner.Bytes()))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(strings.Replace(srv.URL, "http://", "http://pubkey@", 1)+"/42", "production", "v1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Capture(Event{
+		Level:       LevelError,
+		Message:     "dispatch failing",
+		Err:         errors.New("status=404"),
+		Stack:       "goroutine 1",
+		Tags:        map[string]string{"owner": "o"},
+		Fingerprint: []string{"dispatch", "job1"},
+	})
+	c.Flush(5 * time.Second)
+
+	if !strings.Contains(auth, "sentry_key=pubkey") {
+		t.Errorf("X-Sentry-Auth = %q, want the public key", auth)
+	}
+	if len(lines) != 3 || string(lines[1]) != `{"type":"event"}` {
+		t.Fatalf("envelope lines = %q, want header, item header and event", lines)
+	}
+	var event struct {
+		EventID     string            `json:"event_id"`
+		Level       string            `json:"level"`
+		Environment string            `json:"environment"`
+		Release     string            `json:"release"`
+		Tags        map[string]string `json:"tags"`
+		Extra       map[string]string `json:"extra"`
+		Fingerprint []string          `json:"fingerprint"`
+		Exception   struct {
+			Values []struct {
+				Value string `json:"value"`
+			} `json:"values"`
+		} `json:"exception"`
+	}
+	if err := json.Unmarshal(lines[2], &event); err != nil {
+		t.Fatal(err)
+	}
+	if len(event.EventID) != 32 || event.Level != "error" || event.Environment != "production" || event.Release != "v1.2.3" {
+		t.Errorf("event = %+v", event)
+	}
+	if event.Tags["owner"] != "o" || event.Extra["stack"] != "goroutine 1" || len(event.Fingerprint) != 2 {
+		t.Errorf("event context = %+v", event)
+	}
+	if len(event.Exception.Values) != 1 || event.Exception.Values[0].Value != "status=404" {
+		t.Errorf("exception = %+v", event.Exception)
+	}
+}
+
+func TestCapture_Nil(t *testing.T) {
+	var c *Client
+	c.Capture(Event{Message: "ignored"}) // must not panic
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/korosuke613/ghacron/api"
+	"github.com/korosuke613/ghacron/config"
+	"github.com/korosuke613/ghacron/github"
+	"github.com/korosuke613/ghacron/logging"
+	"github.com/korosuke613/ghacron/notify"
+	"github.com/korosuke613/ghacron/scheduler"
+	"github.com/korosuke613/ghacron/sentry"
+	"github.com/korosuke613/ghacron/tracing"
+)
+
+// serve runs the scheduler and the web API until a shutdown signal.
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	showVersion := fs.Bool("version", false, "show version")
+	configPath := configFlag(fs)
+	_ = fs.Parse(args)
+
+	if *showVersion {
+		fmt.Printf("ghacron v%s\n", version)
+		return
+	}
+
+	cfg := loadConfig(*configPath, os.Stdout)
+
+	slog.Info("starting ghacron", "version", version)
+
+	if err := cfg.ResolveSecrets(context.Background()); err != nil {
+		slog.Error("failed to resolve secrets", "error", err)
+		os.Exit(1)
+	}
+	logging.RegisterSecrets(cfg.WebAPI.WebhookSecret)
+
+	if cfg.Tracing.Endpoint != "" {
+		tracer := tracing.Init(cfg.Tracing.Endpoint, "ghacron", version)
+		defer shutdownTracing(tracer)
+		slog.Info("exporting traces", "endpoint", cfg.Tracing.Endpoint)
+	}
+
+	ghClient := newGitHubClient(cfg)
+
+	sched, stopScheduler := newScheduler(cfg, ghClient)
+
+	// Initialize and start API server
+	apiServer := api.NewServer(&cfg.WebAPI, cfg)
+	apiServer.SetStatusProvider(sched)
+	apiServer.SetJobController(sched)
+	apiServer.SetRepoReconciler(sched)
+	apiServer.SetPushChecker(sched)
+	apiServer.SetRunRecorder(sched)
+	apiServer.SetReconciler(sched)
+	apiServer.SetRateLimitProvider(ghClient)
+	apiServer.SetRepoListCache(ghClient)
+	apiServer.SetAuthStatusProvider(ghClient)
+	apiServer.SetConnectivityChecker(ghClient)
+	apiServer.SetBuildInfo(buildInfo())
+	if err := apiServer.Start(); err != nil {
+		slog.Error("failed to start API server", "error", err)
+		os.Exit(1)
+	}
+
+	// Start reconciliation loop
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := sched.RestoreSnapshot(ctx); err != nil {
+			slog.Error("failed to restore job snapshot, waiting for the first reconcile", "error", err)
+		}
+		sched.RunReconcileLoop(ctx, time.Duration(cfg.Reconcile.IntervalMinutes)*time.Minute)
+	}()
+	go sched.RunDeadmanLoop(ctx)
+	if cfg.GitHub.RateLimitPollSeconds > 0 {
+		go ghClient.PollRateLimit(ctx, time.Duration(cfg.GitHub.RateLimitPollSeconds)*time.Second)
+	}
+
+	slog.Info("ghacron started",
+		"interval_minutes", cfg.Reconcile.IntervalMinutes,
+		"duplicate_guard_seconds", cfg.Reconcile.DuplicateGuardSeconds,
+		"dry_run", cfg.Reconcile.DryRun,
+	)
+
+	// Wait for shutdown signal; SIGHUP reloads the private key.
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	for sig := range sigChan {
+		if sig == syscall.SIGHUP {
+			reloadPrivateKey(cfg, ghClient)
+			continue
+		}
+		slog.Info("received signal, shutting down", "signal", sig.String())
+		break
+	}
+
+	cancel()
+	stopScheduler()
+	apiServer.Stop()
+
+	slog.Info("ghacron stopped")
+}
+
+// newScheduler creates the scheduler with the audit log, persistent history,
+// error reporting and notifications enabled by cfg, exiting on failure. The
+// returned function stops the scheduler and sends pending notifications and
+// error reports.
+func newScheduler(cfg *config.Config, ghClient *github.Client) (*scheduler.Scheduler, func()) {
+	loc, err := time.LoadLocation(cfg.Reconcile.Timezone)
+	if err != nil {
+		slog.Error("failed to load timezone", "error", err)
+		os.Exit(1)
+	}
+
+	sched := scheduler.New(ghClient, &cfg.Reconcile, loc)
+	sched.SetRateLimitProvider(ghClient)
+	var audit *scheduler.AuditLog
+	if cfg.Log.AuditPath != "" {
+		audit, err = scheduler.OpenAuditLog(cfg.Log.AuditPath)
+		if err != nil {
+			slog.Error("failed to initialize audit log", "error", err)
+			os.Exit(1)
+		}
+		sched.SetAuditLog(audit)
+		slog.Info("writing audit log", "path", cfg.Log.AuditPath)
+	}
+	var history *scheduler.HistoryStore
+	if cfg.Reconcile.HistoryPath != "" {
+		history, err = scheduler.OpenHistoryStore(cfg.Reconcile.HistoryPath, time.Duration(cfg.Reconcile.HistoryRetentionDays)*24*time.Hour)
+		if err != nil {
+			slog.Error("failed to initialize dispatch history", "error", err)
+			os.Exit(1)
+		}
+		sched.SetHistoryStore(history)
+		slog.Info("persisting dispatch history", "path", cfg.Reconcile.HistoryPath, "retention_days", cfg.Reconcile.HistoryRetentionDays)
+	}
+	var reporter *sentry.Client
+	if cfg.Sentry.DSN != "" {
+		reporter, err = sentry.New(cfg.Sentry.DSN, cfg.Sentry.Environment, version)
+		if err != nil {
+			slog.Error("failed to initialize Sentry", "error", err)
+			os.Exit(1)
+		}
+		sched.SetErrorReporter(reporter, cfg.Sentry.DispatchFailureThreshold)
+	}
+	var notifier *notify.Notifier
+	if cfg.Notify.Enabled() {
+		notifier = newNotifier(&cfg.Notify)
+		sched.SetNotifier(notifier)
+	}
+
+	return sched, func() {
+		sched.Stop()
+		if notifier != nil {
+			notifier.Wait(flushTimeout)
+		}
+		if reporter != nil {
+			reporter.Flush(flushTimeout)
+		}
+		if audit != nil {
+			audit.Close()
+		}
+		if history != nil {
+			history.Close()
+		}
+	}
+}
+
+// flushTimeout bounds how long shutdown waits for pending notifications and
+// error reports to be sent.
+const flushTimeout = 10 * time.Second
+
+// newNotifier creates a Notifier posting to the configured webhooks.
+func newNotifier(cfg *config.NotifyConfig) *notify.Notifier {
+	var targets []notify.Target
+	for _, t := range []notify.Target{
+		{Kind: notify.TargetSlack, URL: cfg.SlackWebhookURL},
+		{Kind: notify.TargetDiscord, URL: cfg.DiscordWebhookURL},
+		{Kind: notify.TargetWebhook, URL: cfg.WebhookURL},
+	} {
+		if t.URL != "" {
+			targets = append(targets, t)
+		}
+	}
+	// The severity is validated by config.
+	minSeverity, _ := notify.ParseSeverity(cfg.MinSeverity)
+	return notify.New(targets, minSeverity, cfg.Repos)
+}
+
+// tracingShutdownTimeout bounds how long shutdown waits for the last spans to
+// be exported.
+const tracingShutdownTimeout = 5 * time.Second
+
+// shutdownTracing exports the remaining spans.
+func shutdownTracing(tracer *tracing.Tracer) {
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	tracer.Shutdown(ctx)
+}
+
+// reloadPrivateKey re-reads the App private key and swaps it into the client.
+// On failure the current key is kept.
+func reloadPrivateKey(cfg *config.Config, ghClient *github.Client) {
+	privateKey, err := cfg.GetPrivateKey(context.Background())
+	if err == nil {
+		err = ghClient.ReloadPrivateKey(privateKey)
+	}
+	if err != nil {
+		slog.Error("failed to reload private key, keeping the current key", "error", err)
+		return
+	}
+	slog.Info("reloaded private key")
+}
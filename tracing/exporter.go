@@ -0,0 +1,224 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// exportInterval is how often queued spans are exported.
+	exportInterval = 5 * time.Second
+	// maxBatchSize triggers an export before the interval elapses.
+	maxBatchSize = 512
+	// maxQueueSize bounds the spans held while the collector is unreachable;
+	// further spans are dropped.
+	maxQueueSize = 4096
+)
+
+// Tracer batches ended spans and exports them to an OTLP/HTTP endpoint.
+type Tracer struct {
+	url        string
+	resource   []Attr
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	queue   []spanData
+	dropped int
+
+	flush chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// Init starts exporting spans to the OTLP/HTTP endpoint (e.g.
+// "http://otel-collector:4318"; spans are posted to /v1/traces) and makes
+// the tracer the one used by Start. Stop it with Shutdown.
+func Init(endpoint, serviceName, serviceVersion string) *Tracer {
+	t := &Tracer{
+		url: strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		resource: []Attr{
+			String("service.name", serviceName),
+			String("service.version", serviceVersion),
+		},
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		flush:      make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go t.run()
+	defaultTracer.Store(t)
+	return t
+}
+
+// Shutdown stops recording spans and exports the queued ones, waiting until
+// ctx is done at most.
+func (t *Tracer) Shutdown(ctx context.Context) {
+	defaultTracer.CompareAndSwap(t, nil)
+	close(t.stop)
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+	}
+}
+
+func (t *Tracer) enqueue(span spanData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.queue) >= maxQueueSize {
+		t.dropped++
+		return
+	}
+	t.queue = append(t.queue, span)
+	if len(t.queue) >= maxBatchSize {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports queued spans periodically until Shutdown.
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			t.exportQueued()
+			return
+		case <-ticker.C:
+		case <-t.flush:
+		}
+		t.exportQueued()
+	}
+}
+
+// exportQueued exports all queued spans in batches. Failed batches are
+// dropped.
+func (t *Tracer) exportQueued() {
+	t.mu.Lock()
+	queue, dropped := t.queue, t.dropped
+	t.queue, t.dropped = nil, 0
+	t.mu.Unlock()
+
+	if dropped > 0 {
+		slog.Warn("trace queue full, dropped spans", "dropped_count", dropped)
+	}
+	for start := 0; start < len(queue); start += maxBatchSize {
+		batch := queue[start:min(start+maxBatchSize, len(queue))]
+		if err := t.export(batch); err != nil {
+			slog.Warn("failed to export spans", "span_count", len(batch), "error", err)
+		}
+	}
+}
+
+// export posts a batch of spans as an OTLP ExportTraceServiceRequest.
+func (t *Tracer) export(spans []spanData) error {
+	body, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+	resp, err := t.httpClient.Post(t.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status=%d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// OTLP JSON encoding of ExportTraceServiceRequest.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttr `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string     `json:"traceId"`
+		SpanID            string     `json:"spanId"`
+		ParentSpanID      string     `json:"parentSpanId,omitempty"`
+		Name              string     `json:"name"`
+		Kind              int        `json:"kind"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+		Attributes        []otlpAttr `json:"attributes,omitempty"`
+		Status            otlpStatus `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttr struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+)
+
+func (t *Tracer) request(spans []spanData) otlpRequest {
+	encoded := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		encoded[i] = otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        encodeAttrs(s.attrs),
+			Status:            otlpStatus{Code: s.statusCode, Message: s.statusMsg},
+		}
+		if s.parentID != [8]byte{} {
+			encoded[i].ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: encodeAttrs(t.resource)},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "ghacron"}, Spans: encoded}},
+	}}}
+}
+
+func encodeAttrs(attrs []Attr) []otlpAttr {
+	encoded := make([]otlpAttr, 0, len(attrs))
+	for _, a := range attrs {
+		var value map[string]any
+		switch v := a.Value.(type) {
+		case int64:
+			// 64-bit integers are strings in the OTLP JSON encoding.
+			value = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+		case bool:
+			value = map[string]any{"boolValue": v}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, otlpAttr{Key: a.Key, Value: value})
+	}
+	return encoded
+}
@@ -0,0 +1,161 @@
+// Package tracing records spans of reconciles, repository scans, dispatches
+// and GitHub API calls, and exports them to an OpenTelemetry collector with
+// OTLP/HTTP (JSON encoding). It is a minimal dependency-free tracer: spans are
+// only recorded after Init, and every function is a no-op before.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Span kinds (OTLP SpanKind).
+const (
+	kindInternal = 1
+	kindClient   = 3
+)
+
+// statusError is the OTLP StatusCode of failed spans.
+const statusError = 2
+
+// Attr is a span attribute with a string, int64 or bool value.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr { return Attr{Key: key, Value: value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr { return Attr{Key: key, Value: int64(value)} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attr { return Attr{Key: key, Value: value} }
+
+// Span is an operation being traced. A nil *Span is valid and records
+// nothing, so callers do not need to check whether tracing is enabled.
+type Span struct {
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+
+	mu         sync.Mutex
+	attrs      []Attr
+	statusCode int
+	statusMsg  string
+	ended      bool
+}
+
+// defaultTracer is the tracer used by Start (nil = tracing disabled).
+var defaultTracer atomic.Pointer[Tracer]
+
+type spanKey struct{}
+
+// Start starts a span as a child of the span in ctx, if any, and returns a
+// context carrying it. The span must be ended with End.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return start(ctx, name, kindInternal, attrs)
+}
+
+// StartClient starts a span for an outgoing request, like Start.
+func StartClient(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return start(ctx, name, kindClient, attrs)
+}
+
+func start(ctx context.Context, name string, kind int, attrs []Attr) (context.Context, *Span) {
+	tracer := defaultTracer.Load()
+	if tracer == nil {
+		return ctx, nil
+	}
+	span := &Span{
+		tracer: tracer,
+		name:   name,
+		kind:   kind,
+		start:  time.Now(),
+		attrs:  attrs,
+	}
+	if parent := FromContext(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		_, _ = rand.Read(span.traceID[:])
+	}
+	_, _ = rand.Read(span.spanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FromContext returns the span carried by ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks the span as failed with the error's message. A nil error is
+// ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCode = statusError
+	s.statusMsg = err.Error()
+}
+
+// End completes the span and queues it for export. Later calls are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := spanData{
+		traceID:    s.traceID,
+		spanID:     s.spanID,
+		parentID:   s.parentID,
+		name:       s.name,
+		kind:       s.kind,
+		start:      s.start,
+		end:        time.Now(),
+		attrs:      s.attrs,
+		statusCode: s.statusCode,
+		statusMsg:  s.statusMsg,
+	}
+	s.mu.Unlock()
+	s.tracer.enqueue(data)
+}
+
+// spanData is an ended span waiting for export.
+type spanData struct {
+	traceID    [16]byte
+	spanID     [8]byte
+	parentID   [8]byte
+	name       string
+	kind       int
+	start, end time.Time
+	attrs      []Attr
+	statusCode int
+	statusMsg  string
+}
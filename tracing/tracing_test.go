@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestStart_Disabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "reconcile")
+	if span != nil || FromContext(ctx) != nil {
+		t.Fatal("Start recorded a span without Init")
+	}
+	// Methods of a nil span are no-ops.
+	span.SetAttributes(String("k", "v"))
+	span.SetError(errors.New("failed"))
+	span.End()
+}
+
+func TestTracer_Export(t *testing.T) {
+	var mu sync.Mutex
+	var got otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		got.ResourceSpans = append(got.ResourceSpans, req.ResourceSpans...)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	tracer := Init(server.URL+"/", "ghacron", "1.2.3")
+	ctx, parent := Start(context.Background(), "reconcile", Int("repo_count", 2))
+	_, child := StartClient(ctx, "GET scan", String("http.request.method", "GET"))
+	child.SetError(errors.New("status=500"))
+	child.End()
+	parent.End()
+	parent.End() // ignored
+	tracer.Shutdown(context.Background())
+
+	if _, span := Start(context.Background(), "after shutdown"); span != nil {
+		t.Error("Start recorded a span after Shutdown")
+	}
+
+	if len(got.ResourceSpans) != 1 {
+		t.Fatalf("resource spans = %d, want 1", len(got.ResourceSpans))
+	}
+	rs := got.ResourceSpans[0]
+	if attr := rs.Resource.Attributes[0]; attr.Key != "service.name" || attr.Value["stringValue"] != "ghacron" {
+		t.Errorf("resource attribute = %+v, want service.name=ghacron", attr)
+	}
+	spans := rs.ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	c, p := spans[0], spans[1]
+	if c.Name != "GET scan" || p.Name != "reconcile" {
+		t.Fatalf("span names = %q, %q", c.Name, p.Name)
+	}
+	if c.TraceID != p.TraceID || c.ParentSpanID != p.SpanID || p.ParentSpanID != "" {
+		t.Errorf("child %+v is not a child of %+v", c, p)
+	}
+	if len(p.TraceID) != 32 || len(p.SpanID) != 16 {
+		t.Errorf("trace ID %q, span ID %q: want hex-encoded IDs", p.TraceID, p.SpanID)
+	}
+	if c.Kind != kindClient || p.Kind != kindInternal {
+		t.Errorf("kinds = %d, %d; want client, internal", c.Kind, p.Kind)
+	}
+	if c.Status.Code != statusError || c.Status.Message != "status=500" {
+		t.Errorf("child status = %+v, want error", c.Status)
+	}
+	if attr := p.Attributes[0]; attr.Key != "repo_count" || attr.Value["intValue"] != "2" {
+		t.Errorf("parent attribute = %+v, want repo_count=2", attr)
+	}
+}